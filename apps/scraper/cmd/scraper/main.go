@@ -10,6 +10,7 @@ import (
 func main() {
 	app := cli.NewApp()
 	if err := app.Run(os.Args); err != nil {
-		log.Fatal(err)
+		log.Print(err)
+		os.Exit(cli.ExitCode(err))
 	}
 }
@@ -1,66 +1,267 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/utils"
 )
 
+// DefaultEMSCEventsURL is the EMSC FDSN event service used for earthquake data
+const DefaultEMSCEventsURL = "https://www.seismicportal.eu/fdsnws/event/1"
+
+// emscEventURL links to the EMSC page of an event by its unique ID
+const emscEventURL = "https://www.seismicportal.eu/eventdetails.html?unid="
+
 // EMSCClient handles communication with the EMSC-CSEM API
 type EMSCClient struct {
 	baseURL    string
+	eventsURL  string
+	userAgent  string
+	limiter    *utils.ConcurrencyLimiter
+	logger     *utils.Logger
 	httpClient *http.Client
 }
 
 // NewEMSCClient creates a new EMSC API client
 func NewEMSCClient(baseURL string, timeout time.Duration) *EMSCClient {
 	return &EMSCClient{
-		baseURL: baseURL,
+		baseURL:   baseURL,
+		eventsURL: DefaultEMSCEventsURL,
+		userAgent: DefaultUserAgent,
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
 	}
 }
 
+// SetEventsURL sets the base URL of the EMSC earthquake event service
+func (c *EMSCClient) SetEventsURL(eventsURL string) {
+	c.eventsURL = eventsURL
+}
+
+// SetUserAgent sets the User-Agent header sent with requests (empty keeps DefaultUserAgent)
+func (c *EMSCClient) SetUserAgent(userAgent string) {
+	if userAgent != "" {
+		c.userAgent = userAgent
+	}
+}
+
+// SetConcurrencyLimiter makes each request wait for a free slot in limiter, which may be
+// shared with other clients to bound the total number of requests in flight
+func (c *EMSCClient) SetConcurrencyLimiter(limiter *utils.ConcurrencyLimiter) {
+	c.limiter = limiter
+}
+
+// SetLogger sets the logger that receives each request's DNS, connect, TLS, first byte and
+// total timings when it is at debug level
+func (c *EMSCClient) SetLogger(logger *utils.Logger) {
+	c.logger = logger
+}
+
 // GetFaults fetches fault data from EMSC API
-func (c *EMSCClient) GetFaults() (*models.Fault, error) {
-	resp, err := c.httpClient.Get(c.baseURL + "/gem_active_faults.geojson")
+func (c *EMSCClient) GetFaults(ctx context.Context) (*models.Fault, error) {
+	req, err := newGetRequest(ctx, c.baseURL+"/gem_active_faults.geojson", c.userAgent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := doRequest(c.httpClient, c.limiter, c.logger, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make HTTP request: %w", err)
+		return nil, utils.ClassifyRequestError(fmt.Errorf("failed to make HTTP request: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+		err := fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+
+		// Rate limiting and maintenance responses may say when to come back
+		if retryAfter, ok := utils.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return nil, utils.ClassifyStatus(resp.StatusCode, &utils.RetryAfterError{Err: err, RetryAfter: retryAfter})
+		}
+		return nil, utils.ClassifyStatus(resp.StatusCode, err)
 	}
 
 	var faults models.Fault
 	if err := json.NewDecoder(resp.Body).Decode(&faults); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, utils.NewCollectionError(utils.ErrorTypeDecode, false, fmt.Errorf("failed to decode response: %w", err))
 	}
 
 	return &faults, nil
 }
 
-// GetFaultsWithRetry fetches fault data with retry logic
-func (c *EMSCClient) GetFaultsWithRetry(maxRetries int, retryDelay time.Duration) (*models.Fault, error) {
-	var lastErr error
+// GetFaultsWithRetry fetches fault data, retrying failures according to the given strategy
+func (c *EMSCClient) GetFaultsWithRetry(ctx context.Context, strategy *utils.RetryStrategy) (*models.Fault, error) {
+	var faults *models.Fault
 
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		faults, err := c.GetFaults()
-		if err == nil {
-			return faults, nil
+	err := utils.RetryWithBackoff(ctx, strategy, func() error {
+		var err error
+		faults, err = c.GetFaults(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch faults: %w", err)
+	}
+
+	return faults, nil
+}
+
+// emscResponse is the GeoJSON returned by the EMSC FDSN event service
+type emscResponse struct {
+	Type     string        `json:"type"`
+	Features []emscFeature `json:"features"`
+}
+
+// emscFeature is a single EMSC event
+type emscFeature struct {
+	ID         string         `json:"id"`
+	Properties emscProperties `json:"properties"`
+}
+
+// emscProperties holds the event fields of an EMSC feature
+type emscProperties struct {
+	UnID          string  `json:"unid"`
+	SourceID      string  `json:"source_id"`
+	SourceCatalog string  `json:"source_catalog"`
+	Time          string  `json:"time"`
+	LastUpdate    string  `json:"lastupdate"`
+	FlynnRegion   string  `json:"flynn_region"`
+	Lat           float64 `json:"lat"`
+	Lon           float64 `json:"lon"`
+	Depth         float64 `json:"depth"`
+	EvType        string  `json:"evtype"`
+	Auth          string  `json:"auth"`
+	Mag           float64 `json:"mag"`
+	MagType       string  `json:"magtype"`
+}
+
+// GetRecentEarthquakes fetches earthquakes from the last hour from the EMSC event service
+func (c *EMSCClient) GetRecentEarthquakes(ctx context.Context, limit int) (*models.USGSResponse, error) {
+	u, err := url.Parse(c.eventsURL + "/query")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("format", "json")
+	q.Set("start", time.Now().UTC().Add(-1*time.Hour).Format("2006-01-02T15:04:05"))
+	q.Set("limit", strconv.Itoa(limit))
+	q.Set("orderby", "time")
+	u.RawQuery = q.Encode()
+
+	req, err := newGetRequest(ctx, u.String(), c.userAgent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := doRequest(c.httpClient, c.limiter, c.logger, req)
+	if err != nil {
+		return nil, utils.ClassifyRequestError(fmt.Errorf("failed to make HTTP request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	// The FDSN service answers 204 when no events match
+	if resp.StatusCode == http.StatusNoContent {
+		return &models.USGSResponse{Type: "FeatureCollection", Features: []models.Earthquake{}}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, utils.ClassifyStatus(resp.StatusCode, fmt.Errorf("API request failed with status: %d", resp.StatusCode))
+	}
+
+	earthquakes, err := ParseEMSCEarthquakes(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	earthquakes.Metadata.URL = u.String()
+
+	return earthquakes, nil
+}
+
+// ParseEMSCEarthquakes decodes an EMSC event response into the USGS earthquake structure
+func ParseEMSCEarthquakes(r io.Reader) (*models.USGSResponse, error) {
+	var response emscResponse
+	if err := json.NewDecoder(r).Decode(&response); err != nil {
+		return nil, utils.NewCollectionError(utils.ErrorTypeDecode, false, fmt.Errorf("failed to decode response: %w", err))
+	}
+
+	earthquakes := &models.USGSResponse{
+		Type: "FeatureCollection",
+		Metadata: models.Metadata{
+			Generated: time.Now().UnixMilli(),
+			Title:     "EMSC Earthquakes",
+			Status:    http.StatusOK,
+		},
+		Features: make([]models.Earthquake, 0, len(response.Features)),
+	}
+
+	for _, feature := range response.Features {
+		eq, err := feature.toEarthquake()
+		if err != nil {
+			return nil, err
 		}
+		earthquakes.Features = append(earthquakes.Features, eq)
+	}
+	earthquakes.Metadata.Count = len(earthquakes.Features)
+
+	return earthquakes, nil
+}
 
-		lastErr = err
+// toEarthquake maps an EMSC event onto the fields of a USGS earthquake
+func (f emscFeature) toEarthquake() (models.Earthquake, error) {
+	p := f.Properties
 
-		if attempt < maxRetries {
-			time.Sleep(retryDelay)
+	id := p.UnID
+	if id == "" {
+		id = f.ID
+	}
+
+	eventTime, err := time.Parse(time.RFC3339Nano, p.Time)
+	if err != nil {
+		return models.Earthquake{}, fmt.Errorf("invalid time for EMSC event %s: %w", id, err)
+	}
+	updated := eventTime
+	if p.LastUpdate != "" {
+		if updated, err = time.Parse(time.RFC3339Nano, p.LastUpdate); err != nil {
+			return models.Earthquake{}, fmt.Errorf("invalid update time for EMSC event %s: %w", id, err)
 		}
 	}
 
-	return nil, fmt.Errorf("failed to fetch faults after %d attempts: %w", maxRetries+1, lastErr)
+	// EMSC codes known, suspected and felt earthquakes as ke, se and fe
+	eventType := p.EvType
+	switch p.EvType {
+	case "ke", "se", "fe", "":
+		eventType = "earthquake"
+	}
+
+	return models.Earthquake{
+		Type: "Feature",
+		ID:   id,
+		Properties: models.EarthquakeProperties{
+			Mag:     models.Float64(p.Mag),
+			Place:   p.FlynnRegion,
+			Time:    eventTime.UnixMilli(),
+			Updated: updated.UnixMilli(),
+			URL:     emscEventURL + id,
+			Net:     strings.ToLower(p.Auth),
+			Code:    p.SourceID,
+			IDs:     "," + id + ",",
+			Sources: ",emsc,",
+			MagType: p.MagType,
+			Type:    eventType,
+			Title:   fmt.Sprintf("M %.1f - %s", p.Mag, p.FlynnRegion),
+		},
+		// EMSC geometry carries elevation rather than depth, so use the depth property
+		Geometry: models.Geometry{
+			Type:        "Point",
+			Coordinates: []float64{p.Lon, p.Lat, p.Depth},
+		},
+	}, nil
 }
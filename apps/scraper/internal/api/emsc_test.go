@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEMSCEarthquakes(t *testing.T) {
+	file, err := os.Open("testdata/emsc_recent.json")
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer file.Close()
+
+	response, err := ParseEMSCEarthquakes(file)
+	if err != nil {
+		t.Fatalf("ParseEMSCEarthquakes() error = %v", err)
+	}
+
+	if response.Type != "FeatureCollection" || response.Metadata.Count != 3 || len(response.Features) != 3 {
+		t.Fatalf("Response = %s with count %d and %d features, want 3", response.Type, response.Metadata.Count, len(response.Features))
+	}
+
+	eq := response.Features[0]
+	if eq.ID != "20240115_0000087" {
+		t.Errorf("ID = %q, want %q", eq.ID, "20240115_0000087")
+	}
+	if magnitude := eq.Properties.GetMagnitude(); magnitude != "2.4 ml" {
+		t.Errorf("Magnitude = %s, want 2.4 ml", magnitude)
+	}
+	if eq.Properties.Place != "CENTRAL ITALY" || eq.Properties.Title != "M 2.4 - CENTRAL ITALY" {
+		t.Errorf("Place/Title = %q/%q", eq.Properties.Place, eq.Properties.Title)
+	}
+	if want := time.Date(2024, 1, 15, 8, 10, 12, 300000000, time.UTC).UnixMilli(); eq.Properties.Time != want {
+		t.Errorf("Time = %d, want %d", eq.Properties.Time, want)
+	}
+	if want := time.Date(2024, 1, 15, 8, 17, 35, 521000000, time.UTC).UnixMilli(); eq.Properties.Updated != want {
+		t.Errorf("Updated = %d, want %d", eq.Properties.Updated, want)
+	}
+	if eq.Properties.Net != "ingv" || eq.Properties.Code != "1602334" || eq.Properties.Sources != ",emsc," {
+		t.Errorf("Net/Code/Sources = %q/%q/%q", eq.Properties.Net, eq.Properties.Code, eq.Properties.Sources)
+	}
+	if eq.Properties.Type != "earthquake" {
+		t.Errorf("Type = %q, want earthquake", eq.Properties.Type)
+	}
+
+	// Depth is taken from the properties, not the negated geometry elevation
+	if lon, lat, depth := eq.Geometry.Longitude(), eq.Geometry.Latitude(), eq.Geometry.Depth(); lon != 13.2305 || lat != 42.8512 || depth != 9.8 {
+		t.Errorf("Coordinates = %v, %v, %v, want 13.2305, 42.8512, 9.8", lon, lat, depth)
+	}
+
+	// Event types other than earthquakes keep their EMSC code
+	if got := response.Features[2].Properties.Type; got != "km" {
+		t.Errorf("Type of mining event = %q, want km", got)
+	}
+}
+
+func TestEMSCClient_GetRecentEarthquakes(t *testing.T) {
+	var query string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		http.ServeFile(w, r, "testdata/emsc_recent.json")
+	}))
+	defer server.Close()
+
+	client := NewEMSCClient(server.URL, 5*time.Second)
+	client.SetEventsURL(server.URL)
+
+	response, err := client.GetRecentEarthquakes(context.Background(), 25)
+	if err != nil {
+		t.Fatalf("GetRecentEarthquakes() error = %v", err)
+	}
+	if len(response.Features) != 3 {
+		t.Errorf("Expected 3 earthquakes, got %d", len(response.Features))
+	}
+	for _, param := range []string{"format=json", "limit=25", "orderby=time", "start="} {
+		if !strings.Contains(query, param) {
+			t.Errorf("Query %q does not contain %q", query, param)
+		}
+	}
+
+	// No matching events is not an error
+	status = http.StatusNoContent
+	empty, err := client.GetRecentEarthquakes(context.Background(), 25)
+	if err != nil {
+		t.Fatalf("GetRecentEarthquakes() error = %v", err)
+	}
+	if len(empty.Features) != 0 {
+		t.Errorf("Expected no earthquakes, got %d", len(empty.Features))
+	}
+}
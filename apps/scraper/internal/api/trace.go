@@ -0,0 +1,91 @@
+package api
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"quakewatch-scraper/internal/utils"
+)
+
+// requestTiming records when the phases of one HTTP request started and finished
+type requestTiming struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	firstByte    time.Time
+	reused       bool
+}
+
+// traceRequest returns a copy of req that records its phase timings
+func traceRequest(req *http.Request) (*http.Request, *requestTiming) {
+	timing := &requestTiming{start: time.Now()}
+	mark := func(t *time.Time) {
+		timing.mu.Lock()
+		defer timing.mu.Unlock()
+		// Happy eyeballs may dial several addresses; keep the first start and the first finish
+		if t.IsZero() {
+			*t = time.Now()
+		}
+	}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { mark(&timing.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { mark(&timing.dnsDone) },
+		ConnectStart:      func(string, string) { mark(&timing.connectStart) },
+		ConnectDone:       func(string, string, error) { mark(&timing.connectDone) },
+		TLSHandshakeStart: func() { mark(&timing.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { mark(&timing.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			timing.mu.Lock()
+			defer timing.mu.Unlock()
+			timing.reused = info.Reused
+		},
+		GotFirstResponseByte: func() { mark(&timing.firstByte) },
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), timing
+}
+
+// fields returns the phase durations as log fields. Phases that did not happen, such as DNS for
+// an IP address or any dialing on a reused connection, are 0. first_byte and total are measured
+// from the start of the request.
+func (t *requestTiming) fields(end time.Time) map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	phase := func(from, to time.Time) time.Duration {
+		if from.IsZero() || to.IsZero() {
+			return 0
+		}
+		return to.Sub(from).Round(time.Microsecond)
+	}
+	return map[string]interface{}{
+		"dns":         phase(t.dnsStart, t.dnsDone),
+		"connect":     phase(t.connectStart, t.connectDone),
+		"tls":         phase(t.tlsStart, t.tlsDone),
+		"first_byte":  phase(t.start, t.firstByte),
+		"total":       phase(t.start, end),
+		"reused_conn": t.reused,
+	}
+}
+
+// logTiming logs the phase breakdown of a finished request at debug level
+func logTiming(logger *utils.Logger, req *http.Request, timing *requestTiming, status int, err error) {
+	fields := timing.fields(time.Now())
+	fields["method"] = req.Method
+	fields["url"] = req.URL.Redacted()
+	if status != 0 {
+		fields["status"] = status
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	logger.Debug("HTTP request timing", fields)
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/utils"
+)
+
+// decodeLogLines decodes the JSON log entries written to buf
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var entries []map[string]interface{}
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var entry map[string]interface{}
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatalf("Failed to decode log entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestUSGSClient_RequestTiming(t *testing.T) {
+	var queries []url.Values
+	server := newTestServer(t, &queries)
+
+	var logs bytes.Buffer
+	logger := utils.NewLogger("debug", "json")
+	logger.SetOutput(&logs)
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+	client.SetLogger(logger)
+	if _, err := client.GetRecentEarthquakes(context.Background(), 10); err != nil {
+		t.Fatalf("GetRecentEarthquakes() error = %v", err)
+	}
+
+	entries := decodeLogLines(t, &logs)
+	if len(entries) != 1 {
+		t.Fatalf("Expected one timing entry, got %d: %s", len(entries), logs.String())
+	}
+	entry := entries[0]
+	if entry["msg"] != "HTTP request timing" || entry["level"] != "debug" {
+		t.Errorf("Unexpected log entry: %v", entry)
+	}
+	for _, field := range []string{"dns", "connect", "tls", "first_byte", "total", "reused_conn", "url"} {
+		if _, ok := entry[field]; !ok {
+			t.Errorf("Timing entry is missing %s: %v", field, entry)
+		}
+	}
+	if entry["status"] != float64(http.StatusOK) || entry["method"] != http.MethodGet {
+		t.Errorf("Expected GET with status 200, got %v %v", entry["method"], entry["status"])
+	}
+
+	// Durations are logged in nanoseconds; a fresh connection to the test server was dialed
+	firstByte, _ := entry["first_byte"].(float64)
+	total, _ := entry["total"].(float64)
+	connect, _ := entry["connect"].(float64)
+	if firstByte <= 0 || total < firstByte || connect <= 0 {
+		t.Errorf("Expected connect > 0 and 0 < first_byte <= total, got connect=%v first_byte=%v total=%v", connect, firstByte, total)
+	}
+}
+
+func TestEMSCClient_RequestTimingOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	var logs bytes.Buffer
+	logger := utils.NewLogger("debug", "json")
+	logger.SetOutput(&logs)
+
+	client := NewEMSCClient(server.URL, 5*time.Second)
+	client.SetLogger(logger)
+	if _, err := client.GetFaults(context.Background()); err == nil {
+		t.Fatal("Expected an error from a closed server")
+	}
+
+	entries := decodeLogLines(t, &logs)
+	if len(entries) != 1 || entries[0]["error"] == nil || entries[0]["status"] != nil {
+		t.Errorf("Expected one timing entry with the error and no status, got %s", logs.String())
+	}
+}
+
+func TestRequestTiming_NotLoggedAboveDebug(t *testing.T) {
+	var queries []url.Values
+	server := newTestServer(t, &queries)
+
+	var logs bytes.Buffer
+	logger := utils.NewLogger("info", "json")
+	logger.SetOutput(&logs)
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+	client.SetLogger(logger)
+	if _, err := client.GetRecentEarthquakes(context.Background(), 10); err != nil {
+		t.Fatalf("GetRecentEarthquakes() error = %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected no timing entries at info level, got %s", logs.String())
+	}
+}
@@ -1,35 +1,298 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/utils"
 )
 
+// DefaultUserAgent identifies the scraper to the USGS and EMSC APIs when the caller sets no
+// User-Agent of its own; the CLI sets one carrying its version
+const DefaultUserAgent = "quakewatch-scraper"
+
+// usgsMaxEvents is the largest number of events USGS returns for a single query
+const usgsMaxEvents = 20000
+
+// NoLimit as the limit of a time range query fetches every earthquake in the range, paginating
+// past the USGS per-query cap as needed
+const NoLimit = 0
+
+// minPaginationWindow is the shortest time window a paginated query is split into
+const minPaginationWindow = time.Minute
+
+// ErrSearchLimitExceeded is returned when USGS rejects a query matching more events than it will return
+var ErrSearchLimitExceeded = errors.New("query exceeds the USGS search limit")
+
+// maxCapturedBody is how much of an undecodable response body is kept for debugging
+const maxCapturedBody = 4096
+
+// ValidOrderBy lists the result orderings supported by the USGS orderby parameter
+var ValidOrderBy = []string{"time", "time-asc", "magnitude", "magnitude-asc"}
+
+// PaginationState records split and completed time windows so an interrupted paginated query can resume
+type PaginationState interface {
+	SubQuery(key string) (split, done bool, earthquakes []models.Earthquake)
+	MarkSplit(key string) error
+	MarkDone(key string, earthquakes []models.Earthquake) error
+}
+
 // USGSClient handles communication with the USGS Earthquake API
 type USGSClient struct {
 	baseURL    string
 	httpClient *http.Client
+	validator  *utils.DataValidator
+	minQuality float64
+	orderBy    string
+	eventType  string
+	catalog    string
+	userAgent  string
+	limiter    *utils.ConcurrencyLimiter
+	logger     *utils.Logger
+	pagination PaginationState
+
+	// decodeRetry, when set, re-fetches responses whose body was cut off before it decoded
+	decodeRetry *utils.RetryStrategy
+
+	// mirrors are tried in order when a request to baseURL fails in a way that may succeed elsewhere
+	mirrors    []string
+	onFailover func(failed, next string, err error)
 }
 
 // NewUSGSClient creates a new USGS API client
 func NewUSGSClient(baseURL string, timeout time.Duration) *USGSClient {
+	validator := utils.NewDefaultDataValidator()
+	validator.AddRule(&utils.CoordinateRule{})
+
 	return &USGSClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
+		validator: validator,
+		userAgent: DefaultUserAgent,
+	}
+}
+
+// SetUserAgent sets the User-Agent header sent with requests (empty keeps DefaultUserAgent)
+func (c *USGSClient) SetUserAgent(userAgent string) {
+	if userAgent != "" {
+		c.userAgent = userAgent
+	}
+}
+
+// SetConcurrencyLimiter makes each request wait for a free slot in limiter, which may be
+// shared with other clients to bound the total number of requests in flight
+func (c *USGSClient) SetConcurrencyLimiter(limiter *utils.ConcurrencyLimiter) {
+	c.limiter = limiter
+}
+
+// SetLogger sets the logger that receives each request's DNS, connect, TLS, first byte and
+// total timings when it is at debug level
+func (c *USGSClient) SetLogger(logger *utils.Logger) {
+	c.logger = logger
+}
+
+// releaseOnClose frees a concurrency slot once a response body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
+// doRequest sends req once limiter has a free slot. The slot is held until the response
+// body is closed. When logger is at debug level, the request's phase timings are logged once
+// the body is closed, so the total includes reading it.
+func doRequest(client *http.Client, limiter *utils.ConcurrencyLimiter, logger *utils.Logger, req *http.Request) (*http.Response, error) {
+	if err := limiter.Acquire(req.Context()); err != nil {
+		return nil, err
 	}
+
+	var timing *requestTiming
+	if logger.DebugEnabled() {
+		req, timing = traceRequest(req)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		limiter.Release()
+		if timing != nil {
+			logTiming(logger, req, timing, 0, err)
+		}
+		return nil, err
+	}
+
+	release := limiter.Release
+	if timing != nil {
+		release = func() {
+			limiter.Release()
+			logTiming(logger, req, timing, resp.StatusCode, nil)
+		}
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
 }
 
-// GetEarthquakes fetches earthquake data from USGS API
-func (c *USGSClient) GetEarthquakes(params map[string]string) (*models.USGSResponse, error) {
-	u, err := url.Parse(c.baseURL + "/query")
+// newGetRequest creates a GET request that identifies the client with userAgent
+func newGetRequest(ctx context.Context, rawURL, userAgent string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	return req, nil
+}
+
+// SetMirrors sets alternative base URLs that are tried in order when a request to the base URL
+// fails with a retryable error, such as a network failure or a server error
+func (c *USGSClient) SetMirrors(mirrors []string) {
+	c.mirrors = mirrors
+}
+
+// SetFailoverHook sets a function called before a request moves on from a failed endpoint to the next mirror
+func (c *USGSClient) SetFailoverHook(hook func(failed, next string, err error)) {
+	c.onFailover = hook
+}
+
+// withMirrors calls fn with the base URL and then with each mirror in turn until it succeeds or
+// fails with an error another endpoint would not avoid. The last error is returned.
+func (c *USGSClient) withMirrors(ctx context.Context, fn func(baseURL string) error) error {
+	urls := append([]string{c.baseURL}, c.mirrors...)
+	var err error
+	for i, baseURL := range urls {
+		if err = fn(baseURL); err == nil || i == len(urls)-1 || !shouldFailover(ctx, err) {
+			return err
+		}
+		if c.onFailover != nil {
+			c.onFailover(baseURL, urls[i+1], err)
+		}
+	}
+	return err
+}
+
+// shouldFailover reports whether err is a retryable collection error that was not caused by ctx ending
+func shouldFailover(ctx context.Context, err error) bool {
+	var collectionErr *utils.CollectionError
+	return ctx.Err() == nil && errors.As(err, &collectionErr) && collectionErr.Retryable
+}
+
+// SetPaginationState makes paginated time range queries record their progress in state and skip windows already completed
+func (c *USGSClient) SetPaginationState(state PaginationState) {
+	c.pagination = state
+}
+
+// SetDecodeRetry makes queries whose response body ends before the JSON is complete be
+// retried according to strategy (nil disables retrying)
+func (c *USGSClient) SetDecodeRetry(strategy *utils.RetryStrategy) {
+	c.decodeRetry = strategy
+}
+
+// SetMinQuality makes requests fail when the validation score of a response is below minQuality (0 disables the check)
+func (c *USGSClient) SetMinQuality(minQuality float64) {
+	c.minQuality = minQuality
+}
+
+// SetOrderBy sets the ordering requested for results, one of ValidOrderBy (empty keeps the USGS default)
+func (c *USGSClient) SetOrderBy(orderBy string) error {
+	if orderBy != "" && !slices.Contains(ValidOrderBy, orderBy) {
+		return fmt.Errorf("invalid order: %s (must be one of %s)", orderBy, strings.Join(ValidOrderBy, ", "))
+	}
+	c.orderBy = orderBy
+	return nil
+}
+
+// SetEventType restricts results to one USGS event type, such as "earthquake" or "quarry blast" (empty returns all types)
+func (c *USGSClient) SetEventType(eventType string) {
+	c.eventType = strings.TrimSpace(eventType)
+}
+
+// catalogPattern matches USGS catalog identifiers such as "ak", "nc" or "us"
+var catalogPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// SetCatalog restricts results to one USGS contributing catalog, such as "ak" or "nc" (empty queries the composite catalog)
+func (c *USGSClient) SetCatalog(catalog string) error {
+	catalog = strings.TrimSpace(catalog)
+	if catalog != "" && !catalogPattern.MatchString(catalog) {
+		return fmt.Errorf("invalid catalog: %q (must be a single token of letters, digits, '-' or '_')", catalog)
+	}
+	c.catalog = catalog
+	return nil
+}
+
+// GetEarthquakes fetches earthquake data from USGS API, retrying truncated responses if a
+// decode retry strategy is set
+func (c *USGSClient) GetEarthquakes(ctx context.Context, params map[string]string) (*models.USGSResponse, error) {
+	var response *models.USGSResponse
+	err := c.withMirrors(ctx, func(baseURL string) error {
+		var err error
+		response, err = c.getEarthquakesWithRetry(ctx, baseURL, params)
+		return err
+	})
+	return response, err
+}
+
+// getEarthquakesWithRetry runs a query against baseURL, re-running it while the response is
+// truncated and decode retries remain
+func (c *USGSClient) getEarthquakesWithRetry(ctx context.Context, baseURL string, params map[string]string) (*models.USGSResponse, error) {
+	response, err := c.getEarthquakes(ctx, baseURL, params)
+	for attempt := 1; c.decodeRetry != nil && attempt <= c.decodeRetry.MaxRetries && isTruncatedResponse(err); attempt++ {
+		delay := c.decodeRetry.Delay(attempt)
+		if c.decodeRetry.OnRetry != nil {
+			c.decodeRetry.OnRetry(attempt, delay, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+		response, err = c.getEarthquakes(ctx, baseURL, params)
+	}
+	return response, err
+}
+
+// isTruncatedResponse reports whether err is a retryable decode failure, i.e. the response
+// body ended before the JSON was complete
+func isTruncatedResponse(err error) bool {
+	var collectionErr *utils.CollectionError
+	return errors.As(err, &collectionErr) && collectionErr.Type == utils.ErrorTypeDecode && collectionErr.Retryable
+}
+
+// prefixBuffer keeps the first limit bytes written to it and discards the rest
+type prefixBuffer struct {
+	data  []byte
+	limit int
+}
+
+func (b *prefixBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - len(b.data); room > 0 {
+		b.data = append(b.data, p[:min(room, len(p))]...)
+	}
+	return len(p), nil
+}
+
+// getEarthquakes runs a single query against the USGS API at baseURL
+func (c *USGSClient) getEarthquakes(ctx context.Context, baseURL string, params map[string]string) (*models.USGSResponse, error) {
+	u, err := url.Parse(baseURL + "/query")
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
@@ -38,34 +301,123 @@ func (c *USGSClient) GetEarthquakes(params map[string]string) (*models.USGSRespo
 
 	// Set default format to geojson
 	q.Set("format", "geojson")
+	if c.orderBy != "" {
+		q.Set("orderby", c.orderBy)
+	}
+	if c.eventType != "" {
+		q.Set("eventtype", c.eventType)
+	}
+	if c.catalog != "" {
+		q.Set("catalog", c.catalog)
+	}
 
 	// Add custom parameters
 	for key, value := range params {
 		q.Set(key, value)
 	}
 
-	u.RawQuery = q.Encode()
+	// Encode spaces as %20 rather than +, as in "eventtype=quarry%20blast"
+	u.RawQuery = strings.ReplaceAll(q.Encode(), "+", "%20")
+
+	req, err := newGetRequest(ctx, u.String(), c.userAgent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
 
-	resp, err := c.httpClient.Get(u.String())
+	resp, err := doRequest(c.httpClient, c.limiter, c.logger, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make HTTP request: %w", err)
+		return nil, utils.ClassifyRequestError(fmt.Errorf("failed to make HTTP request: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+		if resp.StatusCode == http.StatusBadRequest {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			if strings.Contains(string(body), "exceeds search limit") {
+				return nil, utils.ClassifyStatus(resp.StatusCode, fmt.Errorf("API request failed with status: %d: %w", resp.StatusCode, ErrSearchLimitExceeded))
+			}
+		}
+		return nil, utils.ClassifyStatus(resp.StatusCode, fmt.Errorf("API request failed with status: %d", resp.StatusCode))
 	}
 
+	// Keep the start of the body so an HTML error page or a cut-off response can be inspected
+	body := &prefixBuffer{limit: maxCapturedBody}
 	var response models.USGSResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := json.NewDecoder(io.TeeReader(resp.Body, body)).Decode(&response); err != nil {
+		// The decoder stops at the first error; read on so the capture is as long as allowed
+		io.Copy(body, io.LimitReader(resp.Body, int64(maxCapturedBody-len(body.data))))
+		truncated := errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+		collectionErr := utils.NewCollectionError(utils.ErrorTypeDecode, truncated, fmt.Errorf("failed to decode response: %w", err))
+		collectionErr.Context[utils.ContextResponseBody] = string(body.data)
+		return nil, collectionErr
+	}
+
+	if c.minQuality > 0 {
+		if err := utils.CheckQuality(c.validator.ValidateResponse(&response), c.minQuality); err != nil {
+			return nil, utils.NewCollectionError(utils.ErrorTypeValidation, false, err)
+		}
 	}
 
 	return &response, nil
 }
 
+// GetEventDetail fetches the detail GeoJSON of a single event, including its products such as
+// moment tensors and phase data. Unlike other queries it ignores the order, event type and
+// catalog settings, which do not apply to a lookup by ID.
+func (c *USGSClient) GetEventDetail(ctx context.Context, eventID string) (*models.EventDetail, error) {
+	eventID = strings.TrimSpace(eventID)
+	if eventID == "" {
+		return nil, fmt.Errorf("event ID must not be empty")
+	}
+
+	var detail *models.EventDetail
+	err := c.withMirrors(ctx, func(baseURL string) error {
+		var err error
+		detail, err = c.getEventDetail(ctx, baseURL, eventID)
+		return err
+	})
+	return detail, err
+}
+
+// getEventDetail fetches the detail of a single event from the USGS API at baseURL
+func (c *USGSClient) getEventDetail(ctx context.Context, baseURL, eventID string) (*models.EventDetail, error) {
+	u, err := url.Parse(baseURL + "/query")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("eventid", eventID)
+	q.Set("format", "geojson")
+	u.RawQuery = q.Encode()
+
+	req, err := newGetRequest(ctx, u.String(), c.userAgent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := doRequest(c.httpClient, c.limiter, c.logger, req)
+	if err != nil {
+		return nil, utils.ClassifyRequestError(fmt.Errorf("failed to make HTTP request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, utils.ClassifyStatus(resp.StatusCode, fmt.Errorf("event %s not found", eventID))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, utils.ClassifyStatus(resp.StatusCode, fmt.Errorf("API request failed with status: %d", resp.StatusCode))
+	}
+
+	var detail models.EventDetail
+	if err := json.NewDecoder(resp.Body).Decode(&detail); err != nil {
+		truncated := errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+		return nil, utils.NewCollectionError(utils.ErrorTypeDecode, truncated, fmt.Errorf("failed to decode event detail: %w", err))
+	}
+	return &detail, nil
+}
+
 // GetRecentEarthquakes fetches earthquakes from the last hour
-func (c *USGSClient) GetRecentEarthquakes(limit int) (*models.USGSResponse, error) {
+func (c *USGSClient) GetRecentEarthquakes(ctx context.Context, limit int) (*models.USGSResponse, error) {
 	endTime := time.Now()
 	startTime := endTime.Add(-1 * time.Hour)
 
@@ -75,33 +427,196 @@ func (c *USGSClient) GetRecentEarthquakes(limit int) (*models.USGSResponse, erro
 		"limit":     strconv.Itoa(limit),
 	}
 
-	return c.GetEarthquakes(params)
+	return c.GetEarthquakes(ctx, params)
+}
+
+// GetEarthquakesUpdatedAfter fetches earthquakes inserted or updated since the given time
+func (c *USGSClient) GetEarthquakesUpdatedAfter(ctx context.Context, updatedAfter time.Time, limit int) (*models.USGSResponse, error) {
+	params := map[string]string{
+		"updatedafter": updatedAfter.UTC().Format("2006-01-02T15:04:05"),
+		"limit":        strconv.Itoa(limit),
+	}
+
+	return c.GetEarthquakes(ctx, params)
+}
+
+// GetEarthquakesByTimeRange fetches earthquakes within a specific time range.
+// Queries that exceed the USGS per-query cap are split into smaller time windows.
+// A limit of NoLimit fetches every earthquake in the range.
+func (c *USGSClient) GetEarthquakesByTimeRange(ctx context.Context, startTime, endTime time.Time, limit int) (*models.USGSResponse, error) {
+	if limit <= NoLimit {
+		limit = math.MaxInt
+	}
+
+	merged := &models.USGSResponse{Type: "FeatureCollection"}
+	seen := make(map[string]bool)
+
+	// Split windows are merged newest first, so any other order needs every window before the
+	// merged result is sorted and the limit applied
+	mergeLimit := limit
+	if c.orderBy != "" && c.orderBy != "time" {
+		mergeLimit = math.MaxInt
+	}
+
+	if limit <= usgsMaxEvents && !c.windowSplit(startTime, endTime) {
+		response, err := c.getEarthquakesInWindow(ctx, startTime, endTime, limit)
+		if !errors.Is(err, ErrSearchLimitExceeded) {
+			return response, err
+		}
+		if err := c.splitWindow(ctx, startTime, endTime, mergeLimit, merged, seen); err != nil {
+			return nil, err
+		}
+	} else if err := c.collectWindow(ctx, startTime, endTime, mergeLimit, merged, seen); err != nil {
+		return nil, err
+	}
+
+	sortEarthquakes(merged.Features, c.orderBy)
+	if len(merged.Features) > limit {
+		merged.Features = merged.Features[:limit]
+	}
+	merged.Metadata.Count = len(merged.Features)
+	return merged, nil
+}
+
+// sortEarthquakes sorts earthquakes into one of ValidOrderBy; earthquakes without a magnitude
+// come last in either magnitude order. An empty order or time keeps the newest-first merge order.
+func sortEarthquakes(earthquakes []models.Earthquake, orderBy string) {
+	var less func(a, b *models.EarthquakeProperties) bool
+	switch orderBy {
+	case "time-asc":
+		less = func(a, b *models.EarthquakeProperties) bool { return a.Time < b.Time }
+	case "magnitude":
+		less = func(a, b *models.EarthquakeProperties) bool {
+			return a.Mag != nil && (b.Mag == nil || *a.Mag > *b.Mag)
+		}
+	case "magnitude-asc":
+		less = func(a, b *models.EarthquakeProperties) bool {
+			return a.Mag != nil && (b.Mag == nil || *a.Mag < *b.Mag)
+		}
+	default:
+		return
+	}
+	sort.SliceStable(earthquakes, func(i, j int) bool {
+		return less(&earthquakes[i].Properties, &earthquakes[j].Properties)
+	})
 }
 
-// GetEarthquakesByTimeRange fetches earthquakes within a specific time range
-func (c *USGSClient) GetEarthquakesByTimeRange(startTime, endTime time.Time, limit int) (*models.USGSResponse, error) {
+// getEarthquakesInWindow runs a single time range query
+func (c *USGSClient) getEarthquakesInWindow(ctx context.Context, startTime, endTime time.Time, limit int) (*models.USGSResponse, error) {
 	params := map[string]string{
 		"starttime": startTime.Format("2006-01-02T15:04:05"),
 		"endtime":   endTime.Format("2006-01-02T15:04:05"),
 		"limit":     strconv.Itoa(limit),
 	}
 
-	return c.GetEarthquakes(params)
+	return c.GetEarthquakes(ctx, params)
+}
+
+// collectWindow queries a time window, bisecting it while USGS rejects or truncates the result,
+// and appends unseen earthquakes to merged until limit is reached. Newer halves are fetched
+// first so the merged result keeps the newest-first order of a single query.
+func (c *USGSClient) collectWindow(ctx context.Context, startTime, endTime time.Time, limit int, merged *models.USGSResponse, seen map[string]bool) error {
+	if len(merged.Features) >= limit {
+		return nil
+	}
+
+	key := windowKey(startTime, endTime)
+	if c.pagination != nil {
+		split, done, earthquakes := c.pagination.SubQuery(key)
+		if done {
+			mergeEarthquakes(merged, earthquakes, limit, seen)
+			return nil
+		}
+		if split {
+			return c.bisectWindow(ctx, startTime, endTime, limit, merged, seen)
+		}
+	}
+
+	response, err := c.getEarthquakesInWindow(ctx, startTime, endTime, usgsMaxEvents)
+	tooLarge := errors.Is(err, ErrSearchLimitExceeded) || (err == nil && len(response.Features) >= usgsMaxEvents)
+
+	if tooLarge && endTime.Sub(startTime) > minPaginationWindow {
+		return c.splitWindow(ctx, startTime, endTime, limit, merged, seen)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch earthquakes from %s to %s: %w",
+			startTime.Format("2006-01-02T15:04:05"), endTime.Format("2006-01-02T15:04:05"), err)
+	}
+
+	if c.pagination != nil {
+		if err := c.pagination.MarkDone(key, response.Features); err != nil {
+			return err
+		}
+	}
+
+	if merged.Metadata.Generated == 0 {
+		merged.Metadata = response.Metadata
+	}
+	mergeEarthquakes(merged, response.Features, limit, seen)
+
+	return nil
+}
+
+// mergeEarthquakes appends unseen earthquakes to merged until limit is reached
+func mergeEarthquakes(merged *models.USGSResponse, earthquakes []models.Earthquake, limit int, seen map[string]bool) {
+	for _, eq := range earthquakes {
+		if len(merged.Features) >= limit {
+			return
+		}
+		if seen[eq.ID] {
+			continue
+		}
+		seen[eq.ID] = true
+		merged.Features = append(merged.Features, eq)
+	}
+}
+
+// windowKey identifies a pagination time window in the pagination state
+func windowKey(startTime, endTime time.Time) string {
+	return startTime.Format("2006-01-02T15:04:05") + "/" + endTime.Format("2006-01-02T15:04:05")
+}
+
+// windowSplit reports whether the pagination state says a window must be split
+func (c *USGSClient) windowSplit(startTime, endTime time.Time) bool {
+	if c.pagination == nil {
+		return false
+	}
+	split, _, _ := c.pagination.SubQuery(windowKey(startTime, endTime))
+	return split
+}
+
+// splitWindow records that a window is too large and collects its halves
+func (c *USGSClient) splitWindow(ctx context.Context, startTime, endTime time.Time, limit int, merged *models.USGSResponse, seen map[string]bool) error {
+	if c.pagination != nil {
+		if err := c.pagination.MarkSplit(windowKey(startTime, endTime)); err != nil {
+			return err
+		}
+	}
+	return c.bisectWindow(ctx, startTime, endTime, limit, merged, seen)
+}
+
+// bisectWindow collects the newer and then the older half of a time window
+func (c *USGSClient) bisectWindow(ctx context.Context, startTime, endTime time.Time, limit int, merged *models.USGSResponse, seen map[string]bool) error {
+	mid := startTime.Add(endTime.Sub(startTime) / 2)
+	if err := c.collectWindow(ctx, mid, endTime, limit, merged, seen); err != nil {
+		return err
+	}
+	return c.collectWindow(ctx, startTime, mid, limit, merged, seen)
 }
 
 // GetEarthquakesByMagnitude fetches earthquakes within a magnitude range
-func (c *USGSClient) GetEarthquakesByMagnitude(minMag, maxMag float64, limit int) (*models.USGSResponse, error) {
+func (c *USGSClient) GetEarthquakesByMagnitude(ctx context.Context, minMag, maxMag float64, limit int) (*models.USGSResponse, error) {
 	params := map[string]string{
 		"minmagnitude": strconv.FormatFloat(minMag, 'f', 1, 64),
 		"maxmagnitude": strconv.FormatFloat(maxMag, 'f', 1, 64),
 		"limit":        strconv.Itoa(limit),
 	}
 
-	return c.GetEarthquakes(params)
+	return c.GetEarthquakes(ctx, params)
 }
 
 // GetSignificantEarthquakes fetches significant earthquakes (M4.5+)
-func (c *USGSClient) GetSignificantEarthquakes(startTime, endTime time.Time, limit int) (*models.USGSResponse, error) {
+func (c *USGSClient) GetSignificantEarthquakes(ctx context.Context, startTime, endTime time.Time, limit int) (*models.USGSResponse, error) {
 	params := map[string]string{
 		"starttime":    startTime.Format("2006-01-02T15:04:05"),
 		"endtime":      endTime.Format("2006-01-02T15:04:05"),
@@ -109,11 +624,11 @@ func (c *USGSClient) GetSignificantEarthquakes(startTime, endTime time.Time, lim
 		"limit":        strconv.Itoa(limit),
 	}
 
-	return c.GetEarthquakes(params)
+	return c.GetEarthquakes(ctx, params)
 }
 
 // GetEarthquakesByRegion fetches earthquakes within a geographic region
-func (c *USGSClient) GetEarthquakesByRegion(minLat, maxLat, minLon, maxLon float64, limit int) (*models.USGSResponse, error) {
+func (c *USGSClient) GetEarthquakesByRegion(ctx context.Context, minLat, maxLat, minLon, maxLon float64, limit int) (*models.USGSResponse, error) {
 	params := map[string]string{
 		"minlatitude":  strconv.FormatFloat(minLat, 'f', 2, 64),
 		"maxlatitude":  strconv.FormatFloat(maxLat, 'f', 2, 64),
@@ -122,11 +637,36 @@ func (c *USGSClient) GetEarthquakesByRegion(minLat, maxLat, minLon, maxLon float
 		"limit":        strconv.Itoa(limit),
 	}
 
-	return c.GetEarthquakes(params)
+	return c.GetEarthquakes(ctx, params)
+}
+
+// MaxRadiusKm is the largest search radius USGS accepts, roughly half the Earth's circumference
+const MaxRadiusKm = 20001.6
+
+// GetEarthquakesByRadius fetches earthquakes within radiusKm kilometers of a point
+func (c *USGSClient) GetEarthquakesByRadius(ctx context.Context, lat, lon, radiusKm float64, limit int) (*models.USGSResponse, error) {
+	if lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("invalid latitude %g: must be between -90 and 90", lat)
+	}
+	if lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("invalid longitude %g: must be between -180 and 180", lon)
+	}
+	if radiusKm <= 0 || radiusKm > MaxRadiusKm {
+		return nil, fmt.Errorf("invalid radius %g km: must be greater than 0 and at most %g", radiusKm, MaxRadiusKm)
+	}
+
+	params := map[string]string{
+		"latitude":    strconv.FormatFloat(lat, 'f', -1, 64),
+		"longitude":   strconv.FormatFloat(lon, 'f', -1, 64),
+		"maxradiuskm": strconv.FormatFloat(radiusKm, 'f', -1, 64),
+		"limit":       strconv.Itoa(limit),
+	}
+
+	return c.GetEarthquakes(ctx, params)
 }
 
 // GetEarthquakesByTimeRangeAndMagnitude fetches earthquakes within a time range and magnitude range
-func (c *USGSClient) GetEarthquakesByTimeRangeAndMagnitude(startTime, endTime time.Time, minMag, maxMag float64, limit int) (*models.USGSResponse, error) {
+func (c *USGSClient) GetEarthquakesByTimeRangeAndMagnitude(ctx context.Context, startTime, endTime time.Time, minMag, maxMag float64, limit int) (*models.USGSResponse, error) {
 	params := map[string]string{
 		"starttime":    startTime.Format("2006-01-02T15:04:05"),
 		"endtime":      endTime.Format("2006-01-02T15:04:05"),
@@ -135,5 +675,5 @@ func (c *USGSClient) GetEarthquakesByTimeRangeAndMagnitude(startTime, endTime ti
 		"limit":        strconv.Itoa(limit),
 	}
 
-	return c.GetEarthquakes(params)
+	return c.GetEarthquakes(ctx, params)
 }
@@ -0,0 +1,816 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/storage"
+	"quakewatch-scraper/internal/utils"
+)
+
+// newTestServer starts a server that records the query of each request and returns an empty result
+func newTestServer(t *testing.T, queries *[]url.Values) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*queries = append(*queries, r.URL.Query())
+		json.NewEncoder(w).Encode(models.USGSResponse{Type: "FeatureCollection"})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestUSGSClient_GetEarthquakesUpdatedAfter(t *testing.T) {
+	var queries []url.Values
+	server := newTestServer(t, &queries)
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+
+	// A non-UTC time must be converted before formatting
+	updatedAfter := time.Date(2024, 1, 15, 14, 30, 45, 0, time.FixedZone("CET", 3600))
+	if _, err := client.GetEarthquakesUpdatedAfter(context.Background(), updatedAfter, 50); err != nil {
+		t.Fatalf("GetEarthquakesUpdatedAfter() error = %v", err)
+	}
+
+	if len(queries) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(queries))
+	}
+
+	if got := queries[0].Get("updatedafter"); got != "2024-01-15T13:30:45" {
+		t.Errorf("updatedafter = %q, want %q", got, "2024-01-15T13:30:45")
+	}
+	if got := queries[0].Get("limit"); got != "50" {
+		t.Errorf("limit = %q, want %q", got, "50")
+	}
+	if got := queries[0].Get("format"); got != "geojson" {
+		t.Errorf("format = %q, want %q", got, "geojson")
+	}
+}
+
+func TestUSGSClient_GetEarthquakesByTimeRangeAndMagnitude(t *testing.T) {
+	var queries []url.Values
+	server := newTestServer(t, &queries)
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+
+	startTime := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(time.Hour)
+	if _, err := client.GetEarthquakesByTimeRangeAndMagnitude(context.Background(), startTime, endTime, 2.5, 7.0, 100); err != nil {
+		t.Fatalf("GetEarthquakesByTimeRangeAndMagnitude() error = %v", err)
+	}
+
+	if len(queries) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(queries))
+	}
+
+	expected := map[string]string{
+		"starttime":    "2024-01-15T08:00:00",
+		"endtime":      "2024-01-15T09:00:00",
+		"minmagnitude": "2.5",
+		"maxmagnitude": "7.0",
+		"limit":        "100",
+	}
+	for key, want := range expected {
+		if got := queries[0].Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestUSGSClient_SetOrderBy(t *testing.T) {
+	var queries []url.Values
+	server := newTestServer(t, &queries)
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+
+	// Without an order the parameter is left to the USGS default
+	if _, err := client.GetEarthquakesByMagnitude(context.Background(), 4.5, 10, 10); err != nil {
+		t.Fatalf("GetEarthquakesByMagnitude() error = %v", err)
+	}
+	if _, ok := queries[0]["orderby"]; ok {
+		t.Errorf("orderby = %q, want it omitted", queries[0].Get("orderby"))
+	}
+
+	if err := client.SetOrderBy("magnitude"); err != nil {
+		t.Fatalf("SetOrderBy() error = %v", err)
+	}
+	if _, err := client.GetEarthquakesByMagnitude(context.Background(), 4.5, 10, 10); err != nil {
+		t.Fatalf("GetEarthquakesByMagnitude() error = %v", err)
+	}
+	if got := queries[1].Get("orderby"); got != "magnitude" {
+		t.Errorf("orderby = %q, want %q", got, "magnitude")
+	}
+
+	// An invalid order is rejected and keeps the previous one
+	if err := client.SetOrderBy("depth"); err == nil {
+		t.Error("Expected error for invalid order")
+	}
+	if _, err := client.GetEarthquakesByMagnitude(context.Background(), 4.5, 10, 10); err != nil {
+		t.Fatalf("GetEarthquakesByMagnitude() error = %v", err)
+	}
+	if got := queries[2].Get("orderby"); got != "magnitude" {
+		t.Errorf("orderby after invalid order = %q, want %q", got, "magnitude")
+	}
+}
+
+func TestUSGSClient_SetCatalog(t *testing.T) {
+	var queries []url.Values
+	server := newTestServer(t, &queries)
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+
+	// Without a catalog the composite catalog is queried
+	if _, err := client.GetEarthquakesByMagnitude(context.Background(), 4.5, 10, 10); err != nil {
+		t.Fatalf("GetEarthquakesByMagnitude() error = %v", err)
+	}
+	if _, ok := queries[0]["catalog"]; ok {
+		t.Errorf("catalog = %q, want it omitted", queries[0].Get("catalog"))
+	}
+
+	if err := client.SetCatalog(" ak "); err != nil {
+		t.Fatalf("SetCatalog() error = %v", err)
+	}
+	if _, err := client.GetEarthquakesByMagnitude(context.Background(), 4.5, 10, 10); err != nil {
+		t.Fatalf("GetEarthquakesByMagnitude() error = %v", err)
+	}
+	if got := queries[1].Get("catalog"); got != "ak" {
+		t.Errorf("catalog = %q, want %q", got, "ak")
+	}
+
+	// Anything but a single token is rejected and keeps the previous catalog
+	for _, catalog := range []string{"ak,nc", "north cal", "ak&limit=1"} {
+		if err := client.SetCatalog(catalog); err == nil {
+			t.Errorf("Expected error for catalog %q", catalog)
+		}
+	}
+	if _, err := client.GetEarthquakesByMagnitude(context.Background(), 4.5, 10, 10); err != nil {
+		t.Fatalf("GetEarthquakesByMagnitude() error = %v", err)
+	}
+	if got := queries[2].Get("catalog"); got != "ak" {
+		t.Errorf("catalog after invalid catalog = %q, want %q", got, "ak")
+	}
+}
+
+func TestUSGSClient_SetEventType(t *testing.T) {
+	var rawQueries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQueries = append(rawQueries, r.URL.RawQuery)
+		json.NewEncoder(w).Encode(models.USGSResponse{Type: "FeatureCollection"})
+	}))
+	defer server.Close()
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+
+	// Without an event type every type is returned
+	if _, err := client.GetEarthquakesByMagnitude(context.Background(), 4.5, 10, 10); err != nil {
+		t.Fatalf("GetEarthquakesByMagnitude() error = %v", err)
+	}
+	if strings.Contains(rawQueries[0], "eventtype") {
+		t.Errorf("query = %q, want eventtype omitted", rawQueries[0])
+	}
+
+	client.SetEventType("quarry blast")
+	if _, err := client.GetEarthquakesByMagnitude(context.Background(), 4.5, 10, 10); err != nil {
+		t.Fatalf("GetEarthquakesByMagnitude() error = %v", err)
+	}
+	if !strings.Contains(rawQueries[1], "eventtype=quarry%20blast") {
+		t.Errorf("query = %q, want eventtype=quarry%%20blast", rawQueries[1])
+	}
+	if strings.Contains(rawQueries[1], "+") {
+		t.Errorf("query = %q, want spaces encoded as %%20", rawQueries[1])
+	}
+
+	// The server decodes it back to the original value
+	values, err := url.ParseQuery(rawQueries[1])
+	if err != nil {
+		t.Fatalf("ParseQuery() error = %v", err)
+	}
+	if got := values.Get("eventtype"); got != "quarry blast" {
+		t.Errorf("eventtype = %q, want %q", got, "quarry blast")
+	}
+}
+
+func TestClients_UserAgent(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		json.NewEncoder(w).Encode(models.USGSResponse{Type: "FeatureCollection"})
+	}))
+	defer server.Close()
+
+	usgs := NewUSGSClient(server.URL, 5*time.Second)
+	if _, err := usgs.GetRecentEarthquakes(context.Background(), 10); err != nil {
+		t.Fatalf("GetRecentEarthquakes() error = %v", err)
+	}
+	usgs.SetUserAgent("custom-agent/2.0")
+	if _, err := usgs.GetRecentEarthquakes(context.Background(), 10); err != nil {
+		t.Fatalf("GetRecentEarthquakes() error = %v", err)
+	}
+
+	emsc := NewEMSCClient(server.URL, 5*time.Second)
+	emsc.SetUserAgent("")
+	if _, err := emsc.GetFaults(context.Background()); err != nil {
+		t.Fatalf("GetFaults() error = %v", err)
+	}
+
+	want := []string{DefaultUserAgent, "custom-agent/2.0", DefaultUserAgent}
+	if !slices.Equal(userAgents, want) {
+		t.Errorf("User-Agent headers = %v, want %v", userAgents, want)
+	}
+}
+
+func TestClients_SharedConcurrencyLimiter(t *testing.T) {
+	const limit = 2
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		json.NewEncoder(w).Encode(models.USGSResponse{Type: "FeatureCollection"})
+	}))
+	defer server.Close()
+
+	limiter := utils.NewConcurrencyLimiter(limit)
+	usgs := NewUSGSClient(server.URL, 5*time.Second)
+	usgs.SetConcurrencyLimiter(limiter)
+	emsc := NewEMSCClient(server.URL, 5*time.Second)
+	emsc.SetConcurrencyLimiter(limiter)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := usgs.GetRecentEarthquakes(context.Background(), 10); err != nil {
+				t.Errorf("GetRecentEarthquakes() error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := emsc.GetFaults(context.Background()); err != nil {
+				t.Errorf("GetFaults() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight > limit {
+		t.Errorf("Expected at most %d requests in flight, saw %d", limit, maxInFlight)
+	}
+	if maxInFlight == 0 {
+		t.Error("Expected requests to reach the server")
+	}
+}
+
+func TestUSGSClient_MinQuality(t *testing.T) {
+	// One of two records lacks coordinates and time, so the response scores 0.5
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.USGSResponse{
+			Type: "FeatureCollection",
+			Features: []models.Earthquake{
+				{
+					ID:         "good",
+					Properties: models.EarthquakeProperties{Time: 1705305600000},
+					Geometry:   models.Geometry{Coordinates: []float64{-122.4, 37.7, 10}},
+				},
+				{ID: "bad"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+
+	tests := []struct {
+		minQuality float64
+		wantErr    bool
+	}{
+		{minQuality: 0, wantErr: false},
+		{minQuality: 0.5, wantErr: false},
+		{minQuality: 0.51, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		client.SetMinQuality(tt.minQuality)
+		_, err := client.GetRecentEarthquakes(context.Background(), 10)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("minQuality %.2f: error = %v, wantErr %v", tt.minQuality, err, tt.wantErr)
+		}
+	}
+}
+
+func TestUSGSClient_GetEarthquakesByTimeRangeBisects(t *testing.T) {
+	const layout = "2006-01-02T15:04:05"
+
+	var windows []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		start, _ := time.Parse(layout, q.Get("starttime"))
+		end, _ := time.Parse(layout, q.Get("endtime"))
+		windows = append(windows, q.Get("starttime")+"/"+q.Get("endtime"))
+
+		// Anything longer than an hour matches too many events
+		if end.Sub(start) > time.Hour {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Error 400: Bad Request\n\n30123 matching events exceeds search limit of 20000. Modify the search to match fewer events.\n"))
+			return
+		}
+
+		// Boundary events appear in both adjacent windows and must be deduplicated
+		json.NewEncoder(w).Encode(models.USGSResponse{
+			Type: "FeatureCollection",
+			Features: []models.Earthquake{
+				{ID: "end-" + q.Get("endtime")},
+				{ID: "start-" + q.Get("starttime")},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+
+	startTime := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	response, err := client.GetEarthquakesByTimeRange(context.Background(), startTime, startTime.Add(4*time.Hour), 1000)
+	if err != nil {
+		t.Fatalf("GetEarthquakesByTimeRange() error = %v", err)
+	}
+
+	// 1 rejected full range + 2 rejected halves + 4 successful quarters
+	if len(windows) != 7 {
+		t.Errorf("Expected 7 requests, got %d: %v", len(windows), windows)
+	}
+
+	var ids []string
+	for _, eq := range response.Features {
+		ids = append(ids, eq.ID)
+	}
+	expected := []string{
+		"end-2024-01-15T04:00:00", "start-2024-01-15T03:00:00",
+		"end-2024-01-15T03:00:00", "start-2024-01-15T02:00:00",
+		"end-2024-01-15T02:00:00", "start-2024-01-15T01:00:00",
+		"end-2024-01-15T01:00:00", "start-2024-01-15T00:00:00",
+	}
+	if len(ids) != len(expected) {
+		t.Fatalf("IDs = %v, want %v", ids, expected)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Fatalf("IDs = %v, want %v", ids, expected)
+		}
+	}
+	if response.Metadata.Count != len(expected) {
+		t.Errorf("Metadata.Count = %d, want %d", response.Metadata.Count, len(expected))
+	}
+
+	// The limit applies to the merged result
+	windows = nil
+	limited, err := client.GetEarthquakesByTimeRange(context.Background(), startTime, startTime.Add(4*time.Hour), 3)
+	if err != nil {
+		t.Fatalf("GetEarthquakesByTimeRange() error = %v", err)
+	}
+	if len(limited.Features) != 3 {
+		t.Errorf("Expected 3 earthquakes with limit 3, got %d", len(limited.Features))
+	}
+}
+
+func TestUSGSClient_GetEarthquakesByTimeRangeOrderedBisects(t *testing.T) {
+	const layout = "2006-01-02T15:04:05"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		start, _ := time.Parse(layout, q.Get("starttime"))
+		end, _ := time.Parse(layout, q.Get("endtime"))
+		if end.Sub(start) > time.Hour {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Error 400: Bad Request\n\n30123 matching events exceeds search limit of 20000.\n"))
+			return
+		}
+
+		// The later an hour, the weaker its earthquake, so the strongest are in the oldest window
+		mag := 8 - float64(start.Hour())
+		json.NewEncoder(w).Encode(models.USGSResponse{
+			Type: "FeatureCollection",
+			Features: []models.Earthquake{
+				{ID: "eq-" + q.Get("starttime"), Properties: models.EarthquakeProperties{Mag: &mag, Time: start.UnixMilli()}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+	startTime := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		orderBy string
+		want    []string
+	}{
+		{orderBy: "magnitude", want: []string{"eq-2024-01-15T00:00:00", "eq-2024-01-15T01:00:00"}},
+		{orderBy: "magnitude-asc", want: []string{"eq-2024-01-15T03:00:00", "eq-2024-01-15T02:00:00"}},
+		{orderBy: "time-asc", want: []string{"eq-2024-01-15T00:00:00", "eq-2024-01-15T01:00:00"}},
+		{orderBy: "time", want: []string{"eq-2024-01-15T03:00:00", "eq-2024-01-15T02:00:00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.orderBy, func(t *testing.T) {
+			if err := client.SetOrderBy(tt.orderBy); err != nil {
+				t.Fatalf("SetOrderBy() error = %v", err)
+			}
+
+			// The limit applies after the windows are merged in the requested order
+			response, err := client.GetEarthquakesByTimeRange(context.Background(), startTime, startTime.Add(4*time.Hour), 2)
+			if err != nil {
+				t.Fatalf("GetEarthquakesByTimeRange() error = %v", err)
+			}
+			var ids []string
+			for _, eq := range response.Features {
+				ids = append(ids, eq.ID)
+			}
+			if !slices.Equal(ids, tt.want) || response.Metadata.Count != len(tt.want) {
+				t.Errorf("IDs = %v (count %d), want %v", ids, response.Metadata.Count, tt.want)
+			}
+		})
+	}
+}
+
+func TestUSGSClient_GetEarthquakesByTimeRangeResume(t *testing.T) {
+	const layout = "2006-01-02T15:04:05"
+
+	var windows []string
+	failWindow := "2024-01-15T01:00:00/2024-01-15T02:00:00"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		start, _ := time.Parse(layout, q.Get("starttime"))
+		end, _ := time.Parse(layout, q.Get("endtime"))
+		window := q.Get("starttime") + "/" + q.Get("endtime")
+		windows = append(windows, window)
+
+		if end.Sub(start) > time.Hour {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("30123 matching events exceeds search limit of 20000."))
+			return
+		}
+		if window == failWindow {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		json.NewEncoder(w).Encode(models.USGSResponse{
+			Type:     "FeatureCollection",
+			Features: []models.Earthquake{{ID: "eq-" + q.Get("starttime")}},
+		})
+	}))
+	defer server.Close()
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	startTime := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(4 * time.Hour)
+
+	// The first run is interrupted by the failing second quarter
+	state, err := storage.LoadCollectionState(statePath)
+	if err != nil {
+		t.Fatalf("LoadCollectionState() error = %v", err)
+	}
+	client := NewUSGSClient(server.URL, 5*time.Second)
+	client.SetPaginationState(state)
+	if _, err := client.GetEarthquakesByTimeRange(context.Background(), startTime, endTime, 1000); err == nil {
+		t.Fatal("Expected the first run to fail")
+	}
+
+	// The second run reloads the state file and only fetches what is missing
+	failWindow = ""
+	windows = nil
+	state, err = storage.LoadCollectionState(statePath)
+	if err != nil {
+		t.Fatalf("LoadCollectionState() error = %v", err)
+	}
+	client = NewUSGSClient(server.URL, 5*time.Second)
+	client.SetPaginationState(state)
+	response, err := client.GetEarthquakesByTimeRange(context.Background(), startTime, endTime, 1000)
+	if err != nil {
+		t.Fatalf("GetEarthquakesByTimeRange() error = %v", err)
+	}
+
+	expectedWindows := []string{
+		"2024-01-15T01:00:00/2024-01-15T02:00:00",
+		"2024-01-15T00:00:00/2024-01-15T01:00:00",
+	}
+	if len(windows) != len(expectedWindows) || windows[0] != expectedWindows[0] || windows[1] != expectedWindows[1] {
+		t.Errorf("Resumed requests = %v, want %v", windows, expectedWindows)
+	}
+
+	var ids []string
+	for _, eq := range response.Features {
+		ids = append(ids, eq.ID)
+	}
+	expectedIDs := []string{"eq-2024-01-15T03:00:00", "eq-2024-01-15T02:00:00", "eq-2024-01-15T01:00:00", "eq-2024-01-15T00:00:00"}
+	if len(ids) != len(expectedIDs) {
+		t.Fatalf("IDs = %v, want %v", ids, expectedIDs)
+	}
+	for i := range expectedIDs {
+		if ids[i] != expectedIDs[i] {
+			t.Fatalf("IDs = %v, want %v", ids, expectedIDs)
+		}
+	}
+}
+
+func TestUSGSClient_ContextTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Hang until the client gives up
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	// The per-request client timeout is generous; only the context bounds the call
+	client := NewUSGSClient(server.URL, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetRecentEarthquakes(ctx, 10)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Request was not cancelled promptly, took %v", elapsed)
+	}
+}
+
+func TestUSGSClient_DecodeRetry(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// A response cut off mid-body
+			w.Write([]byte(`{"type":"FeatureCollection","features":[{"id":"us1","prop`))
+			return
+		}
+		json.NewEncoder(w).Encode(models.USGSResponse{Type: "FeatureCollection", Features: []models.Earthquake{{ID: "us1"}}})
+	}))
+	defer server.Close()
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+
+	// Without a decode retry strategy the truncated body fails the query and is kept for debugging
+	_, err := client.GetRecentEarthquakes(context.Background(), 10)
+	var collectionErr *utils.CollectionError
+	if !errors.As(err, &collectionErr) {
+		t.Fatalf("Expected a CollectionError, got %v", err)
+	}
+	if collectionErr.Type != utils.ErrorTypeDecode || !collectionErr.Retryable {
+		t.Errorf("Expected a retryable decode error, got type %s retryable %v", collectionErr.Type, collectionErr.Retryable)
+	}
+	if body := collectionErr.Context[utils.ContextResponseBody]; !strings.HasPrefix(body, `{"type":"FeatureCollection"`) {
+		t.Errorf("Unexpected captured body: %q", body)
+	}
+
+	requests.Store(0)
+	client.SetDecodeRetry(&utils.RetryStrategy{MaxRetries: 2, BaseDelay: time.Millisecond})
+	response, err := client.GetRecentEarthquakes(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("GetRecentEarthquakes() error = %v", err)
+	}
+	if len(response.Features) != 1 || requests.Load() != 2 {
+		t.Errorf("Expected 1 earthquake after 2 requests, got %d after %d", len(response.Features), requests.Load())
+	}
+}
+
+func TestUSGSClient_DecodeErrorPage(t *testing.T) {
+	page := "<html><body>" + strings.Repeat("Service Unavailable ", 500) + "</body></html>"
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+	client.SetDecodeRetry(&utils.RetryStrategy{MaxRetries: 2, BaseDelay: time.Millisecond})
+
+	// A complete response that is not JSON is not retried
+	_, err := client.GetRecentEarthquakes(context.Background(), 10)
+	var collectionErr *utils.CollectionError
+	if !errors.As(err, &collectionErr) || collectionErr.Retryable {
+		t.Fatalf("Expected a non-retryable CollectionError, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 request, got %d", requests.Load())
+	}
+
+	// Only the start of the page is kept
+	if body := collectionErr.Context[utils.ContextResponseBody]; body != page[:4096] {
+		t.Errorf("Captured %d bytes, want the first 4096", len(body))
+	}
+}
+
+func TestUSGSClient_GetEventDetail(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if query.Get("eventid") != "ci38457511" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, "testdata/usgs_detail.json")
+	}))
+	defer server.Close()
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+	client.SetEventType("earthquake")
+
+	detail, err := client.GetEventDetail(context.Background(), "ci38457511")
+	if err != nil {
+		t.Fatalf("GetEventDetail() error = %v", err)
+	}
+	if query.Get("format") != "geojson" || query.Has("eventtype") {
+		t.Errorf("Unexpected query: %v", query)
+	}
+
+	if detail.ID != "ci38457511" || detail.Properties.GetMagnitude() != "7.1 mw" {
+		t.Errorf("Unexpected summary: id %q, mag %s", detail.ID, detail.Properties.GetMagnitude())
+	}
+	if types := detail.ProductTypes(); types["moment-tensor"] != 1 || types["phase-data"] != 1 || len(types) != 2 {
+		t.Errorf("ProductTypes() = %v", types)
+	}
+	tensor := detail.Properties.Products["moment-tensor"][0]
+	if tensor.Properties["derived-magnitude-type"] != "Mww" || tensor.Contents["quakeml.xml"].Length != 3528 {
+		t.Errorf("Unexpected moment tensor: %+v", tensor)
+	}
+
+	// Unknown events are a non-retryable client error
+	_, err = client.GetEventDetail(context.Background(), "xx0")
+	var collectionErr *utils.CollectionError
+	if !errors.As(err, &collectionErr) || collectionErr.Type != utils.ErrorTypeClient || collectionErr.Retryable {
+		t.Errorf("Expected a non-retryable client error, got %v", err)
+	}
+}
+
+func TestUSGSClient_GetEarthquakesByTimeRangeNoLimit(t *testing.T) {
+	const layout = "2006-01-02T15:04:05"
+
+	var limits []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		start, _ := time.Parse(layout, q.Get("starttime"))
+		end, _ := time.Parse(layout, q.Get("endtime"))
+		limits = append(limits, q.Get("limit"))
+
+		if end.Sub(start) > time.Hour {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Error 400: Bad Request\n\n30123 matching events exceeds search limit of 20000.\n"))
+			return
+		}
+		json.NewEncoder(w).Encode(models.USGSResponse{
+			Type:     "FeatureCollection",
+			Features: []models.Earthquake{{ID: "eq-" + q.Get("starttime")}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+
+	startTime := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	response, err := client.GetEarthquakesByTimeRange(context.Background(), startTime, startTime.Add(4*time.Hour), NoLimit)
+	if err != nil {
+		t.Fatalf("GetEarthquakesByTimeRange() error = %v", err)
+	}
+
+	// Every window is queried at the USGS cap and the range is paginated down to hour windows
+	if len(limits) != 7 {
+		t.Errorf("Expected 7 requests, got %d", len(limits))
+	}
+	for _, limit := range limits {
+		if limit != "20000" {
+			t.Errorf("Expected every request to ask for 20000 events, got limits %v", limits)
+			break
+		}
+	}
+	if len(response.Features) != 4 || response.Metadata.Count != 4 {
+		t.Errorf("Expected all 4 earthquakes, got %d (count %d)", len(response.Features), response.Metadata.Count)
+	}
+}
+
+func TestUSGSClient_GetEarthquakesByRadius(t *testing.T) {
+	var queries []url.Values
+	server := newTestServer(t, &queries)
+
+	client := NewUSGSClient(server.URL, 5*time.Second)
+	if _, err := client.GetEarthquakesByRadius(context.Background(), 34.05, -118.24, 100, 50); err != nil {
+		t.Fatalf("GetEarthquakesByRadius() error = %v", err)
+	}
+
+	if len(queries) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(queries))
+	}
+	expected := map[string]string{
+		"latitude":    "34.05",
+		"longitude":   "-118.24",
+		"maxradiuskm": "100",
+		"limit":       "50",
+	}
+	for key, want := range expected {
+		if got := queries[0].Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestUSGSClient_GetEarthquakesByRadiusInvalid(t *testing.T) {
+	var queries []url.Values
+	server := newTestServer(t, &queries)
+	client := NewUSGSClient(server.URL, 5*time.Second)
+
+	tests := []struct {
+		name               string
+		lat, lon, radiusKm float64
+		want               string
+	}{
+		{"zero radius", 0, 0, 0, "invalid radius"},
+		{"negative radius", 0, 0, -5, "invalid radius"},
+		{"radius too large", 0, 0, 30000, "invalid radius"},
+		{"latitude out of range", 91, 0, 10, "invalid latitude"},
+		{"longitude out of range", 0, -181, 10, "invalid longitude"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetEarthquakesByRadius(context.Background(), tt.lat, tt.lon, tt.radiusKm, 10)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("GetEarthquakesByRadius() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if len(queries) != 0 {
+		t.Errorf("Expected no requests for invalid input, got %d", len(queries))
+	}
+}
+
+func TestUSGSClient_Mirrors(t *testing.T) {
+	var primaryRequests int
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryRequests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+
+	var queries []url.Values
+	mirror := newTestServer(t, &queries)
+
+	client := NewUSGSClient(primary.URL, 5*time.Second)
+	client.SetMirrors([]string{mirror.URL})
+	var failed, next string
+	client.SetFailoverHook(func(failedURL, nextURL string, err error) {
+		failed, next = failedURL, nextURL
+	})
+
+	if _, err := client.GetRecentEarthquakes(context.Background(), 10); err != nil {
+		t.Fatalf("GetRecentEarthquakes() error = %v", err)
+	}
+	if primaryRequests != 1 || len(queries) != 1 {
+		t.Errorf("Expected one request to each endpoint, got %d and %d", primaryRequests, len(queries))
+	}
+	if failed != primary.URL || next != mirror.URL {
+		t.Errorf("Failover hook got %q -> %q, want %q -> %q", failed, next, primary.URL, mirror.URL)
+	}
+}
+
+func TestUSGSClient_MirrorsSkipClientErrors(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer primary.Close()
+
+	var queries []url.Values
+	mirror := newTestServer(t, &queries)
+
+	client := NewUSGSClient(primary.URL, 5*time.Second)
+	client.SetMirrors([]string{mirror.URL})
+
+	// A rejected query would be rejected by every mirror
+	if _, err := client.GetRecentEarthquakes(context.Background(), 10); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if len(queries) != 0 {
+		t.Errorf("Expected no requests to the mirror, got %d", len(queries))
+	}
+}
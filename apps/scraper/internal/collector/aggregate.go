@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// maxRateCells caps the size of a rate tally so a tiny bucket over a long span fails instead of
+// exhausting memory
+const maxRateCells = 10_000_000
+
+// RateTally counts earthquakes per time window and magnitude band. Windows and bands are
+// contiguous, from the earliest to the latest earthquake and the smallest to the largest
+// magnitude, so windows and bands without earthquakes are counted as zero.
+type RateTally struct {
+	Bucket    time.Duration
+	BandWidth float64
+	Windows   []time.Time // start of each window, ascending
+	Bands     []float64   // lower bound of each band, ascending
+	Counts    [][]int     // Counts[window][band]
+}
+
+// TallyRates buckets earthquakes into time windows of the given length, aligned to UTC, and
+// magnitude bands of the given width. A band includes its lower bound and excludes its upper one.
+// Earthquakes without a magnitude are not counted.
+func TallyRates(earthquakes []models.Earthquake, bucket time.Duration, bandWidth float64) (*RateTally, error) {
+	if bucket <= 0 {
+		return nil, fmt.Errorf("bucket must be positive, got %v", bucket)
+	}
+	if bandWidth <= 0 {
+		return nil, fmt.Errorf("magnitude band width must be positive, got %g", bandWidth)
+	}
+
+	var known []models.Earthquake
+	for _, eq := range earthquakes {
+		if eq.Properties.Mag != nil {
+			known = append(known, eq)
+		}
+	}
+	earthquakes = known
+
+	tally := &RateTally{Bucket: bucket, BandWidth: bandWidth}
+	if len(earthquakes) == 0 {
+		return tally, nil
+	}
+
+	windows := make([]time.Time, len(earthquakes))
+	bands := make([]int, len(earthquakes))
+	for i, eq := range earthquakes {
+		windows[i] = time.UnixMilli(eq.Properties.Time).UTC().Truncate(bucket)
+		bands[i] = magnitudeBand(*eq.Properties.Mag, bandWidth)
+	}
+
+	firstWindow, lastWindow := windows[0], windows[0]
+	minBand, maxBand := bands[0], bands[0]
+	for i := range earthquakes {
+		if windows[i].Before(firstWindow) {
+			firstWindow = windows[i]
+		}
+		if windows[i].After(lastWindow) {
+			lastWindow = windows[i]
+		}
+		minBand = min(minBand, bands[i])
+		maxBand = max(maxBand, bands[i])
+	}
+
+	windowCount := int64(lastWindow.Sub(firstWindow)/bucket) + 1
+	bandCount := int64(maxBand-minBand) + 1
+	if windowCount*bandCount > maxRateCells {
+		return nil, fmt.Errorf("%d windows of %v and %d magnitude bands are too many; use a larger bucket or band", windowCount, bucket, bandCount)
+	}
+
+	for w := int64(0); w < windowCount; w++ {
+		tally.Windows = append(tally.Windows, firstWindow.Add(time.Duration(w)*bucket))
+		tally.Counts = append(tally.Counts, make([]int, bandCount))
+	}
+	for b := minBand; b <= maxBand; b++ {
+		tally.Bands = append(tally.Bands, float64(b)*bandWidth)
+	}
+
+	for i := range earthquakes {
+		w := windows[i].Sub(firstWindow) / bucket
+		tally.Counts[w][bands[i]-minBand]++
+	}
+	return tally, nil
+}
+
+// magnitudeBand returns the index of the band a magnitude falls into. The small epsilon keeps
+// magnitudes on a band boundary, such as 0.3 with a width of 0.1, from landing one band low.
+func magnitudeBand(mag, bandWidth float64) int {
+	return int(math.Floor(mag/bandWidth + 1e-9))
+}
+
+// bandLabel formats the lower bound of a band with as many decimals as the band width, and at least one
+func (t *RateTally) bandLabel(lower float64) string {
+	decimals := 1
+	if _, fraction, ok := strings.Cut(strconv.FormatFloat(t.BandWidth, 'f', -1, 64), "."); ok {
+		decimals = max(decimals, len(fraction))
+	}
+	return strconv.FormatFloat(lower, 'f', decimals, 64)
+}
+
+// WriteCSV writes the tally as window_start,mag_band,count rows, one per window and band, where
+// mag_band is the band's lower bound
+func (t *RateTally) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"window_start", "mag_band", "count"}); err != nil {
+		return err
+	}
+
+	for i, window := range t.Windows {
+		for j, band := range t.Bands {
+			row := []string{window.Format(time.RFC3339), t.bandLabel(band), strconv.Itoa(t.Counts[i][j])}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// earthquakeWithMag returns an earthquake of the given magnitude at the given time
+func earthquakeWithMag(id string, at time.Time, mag float64) models.Earthquake {
+	return testEarthquake(id, models.EarthquakeProperties{Time: at.UnixMilli(), Mag: models.Float64(mag)})
+}
+
+func TestTallyRates(t *testing.T) {
+	base := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+
+	earthquakes := []models.Earthquake{
+		// On the window boundary: belongs to the window starting at 08:00, not 07:00
+		earthquakeWithMag("start", base, 2.0),
+		// Last millisecond of the 08:00 window
+		earthquakeWithMag("end", base.Add(time.Hour-time.Millisecond), 2.9),
+		// 09:00 has no earthquakes
+		earthquakeWithMag("later", base.Add(2*time.Hour+30*time.Minute), 4.0),
+	}
+
+	tally, err := TallyRates(earthquakes, time.Hour, 1.0)
+	if err != nil {
+		t.Fatalf("TallyRates() error = %v", err)
+	}
+
+	wantWindows := []time.Time{base, base.Add(time.Hour), base.Add(2 * time.Hour)}
+	if !reflect.DeepEqual(tally.Windows, wantWindows) {
+		t.Errorf("Windows = %v, want %v", tally.Windows, wantWindows)
+	}
+	// Magnitude 3 has no earthquakes but sits between 2 and 4
+	if want := []float64{2, 3, 4}; !reflect.DeepEqual(tally.Bands, want) {
+		t.Errorf("Bands = %v, want %v", tally.Bands, want)
+	}
+	wantCounts := [][]int{
+		{2, 0, 0},
+		{0, 0, 0},
+		{0, 0, 1},
+	}
+	if !reflect.DeepEqual(tally.Counts, wantCounts) {
+		t.Errorf("Counts = %v, want %v", tally.Counts, wantCounts)
+	}
+
+	var csv strings.Builder
+	if err := tally.WriteCSV(&csv); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != 1+3*3 {
+		t.Fatalf("Expected a header and 9 rows, got %d lines:\n%s", len(lines), csv.String())
+	}
+	if lines[0] != "window_start,mag_band,count" || lines[1] != "2024-01-15T08:00:00Z,2.0,2" || lines[4] != "2024-01-15T09:00:00Z,2.0,0" {
+		t.Errorf("Unexpected CSV:\n%s", csv.String())
+	}
+}
+
+func TestTallyRates_BandBoundaries(t *testing.T) {
+	at := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	earthquakes := []models.Earthquake{
+		earthquakeWithMag("a", at, 0.3),
+		earthquakeWithMag("b", at, 0.39),
+		earthquakeWithMag("c", at, -0.1),
+	}
+
+	tally, err := TallyRates(earthquakes, 24*time.Hour, 0.1)
+	if err != nil {
+		t.Fatalf("TallyRates() error = %v", err)
+	}
+
+	// 0.3 / 0.1 is just below 3 in floating point but still belongs to the 0.3 band
+	counts := map[string]int{}
+	for j, band := range tally.Bands {
+		counts[tally.bandLabel(band)] = tally.Counts[0][j]
+	}
+	if counts["0.3"] != 2 || counts["-0.1"] != 1 || counts["0.2"] != 0 {
+		t.Errorf("Unexpected band counts %v", counts)
+	}
+	if len(tally.Bands) != 5 {
+		t.Errorf("Expected 5 contiguous bands from -0.1 to 0.3, got %v", tally.Bands)
+	}
+}
+
+func TestTallyRates_Empty(t *testing.T) {
+	tally, err := TallyRates(nil, time.Hour, 1.0)
+	if err != nil {
+		t.Fatalf("TallyRates() error = %v", err)
+	}
+	var csv strings.Builder
+	if err := tally.WriteCSV(&csv); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	if csv.String() != "window_start,mag_band,count\n" {
+		t.Errorf("Expected only the header, got %q", csv.String())
+	}
+
+	if _, err := TallyRates(nil, 0, 1.0); err == nil {
+		t.Error("Expected error for a zero bucket")
+	}
+	if _, err := TallyRates(nil, time.Hour, 0); err == nil {
+		t.Error("Expected error for a zero band width")
+	}
+}
@@ -0,0 +1,207 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"quakewatch-scraper/internal/api"
+	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/utils"
+)
+
+// DefaultBackfillChunk is the time span collected into each backfill file
+const DefaultBackfillChunk = 7 * 24 * time.Hour
+
+// backfillLimit is the USGS per-query cap; a chunk reaching it is too large to collect in one request
+const backfillLimit = 20000
+
+// BackfillChunk is one time window of a backfill, from Start up to End
+type BackfillChunk struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Key identifies the chunk in the backfill progress
+func (c BackfillChunk) Key() string {
+	return formatQueryTime(c.Start) + "/" + formatQueryTime(c.End)
+}
+
+// Filename returns the name the chunk is saved under: its dates, or its times when the chunk
+// does not start and end at midnight
+func (c BackfillChunk) Filename() string {
+	layout := "2006-01-02"
+	if !isMidnight(c.Start) || !isMidnight(c.End) {
+		layout = "2006-01-02_15-04-05"
+	}
+	return fmt.Sprintf("earthquakes_backfill_%s_%s", c.Start.Format(layout), c.End.Format(layout))
+}
+
+// isMidnight reports whether t is the start of a day
+func isMidnight(t time.Time) bool {
+	return t.Equal(t.Truncate(24 * time.Hour))
+}
+
+// BackfillChunks splits the range from start to end into consecutive chunks of the given size.
+// The last chunk ends at end and may be shorter.
+func BackfillChunks(start, end time.Time, size time.Duration) ([]BackfillChunk, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("backfill chunk size must be positive, got %v", size)
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("backfill end %s must be after start %s", formatQueryTime(end), formatQueryTime(start))
+	}
+
+	var chunks []BackfillChunk
+	for chunkStart := start; chunkStart.Before(end); chunkStart = chunkStart.Add(size) {
+		chunkEnd := chunkStart.Add(size)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+		chunks = append(chunks, BackfillChunk{Start: chunkStart, End: chunkEnd})
+	}
+	return chunks, nil
+}
+
+// BackfillProgress records which backfill chunks have been saved, e.g. a storage.CollectionState
+type BackfillProgress interface {
+	SubQuery(key string) (split, done bool, earthquakes []models.Earthquake)
+	MarkDone(key string, earthquakes []models.Earthquake) error
+}
+
+// BackfillResult counts the chunks and earthquakes of a backfill run
+type BackfillResult struct {
+	Chunks      int
+	Skipped     int
+	Earthquakes int
+}
+
+// Backfiller collects a long time range one chunk at a time, saving each chunk to its own file
+type Backfiller struct {
+	collector *EarthquakeCollector
+	minMag    float64
+	chunk     time.Duration
+	progress  BackfillProgress
+	pause     time.Duration
+}
+
+// NewBackfiller creates a backfiller collecting earthquakes of at least minMag in chunks of the given size
+func NewBackfiller(collector *EarthquakeCollector, minMag float64, chunk time.Duration) *Backfiller {
+	return &Backfiller{
+		collector: collector,
+		minMag:    minMag,
+		chunk:     chunk,
+	}
+}
+
+// SetProgress records each saved chunk in progress and skips the chunks it already holds,
+// so an interrupted backfill continues where it stopped
+func (b *Backfiller) SetProgress(progress BackfillProgress) {
+	b.progress = progress
+}
+
+// SetPause sets how long to wait between chunk requests to stay within the USGS rate limit
+func (b *Backfiller) SetPause(pause time.Duration) {
+	b.pause = pause
+}
+
+// StateName returns the name the progress of a backfill from start to end is kept under. Besides
+// the range it covers every setting that changes what the chunks hold: the minimum magnitude, the
+// chunk size, the filters and the other query options. A run with other settings therefore does
+// not skip chunks saved by this one.
+func (b *Backfiller) StateName(start, end time.Time) string {
+	params := map[string]string{
+		"query":  "backfill",
+		"start":  formatQueryTime(start),
+		"end":    formatQueryTime(end),
+		"minmag": formatQueryFloat(b.minMag),
+		"chunk":  b.chunk.String(),
+	}
+	for key, value := range b.collector.queryOptions {
+		params[key] = value
+	}
+	hash := strings.TrimPrefix(QueryFilename(params), "earthquakes_")
+	return fmt.Sprintf("backfill_%s_%s_%s", start.Format("2006-01-02"), end.Format("2006-01-02"), hash)
+}
+
+// Run collects every chunk from start to end that is not recorded as done. It stops at the first
+// chunk that fails; the chunks saved before it stay recorded.
+func (b *Backfiller) Run(ctx context.Context, start, end time.Time) (BackfillResult, error) {
+	chunks, err := BackfillChunks(start, end, b.chunk)
+	if err != nil {
+		return BackfillResult{}, err
+	}
+
+	result := BackfillResult{Chunks: len(chunks)}
+	requested := false
+	for i, chunk := range chunks {
+		if b.progress != nil {
+			if _, done, _ := b.progress.SubQuery(chunk.Key()); done {
+				result.Skipped++
+				continue
+			}
+		}
+
+		if requested && b.pause > 0 {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(b.pause):
+			}
+		}
+		requested = true
+
+		b.collector.printf("Backfilling chunk %d of %d: %s to %s\n", i+1, len(chunks),
+			chunk.Start.Format("2006-01-02 15:04:05"), chunk.End.Format("2006-01-02 15:04:05"))
+		count, err := b.collectChunk(ctx, chunk)
+		if err != nil {
+			return result, err
+		}
+		result.Earthquakes += count
+
+		if b.progress != nil {
+			if err := b.progress.MarkDone(chunk.Key(), nil); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// collectChunk fetches, filters and saves the earthquakes of one chunk and returns how many were saved
+func (b *Backfiller) collectChunk(ctx context.Context, chunk BackfillChunk) (int, error) {
+	c := b.collector
+	query := map[string]string{
+		"query":  "backfill",
+		"start":  formatQueryTime(chunk.Start),
+		"end":    formatQueryTime(chunk.End),
+		"minmag": formatQueryFloat(b.minMag),
+	}
+	params := map[string]string{
+		"starttime":    chunk.Start.Format("2006-01-02T15:04:05"),
+		"endtime":      chunk.End.Format("2006-01-02T15:04:05"),
+		"minmagnitude": strconv.FormatFloat(b.minMag, 'f', -1, 64),
+		"limit":        strconv.Itoa(backfillLimit),
+	}
+
+	earthquakes, err := c.usgsClient.GetEarthquakes(ctx, params)
+	if err == nil && len(earthquakes.Features) >= backfillLimit {
+		err = api.ErrSearchLimitExceeded
+	}
+	if errors.Is(err, api.ErrSearchLimitExceeded) {
+		return 0, fmt.Errorf("chunk %s holds more than %d earthquakes; use a smaller chunk: %w", chunk.Key(), backfillLimit, err)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to backfill chunk %s: %w", chunk.Key(), utils.ErrorContext(err, query))
+	}
+
+	c.printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
+	if err := c.save(earthquakes, chunk.Filename(), query); err != nil {
+		return 0, err
+	}
+	return len(earthquakes.Features), nil
+}
@@ -0,0 +1,188 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/api"
+	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/storage"
+)
+
+func TestBackfillChunks(t *testing.T) {
+	day := 24 * time.Hour
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		end     time.Time
+		size    time.Duration
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "even split",
+			end:  start.Add(14 * day),
+			size: 7 * day,
+			want: []string{"2024-01-01T00:00:00Z/2024-01-08T00:00:00Z", "2024-01-08T00:00:00Z/2024-01-15T00:00:00Z"},
+		},
+		{
+			name: "shorter last chunk",
+			end:  start.Add(10 * day),
+			size: 7 * day,
+			want: []string{"2024-01-01T00:00:00Z/2024-01-08T00:00:00Z", "2024-01-08T00:00:00Z/2024-01-11T00:00:00Z"},
+		},
+		{
+			name: "range shorter than a chunk",
+			end:  start.Add(12 * time.Hour),
+			size: 7 * day,
+			want: []string{"2024-01-01T00:00:00Z/2024-01-01T12:00:00Z"},
+		},
+		{name: "end before start", end: start.Add(-day), size: day, wantErr: true},
+		{name: "empty range", end: start, size: day, wantErr: true},
+		{name: "zero size", end: start.Add(day), size: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := BackfillChunks(start, tt.end, tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BackfillChunks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, chunk := range chunks {
+				got = append(got, chunk.Key())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BackfillChunks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackfillChunk_Filename(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got, want := (BackfillChunk{Start: start, End: start.AddDate(0, 0, 7)}).Filename(), "earthquakes_backfill_2024-01-01_2024-01-08"; got != want {
+		t.Errorf("Filename() = %q, want %q", got, want)
+	}
+	if got, want := (BackfillChunk{Start: start, End: start.Add(6 * time.Hour)}).Filename(), "earthquakes_backfill_2024-01-01_00-00-00_2024-01-01_06-00-00"; got != want {
+		t.Errorf("Filename() = %q, want %q", got, want)
+	}
+}
+
+func TestBackfiller_Resume(t *testing.T) {
+	var windows []string
+	failAt := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		window := q.Get("starttime") + "/" + q.Get("endtime")
+		windows = append(windows, window)
+		if q.Get("minmagnitude") != "4.5" {
+			t.Errorf("minmagnitude = %q, want 4.5", q.Get("minmagnitude"))
+		}
+		if window == failAt {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(models.USGSResponse{
+			Type:     "FeatureCollection",
+			Features: []models.Earthquake{{Type: "Feature", ID: "eq-" + q.Get("starttime")}},
+		})
+	}))
+	defer server.Close()
+
+	outputDir := t.TempDir()
+	jsonStorage := storage.NewJSONStorage(outputDir)
+	statePath := jsonStorage.CollectionStatePath("backfill")
+
+	newBackfiller := func() *Backfiller {
+		t.Helper()
+		collector := NewEarthquakeCollector(api.NewUSGSClient(server.URL, 5*time.Second), jsonStorage)
+		collector.SetProgressOutput(io.Discard)
+		state, err := storage.LoadCollectionState(statePath)
+		if err != nil {
+			t.Fatalf("LoadCollectionState() error = %v", err)
+		}
+		backfiller := NewBackfiller(collector, 4.5, DefaultBackfillChunk)
+		backfiller.SetProgress(state)
+		return backfiller
+	}
+
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 21)
+
+	// The third chunk fails: the first two are saved and recorded
+	failAt = "2024-01-15T00:00:00/2024-01-22T00:00:00"
+	result, err := newBackfiller().Run(ctx, start, end)
+	if err == nil {
+		t.Fatal("Expected the failing chunk to stop the backfill")
+	}
+	if result.Earthquakes != 2 || len(windows) != 3 {
+		t.Errorf("Expected 2 saved earthquakes after 3 requests, got %d after %v", result.Earthquakes, windows)
+	}
+
+	// The rerun only requests the chunk that failed
+	failAt = ""
+	windows = nil
+	result, err = newBackfiller().Run(ctx, start, end)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{"2024-01-15T00:00:00/2024-01-22T00:00:00"}; !reflect.DeepEqual(windows, want) {
+		t.Errorf("Resumed run requested %v, want %v", windows, want)
+	}
+	if result != (BackfillResult{Chunks: 3, Skipped: 2, Earthquakes: 1}) {
+		t.Errorf("Run() = %+v, want 3 chunks with 2 skipped and 1 earthquake", result)
+	}
+
+	for _, name := range []string{"2024-01-01_2024-01-08", "2024-01-08_2024-01-15", "2024-01-15_2024-01-22"} {
+		path := filepath.Join(outputDir, "earthquakes", "earthquakes_backfill_"+name+".json")
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected chunk file %s: %v", path, err)
+		}
+	}
+}
+
+func TestBackfiller_StateName(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	stateName := func(minMag float64, chunk time.Duration, filters FilterSpec) string {
+		t.Helper()
+		collector := NewEarthquakeCollector(nil, nil)
+		if err := collector.AddFilters(filters); err != nil {
+			t.Fatalf("AddFilters() error = %v", err)
+		}
+		return NewBackfiller(collector, minMag, chunk).StateName(start, end)
+	}
+
+	base := stateName(4.5, DefaultBackfillChunk, FilterSpec{})
+	if !strings.HasPrefix(base, "backfill_2024-01-01_2024-02-01_") {
+		t.Errorf("StateName() = %q, want it to start with the range", base)
+	}
+	if again := stateName(4.5, DefaultBackfillChunk, FilterSpec{}); again != base {
+		t.Errorf("Expected the same settings to resume the same state, got %q and %q", base, again)
+	}
+
+	// A run with other settings must not skip chunks saved with these
+	others := map[string]string{
+		"min magnitude": stateName(5, DefaultBackfillChunk, FilterSpec{}),
+		"chunk size":    stateName(4.5, 24*time.Hour, FilterSpec{}),
+		"filters":       stateName(4.5, DefaultBackfillChunk, FilterSpec{Network: []string{"us"}}),
+	}
+	for setting, name := range others {
+		if name == base {
+			t.Errorf("Expected a different state name for a different %s, got %q", setting, name)
+		}
+	}
+}
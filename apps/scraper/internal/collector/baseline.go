@@ -0,0 +1,235 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+const (
+	// DefaultBaselineWindow is how many hourly samples a baseline keeps: four weeks
+	DefaultBaselineWindow = 4 * 7 * 24
+	// DefaultAnomalyThreshold is how many standard deviations above the baseline mean an hour
+	// must be to be flagged
+	DefaultAnomalyThreshold = 3.0
+	// MinBaselineSamples is how many hours a baseline needs before it flags anomalies
+	MinBaselineSamples = 24
+)
+
+// HourStats summarizes the earthquakes of one hour
+type HourStats struct {
+	Hour          time.Time `json:"hour"` // start of the clock hour, UTC
+	Count         int       `json:"count"`
+	MeanMagnitude float64   `json:"mean_magnitude"` // over earthquakes with a magnitude; 0 if there are none
+	MaxMagnitude  float64   `json:"max_magnitude"`
+}
+
+// StatsForHour summarizes the earthquakes that occurred in the hour up to end. The sample is
+// keyed by the clock hour end falls in, so repeated collections within an hour replace each
+// other in a baseline.
+func StatsForHour(earthquakes []models.Earthquake, end time.Time) HourStats {
+	stats := HourStats{Hour: end.UTC().Truncate(time.Hour)}
+	from, to := end.Add(-time.Hour).UnixMilli(), end.UnixMilli()
+
+	var totalMag float64
+	magnitudes := 0
+	for _, eq := range earthquakes {
+		if eq.Properties.Time <= from || eq.Properties.Time > to {
+			continue
+		}
+		stats.Count++
+
+		mag, ok := eq.Properties.Magnitude()
+		if !ok {
+			continue
+		}
+		if magnitudes == 0 || mag > stats.MaxMagnitude {
+			stats.MaxMagnitude = mag
+		}
+		totalMag += mag
+		magnitudes++
+	}
+	if magnitudes > 0 {
+		stats.MeanMagnitude = totalMag / float64(magnitudes)
+	}
+	return stats
+}
+
+// Baseline is a rolling window of hourly statistics that new hours are compared against
+type Baseline struct {
+	Window int         `json:"window"` // samples kept; the oldest are dropped first
+	Hours  []HourStats `json:"hours"`  // ascending by hour
+}
+
+// Anomaly is a metric of an hour that lies far above its baseline
+type Anomaly struct {
+	Metric string
+	Value  float64
+	Mean   float64
+	StdDev float64
+	ZScore float64 // +Inf when the baseline never varied
+}
+
+// String describes the anomaly for progress output
+func (a Anomaly) String() string {
+	return fmt.Sprintf("%s %.2f is %.1f standard deviations above the baseline mean %.2f (stddev %.2f)",
+		a.Metric, a.Value, a.ZScore, a.Mean, a.StdDev)
+}
+
+// LoadBaseline reads a baseline file. A missing file is an empty baseline with the default window.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Baseline{Window: DefaultBaselineWindow}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to decode baseline %s: %w", path, err)
+	}
+	if baseline.Window <= 0 {
+		baseline.Window = DefaultBaselineWindow
+	}
+	return &baseline, nil
+}
+
+// Save writes the baseline file, replacing it only once the new contents are written
+func (b *Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create baseline directory: %w", err)
+		}
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace baseline: %w", err)
+	}
+	return nil
+}
+
+// Update records an hour's statistics, replacing an earlier sample of the same hour and dropping
+// the oldest samples beyond the window
+func (b *Baseline) Update(stats HourStats) {
+	i := sort.Search(len(b.Hours), func(i int) bool { return !b.Hours[i].Hour.Before(stats.Hour) })
+	if i < len(b.Hours) && b.Hours[i].Hour.Equal(stats.Hour) {
+		b.Hours[i] = stats
+	} else {
+		b.Hours = append(b.Hours, HourStats{})
+		copy(b.Hours[i+1:], b.Hours[i:])
+		b.Hours[i] = stats
+	}
+
+	if b.Window > 0 && len(b.Hours) > b.Window {
+		b.Hours = b.Hours[len(b.Hours)-b.Window:]
+	}
+}
+
+// Check compares an hour against the other hours of the baseline and returns the metrics more than
+// threshold standard deviations above their mean: the event count, and the mean and maximum
+// magnitude when the hour had earthquakes (measured against hours that had earthquakes too).
+// Nothing is flagged until the baseline holds MinBaselineSamples other hours.
+func (b *Baseline) Check(stats HourStats, threshold float64) []Anomaly {
+	var counts, means, maxes []float64
+	for _, sample := range b.Hours {
+		if sample.Hour.Equal(stats.Hour) {
+			continue
+		}
+		counts = append(counts, float64(sample.Count))
+		if sample.Count > 0 {
+			means = append(means, sample.MeanMagnitude)
+			maxes = append(maxes, sample.MaxMagnitude)
+		}
+	}
+	if len(counts) < MinBaselineSamples {
+		return nil
+	}
+
+	var anomalies []Anomaly
+	check := func(metric string, value float64, samples []float64) {
+		if len(samples) < MinBaselineSamples {
+			return
+		}
+		mean, stddev := meanStdDev(samples)
+		var z float64
+		switch {
+		case stddev > 0:
+			z = (value - mean) / stddev
+		case value > mean:
+			z = math.Inf(1)
+		}
+		if z > threshold {
+			anomalies = append(anomalies, Anomaly{Metric: metric, Value: value, Mean: mean, StdDev: stddev, ZScore: z})
+		}
+	}
+
+	check("count", float64(stats.Count), counts)
+	if stats.Count > 0 {
+		check("mean_magnitude", stats.MeanMagnitude, means)
+		check("max_magnitude", stats.MaxMagnitude, maxes)
+	}
+	return anomalies
+}
+
+// meanStdDev returns the mean and population standard deviation of values
+func meanStdDev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
+
+// CompareToBaseline summarizes the hour up to now, writes any anomalies against the baseline
+// file to w and records the hour in the baseline
+func CompareToBaseline(w io.Writer, path string, earthquakes []models.Earthquake, now time.Time, threshold float64) error {
+	baseline, err := LoadBaseline(path)
+	if err != nil {
+		return err
+	}
+
+	stats := StatsForHour(earthquakes, now)
+	anomalies := baseline.Check(stats, threshold)
+	samples := 0
+	for _, sample := range baseline.Hours {
+		if !sample.Hour.Equal(stats.Hour) {
+			samples++
+		}
+	}
+	if samples < MinBaselineSamples {
+		fmt.Fprintf(w, "Baseline has %d of the %d hours needed to detect anomalies\n", samples, MinBaselineSamples)
+	} else if len(anomalies) == 0 {
+		fmt.Fprintf(w, "No anomalies against the baseline (%d events this hour)\n", stats.Count)
+	}
+	for _, anomaly := range anomalies {
+		fmt.Fprintf(w, "Anomaly: %s\n", anomaly)
+	}
+
+	baseline.Update(stats)
+	return baseline.Save(path)
+}
@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// quietBaseline builds a baseline of hours alternating between 2 and 4 events of M2 and M3, so
+// the count has mean 3 and standard deviation 1
+func quietBaseline(start time.Time, hours int) *Baseline {
+	baseline := &Baseline{Window: DefaultBaselineWindow}
+	for i := 0; i < hours; i++ {
+		stats := HourStats{Hour: start.Add(time.Duration(i) * time.Hour), Count: 2, MeanMagnitude: 2, MaxMagnitude: 2}
+		if i%2 == 1 {
+			stats = HourStats{Hour: stats.Hour, Count: 4, MeanMagnitude: 3, MaxMagnitude: 3}
+		}
+		baseline.Update(stats)
+	}
+	return baseline
+}
+
+func TestStatsForHour(t *testing.T) {
+	end := time.Date(2024, 1, 15, 10, 20, 0, 0, time.UTC)
+	at := func(ago time.Duration, mag *float64) models.Earthquake {
+		return models.Earthquake{Properties: models.EarthquakeProperties{Mag: mag, Time: end.Add(-ago).UnixMilli()}}
+	}
+
+	stats := StatsForHour([]models.Earthquake{
+		at(5*time.Minute, models.Float64(2.0)),
+		at(30*time.Minute, models.Float64(4.0)),
+		at(45*time.Minute, nil),
+		at(2*time.Hour, models.Float64(6.0)), // before the hour
+	}, end)
+
+	want := HourStats{Hour: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), Count: 3, MeanMagnitude: 3.0, MaxMagnitude: 4.0}
+	if stats != want {
+		t.Errorf("StatsForHour() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestBaseline_Check(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	baseline := quietBaseline(start, MinBaselineSamples)
+	hour := start.Add(time.Duration(MinBaselineSamples) * time.Hour)
+
+	tests := []struct {
+		name    string
+		stats   HourStats
+		metrics []string
+	}{
+		{"ordinary hour", HourStats{Hour: hour, Count: 4, MeanMagnitude: 2.5, MaxMagnitude: 3}, nil},
+		{"count within threshold", HourStats{Hour: hour, Count: 6, MeanMagnitude: 2.5, MaxMagnitude: 3}, nil},
+		{"swarm", HourStats{Hour: hour, Count: 10, MeanMagnitude: 2.5, MaxMagnitude: 3}, []string{"count"}},
+		{"strong event", HourStats{Hour: hour, Count: 3, MeanMagnitude: 4.5, MaxMagnitude: 6.1}, []string{"mean_magnitude", "max_magnitude"}},
+		{"quiet hour is not flagged", HourStats{Hour: hour}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := baseline.Check(tt.stats, DefaultAnomalyThreshold)
+			if len(anomalies) != len(tt.metrics) {
+				t.Fatalf("Check() = %v, want anomalies in %v", anomalies, tt.metrics)
+			}
+			for i, anomaly := range anomalies {
+				if anomaly.Metric != tt.metrics[i] || anomaly.ZScore <= DefaultAnomalyThreshold {
+					t.Errorf("Anomaly %d = %+v, want %s above the threshold", i, anomaly, tt.metrics[i])
+				}
+			}
+		})
+	}
+
+	anomalies := baseline.Check(HourStats{Hour: hour, Count: 10}, DefaultAnomalyThreshold)
+	if len(anomalies) != 1 || anomalies[0].Mean != 3 || anomalies[0].StdDev != 1 || anomalies[0].ZScore != 7 {
+		t.Errorf("Expected count z-score 7 against mean 3 and stddev 1, got %+v", anomalies)
+	}
+}
+
+func TestBaseline_CheckNeedsSamples(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	baseline := quietBaseline(start, MinBaselineSamples)
+
+	// The sample of the hour being checked does not count towards the baseline
+	last := baseline.Hours[len(baseline.Hours)-1].Hour
+	if anomalies := baseline.Check(HourStats{Hour: last, Count: 100}, DefaultAnomalyThreshold); anomalies != nil {
+		t.Errorf("Expected no anomalies from %d other hours, got %v", MinBaselineSamples-1, anomalies)
+	}
+}
+
+func TestBaseline_CheckConstantBaseline(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	baseline := &Baseline{Window: DefaultBaselineWindow}
+	for i := 0; i < MinBaselineSamples; i++ {
+		baseline.Update(HourStats{Hour: start.Add(time.Duration(i) * time.Hour)})
+	}
+
+	anomalies := baseline.Check(HourStats{Hour: start.Add(48 * time.Hour), Count: 1, MeanMagnitude: 1, MaxMagnitude: 1}, DefaultAnomalyThreshold)
+	if len(anomalies) != 1 || anomalies[0].Metric != "count" || !math.IsInf(anomalies[0].ZScore, 1) {
+		t.Errorf("Expected an infinite count z-score over a baseline that never varied, got %+v", anomalies)
+	}
+}
+
+func TestBaseline_Update(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	baseline := &Baseline{Window: 3}
+	for _, offset := range []int{2, 0, 1, 3} {
+		baseline.Update(HourStats{Hour: start.Add(time.Duration(offset) * time.Hour), Count: offset})
+	}
+	baseline.Update(HourStats{Hour: start.Add(2 * time.Hour), Count: 20})
+
+	var got []int
+	for _, sample := range baseline.Hours {
+		got = append(got, sample.Count)
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 20 || got[2] != 3 {
+		t.Errorf("Hours = %v, want the 3 newest in order with hour 2 replaced: [1 20 3]", got)
+	}
+}
+
+func TestCompareToBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline", "hourly.json")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := quietBaseline(start, MinBaselineSamples).Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	now := start.Add(time.Duration(MinBaselineSamples)*time.Hour + 30*time.Minute)
+	var swarm []models.Earthquake
+	for i := 0; i < 12; i++ {
+		swarm = append(swarm, models.Earthquake{Properties: models.EarthquakeProperties{
+			Mag:  models.Float64(2.5),
+			Time: now.Add(-time.Duration(i) * time.Minute).UnixMilli(),
+		}})
+	}
+
+	var out bytes.Buffer
+	if err := CompareToBaseline(&out, path, swarm, now, DefaultAnomalyThreshold); err != nil {
+		t.Fatalf("CompareToBaseline() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Anomaly: count 12.00") {
+		t.Errorf("Expected a count anomaly, got:\n%s", out.String())
+	}
+
+	baseline, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("LoadBaseline() error = %v", err)
+	}
+	if len(baseline.Hours) != MinBaselineSamples+1 || baseline.Hours[MinBaselineSamples].Count != 12 {
+		t.Errorf("Expected the hour to be added to the baseline, got %d hours", len(baseline.Hours))
+	}
+}
+
+func TestLoadBaselineMissing(t *testing.T) {
+	baseline, err := LoadBaseline(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || baseline.Window != DefaultBaselineWindow || len(baseline.Hours) != 0 {
+		t.Errorf("LoadBaseline() = %+v, %v, want an empty baseline", baseline, err)
+	}
+}
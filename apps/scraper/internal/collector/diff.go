@@ -0,0 +1,126 @@
+package collector
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// FieldChange describes a field whose value differs between two versions of an earthquake
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// EarthquakeChange lists the field changes of an earthquake present in both sets
+type EarthquakeChange struct {
+	ID      string        `json:"id"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// EarthquakeDiff is the difference between two sets of earthquakes keyed by USGS ID
+type EarthquakeDiff struct {
+	Added     []string           `json:"added"`
+	Removed   []string           `json:"removed"`
+	Changed   []EarthquakeChange `json:"changed"`
+	Unchanged int                `json:"unchanged"`
+}
+
+// diffFields are the fields compared between two versions of an earthquake
+var diffFields = []struct {
+	name  string
+	value func(eq *models.Earthquake) string
+}{
+	{"magnitude", func(eq *models.Earthquake) string {
+		mag, ok := eq.Properties.Magnitude()
+		if !ok {
+			return ""
+		}
+		return strconv.FormatFloat(mag, 'f', -1, 64)
+	}},
+	{"mag_type", func(eq *models.Earthquake) string { return eq.Properties.MagType }},
+	{"alert", func(eq *models.Earthquake) string { return eq.Properties.Alert }},
+	{"status", func(eq *models.Earthquake) string { return eq.Properties.Status }},
+	{"tsunami", func(eq *models.Earthquake) string { return strconv.Itoa(eq.Properties.Tsunami) }},
+	{"place", func(eq *models.Earthquake) string { return eq.Properties.Place }},
+	{"time", func(eq *models.Earthquake) string {
+		return time.UnixMilli(eq.Properties.Time).UTC().Format(time.RFC3339)
+	}},
+	{"depth", func(eq *models.Earthquake) string { return strconv.FormatFloat(eq.Geometry.Depth(), 'f', -1, 64) }},
+}
+
+// DiffEarthquakes compares two sets of earthquakes, reporting IDs only in b as added, IDs only
+// in a as removed, and field changes for IDs in both. All lists are sorted by ID.
+func DiffEarthquakes(a, b []models.Earthquake) EarthquakeDiff {
+	before := make(map[string]*models.Earthquake, len(a))
+	for i := range a {
+		before[a[i].ID] = &a[i]
+	}
+	after := make(map[string]*models.Earthquake, len(b))
+	for i := range b {
+		after[b[i].ID] = &b[i]
+	}
+
+	diff := EarthquakeDiff{
+		Added:   []string{},
+		Removed: []string{},
+		Changed: []EarthquakeChange{},
+	}
+
+	for id, old := range before {
+		updated, ok := after[id]
+		if !ok {
+			diff.Removed = append(diff.Removed, id)
+			continue
+		}
+
+		var changes []FieldChange
+		for _, field := range diffFields {
+			if oldValue, newValue := field.value(old), field.value(updated); oldValue != newValue {
+				changes = append(changes, FieldChange{Field: field.name, Old: oldValue, New: newValue})
+			}
+		}
+		if len(changes) == 0 {
+			diff.Unchanged++
+			continue
+		}
+		diff.Changed = append(diff.Changed, EarthquakeChange{ID: id, Changes: changes})
+	}
+
+	for id := range after {
+		if _, ok := before[id]; !ok {
+			diff.Added = append(diff.Added, id)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].ID < diff.Changed[j].ID
+	})
+
+	return diff
+}
+
+// Write renders the diff as human-readable text
+func (d EarthquakeDiff) Write(w io.Writer) {
+	fmt.Fprintf(w, "Added: %d, removed: %d, changed: %d, unchanged: %d\n", len(d.Added), len(d.Removed), len(d.Changed), d.Unchanged)
+
+	for _, id := range d.Added {
+		fmt.Fprintf(w, "+ %s\n", id)
+	}
+	for _, id := range d.Removed {
+		fmt.Fprintf(w, "- %s\n", id)
+	}
+	for _, change := range d.Changed {
+		fmt.Fprintf(w, "~ %s\n", change.ID)
+		for _, field := range change.Changes {
+			fmt.Fprintf(w, "    %s: %q -> %q\n", field.Field, field.Old, field.New)
+		}
+	}
+}
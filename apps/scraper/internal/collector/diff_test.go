@@ -0,0 +1,41 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	"quakewatch-scraper/internal/models"
+)
+
+func TestDiffEarthquakes(t *testing.T) {
+	a := []models.Earthquake{
+		testEarthquake("kept", models.EarthquakeProperties{Mag: models.Float64(3.1), Status: "reviewed"}),
+		testEarthquake("revised", models.EarthquakeProperties{Mag: models.Float64(4.5), Status: "automatic"}),
+		testEarthquake("dropped", models.EarthquakeProperties{Mag: models.Float64(2.0)}),
+	}
+	b := []models.Earthquake{
+		testEarthquake("new", models.EarthquakeProperties{Mag: models.Float64(5.0)}),
+		testEarthquake("revised", models.EarthquakeProperties{Mag: models.Float64(4.7), Alert: "green", Status: "automatic"}),
+		testEarthquake("kept", models.EarthquakeProperties{Mag: models.Float64(3.1), Status: "reviewed"}),
+	}
+
+	diff := DiffEarthquakes(a, b)
+
+	if !reflect.DeepEqual(diff.Added, []string{"new"}) {
+		t.Errorf("Added = %v, want [new]", diff.Added)
+	}
+	if !reflect.DeepEqual(diff.Removed, []string{"dropped"}) {
+		t.Errorf("Removed = %v, want [dropped]", diff.Removed)
+	}
+	if diff.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, want 1", diff.Unchanged)
+	}
+
+	want := []EarthquakeChange{{ID: "revised", Changes: []FieldChange{
+		{Field: "magnitude", Old: "4.5", New: "4.7"},
+		{Field: "alert", Old: "", New: "green"},
+	}}}
+	if !reflect.DeepEqual(diff.Changed, want) {
+		t.Errorf("Changed = %+v, want %+v", diff.Changed, want)
+	}
+}
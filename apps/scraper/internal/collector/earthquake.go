@@ -1,147 +1,607 @@
 package collector
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"quakewatch-scraper/internal/api"
 	"quakewatch-scraper/internal/models"
 	"quakewatch-scraper/internal/storage"
+	"quakewatch-scraper/internal/utils"
 )
 
+// RecentSource fetches the earthquakes of the last hour
+type RecentSource interface {
+	GetRecentEarthquakes(ctx context.Context, limit int) (*models.USGSResponse, error)
+}
+
 // EarthquakeCollector handles collecting earthquake data
 type EarthquakeCollector struct {
-	usgsClient *api.USGSClient
-	storage    *storage.JSONStorage
+	usgsClient  *api.USGSClient
+	storage     *storage.JSONStorage
+	filters     []EarthquakeFilter
+	progress    io.Writer
+	summary     bool
+	notifier    *utils.Notifier
+	onCollected func(count int)
+	fields      []string
+	verbose     bool
+	noSave      bool
+
+	// seen, when set, drops earthquakes collected by earlier runs
+	seen *utils.SeenCache
+
+	// baselinePath, when set, is the baseline file each collection is compared against and added to
+	baselinePath     string
+	anomalyThreshold float64
+
+	// recentSource serves recent earthquakes; fallbackSource, when set, is tried if it fails
+	recentSource       RecentSource
+	recentSourceName   string
+	fallbackSource     RecentSource
+	fallbackSourceName string
+
+	// deterministicNames names unnamed output files after a hash of the query instead of the time;
+	// queryOptions holds client settings that change the results and so belong in that hash
+	deterministicNames bool
+	queryOptions       map[string]string
 }
 
 // NewEarthquakeCollector creates a new earthquake collector
 func NewEarthquakeCollector(usgsClient *api.USGSClient, storage *storage.JSONStorage) *EarthquakeCollector {
 	return &EarthquakeCollector{
-		usgsClient: usgsClient,
-		storage:    storage,
+		usgsClient:       usgsClient,
+		recentSource:     usgsClient,
+		recentSourceName: "usgs",
+		storage:          storage,
+		progress:         os.Stdout,
+		queryOptions:     make(map[string]string),
 	}
 }
 
-// CollectRecent collects recent earthquakes (last hour)
-func (c *EarthquakeCollector) CollectRecent(limit int, filename string) error {
-	fmt.Printf("Collecting recent earthquakes (last hour, limit: %d)...\n", limit)
+// SetRecentSource sets where recent earthquakes are fetched from (the USGS client by default)
+func (c *EarthquakeCollector) SetRecentSource(name string, source RecentSource) {
+	c.recentSource = source
+	c.recentSourceName = name
+}
 
-	earthquakes, err := c.usgsClient.GetRecentEarthquakes(limit)
-	if err != nil {
-		return fmt.Errorf("failed to fetch recent earthquakes: %w", err)
+// SetFallbackSource sets a source to fetch recent earthquakes from when the recent source fails
+func (c *EarthquakeCollector) SetFallbackSource(name string, source RecentSource) {
+	c.fallbackSource = source
+	c.fallbackSourceName = name
+}
+
+// fetchRecent fetches recent earthquakes from the recent source, falling back to the fallback
+// source if one is set and the recent source fails for any reason other than cancellation
+func (c *EarthquakeCollector) fetchRecent(ctx context.Context, limit int) (*models.USGSResponse, error) {
+	earthquakes, err := c.recentSource.GetRecentEarthquakes(ctx, limit)
+	if c.fallbackSource == nil {
+		return earthquakes, err
+	}
+	if err == nil {
+		c.printf("Recent earthquakes served by %s\n", c.recentSourceName)
+		return earthquakes, nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
 	}
 
-	fmt.Printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.printf("Fetching from %s failed: %v; falling back to %s\n", c.recentSourceName, err, c.fallbackSourceName)
+	earthquakes, fallbackErr := c.fallbackSource.GetRecentEarthquakes(ctx, limit)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("%w (fallback %s also failed: %v)", err, c.fallbackSourceName, fallbackErr)
+	}
 
-	if err := c.storage.SaveEarthquakes(earthquakes, filename); err != nil {
-		return fmt.Errorf("failed to save earthquakes: %w", err)
+	c.printf("Recent earthquakes served by %s\n", c.fallbackSourceName)
+	return earthquakes, nil
+}
+
+// SetProgressOutput sets where progress messages are written (stdout by default)
+func (c *EarthquakeCollector) SetProgressOutput(w io.Writer) {
+	c.progress = w
+}
+
+// SetVerbose makes the collector also report details such as the query behind each save and
+// how many earthquakes each filter kept
+func (c *EarthquakeCollector) SetVerbose(verbose bool) {
+	c.verbose = verbose
+}
+
+// printf writes a progress message
+func (c *EarthquakeCollector) printf(format string, args ...interface{}) {
+	fmt.Fprintf(c.progress, format, args...)
+}
+
+// debugf writes a progress message in verbose mode only
+func (c *EarthquakeCollector) debugf(format string, args ...interface{}) {
+	if c.verbose {
+		c.printf(format, args...)
 	}
+}
+
+// EnableSummary makes the collector print aggregate statistics after each save
+func (c *EarthquakeCollector) EnableSummary() {
+	c.summary = true
+}
+
+// SetNoSave makes the collector fetch, validate and filter earthquakes but discard them instead
+// of saving, printing only how many were collected. Notifications are not sent for discarded
+// collections.
+func (c *EarthquakeCollector) SetNoSave(noSave bool) {
+	c.noSave = noSave
+}
+
+// SetBaseline makes the collector compare the hour before each collection against the hourly
+// statistics in a baseline file, report the metrics more than threshold standard deviations above
+// their mean, and then add the hour to the baseline
+func (c *EarthquakeCollector) SetBaseline(path string, threshold float64) {
+	c.baselinePath = path
+	c.anomalyThreshold = threshold
+}
+
+// SetSeenCache makes the collector drop earthquakes whose IDs are in cache, after the filters
+// have run, and record the IDs of every earthquake it collects. Collections that save write the
+// cache once the earthquakes are saved; callers of the Data methods call SaveSeenCache once they
+// have delivered the earthquakes.
+func (c *EarthquakeCollector) SetSeenCache(cache *utils.SeenCache) {
+	c.seen = cache
+}
 
-	fmt.Printf("Saved earthquakes to %s\n", filename)
+// SaveSeenCache prunes expired IDs from the seen cache and writes it, if one is set
+func (c *EarthquakeCollector) SaveSeenCache() error {
+	if c.seen == nil {
+		return nil
+	}
+	c.seen.Prune(time.Now())
+	if err := c.seen.Save(); err != nil {
+		return fmt.Errorf("failed to save seen cache: %w", err)
+	}
 	return nil
 }
 
-// CollectByTimeRange collects earthquakes within a specific time range
-func (c *EarthquakeCollector) CollectByTimeRange(startTime, endTime time.Time, limit int, filename string) error {
-	fmt.Printf("Collecting earthquakes from %s to %s (limit: %d)...\n",
-		startTime.Format("2006-01-02 15:04:05"),
-		endTime.Format("2006-01-02 15:04:05"),
-		limit)
+// dropSeen removes the earthquakes already in the seen cache and records all of them as seen now
+func (c *EarthquakeCollector) dropSeen(earthquakes *models.USGSResponse) {
+	now := time.Now()
+	fresh := make([]models.Earthquake, 0, len(earthquakes.Features))
+	for _, eq := range earthquakes.Features {
+		if !c.seen.Contains(eq.ID) {
+			fresh = append(fresh, eq)
+		}
+		c.seen.Add(eq.ID, now)
+	}
 
-	earthquakes, err := c.usgsClient.GetEarthquakesByTimeRange(startTime, endTime, limit)
-	if err != nil {
-		return fmt.Errorf("failed to fetch earthquakes by time range: %w", err)
+	c.printf("%d of %d earthquakes were not seen in earlier runs\n", len(fresh), len(earthquakes.Features))
+	earthquakes.Features = fresh
+	earthquakes.Metadata.Count = len(fresh)
+}
+
+// SetNotifier sets a notifier that is sent the earthquakes of each saved collection
+func (c *EarthquakeCollector) SetNotifier(notifier *utils.Notifier) {
+	c.notifier = notifier
+}
+
+// SetMinQuality makes collection fail when the fetched data scores below minQuality
+func (c *EarthquakeCollector) SetMinQuality(minQuality float64) {
+	c.usgsClient.SetMinQuality(minQuality)
+}
+
+// SetOrderBy sets the ordering the USGS API returns results in
+func (c *EarthquakeCollector) SetOrderBy(orderBy string) error {
+	if err := c.usgsClient.SetOrderBy(orderBy); err != nil {
+		return err
 	}
+	c.queryOptions["orderby"] = orderBy
+	return nil
+}
 
-	fmt.Printf("Found %d earthquakes\n", len(earthquakes.Features))
+// SetEventType restricts collection to one USGS event type (empty collects all types)
+func (c *EarthquakeCollector) SetEventType(eventType string) {
+	c.usgsClient.SetEventType(eventType)
+	c.queryOptions["eventtype"] = strings.TrimSpace(eventType)
+}
 
-	if err := c.storage.SaveEarthquakes(earthquakes, filename); err != nil {
-		return fmt.Errorf("failed to save earthquakes: %w", err)
+// SetCatalog restricts collection to one USGS contributing catalog (empty uses the composite catalog)
+func (c *EarthquakeCollector) SetCatalog(catalog string) error {
+	if err := c.usgsClient.SetCatalog(catalog); err != nil {
+		return err
 	}
+	c.queryOptions["catalog"] = strings.TrimSpace(catalog)
+	return nil
+}
 
-	fmt.Printf("Saved earthquakes to %s\n", filename)
+// SetFields makes the collector save and output only the given fields of each earthquake, as a JSON
+// array of objects instead of GeoJSON (no fields keeps the full GeoJSON)
+func (c *EarthquakeCollector) SetFields(fields []string) error {
+	if err := ValidateFields(fields); err != nil {
+		return err
+	}
+	c.fields = fields
+	if len(fields) > 0 {
+		c.queryOptions["fields"] = strings.Join(fields, ",")
+	}
 	return nil
 }
 
-// CollectByMagnitude collects earthquakes within a magnitude range
-func (c *EarthquakeCollector) CollectByMagnitude(minMag, maxMag float64, limit int, filename string) error {
-	fmt.Printf("Collecting earthquakes with magnitude %.1f to %.1f (limit: %d)...\n", minMag, maxMag, limit)
+// Output returns what collected earthquakes are printed as: the GeoJSON response, or the
+// earthquakes reduced to the fields set with SetFields. Like a save, it reports the summary and
+// baseline comparison requested for the collection.
+func (c *EarthquakeCollector) Output(earthquakes *models.USGSResponse) interface{} {
+	c.report(earthquakes)
+	if len(c.fields) == 0 {
+		return earthquakes
+	}
+	// The fields were validated by SetFields
+	records, _ := ProjectFields(earthquakes.Features, c.fields)
+	return records
+}
 
-	earthquakes, err := c.usgsClient.GetEarthquakesByMagnitude(minMag, maxMag, limit)
-	if err != nil {
-		return fmt.Errorf("failed to fetch earthquakes by magnitude: %w", err)
+// EnableDeterministicNames makes collections saved without a filename use a name derived from
+// the query, so re-running the same query overwrites its previous output
+func (c *EarthquakeCollector) EnableDeterministicNames() {
+	c.deterministicNames = true
+}
+
+// QueryFilename returns "earthquakes_<hash>" for a set of query parameters, where the hash is
+// the first 16 hex digits of the SHA-256 of the parameters sorted by key
+func QueryFilename(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s=%s\n", key, params[key])
+	}
+	return "earthquakes_" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// TimeRangeStateName names the state file that lets a time range collection resume. Besides the
+// range and limit it covers the query options, such as the event type and catalog, which change
+// the responses recorded for each window. A run with other options therefore does not reuse
+// windows fetched by this one.
+func (c *EarthquakeCollector) TimeRangeStateName(start, end time.Time, limit int) string {
+	params := map[string]string{
+		"query": "time-range",
+		"start": formatQueryTime(start),
+		"end":   formatQueryTime(end),
+		"limit": strconv.Itoa(limit),
+	}
+	for key, value := range c.queryOptions {
+		params[key] = value
+	}
+	hash := strings.TrimPrefix(QueryFilename(params), "earthquakes_")
+	return fmt.Sprintf("time-range_%s_%s_%s", start.Format("2006-01-02"), end.Format("2006-01-02"), hash)
+}
+
+// outputFilename returns the filename to save a query's results under. An explicit filename is
+// kept; otherwise, with deterministic names enabled, the name is derived from the query.
+func (c *EarthquakeCollector) outputFilename(filename string, params map[string]string) string {
+	if filename != "" || !c.deterministicNames {
+		return filename
+	}
+
+	all := make(map[string]string, len(params)+len(c.queryOptions))
+	for key, value := range c.queryOptions {
+		all[key] = value
+	}
+	for key, value := range params {
+		all[key] = value
+	}
+	return QueryFilename(all)
+}
+
+// formatLimit describes a time range query limit for progress messages
+func formatLimit(limit int) string {
+	if limit <= api.NoLimit {
+		return "all"
+	}
+	return strconv.Itoa(limit)
+}
+
+// formatQuery formats query parameters as sorted key=value pairs
+func formatQuery(query map[string]string) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + query[key]
+	}
+	return strings.Join(pairs, " ")
+}
+
+// formatQueryTime formats a query time for filename hashing
+func formatQueryTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatQueryFloat formats a query number for filename hashing
+func formatQueryFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// AddFilter registers a filter that is applied to collected earthquakes before they are saved or returned
+func (c *EarthquakeCollector) AddFilter(filter EarthquakeFilter) {
+	c.filters = append(c.filters, filter)
+}
+
+// SetCollectedHook sets a function that is told how many earthquakes each collection returned after filtering
+func (c *EarthquakeCollector) SetCollectedHook(hook func(count int)) {
+	c.onCollected = hook
+}
+
+// applyFilters runs the registered filters and the seen cache over the response, updates its
+// metadata count and reports the remaining earthquakes to the collected hook
+func (c *EarthquakeCollector) applyFilters(earthquakes *models.USGSResponse) {
+	if len(c.filters) > 0 {
+		features := earthquakes.Features
+		for i, filter := range c.filters {
+			before := len(features)
+			features = filter(features)
+			c.debugf("Filter %d of %d kept %d of %d earthquakes\n", i+1, len(c.filters), len(features), before)
+		}
+
+		c.printf("%d of %d earthquakes matched filters\n", len(features), len(earthquakes.Features))
+		earthquakes.Features = features
+		earthquakes.Metadata.Count = len(features)
+	}
+	if c.seen != nil {
+		c.dropSeen(earthquakes)
 	}
 
-	fmt.Printf("Found %d earthquakes\n", len(earthquakes.Features))
+	if c.onCollected != nil {
+		c.onCollected(len(earthquakes.Features))
+	}
+}
 
-	if err := c.storage.SaveEarthquakes(earthquakes, filename); err != nil {
+// save stores the earthquakes, recording the query in the file's manifest, and reports the result
+func (c *EarthquakeCollector) save(earthquakes *models.USGSResponse, filename string, query map[string]string) error {
+	if c.noSave {
+		c.printf("Collected %d earthquakes (not saved)\n", len(earthquakes.Features))
+		c.debugf("Query: %s\n", formatQuery(query))
+		c.report(earthquakes)
+		return nil
+	}
+
+	var err error
+	if len(c.fields) > 0 {
+		records, _ := ProjectFields(earthquakes.Features, c.fields)
+		err = c.storage.SaveEarthquakeRecords(records, filename, query)
+	} else {
+		err = c.storage.SaveEarthquakesWithQuery(earthquakes, filename, query)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to save earthquakes: %w", err)
 	}
 
-	fmt.Printf("Saved earthquakes to %s\n", filename)
+	c.printf("Saved earthquakes to %s\n", filename)
+	c.debugf("Query: %s\n", formatQuery(query))
+	if err := c.SaveSeenCache(); err != nil {
+		return err
+	}
+	c.report(earthquakes)
+
+	// Notification failures must not fail an otherwise successful collection
+	if c.notifier != nil {
+		sent, err := c.notifier.Notify(earthquakes.Features)
+		if err != nil {
+			c.printf("Warning: webhook notification failed: %v\n", err)
+		}
+		if sent > 0 {
+			c.printf("Sent %d earthquake notification(s)\n", sent)
+		}
+	}
 	return nil
 }
 
-// CollectSignificant collects significant earthquakes (M4.5+)
-func (c *EarthquakeCollector) CollectSignificant(startTime, endTime time.Time, limit int, filename string) error {
-	fmt.Printf("Collecting significant earthquakes (M4.5+) from %s to %s (limit: %d)...\n",
+// report prints the summary and baseline comparison requested for a collection. A baseline that
+// cannot be read or written is a warning, not a failed collection.
+func (c *EarthquakeCollector) report(earthquakes *models.USGSResponse) {
+	if c.summary {
+		Summarize(earthquakes.Features).Write(c.progress)
+	}
+	if c.baselinePath != "" {
+		if err := CompareToBaseline(c.progress, c.baselinePath, earthquakes.Features, time.Now(), c.anomalyThreshold); err != nil {
+			c.printf("Warning: baseline comparison failed: %v\n", err)
+		}
+	}
+}
+
+// CollectRecent collects recent earthquakes (last hour)
+func (c *EarthquakeCollector) CollectRecent(ctx context.Context, limit int, filename string) error {
+	query := map[string]string{
+		"query": "recent",
+		"limit": strconv.Itoa(limit),
+	}
+	filename = c.outputFilename(filename, query)
+	c.printf("Collecting recent earthquakes (last hour, limit: %d)...\n", limit)
+
+	earthquakes, err := c.fetchRecent(ctx, limit)
+	if err != nil {
+		return fmt.Errorf("failed to fetch recent earthquakes: %w", utils.ErrorContext(err, query))
+	}
+
+	c.printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
+
+	return c.save(earthquakes, filename, query)
+}
+
+// CollectRecentByMagnitude collects earthquakes from the last hoursBack hours within a magnitude range
+func (c *EarthquakeCollector) CollectRecentByMagnitude(ctx context.Context, hoursBack int, minMag, maxMag float64, limit int, filename string) error {
+	query := map[string]string{
+		"query":  "recent-magnitude",
+		"hours":  strconv.Itoa(hoursBack),
+		"minmag": formatQueryFloat(minMag),
+		"maxmag": formatQueryFloat(maxMag),
+		"limit":  strconv.Itoa(limit),
+	}
+	filename = c.outputFilename(filename, query)
+
+	earthquakes, err := c.CollectRecentByMagnitudeData(ctx, hoursBack, minMag, maxMag, limit)
+	if err != nil {
+		return utils.ErrorContext(err, query)
+	}
+
+	return c.save(earthquakes, filename, query)
+}
+
+// CollectByTimeRange collects earthquakes within a specific time range. A limit of api.NoLimit
+// collects every earthquake in the range.
+func (c *EarthquakeCollector) CollectByTimeRange(ctx context.Context, startTime, endTime time.Time, limit int, filename string) error {
+	query := map[string]string{
+		"query": "time-range",
+		"start": formatQueryTime(startTime),
+		"end":   formatQueryTime(endTime),
+		"limit": strconv.Itoa(limit),
+	}
+	filename = c.outputFilename(filename, query)
+	c.printf("Collecting earthquakes from %s to %s (limit: %s)...\n",
 		startTime.Format("2006-01-02 15:04:05"),
 		endTime.Format("2006-01-02 15:04:05"),
-		limit)
+		formatLimit(limit))
 
-	earthquakes, err := c.usgsClient.GetSignificantEarthquakes(startTime, endTime, limit)
+	earthquakes, err := c.usgsClient.GetEarthquakesByTimeRange(ctx, startTime, endTime, limit)
 	if err != nil {
-		return fmt.Errorf("failed to fetch significant earthquakes: %w", err)
+		return fmt.Errorf("failed to fetch earthquakes by time range: %w", utils.ErrorContext(err, query))
 	}
 
-	fmt.Printf("Found %d significant earthquakes\n", len(earthquakes.Features))
+	c.printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
 
-	if err := c.storage.SaveEarthquakes(earthquakes, filename); err != nil {
-		return fmt.Errorf("failed to save earthquakes: %w", err)
+	return c.save(earthquakes, filename, query)
+}
+
+// CollectByMagnitude collects earthquakes within a magnitude range
+func (c *EarthquakeCollector) CollectByMagnitude(ctx context.Context, minMag, maxMag float64, limit int, filename string) error {
+	query := map[string]string{
+		"query":  "magnitude",
+		"minmag": formatQueryFloat(minMag),
+		"maxmag": formatQueryFloat(maxMag),
+		"limit":  strconv.Itoa(limit),
 	}
+	filename = c.outputFilename(filename, query)
+	c.printf("Collecting earthquakes with magnitude %.1f to %.1f (limit: %d)...\n", minMag, maxMag, limit)
 
-	fmt.Printf("Saved significant earthquakes to %s\n", filename)
-	return nil
+	earthquakes, err := c.usgsClient.GetEarthquakesByMagnitude(ctx, minMag, maxMag, limit)
+	if err != nil {
+		return fmt.Errorf("failed to fetch earthquakes by magnitude: %w", utils.ErrorContext(err, query))
+	}
+
+	c.printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
+
+	return c.save(earthquakes, filename, query)
+}
+
+// CollectSignificant collects significant earthquakes (M4.5+)
+func (c *EarthquakeCollector) CollectSignificant(ctx context.Context, startTime, endTime time.Time, limit int, filename string) error {
+	query := map[string]string{
+		"query": "significant",
+		"start": formatQueryTime(startTime),
+		"end":   formatQueryTime(endTime),
+		"limit": strconv.Itoa(limit),
+	}
+	filename = c.outputFilename(filename, query)
+	c.printf("Collecting significant earthquakes (M4.5+) from %s to %s (limit: %d)...\n",
+		startTime.Format("2006-01-02 15:04:05"),
+		endTime.Format("2006-01-02 15:04:05"),
+		limit)
+
+	earthquakes, err := c.usgsClient.GetSignificantEarthquakes(ctx, startTime, endTime, limit)
+	if err != nil {
+		return fmt.Errorf("failed to fetch significant earthquakes: %w", utils.ErrorContext(err, query))
+	}
+
+	c.printf("Found %d significant earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
+
+	return c.save(earthquakes, filename, query)
 }
 
 // CollectByRegion collects earthquakes within a geographic region
-func (c *EarthquakeCollector) CollectByRegion(minLat, maxLat, minLon, maxLon float64, limit int, filename string) error {
-	fmt.Printf("Collecting earthquakes in region (%.2f,%.2f) to (%.2f,%.2f) (limit: %d)...\n",
+func (c *EarthquakeCollector) CollectByRegion(ctx context.Context, minLat, maxLat, minLon, maxLon float64, limit int, filename string) error {
+	query := map[string]string{
+		"query":  "region",
+		"minlat": formatQueryFloat(minLat),
+		"maxlat": formatQueryFloat(maxLat),
+		"minlon": formatQueryFloat(minLon),
+		"maxlon": formatQueryFloat(maxLon),
+		"limit":  strconv.Itoa(limit),
+	}
+	filename = c.outputFilename(filename, query)
+	c.printf("Collecting earthquakes in region (%.2f,%.2f) to (%.2f,%.2f) (limit: %d)...\n",
 		minLat, minLon, maxLat, maxLon, limit)
 
-	earthquakes, err := c.usgsClient.GetEarthquakesByRegion(minLat, maxLat, minLon, maxLon, limit)
+	earthquakes, err := c.usgsClient.GetEarthquakesByRegion(ctx, minLat, maxLat, minLon, maxLon, limit)
 	if err != nil {
-		return fmt.Errorf("failed to fetch earthquakes by region: %w", err)
+		return fmt.Errorf("failed to fetch earthquakes by region: %w", utils.ErrorContext(err, query))
 	}
 
-	fmt.Printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
 
-	if err := c.storage.SaveEarthquakes(earthquakes, filename); err != nil {
-		return fmt.Errorf("failed to save earthquakes: %w", err)
+	return c.save(earthquakes, filename, query)
+}
+
+// CollectByRadius collects earthquakes within radiusKm kilometers of a point
+func (c *EarthquakeCollector) CollectByRadius(ctx context.Context, lat, lon, radiusKm float64, limit int, filename string) error {
+	query := map[string]string{
+		"query":    "radius",
+		"lat":      formatQueryFloat(lat),
+		"lon":      formatQueryFloat(lon),
+		"radiuskm": formatQueryFloat(radiusKm),
+		"limit":    strconv.Itoa(limit),
 	}
+	filename = c.outputFilename(filename, query)
 
-	fmt.Printf("Saved earthquakes to %s\n", filename)
-	return nil
+	earthquakes, err := c.CollectByRadiusData(ctx, lat, lon, radiusKm, limit)
+	if err != nil {
+		return utils.ErrorContext(err, query)
+	}
+
+	return c.save(earthquakes, filename, query)
 }
 
 // CollectByCountry collects earthquakes filtered by country name
-func (c *EarthquakeCollector) CollectByCountry(country string, startTime, endTime time.Time, minMag, maxMag float64, limit int, filename string) error {
-	fmt.Printf("Collecting earthquakes in %s from %s to %s (magnitude %.1f-%.1f, limit: %d)...\n",
+func (c *EarthquakeCollector) CollectByCountry(ctx context.Context, country string, startTime, endTime time.Time, minMag, maxMag float64, limit int, filename string) error {
+	query := map[string]string{
+		"query":   "country",
+		"country": strings.ToLower(strings.TrimSpace(country)),
+		"start":   formatQueryTime(startTime),
+		"end":     formatQueryTime(endTime),
+		"minmag":  formatQueryFloat(minMag),
+		"maxmag":  formatQueryFloat(maxMag),
+		"limit":   strconv.Itoa(limit),
+	}
+	filename = c.outputFilename(filename, query)
+	c.printf("Collecting earthquakes in %s from %s to %s (magnitude %.1f-%.1f, limit: %d)...\n",
 		country,
 		startTime.Format("2006-01-02 15:04:05"),
 		endTime.Format("2006-01-02 15:04:05"),
 		minMag, maxMag, limit)
 
 	// First, fetch earthquakes by time range and magnitude
-	earthquakes, err := c.usgsClient.GetEarthquakesByTimeRangeAndMagnitude(startTime, endTime, minMag, maxMag, limit*2) // Fetch more to account for filtering
+	earthquakes, err := c.usgsClient.GetEarthquakesByTimeRangeAndMagnitude(ctx, startTime, endTime, minMag, maxMag, limit*2) // Fetch more to account for filtering
 	if err != nil {
-		return fmt.Errorf("failed to fetch earthquakes: %w", err)
+		return fmt.Errorf("failed to fetch earthquakes: %w", utils.ErrorContext(err, query))
 	}
 
 	// Filter earthquakes by country
-	var filteredEarthquakes []models.Earthquake
+	filteredEarthquakes := make([]models.Earthquake, 0, len(earthquakes.Features))
 	for _, eq := range earthquakes.Features {
 		if containsCountry(eq.Properties.Place, country) {
 			filteredEarthquakes = append(filteredEarthquakes, eq)
@@ -163,104 +623,160 @@ func (c *EarthquakeCollector) CollectByCountry(country string, startTime, endTim
 	// Update metadata count
 	filteredResponse.Metadata.Count = len(filteredEarthquakes)
 
-	fmt.Printf("Found %d earthquakes in %s\n", len(filteredEarthquakes), country)
-
-	if err := c.storage.SaveEarthquakes(filteredResponse, filename); err != nil {
-		return fmt.Errorf("failed to save earthquakes: %w", err)
-	}
+	c.printf("Found %d earthquakes in %s\n", len(filteredEarthquakes), country)
+	c.applyFilters(filteredResponse)
 
-	fmt.Printf("Saved earthquakes to %s\n", filename)
-	return nil
+	return c.save(filteredResponse, filename, query)
 }
 
 // CollectRecentData collects recent earthquakes and returns the data without saving
-func (c *EarthquakeCollector) CollectRecentData(limit int) (*models.USGSResponse, error) {
-	fmt.Printf("Collecting recent earthquakes (last hour, limit: %d)...\n", limit)
+func (c *EarthquakeCollector) CollectRecentData(ctx context.Context, limit int) (*models.USGSResponse, error) {
+	c.printf("Collecting recent earthquakes (last hour, limit: %d)...\n", limit)
 
-	earthquakes, err := c.usgsClient.GetRecentEarthquakes(limit)
+	earthquakes, err := c.fetchRecent(ctx, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch recent earthquakes: %w", err)
 	}
 
-	fmt.Printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
+	return earthquakes, nil
+}
+
+// CollectRecentByMagnitudeData collects earthquakes from the last hoursBack hours within a magnitude range and returns the data without saving
+func (c *EarthquakeCollector) CollectRecentByMagnitudeData(ctx context.Context, hoursBack int, minMag, maxMag float64, limit int) (*models.USGSResponse, error) {
+	if hoursBack <= 0 {
+		return nil, fmt.Errorf("hours back must be positive, got %d", hoursBack)
+	}
+	if minMag > maxMag {
+		return nil, fmt.Errorf("minimum magnitude %.1f is greater than maximum magnitude %.1f", minMag, maxMag)
+	}
+
+	c.printf("Collecting recent earthquakes (last %d hour(s), magnitude %.1f to %.1f, limit: %d)...\n", hoursBack, minMag, maxMag, limit)
+
+	endTime := time.Now()
+	startTime := endTime.Add(-time.Duration(hoursBack) * time.Hour)
+
+	earthquakes, err := c.usgsClient.GetEarthquakesByTimeRangeAndMagnitude(ctx, startTime, endTime, minMag, maxMag, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recent earthquakes by magnitude: %w", err)
+	}
+
+	c.printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
 	return earthquakes, nil
 }
 
-// CollectByTimeRangeData collects earthquakes within a specific time range and returns the data without saving
-func (c *EarthquakeCollector) CollectByTimeRangeData(startTime, endTime time.Time, limit int) (*models.USGSResponse, error) {
-	fmt.Printf("Collecting earthquakes from %s to %s (limit: %d)...\n",
+// CollectUpdatedAfterData collects earthquakes inserted or updated since the given time and returns the data without saving
+func (c *EarthquakeCollector) CollectUpdatedAfterData(ctx context.Context, updatedAfter time.Time, limit int) (*models.USGSResponse, error) {
+	c.printf("Collecting earthquakes updated after %s (limit: %d)...\n",
+		updatedAfter.Format("2006-01-02 15:04:05"),
+		limit)
+
+	earthquakes, err := c.usgsClient.GetEarthquakesUpdatedAfter(ctx, updatedAfter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch updated earthquakes: %w", err)
+	}
+
+	c.printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
+	return earthquakes, nil
+}
+
+// CollectByTimeRangeData collects earthquakes within a specific time range and returns the data without saving.
+// A limit of api.NoLimit collects every earthquake in the range.
+func (c *EarthquakeCollector) CollectByTimeRangeData(ctx context.Context, startTime, endTime time.Time, limit int) (*models.USGSResponse, error) {
+	c.printf("Collecting earthquakes from %s to %s (limit: %s)...\n",
 		startTime.Format("2006-01-02 15:04:05"),
 		endTime.Format("2006-01-02 15:04:05"),
-		limit)
+		formatLimit(limit))
 
-	earthquakes, err := c.usgsClient.GetEarthquakesByTimeRange(startTime, endTime, limit)
+	earthquakes, err := c.usgsClient.GetEarthquakesByTimeRange(ctx, startTime, endTime, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch earthquakes by time range: %w", err)
 	}
 
-	fmt.Printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
 	return earthquakes, nil
 }
 
 // CollectByMagnitudeData collects earthquakes within a magnitude range and returns the data without saving
-func (c *EarthquakeCollector) CollectByMagnitudeData(minMag, maxMag float64, limit int) (*models.USGSResponse, error) {
-	fmt.Printf("Collecting earthquakes with magnitude %.1f to %.1f (limit: %d)...\n", minMag, maxMag, limit)
+func (c *EarthquakeCollector) CollectByMagnitudeData(ctx context.Context, minMag, maxMag float64, limit int) (*models.USGSResponse, error) {
+	c.printf("Collecting earthquakes with magnitude %.1f to %.1f (limit: %d)...\n", minMag, maxMag, limit)
 
-	earthquakes, err := c.usgsClient.GetEarthquakesByMagnitude(minMag, maxMag, limit)
+	earthquakes, err := c.usgsClient.GetEarthquakesByMagnitude(ctx, minMag, maxMag, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch earthquakes by magnitude: %w", err)
 	}
 
-	fmt.Printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
 	return earthquakes, nil
 }
 
 // CollectSignificantData collects significant earthquakes and returns the data without saving
-func (c *EarthquakeCollector) CollectSignificantData(startTime, endTime time.Time, limit int) (*models.USGSResponse, error) {
-	fmt.Printf("Collecting significant earthquakes (M4.5+) from %s to %s (limit: %d)...\n",
+func (c *EarthquakeCollector) CollectSignificantData(ctx context.Context, startTime, endTime time.Time, limit int) (*models.USGSResponse, error) {
+	c.printf("Collecting significant earthquakes (M4.5+) from %s to %s (limit: %d)...\n",
 		startTime.Format("2006-01-02 15:04:05"),
 		endTime.Format("2006-01-02 15:04:05"),
 		limit)
 
-	earthquakes, err := c.usgsClient.GetSignificantEarthquakes(startTime, endTime, limit)
+	earthquakes, err := c.usgsClient.GetSignificantEarthquakes(ctx, startTime, endTime, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch significant earthquakes: %w", err)
 	}
 
-	fmt.Printf("Found %d significant earthquakes\n", len(earthquakes.Features))
+	c.printf("Found %d significant earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
 	return earthquakes, nil
 }
 
 // CollectByRegionData collects earthquakes within a geographic region and returns the data without saving
-func (c *EarthquakeCollector) CollectByRegionData(minLat, maxLat, minLon, maxLon float64, limit int) (*models.USGSResponse, error) {
-	fmt.Printf("Collecting earthquakes in region (%.2f,%.2f) to (%.2f,%.2f) (limit: %d)...\n",
+func (c *EarthquakeCollector) CollectByRegionData(ctx context.Context, minLat, maxLat, minLon, maxLon float64, limit int) (*models.USGSResponse, error) {
+	c.printf("Collecting earthquakes in region (%.2f,%.2f) to (%.2f,%.2f) (limit: %d)...\n",
 		minLat, minLon, maxLat, maxLon, limit)
 
-	earthquakes, err := c.usgsClient.GetEarthquakesByRegion(minLat, maxLat, minLon, maxLon, limit)
+	earthquakes, err := c.usgsClient.GetEarthquakesByRegion(ctx, minLat, maxLat, minLon, maxLon, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch earthquakes by region: %w", err)
 	}
 
-	fmt.Printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
+	return earthquakes, nil
+}
+
+// CollectByRadiusData collects earthquakes within radiusKm kilometers of a point and returns the data without saving
+func (c *EarthquakeCollector) CollectByRadiusData(ctx context.Context, lat, lon, radiusKm float64, limit int) (*models.USGSResponse, error) {
+	c.printf("Collecting earthquakes within %.1f km of (%.2f,%.2f) (limit: %d)...\n", radiusKm, lat, lon, limit)
+
+	earthquakes, err := c.usgsClient.GetEarthquakesByRadius(ctx, lat, lon, radiusKm, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch earthquakes by radius: %w", err)
+	}
+
+	c.printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
 	return earthquakes, nil
 }
 
 // CollectByCountryData collects earthquakes filtered by country name and returns the data without saving
-func (c *EarthquakeCollector) CollectByCountryData(country string, startTime, endTime time.Time, minMag, maxMag float64, limit int) (*models.USGSResponse, error) {
-	fmt.Printf("Collecting earthquakes in %s from %s to %s (magnitude %.1f-%.1f, limit: %d)...\n",
+func (c *EarthquakeCollector) CollectByCountryData(ctx context.Context, country string, startTime, endTime time.Time, minMag, maxMag float64, limit int) (*models.USGSResponse, error) {
+	c.printf("Collecting earthquakes in %s from %s to %s (magnitude %.1f-%.1f, limit: %d)...\n",
 		country,
 		startTime.Format("2006-01-02 15:04:05"),
 		endTime.Format("2006-01-02 15:04:05"),
 		minMag, maxMag, limit)
 
 	// First, fetch earthquakes by time range and magnitude
-	earthquakes, err := c.usgsClient.GetEarthquakesByTimeRangeAndMagnitude(startTime, endTime, minMag, maxMag, limit*2) // Fetch more to account for filtering
+	earthquakes, err := c.usgsClient.GetEarthquakesByTimeRangeAndMagnitude(ctx, startTime, endTime, minMag, maxMag, limit*2) // Fetch more to account for filtering
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch earthquakes: %w", err)
 	}
 
 	// Filter earthquakes by country
-	var filteredEarthquakes []models.Earthquake
+	filteredEarthquakes := make([]models.Earthquake, 0, len(earthquakes.Features))
 	for _, eq := range earthquakes.Features {
 		if containsCountry(eq.Properties.Place, country) {
 			filteredEarthquakes = append(filteredEarthquakes, eq)
@@ -282,10 +798,57 @@ func (c *EarthquakeCollector) CollectByCountryData(country string, startTime, en
 	// Update metadata count
 	filteredResponse.Metadata.Count = len(filteredEarthquakes)
 
-	fmt.Printf("Found %d earthquakes in %s\n", len(filteredEarthquakes), country)
+	c.printf("Found %d earthquakes in %s\n", len(filteredEarthquakes), country)
+	c.applyFilters(filteredResponse)
 	return filteredResponse, nil
 }
 
+// CollectEventDetail collects the detail of a single event and saves it to the events directory,
+// as event_<id>.json unless a filename is given
+func (c *EarthquakeCollector) CollectEventDetail(ctx context.Context, eventID, filename string) error {
+	detail, err := c.CollectEventDetailData(ctx, eventID)
+	if err != nil {
+		return err
+	}
+
+	if c.noSave {
+		c.printf("Collected detail of event %s (not saved)\n", detail.ID)
+		return nil
+	}
+
+	if filename == "" {
+		filename = "event_" + detail.ID
+	}
+	if err := c.storage.SaveEventDetail(detail, filename); err != nil {
+		return fmt.Errorf("failed to save event detail: %w", err)
+	}
+
+	c.printf("Saved event detail to %s\n", filename)
+	return nil
+}
+
+// CollectEventDetailData collects the detail of a single event and returns it without saving
+func (c *EarthquakeCollector) CollectEventDetailData(ctx context.Context, eventID string) (*models.EventDetail, error) {
+	c.printf("Collecting detail of event %s...\n", eventID)
+
+	detail, err := c.usgsClient.GetEventDetail(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch event detail: %w", utils.ErrorContext(err, map[string]string{"eventid": eventID}))
+	}
+
+	types := detail.ProductTypes()
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		names[i] = fmt.Sprintf("%s (%d)", name, types[name])
+	}
+	c.printf("Found %s with products: %s\n", detail.Properties.Title, strings.Join(names, ", "))
+	return detail, nil
+}
+
 // containsCountry checks if the place string contains the specified country
 func containsCountry(place, country string) bool {
 	// Convert both to lowercase for case-insensitive comparison
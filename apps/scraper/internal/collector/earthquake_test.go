@@ -0,0 +1,385 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/api"
+	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/storage"
+	"quakewatch-scraper/internal/utils"
+)
+
+func TestEarthquakeCollector_CollectRecentByMagnitudeData(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		q := r.URL.Query()
+		if q.Get("minmagnitude") != "2.5" || q.Get("maxmagnitude") != "6.0" {
+			t.Errorf("Unexpected magnitude params: %v", q)
+		}
+		json.NewEncoder(w).Encode(models.USGSResponse{Type: "FeatureCollection"})
+	}))
+	defer server.Close()
+
+	collector := NewEarthquakeCollector(api.NewUSGSClient(server.URL, 5*time.Second), nil)
+	collector.SetProgressOutput(io.Discard)
+
+	if _, err := collector.CollectRecentByMagnitudeData(context.Background(), 1, 2.5, 6.0, 100); err != nil {
+		t.Fatalf("CollectRecentByMagnitudeData() error = %v", err)
+	}
+
+	// Invalid ranges are rejected before any request is made
+	if _, err := collector.CollectRecentByMagnitudeData(context.Background(), 1, 6.0, 2.5, 100); err == nil {
+		t.Error("Expected error when min magnitude exceeds max magnitude")
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+}
+
+func TestQueryFilename(t *testing.T) {
+	a := QueryFilename(map[string]string{"query": "region", "minlat": "32", "maxlat": "42", "limit": "100"})
+	b := QueryFilename(map[string]string{"limit": "100", "maxlat": "42", "minlat": "32", "query": "region"})
+	if a != b {
+		t.Errorf("Expected identical names for identical queries, got %q and %q", a, b)
+	}
+	if !regexp.MustCompile(`^earthquakes_[0-9a-f]{16}$`).MatchString(a) {
+		t.Errorf("Unexpected name format: %q", a)
+	}
+
+	if c := QueryFilename(map[string]string{"query": "region", "minlat": "32", "maxlat": "42", "limit": "200"}); c == a {
+		t.Errorf("Expected a different name for a different limit, got %q", c)
+	}
+}
+
+func TestEarthquakeCollector_DeterministicNames(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.USGSResponse{Type: "FeatureCollection"})
+	}))
+	defer server.Close()
+
+	jsonStorage := storage.NewJSONStorage(t.TempDir())
+	collector := NewEarthquakeCollector(api.NewUSGSClient(server.URL, 5*time.Second), jsonStorage)
+	collector.SetProgressOutput(io.Discard)
+	collector.EnableDeterministicNames()
+
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	// Re-running the same query overwrites its file
+	for i := 0; i < 2; i++ {
+		if err := collector.CollectByTimeRange(ctx, start, end, 100, ""); err != nil {
+			t.Fatalf("CollectByTimeRange() error = %v", err)
+		}
+	}
+	files, err := jsonStorage.ListFiles("earthquakes")
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file after repeating a query, got %v", files)
+	}
+
+	// The same instant in another time zone is the same query
+	if err := collector.CollectByTimeRange(ctx, start.In(time.FixedZone("CET", 3600)), end, 100, ""); err != nil {
+		t.Fatalf("CollectByTimeRange() error = %v", err)
+	}
+	if files, _ := jsonStorage.ListFiles("earthquakes"); len(files) != 1 {
+		t.Errorf("Expected the time zone not to change the name, got %v", files)
+	}
+
+	// A different query or event type gets its own file, and an explicit filename wins
+	if err := collector.CollectByTimeRange(ctx, start, end, 50, ""); err != nil {
+		t.Fatalf("CollectByTimeRange() error = %v", err)
+	}
+	collector.SetEventType("quarry blast")
+	if err := collector.CollectByTimeRange(ctx, start, end, 50, ""); err != nil {
+		t.Fatalf("CollectByTimeRange() error = %v", err)
+	}
+	if err := collector.CollectByTimeRange(ctx, start, end, 50, "custom"); err != nil {
+		t.Fatalf("CollectByTimeRange() error = %v", err)
+	}
+	if files, _ := jsonStorage.ListFiles("earthquakes"); len(files) != 4 {
+		t.Errorf("Expected 4 files, got %v", files)
+	}
+}
+
+func TestEarthquakeCollector_TimeRangeStateName(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+
+	stateName := func(limit int, eventType, catalog string) string {
+		t.Helper()
+		collector := NewEarthquakeCollector(api.NewUSGSClient("http://localhost", 5*time.Second), nil)
+		collector.SetEventType(eventType)
+		if err := collector.SetCatalog(catalog); err != nil {
+			t.Fatalf("SetCatalog() error = %v", err)
+		}
+		return collector.TimeRangeStateName(start, end, limit)
+	}
+
+	base := stateName(1000, "earthquake", "")
+	if !strings.HasPrefix(base, "time-range_2024-01-01_2024-01-08_") {
+		t.Errorf("TimeRangeStateName() = %q, want it to start with the range", base)
+	}
+	if again := stateName(1000, "earthquake", ""); again != base {
+		t.Errorf("Expected the same query to resume the same state, got %q and %q", base, again)
+	}
+
+	// A run with another query must not reuse the windows fetched for this one
+	others := map[string]string{
+		"limit":      stateName(500, "earthquake", ""),
+		"event type": stateName(1000, "explosion", ""),
+		"catalog":    stateName(1000, "earthquake", "us"),
+	}
+	for setting, name := range others {
+		if name == base {
+			t.Errorf("Expected a different state name for a different %s, got %q", setting, name)
+		}
+	}
+}
+
+func TestEarthquakeCollector_DeterministicNamesWithFilters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.USGSResponse{Type: "FeatureCollection"})
+	}))
+	defer server.Close()
+
+	jsonStorage := storage.NewJSONStorage(t.TempDir())
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	collect := func(filters FilterSpec) {
+		t.Helper()
+		collector := NewEarthquakeCollector(api.NewUSGSClient(server.URL, 5*time.Second), jsonStorage)
+		collector.SetProgressOutput(io.Discard)
+		collector.EnableDeterministicNames()
+		if err := collector.AddFilters(filters); err != nil {
+			t.Fatalf("AddFilters() error = %v", err)
+		}
+		if err := collector.CollectByTimeRange(ctx, start, end, 100, ""); err != nil {
+			t.Fatalf("CollectByTimeRange() error = %v", err)
+		}
+	}
+
+	// The same request with different client-side filters must not overwrite one another
+	collect(FilterSpec{})
+	collect(FilterSpec{MinSig: 100})
+	collect(FilterSpec{Network: []string{"us"}})
+	collect(FilterSpec{Network: []string{"us"}, Tsunami: true})
+	if files, _ := jsonStorage.ListFiles("earthquakes"); len(files) != 4 {
+		t.Fatalf("Expected 4 files for 4 filter settings, got %v", files)
+	}
+
+	// The same filters in another case reuse their file
+	collect(FilterSpec{Network: []string{"US"}, Tsunami: true})
+	if files, _ := jsonStorage.ListFiles("earthquakes"); len(files) != 4 {
+		t.Errorf("Expected repeated filters to reuse their file, got %v", files)
+	}
+}
+
+func TestEarthquakeCollector_ErrorContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	collector := NewEarthquakeCollector(api.NewUSGSClient(server.URL, 5*time.Second), nil)
+	collector.SetProgressOutput(io.Discard)
+
+	err := collector.CollectByMagnitude(context.Background(), 4.5, 6.0, 100, "")
+	var collectionErr *utils.CollectionError
+	if !errors.As(err, &collectionErr) {
+		t.Fatalf("Expected *utils.CollectionError, got %v", err)
+	}
+	if collectionErr.Type != utils.ErrorTypeServer || !collectionErr.Retryable {
+		t.Errorf("Expected retryable server error, got %s retryable=%v", collectionErr.Type, collectionErr.Retryable)
+	}
+	if collectionErr.Context["query"] != "magnitude" || collectionErr.Context["minmag"] != "4.5" {
+		t.Errorf("Expected query parameters in context, got %v", collectionErr.Context)
+	}
+}
+
+// stubRecentSource serves a fixed response and counts its calls
+type stubRecentSource struct {
+	response *models.USGSResponse
+	calls    int
+}
+
+func (s *stubRecentSource) GetRecentEarthquakes(ctx context.Context, limit int) (*models.USGSResponse, error) {
+	s.calls++
+	return s.response, nil
+}
+
+func TestEarthquakeCollector_FallbackSource(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		json.NewEncoder(w).Encode(models.USGSResponse{
+			Type:     "FeatureCollection",
+			Features: []models.Earthquake{{ID: "us1"}},
+		})
+	}))
+	defer server.Close()
+
+	fallback := &stubRecentSource{response: &models.USGSResponse{
+		Type:     "FeatureCollection",
+		Features: []models.Earthquake{{ID: "emsc1"}, {ID: "emsc2"}},
+	}}
+
+	var progress strings.Builder
+	collector := NewEarthquakeCollector(api.NewUSGSClient(server.URL, 5*time.Second), nil)
+	collector.SetProgressOutput(&progress)
+	collector.SetFallbackSource("emsc", fallback)
+
+	// USGS is up: the fallback is never asked
+	earthquakes, err := collector.CollectRecentData(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("CollectRecentData() error = %v", err)
+	}
+	if len(earthquakes.Features) != 1 || earthquakes.Features[0].ID != "us1" {
+		t.Errorf("Expected the USGS earthquake, got %+v", earthquakes.Features)
+	}
+	if fallback.calls != 0 {
+		t.Errorf("Expected no fallback calls, got %d", fallback.calls)
+	}
+	if !strings.Contains(progress.String(), "served by usgs") {
+		t.Errorf("Expected USGS to be logged as the source, got %q", progress.String())
+	}
+
+	// USGS is down: the fallback serves the data
+	status = http.StatusServiceUnavailable
+	progress.Reset()
+	earthquakes, err = collector.CollectRecentData(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("CollectRecentData() error = %v", err)
+	}
+	if len(earthquakes.Features) != 2 {
+		t.Errorf("Expected the 2 fallback earthquakes, got %+v", earthquakes.Features)
+	}
+	if fallback.calls != 1 {
+		t.Errorf("Expected 1 fallback call, got %d", fallback.calls)
+	}
+	if !strings.Contains(progress.String(), "served by emsc") {
+		t.Errorf("Expected EMSC to be logged as the source, got %q", progress.String())
+	}
+}
+
+func TestEarthquakeCollector_CollectEventDetail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.EventDetail{
+			Type: "Feature",
+			ID:   r.URL.Query().Get("eventid"),
+			Properties: models.EventDetailProperties{
+				Products: map[string][]models.EventProduct{"moment-tensor": {{Type: "moment-tensor", Source: "us"}}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	outputDir := t.TempDir()
+	jsonStorage := storage.NewJSONStorage(outputDir)
+	collector := NewEarthquakeCollector(api.NewUSGSClient(server.URL, 5*time.Second), jsonStorage)
+	collector.SetProgressOutput(io.Discard)
+
+	if err := collector.CollectEventDetail(context.Background(), "us7000abcd", ""); err != nil {
+		t.Fatalf("CollectEventDetail() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "events", "event_us7000abcd.json"))
+	if err != nil {
+		t.Fatalf("Event detail was not saved: %v", err)
+	}
+	var detail models.EventDetail
+	if err := json.Unmarshal(data, &detail); err != nil {
+		t.Fatalf("Saved detail is not valid JSON: %v", err)
+	}
+	if detail.ID != "us7000abcd" || len(detail.Properties.Products["moment-tensor"]) != 1 {
+		t.Errorf("Unexpected saved detail: %+v", detail)
+	}
+}
+
+func TestEarthquakeCollector_SeenCache(t *testing.T) {
+	dir := t.TempDir()
+	cachePath := filepath.Join(dir, "seen.txt")
+	jsonStorage := storage.NewJSONStorage(dir)
+
+	// Each run loads the cache like a separate invocation of the CLI would
+	run := func(filename string, ids ...string) *models.USGSResponse {
+		t.Helper()
+		cache, err := utils.LoadSeenCache(cachePath, time.Hour)
+		if err != nil {
+			t.Fatalf("LoadSeenCache() error = %v", err)
+		}
+		response := &models.USGSResponse{Type: "FeatureCollection"}
+		for _, id := range ids {
+			response.Features = append(response.Features, models.Earthquake{ID: id})
+		}
+
+		collector := NewEarthquakeCollector(nil, jsonStorage)
+		collector.SetProgressOutput(io.Discard)
+		collector.SetRecentSource("stub", &stubRecentSource{response: response})
+		collector.SetSeenCache(cache)
+		if err := collector.CollectRecent(context.Background(), 10, filename); err != nil {
+			t.Fatalf("CollectRecent() error = %v", err)
+		}
+
+		saved, err := jsonStorage.LoadEarthquakes(filename)
+		if err != nil {
+			t.Fatalf("LoadEarthquakes() error = %v", err)
+		}
+		return saved
+	}
+	ids := func(response *models.USGSResponse) []string {
+		var got []string
+		for _, eq := range response.Features {
+			got = append(got, eq.ID)
+		}
+		return got
+	}
+
+	if got := ids(run("first", "a", "b")); strings.Join(got, ",") != "a,b" {
+		t.Errorf("First run saved %v, want [a b]", got)
+	}
+	if got := ids(run("second", "b", "c")); strings.Join(got, ",") != "c" {
+		t.Errorf("Second run saved %v, want only the unseen [c]", got)
+	}
+	if got := ids(run("third", "a", "b", "c")); len(got) != 0 {
+		t.Errorf("Third run saved %v, want nothing", got)
+	}
+
+	// The Data methods leave writing the cache to the caller
+	cache, _ := utils.LoadSeenCache(cachePath, time.Hour)
+	collector := NewEarthquakeCollector(nil, nil)
+	collector.SetProgressOutput(io.Discard)
+	collector.SetRecentSource("stub", &stubRecentSource{response: &models.USGSResponse{Features: []models.Earthquake{{ID: "d"}}}})
+	collector.SetSeenCache(cache)
+	if _, err := collector.CollectRecentData(context.Background(), 10); err != nil {
+		t.Fatalf("CollectRecentData() error = %v", err)
+	}
+	if reloaded, _ := utils.LoadSeenCache(cachePath, time.Hour); reloaded.Contains("d") {
+		t.Error("Expected the cache file to be unchanged until SaveSeenCache")
+	}
+	if err := collector.SaveSeenCache(); err != nil {
+		t.Fatalf("SaveSeenCache() error = %v", err)
+	}
+	if reloaded, _ := utils.LoadSeenCache(cachePath, time.Hour); !reloaded.Contains("d") || reloaded.Len() != 4 {
+		t.Errorf("Expected a, b, c and d in the saved cache, got %d IDs", reloaded.Len())
+	}
+}
@@ -1,18 +1,25 @@
 package collector
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"quakewatch-scraper/internal/api"
 	"quakewatch-scraper/internal/models"
 	"quakewatch-scraper/internal/storage"
+	"quakewatch-scraper/internal/utils"
 )
 
 // FaultCollector handles collecting fault data
 type FaultCollector struct {
-	emscClient *api.EMSCClient
-	storage    *storage.JSONStorage
+	emscClient  *api.EMSCClient
+	storage     *storage.JSONStorage
+	progress    io.Writer
+	onCollected func(count int)
+	box         *Box
 }
 
 // NewFaultCollector creates a new fault collector
@@ -20,69 +27,133 @@ func NewFaultCollector(emscClient *api.EMSCClient, storage *storage.JSONStorage)
 	return &FaultCollector{
 		emscClient: emscClient,
 		storage:    storage,
+		progress:   os.Stdout,
 	}
 }
 
+// SetProgressOutput sets where progress messages are written (stdout by default)
+func (c *FaultCollector) SetProgressOutput(w io.Writer) {
+	c.progress = w
+}
+
+// SetCollectedHook sets a function that is told how many fault features each collection returned
+func (c *FaultCollector) SetCollectedHook(hook func(count int)) {
+	c.onCollected = hook
+}
+
+// SetBoundingBox keeps only the faults with at least one coordinate inside box. EMSC serves the
+// fault dataset as a single file, so the filter runs after the fetch.
+func (c *FaultCollector) SetBoundingBox(box Box) {
+	c.box = &box
+}
+
+// found reports the number of fetched fault features and applies the bounding box, if any
+func (c *FaultCollector) found(faults *models.Fault) *models.Fault {
+	c.printf("Found %d fault features\n", len(faults.Features))
+	if c.onCollected != nil {
+		c.onCollected(len(faults.Features))
+	}
+	if c.box == nil {
+		return faults
+	}
+
+	filtered := FilterFaultsByBox(faults, *c.box)
+	c.printf("Kept %d fault features inside the bounding box\n", len(filtered.Features))
+	return filtered
+}
+
+// FilterFaultsByBox returns the faults with at least one coordinate inside box. Fault
+// coordinates are GeoJSON [longitude, latitude] pairs.
+func FilterFaultsByBox(faults *models.Fault, box Box) *models.Fault {
+	filtered := &models.Fault{Type: faults.Type, Features: []models.FaultFeature{}}
+	for _, fault := range faults.Features {
+		for _, coord := range fault.Geometry.Coordinates {
+			if len(coord) >= 2 && box.Contains(coord[1], coord[0]) {
+				filtered.Features = append(filtered.Features, fault)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// printf writes a progress message
+func (c *FaultCollector) printf(format string, args ...interface{}) {
+	fmt.Fprintf(c.progress, format, args...)
+}
+
 // CollectFaults collects fault data from EMSC
-func (c *FaultCollector) CollectFaults(filename string) error {
-	fmt.Println("Collecting fault data from EMSC...")
+func (c *FaultCollector) CollectFaults(ctx context.Context, filename string) error {
+	c.printf("Collecting fault data from EMSC...\n")
 
-	faults, err := c.emscClient.GetFaults()
+	faults, err := c.emscClient.GetFaults(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to fetch fault data: %w", err)
 	}
 
-	fmt.Printf("Found %d fault features\n", len(faults.Features))
+	faults = c.found(faults)
 
 	if err := c.storage.SaveFaults(faults, filename); err != nil {
 		return fmt.Errorf("failed to save fault data: %w", err)
 	}
 
-	fmt.Printf("Saved fault data to %s\n", filename)
+	c.printf("Saved fault data to %s\n", filename)
 	return nil
 }
 
-// UpdateFaults updates fault data with retry logic
-func (c *FaultCollector) UpdateFaults(filename string, maxRetries int, retryDelay time.Duration) error {
-	fmt.Printf("Updating fault data from EMSC (max retries: %d)...\n", maxRetries)
+// UpdateFaults updates fault data, retrying failed requests according to the strategy
+func (c *FaultCollector) UpdateFaults(ctx context.Context, filename string, strategy *utils.RetryStrategy) error {
+	c.printf("Updating fault data from EMSC (max retries: %d)...\n", strategy.MaxRetries)
 
-	faults, err := c.emscClient.GetFaultsWithRetry(maxRetries, retryDelay)
+	faults, err := c.emscClient.GetFaultsWithRetry(ctx, withRetryLogging(strategy, c.progress))
 	if err != nil {
 		return fmt.Errorf("failed to fetch fault data with retry: %w", err)
 	}
 
-	fmt.Printf("Found %d fault features\n", len(faults.Features))
+	faults = c.found(faults)
 
 	if err := c.storage.SaveFaults(faults, filename); err != nil {
 		return fmt.Errorf("failed to save fault data: %w", err)
 	}
 
-	fmt.Printf("Updated fault data saved to %s\n", filename)
+	c.printf("Updated fault data saved to %s\n", filename)
 	return nil
 }
 
 // CollectFaultsData collects fault data from EMSC and returns the data without saving
-func (c *FaultCollector) CollectFaultsData() (*models.Fault, error) {
-	fmt.Println("Collecting fault data from EMSC...")
+func (c *FaultCollector) CollectFaultsData(ctx context.Context) (*models.Fault, error) {
+	c.printf("Collecting fault data from EMSC...\n")
 
-	faults, err := c.emscClient.GetFaults()
+	faults, err := c.emscClient.GetFaults(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch fault data: %w", err)
 	}
 
-	fmt.Printf("Found %d fault features\n", len(faults.Features))
+	faults = c.found(faults)
 	return faults, nil
 }
 
-// UpdateFaultsData updates fault data with retry logic and returns the data without saving
-func (c *FaultCollector) UpdateFaultsData(maxRetries int, retryDelay time.Duration) (*models.Fault, error) {
-	fmt.Printf("Updating fault data from EMSC (max retries: %d)...\n", maxRetries)
+// UpdateFaultsData updates fault data, retrying failed requests according to the strategy, and returns the data without saving
+func (c *FaultCollector) UpdateFaultsData(ctx context.Context, strategy *utils.RetryStrategy) (*models.Fault, error) {
+	c.printf("Updating fault data from EMSC (max retries: %d)...\n", strategy.MaxRetries)
 
-	faults, err := c.emscClient.GetFaultsWithRetry(maxRetries, retryDelay)
+	faults, err := c.emscClient.GetFaultsWithRetry(ctx, withRetryLogging(strategy, c.progress))
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch fault data with retry: %w", err)
 	}
 
-	fmt.Printf("Found %d fault features\n", len(faults.Features))
+	faults = c.found(faults)
 	return faults, nil
 }
+
+// withRetryLogging returns a copy of the strategy that reports each retry to w
+func withRetryLogging(strategy *utils.RetryStrategy, w io.Writer) *utils.RetryStrategy {
+	logged := *strategy
+	logged.OnRetry = func(attempt int, delay time.Duration, err error) {
+		if strategy.OnRetry != nil {
+			strategy.OnRetry(attempt, delay, err)
+		}
+		fmt.Fprintf(w, "Attempt %d failed: %v (retrying in %v)\n", attempt, err, delay.Round(time.Millisecond))
+	}
+	return &logged
+}
@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/api"
+	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/utils"
+)
+
+func TestFaultCollector_UpdateFaultsDataRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.WriteHeader(http.StatusInternalServerError)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			json.NewEncoder(w).Encode(models.Fault{
+				Type:     "FeatureCollection",
+				Features: []models.FaultFeature{{Type: "Feature"}},
+			})
+		}
+	}))
+	defer server.Close()
+
+	var retries []time.Duration
+	strategy := utils.NewRetryStrategy(3, time.Millisecond, 5*time.Millisecond)
+	strategy.OnRetry = func(attempt int, delay time.Duration, err error) {
+		retries = append(retries, delay)
+	}
+
+	collector := NewFaultCollector(api.NewEMSCClient(server.URL, 5*time.Second), nil)
+	faults, err := collector.UpdateFaultsData(context.Background(), strategy)
+	if err != nil {
+		t.Fatalf("UpdateFaultsData() error = %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+	if len(faults.Features) != 1 {
+		t.Errorf("Expected 1 fault feature, got %d", len(faults.Features))
+	}
+	if len(retries) != 2 {
+		t.Fatalf("Expected 2 retries, got %d", len(retries))
+	}
+	// The second failure carried Retry-After: 0
+	if retries[1] != 0 {
+		t.Errorf("Expected Retry-After delay of 0, got %v", retries[1])
+	}
+}
+
+func TestFilterFaultsByBox(t *testing.T) {
+	fault := func(id string, coords ...[]float64) models.FaultFeature {
+		return models.FaultFeature{ID: id, Geometry: models.FaultGeometry{Type: "LineString", Coordinates: coords}}
+	}
+	faults := &models.Fault{
+		Type: "FeatureCollection",
+		Features: []models.FaultFeature{
+			fault("inside", []float64{10.5, 45.5}, []float64{10.8, 45.9}),
+			fault("crossing", []float64{8.0, 44.0}, []float64{11.0, 46.0}),
+			fault("outside", []float64{20.0, 30.0}, []float64{21.0, 31.0}),
+			fault("pacific", []float64{179.5, -17.0}, []float64{-179.5, -17.5}),
+			fault("no geometry"),
+		},
+	}
+
+	tests := []struct {
+		name string
+		box  string
+		want []string
+	}{
+		{"northern Italy", "45,47,9,12", []string{"inside", "crossing"}},
+		{"edges included", "46,46,11,11", []string{"crossing"}},
+		{"antimeridian", "-18,-16,179,-179", []string{"pacific"}},
+		{"empty region", "0,1,0,1", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box, err := ParseBox(tt.box)
+			if err != nil {
+				t.Fatalf("ParseBox(%q) error = %v", tt.box, err)
+			}
+
+			filtered := FilterFaultsByBox(faults, box)
+			if filtered.Type != "FeatureCollection" || len(filtered.Features) != len(tt.want) {
+				t.Fatalf("FilterFaultsByBox() = %+v, want %v", filtered.Features, tt.want)
+			}
+			for i, feature := range filtered.Features {
+				if feature.ID != tt.want[i] {
+					t.Errorf("Feature %d = %s, want %s", i, feature.ID, tt.want[i])
+				}
+			}
+		})
+	}
+	if len(faults.Features) != 5 {
+		t.Errorf("FilterFaultsByBox() modified its input")
+	}
+}
+
+func TestFaultCollector_BoundingBox(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.Fault{
+			Type: "FeatureCollection",
+			Features: []models.FaultFeature{
+				{ID: "near", Geometry: models.FaultGeometry{Coordinates: [][]float64{{13.4, 42.3}}}},
+				{ID: "far", Geometry: models.FaultGeometry{Coordinates: [][]float64{{-120.0, 36.0}}}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	collector := NewFaultCollector(api.NewEMSCClient(server.URL, 5*time.Second), nil)
+	collector.SetProgressOutput(io.Discard)
+	collected := 0
+	collector.SetCollectedHook(func(count int) { collected = count })
+	collector.SetBoundingBox(Box{MinLat: 40, MaxLat: 44, MinLon: 12, MaxLon: 15})
+
+	faults, err := collector.CollectFaultsData(context.Background())
+	if err != nil {
+		t.Fatalf("CollectFaultsData() error = %v", err)
+	}
+	if len(faults.Features) != 1 || faults.Features[0].ID != "near" {
+		t.Errorf("Expected only the fault inside the box, got %+v", faults.Features)
+	}
+	if collected != 2 {
+		t.Errorf("Expected the collected hook to see every fetched fault, got %d", collected)
+	}
+}
@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// earthquakeField reads one selectable field of an earthquake
+type earthquakeField struct {
+	name  string
+	value func(eq *models.Earthquake) interface{}
+}
+
+// earthquakeFields maps the lower-cased names accepted by ProjectFields to their fields: the JSON
+// names of the USGS properties plus id, coordinates, longitude, latitude and depth
+var earthquakeFields = buildEarthquakeFields()
+
+func buildEarthquakeFields() map[string]earthquakeField {
+	fields := map[string]earthquakeField{}
+	add := func(name string, value func(eq *models.Earthquake) interface{}) {
+		fields[strings.ToLower(name)] = earthquakeField{name: name, value: value}
+	}
+
+	add("id", func(eq *models.Earthquake) interface{} { return eq.ID })
+	add("coordinates", func(eq *models.Earthquake) interface{} { return eq.Geometry.Coordinates })
+	add("longitude", func(eq *models.Earthquake) interface{} { return eq.Geometry.Longitude() })
+	add("latitude", func(eq *models.Earthquake) interface{} { return eq.Geometry.Latitude() })
+	add("depth", func(eq *models.Earthquake) interface{} { return eq.Geometry.Depth() })
+
+	properties := reflect.TypeOf(models.EarthquakeProperties{})
+	for i := 0; i < properties.NumField(); i++ {
+		index := i
+		name := strings.Split(properties.Field(i).Tag.Get("json"), ",")[0]
+		add(name, func(eq *models.Earthquake) interface{} {
+			return reflect.ValueOf(eq.Properties).Field(index).Interface()
+		})
+	}
+	return fields
+}
+
+// ValidFieldNames returns the names accepted by ProjectFields, sorted
+func ValidFieldNames() []string {
+	names := make([]string, 0, len(earthquakeFields))
+	for _, field := range earthquakeFields {
+		names = append(names, field.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateFields checks that every field name is known, ignoring case
+func ValidateFields(fields []string) error {
+	for _, name := range fields {
+		if _, ok := earthquakeFields[strings.ToLower(strings.TrimSpace(name))]; !ok {
+			return fmt.Errorf("unknown field: %s (must be one of %s)", name, strings.Join(ValidFieldNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// ProjectFields reduces each earthquake to an object holding only the given fields, keyed by
+// their canonical names (e.g. "magType" for "magtype")
+func ProjectFields(earthquakes []models.Earthquake, fields []string) ([]map[string]interface{}, error) {
+	if err := ValidateFields(fields); err != nil {
+		return nil, err
+	}
+
+	records := make([]map[string]interface{}, len(earthquakes))
+	for i := range earthquakes {
+		record := make(map[string]interface{}, len(fields))
+		for _, name := range fields {
+			field := earthquakeFields[strings.ToLower(strings.TrimSpace(name))]
+			record[field.name] = field.value(&earthquakes[i])
+		}
+		records[i] = record
+	}
+	return records, nil
+}
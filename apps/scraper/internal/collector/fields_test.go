@@ -0,0 +1,101 @@
+package collector
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/storage"
+)
+
+func TestProjectFields(t *testing.T) {
+	felt := 12
+	earthquakes := []models.Earthquake{{
+		ID: "us1",
+		Properties: models.EarthquakeProperties{
+			Mag:     models.Float64(4.2),
+			Time:    1705305600000,
+			Place:   "10 km N of Somewhere",
+			MagType: "mww",
+			Felt:    &felt,
+		},
+		Geometry: models.Geometry{Type: "Point", Coordinates: []float64{-117.5, 35.7, 8.2}},
+	}}
+
+	tests := []struct {
+		name   string
+		fields []string
+		want   map[string]interface{}
+	}{
+		{
+			name:   "id time mag coordinates",
+			fields: []string{"id", "time", "mag", "coordinates"},
+			want: map[string]interface{}{
+				"id":          "us1",
+				"time":        int64(1705305600000),
+				"mag":         models.Float64(4.2),
+				"coordinates": []float64{-117.5, 35.7, 8.2},
+			},
+		},
+		{
+			name:   "case-insensitive names and split coordinates",
+			fields: []string{"MAGTYPE", "latitude", "depth", "felt"},
+			want: map[string]interface{}{
+				"magType":  "mww",
+				"latitude": 35.7,
+				"depth":    8.2,
+				"felt":     &felt,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := ProjectFields(earthquakes, tt.fields)
+			if err != nil {
+				t.Fatalf("ProjectFields() error = %v", err)
+			}
+			if len(records) != 1 || !reflect.DeepEqual(records[0], tt.want) {
+				t.Errorf("ProjectFields() = %v, want %v", records, tt.want)
+			}
+		})
+	}
+
+	if _, err := ProjectFields(earthquakes, []string{"id", "magnitude"}); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+}
+
+func TestEarthquakeCollector_SaveFields(t *testing.T) {
+	outputDir := t.TempDir()
+	collector := NewEarthquakeCollector(nil, storage.NewJSONStorage(outputDir))
+	if err := collector.SetFields([]string{"id", "mag"}); err != nil {
+		t.Fatalf("SetFields() error = %v", err)
+	}
+	collector.SetProgressOutput(io.Discard)
+
+	earthquakes := &models.USGSResponse{
+		Type:     "FeatureCollection",
+		Features: []models.Earthquake{{ID: "us1", Properties: models.EarthquakeProperties{Mag: models.Float64(3.1), Place: "Somewhere"}}},
+	}
+	if err := collector.save(earthquakes, "reduced.json", nil); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "earthquakes", "reduced.json"))
+	if err != nil {
+		t.Fatalf("Failed to read saved file: %v", err)
+	}
+	var records []map[string]interface{}
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatalf("Saved file is not a JSON array: %v", err)
+	}
+	want := []map[string]interface{}{{"id": "us1", "mag": 3.1}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("Saved records = %v, want %v", records, want)
+	}
+}
@@ -0,0 +1,212 @@
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// EarthquakeFilter narrows a set of earthquakes down to the ones of interest
+type EarthquakeFilter func([]models.Earthquake) []models.Earthquake
+
+// ValidAlertLevels lists the PAGER alert levels assigned by USGS
+var ValidAlertLevels = []string{"green", "yellow", "orange", "red"}
+
+// FilterByAlert returns the earthquakes whose PAGER alert level matches one of the given levels.
+// Earthquakes without an alert level are excluded whenever at least one level is given.
+func FilterByAlert(earthquakes []models.Earthquake, levels []string) []models.Earthquake {
+	if len(levels) == 0 {
+		return earthquakes
+	}
+
+	wanted := make(map[string]bool, len(levels))
+	for _, level := range levels {
+		wanted[strings.ToLower(strings.TrimSpace(level))] = true
+	}
+
+	filtered := make([]models.Earthquake, 0, len(earthquakes))
+	for _, eq := range earthquakes {
+		if eq.Properties.Alert == "" {
+			continue
+		}
+		if wanted[strings.ToLower(eq.Properties.Alert)] {
+			filtered = append(filtered, eq)
+		}
+	}
+
+	return filtered
+}
+
+// FilterTsunami returns only the earthquakes flagged by USGS as having tsunami potential
+func FilterTsunami(earthquakes []models.Earthquake) []models.Earthquake {
+	filtered := make([]models.Earthquake, 0, len(earthquakes))
+	for _, eq := range earthquakes {
+		if eq.Properties.Tsunami != 0 {
+			filtered = append(filtered, eq)
+		}
+	}
+	return filtered
+}
+
+// FilterByMagnitude returns the earthquakes whose magnitude lies within [minMag, maxMag].
+// Earthquakes without a magnitude are dropped.
+func FilterByMagnitude(earthquakes []models.Earthquake, minMag, maxMag float64) []models.Earthquake {
+	filtered := make([]models.Earthquake, 0, len(earthquakes))
+	for _, eq := range earthquakes {
+		if mag, ok := eq.Properties.Magnitude(); ok && mag >= minMag && mag <= maxMag {
+			filtered = append(filtered, eq)
+		}
+	}
+	return filtered
+}
+
+// FilterBySignificance returns the earthquakes whose USGS significance (sig) is at least minSig
+func FilterBySignificance(earthquakes []models.Earthquake, minSig int) []models.Earthquake {
+	filtered := make([]models.Earthquake, 0, len(earthquakes))
+	for _, eq := range earthquakes {
+		if eq.Properties.Sig >= minSig {
+			filtered = append(filtered, eq)
+		}
+	}
+	return filtered
+}
+
+// FilterByPlace returns the earthquakes whose place description contains substr, ignoring case.
+// An empty substr keeps every earthquake.
+func FilterByPlace(earthquakes []models.Earthquake, substr string) []models.Earthquake {
+	substr = strings.ToLower(strings.TrimSpace(substr))
+	if substr == "" {
+		return earthquakes
+	}
+
+	filtered := make([]models.Earthquake, 0, len(earthquakes))
+	for _, eq := range earthquakes {
+		if strings.Contains(strings.ToLower(eq.Properties.Place), substr) {
+			filtered = append(filtered, eq)
+		}
+	}
+	return filtered
+}
+
+// FilterByNetwork returns the earthquakes contributed by one of the given seismic networks (e.g. us, ci, nc),
+// ignoring case. No networks keeps every earthquake.
+func FilterByNetwork(earthquakes []models.Earthquake, networks []string) []models.Earthquake {
+	if len(networks) == 0 {
+		return earthquakes
+	}
+
+	wanted := make(map[string]bool, len(networks))
+	for _, network := range networks {
+		wanted[strings.ToLower(strings.TrimSpace(network))] = true
+	}
+
+	filtered := make([]models.Earthquake, 0, len(earthquakes))
+	for _, eq := range earthquakes {
+		if wanted[strings.ToLower(eq.Properties.Net)] {
+			filtered = append(filtered, eq)
+		}
+	}
+	return filtered
+}
+
+// FilterByMagType returns the earthquakes whose magnitude was measured on one of the given scales.
+// Types are compared after models.NormalizeMagType, so "mw" also matches Mww, Mwc and Mwr.
+// No types keeps every earthquake.
+func FilterByMagType(earthquakes []models.Earthquake, magTypes []string) []models.Earthquake {
+	if len(magTypes) == 0 {
+		return earthquakes
+	}
+
+	wanted := make(map[string]bool, len(magTypes))
+	for _, magType := range magTypes {
+		wanted[models.NormalizeMagType(magType)] = true
+	}
+
+	filtered := make([]models.Earthquake, 0, len(earthquakes))
+	for _, eq := range earthquakes {
+		if eq.Properties.MagType != "" && wanted[models.NormalizeMagType(eq.Properties.MagType)] {
+			filtered = append(filtered, eq)
+		}
+	}
+	return filtered
+}
+
+// Box is a latitude/longitude bounding box. A box whose MinLon is greater than its MaxLon
+// crosses the antimeridian.
+type Box struct {
+	MinLat, MaxLat, MinLon, MaxLon float64
+}
+
+// ParseBox parses a box written as "minLat,maxLat,minLon,maxLon"
+func ParseBox(s string) (Box, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return Box{}, fmt.Errorf("invalid box %q: expected minLat,maxLat,minLon,maxLon", s)
+	}
+
+	var values [4]float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return Box{}, fmt.Errorf("invalid box %q: %w", s, err)
+		}
+		values[i] = value
+	}
+
+	box := Box{MinLat: values[0], MaxLat: values[1], MinLon: values[2], MaxLon: values[3]}
+	if box.MinLat < -90 || box.MaxLat > 90 || box.MinLat > box.MaxLat {
+		return Box{}, fmt.Errorf("invalid box %q: latitudes must satisfy -90 <= minLat <= maxLat <= 90", s)
+	}
+	if box.MinLon < -180 || box.MinLon > 180 || box.MaxLon < -180 || box.MaxLon > 180 {
+		return Box{}, fmt.Errorf("invalid box %q: longitudes must be between -180 and 180", s)
+	}
+	return box, nil
+}
+
+// Contains reports whether a point lies inside the box, edges included
+func (b Box) Contains(lat, lon float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.MinLon <= b.MaxLon {
+		return lon >= b.MinLon && lon <= b.MaxLon
+	}
+	// The box wraps around the antimeridian
+	return lon >= b.MinLon || lon <= b.MaxLon
+}
+
+// FilterExcludeBoxes returns the earthquakes that lie outside every one of the given boxes
+func FilterExcludeBoxes(earthquakes []models.Earthquake, boxes []Box) []models.Earthquake {
+	if len(boxes) == 0 {
+		return earthquakes
+	}
+
+	filtered := make([]models.Earthquake, 0, len(earthquakes))
+	for _, eq := range earthquakes {
+		lat, lon := eq.Geometry.Latitude(), eq.Geometry.Longitude()
+		excluded := false
+		for _, box := range boxes {
+			if box.Contains(lat, lon) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			filtered = append(filtered, eq)
+		}
+	}
+	return filtered
+}
+
+// IsValidAlertLevel checks if the given level is a known PAGER alert level
+func IsValidAlertLevel(level string) bool {
+	level = strings.ToLower(strings.TrimSpace(level))
+	for _, valid := range ValidAlertLevels {
+		if level == valid {
+			return true
+		}
+	}
+	return false
+}
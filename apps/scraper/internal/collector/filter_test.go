@@ -0,0 +1,313 @@
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// testEarthquake builds a minimal earthquake for filter tests
+func testEarthquake(id string, props models.EarthquakeProperties) models.Earthquake {
+	return models.Earthquake{
+		Type:       "Feature",
+		ID:         id,
+		Properties: props,
+		Geometry: models.Geometry{
+			Type:        "Point",
+			Coordinates: []float64{-122.4194, 37.7749, 10.0},
+		},
+	}
+}
+
+// featureIDs returns the IDs of the given earthquakes in order
+func featureIDs(earthquakes []models.Earthquake) []string {
+	ids := make([]string, 0, len(earthquakes))
+	for _, eq := range earthquakes {
+		ids = append(ids, eq.ID)
+	}
+	return ids
+}
+
+func assertIDs(t *testing.T, got []models.Earthquake, want ...string) {
+	t.Helper()
+
+	ids := featureIDs(got)
+	if len(ids) != len(want) {
+		t.Fatalf("Expected IDs %v, got %v", want, ids)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("Expected IDs %v, got %v", want, ids)
+		}
+	}
+}
+
+func TestFilterByAlert(t *testing.T) {
+	earthquakes := []models.Earthquake{
+		testEarthquake("green", models.EarthquakeProperties{Alert: "green"}),
+		testEarthquake("orange", models.EarthquakeProperties{Alert: "orange"}),
+		testEarthquake("none", models.EarthquakeProperties{}),
+		testEarthquake("red", models.EarthquakeProperties{Alert: "RED"}),
+	}
+
+	tests := []struct {
+		name   string
+		levels []string
+		want   []string
+	}{
+		{
+			name:   "No levels keeps everything",
+			levels: nil,
+			want:   []string{"green", "orange", "none", "red"},
+		},
+		{
+			name:   "Single level",
+			levels: []string{"green"},
+			want:   []string{"green"},
+		},
+		{
+			name:   "Multiple levels are case-insensitive",
+			levels: []string{"orange", "Red"},
+			want:   []string{"orange", "red"},
+		},
+		{
+			name:   "Unmatched level",
+			levels: []string{"yellow"},
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertIDs(t, FilterByAlert(earthquakes, tt.levels), tt.want...)
+		})
+	}
+}
+
+func TestFilterByAlert_ExcludesEmptyAlert(t *testing.T) {
+	earthquakes := []models.Earthquake{
+		testEarthquake("none", models.EarthquakeProperties{}),
+	}
+
+	// Every level is requested, but records without an alert must still be dropped
+	filtered := FilterByAlert(earthquakes, ValidAlertLevels)
+	if len(filtered) != 0 {
+		t.Errorf("Expected empty-alert earthquake to be excluded, got %v", featureIDs(filtered))
+	}
+}
+
+func TestFilterTsunami(t *testing.T) {
+	earthquakes := []models.Earthquake{
+		testEarthquake("flagged", models.EarthquakeProperties{Tsunami: 1, Alert: "red"}),
+		testEarthquake("unflagged", models.EarthquakeProperties{Tsunami: 0, Alert: "red"}),
+		testEarthquake("flagged-green", models.EarthquakeProperties{Tsunami: 1, Alert: "green"}),
+	}
+
+	assertIDs(t, FilterTsunami(earthquakes), "flagged", "flagged-green")
+
+	// Filters compose: tsunami-flagged and red alert
+	composed := FilterByAlert(FilterTsunami(earthquakes), []string{"red"})
+	assertIDs(t, composed, "flagged")
+
+	if len(FilterTsunami(nil)) != 0 {
+		t.Error("Expected no earthquakes from an empty input")
+	}
+}
+
+func TestFilterByPlace(t *testing.T) {
+	earthquakes := []models.Earthquake{
+		testEarthquake("ridgecrest", models.EarthquakeProperties{Place: "17 km N of Ridgecrest, CA"}),
+		testEarthquake("upper", models.EarthquakeProperties{Place: "5 KM W OF RIDGECREST, CA"}),
+		testEarthquake("other", models.EarthquakeProperties{Place: "10 km SE of Anza, CA"}),
+		testEarthquake("empty", models.EarthquakeProperties{}),
+	}
+
+	tests := []struct {
+		name   string
+		substr string
+		want   []string
+	}{
+		{name: "Case-insensitive match", substr: "ridgecrest", want: []string{"ridgecrest", "upper"}},
+		{name: "Mixed case query", substr: "RidgeCrest", want: []string{"ridgecrest", "upper"}},
+		{name: "Shared suffix", substr: ", ca", want: []string{"ridgecrest", "upper", "other"}},
+		{name: "No match", substr: "Tokyo", want: []string{}},
+		{name: "Empty substring keeps everything", substr: "", want: []string{"ridgecrest", "upper", "other", "empty"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertIDs(t, FilterByPlace(earthquakes, tt.substr), tt.want...)
+		})
+	}
+}
+
+// earthquakeAt returns a test earthquake at the given coordinates
+func earthquakeAt(id string, lat, lon float64) models.Earthquake {
+	eq := testEarthquake(id, models.EarthquakeProperties{})
+	eq.Geometry.Coordinates = []float64{lon, lat, 10.0}
+	return eq
+}
+
+func TestFilterExcludeBoxes(t *testing.T) {
+	earthquakes := []models.Earthquake{
+		earthquakeAt("swarm", 19.4, -155.3),
+		earthquakeAt("edge", 19.6, -155.0),
+		earthquakeAt("nearby", 19.8, -155.3),
+		earthquakeAt("fiji-east", -17.5, 179.5),
+		earthquakeAt("fiji-west", -17.5, -179.5),
+		earthquakeAt("tonga", -21.0, -175.0),
+	}
+
+	swarm := Box{MinLat: 19.2, MaxLat: 19.6, MinLon: -155.5, MaxLon: -155.0}
+	antimeridian := Box{MinLat: -20, MaxLat: -15, MinLon: 178, MaxLon: -178}
+
+	tests := []struct {
+		name  string
+		boxes []Box
+		want  []string
+	}{
+		{name: "No boxes keeps everything", boxes: nil, want: []string{"swarm", "edge", "nearby", "fiji-east", "fiji-west", "tonga"}},
+		{name: "Single box, edges excluded", boxes: []Box{swarm}, want: []string{"nearby", "fiji-east", "fiji-west", "tonga"}},
+		{name: "Antimeridian box", boxes: []Box{antimeridian}, want: []string{"swarm", "edge", "nearby", "tonga"}},
+		{name: "Multiple boxes", boxes: []Box{swarm, antimeridian}, want: []string{"nearby", "tonga"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertIDs(t, FilterExcludeBoxes(earthquakes, tt.boxes), tt.want...)
+		})
+	}
+}
+
+func TestParseBox(t *testing.T) {
+	box, err := ParseBox("19.2, 19.6, -155.5, -155.0")
+	if err != nil {
+		t.Fatalf("ParseBox() error = %v", err)
+	}
+	if box != (Box{MinLat: 19.2, MaxLat: 19.6, MinLon: -155.5, MaxLon: -155.0}) {
+		t.Errorf("ParseBox() = %+v", box)
+	}
+
+	if _, err := ParseBox("-20,-15,178,-178"); err != nil {
+		t.Errorf("Expected an antimeridian box to parse, got %v", err)
+	}
+
+	for _, invalid := range []string{"1,2,3", "a,2,3,4", "10,5,0,1", "0,95,0,1", "0,1,0,190"} {
+		if _, err := ParseBox(invalid); err == nil {
+			t.Errorf("Expected ParseBox(%q) to fail", invalid)
+		}
+	}
+}
+
+func TestFilterBySignificance(t *testing.T) {
+	earthquakes := []models.Earthquake{
+		testEarthquake("below", models.EarthquakeProperties{Sig: 599}),
+		testEarthquake("at", models.EarthquakeProperties{Sig: 600}),
+		testEarthquake("above", models.EarthquakeProperties{Sig: 1200}),
+	}
+
+	tests := []struct {
+		name   string
+		minSig int
+		want   []string
+	}{
+		{name: "Threshold is inclusive", minSig: 600, want: []string{"at", "above"}},
+		{name: "Just above the boundary", minSig: 601, want: []string{"above"}},
+		{name: "Zero keeps everything", minSig: 0, want: []string{"below", "at", "above"}},
+		{name: "Nothing meets the threshold", minSig: 2000, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertIDs(t, FilterBySignificance(earthquakes, tt.minSig), tt.want...)
+		})
+	}
+}
+
+func TestFilterByMagType(t *testing.T) {
+	earthquakes := []models.Earthquake{
+		testEarthquake("mww", models.EarthquakeProperties{MagType: "mww"}),
+		testEarthquake("mwr", models.EarthquakeProperties{MagType: "Mwr"}),
+		testEarthquake("ml", models.EarthquakeProperties{MagType: "ml"}),
+		testEarthquake("mb", models.EarthquakeProperties{MagType: "mb"}),
+		testEarthquake("mblg", models.EarthquakeProperties{MagType: "mb_lg"}),
+		testEarthquake("none", models.EarthquakeProperties{}),
+	}
+
+	tests := []struct {
+		name     string
+		magTypes []string
+		want     []string
+	}{
+		{name: "No types keeps everything", magTypes: nil, want: []string{"mww", "mwr", "ml", "mb", "mblg", "none"}},
+		{name: "Moment magnitude family", magTypes: []string{"mw"}, want: []string{"mww", "mwr"}},
+		{name: "Variant matches its family", magTypes: []string{"MWC"}, want: []string{"mww", "mwr"}},
+		{name: "Body wave is not Lg", magTypes: []string{"mb"}, want: []string{"mb"}},
+		{name: "Several types", magTypes: []string{"ml", "mb_lg"}, want: []string{"ml", "mblg"}},
+		{name: "Unmatched type is dropped", magTypes: []string{"md"}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertIDs(t, FilterByMagType(earthquakes, tt.magTypes), tt.want...)
+		})
+	}
+}
+
+func TestFilterByNetwork(t *testing.T) {
+	earthquakes := []models.Earthquake{
+		testEarthquake("us", models.EarthquakeProperties{Net: "us"}),
+		testEarthquake("ci", models.EarthquakeProperties{Net: "ci"}),
+		testEarthquake("nc-upper", models.EarthquakeProperties{Net: "NC"}),
+		testEarthquake("none", models.EarthquakeProperties{}),
+	}
+
+	tests := []struct {
+		name     string
+		networks []string
+		want     []string
+	}{
+		{name: "No networks keeps everything", networks: nil, want: []string{"us", "ci", "nc-upper", "none"}},
+		{name: "Single network", networks: []string{"ci"}, want: []string{"ci"}},
+		{name: "Case-insensitive", networks: []string{"nc", "US"}, want: []string{"us", "nc-upper"}},
+		{name: "Unmatched network is dropped", networks: []string{"ak"}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertIDs(t, FilterByNetwork(earthquakes, tt.networks), tt.want...)
+		})
+	}
+}
+
+func TestFilters_EmptyResultEncodesEmptyArray(t *testing.T) {
+	earthquakes := []models.Earthquake{earthquakeAt("swarm", 19.4, -155.3)}
+
+	filters := map[string]func([]models.Earthquake) []models.Earthquake{
+		"alert":     func(eqs []models.Earthquake) []models.Earthquake { return FilterByAlert(eqs, []string{"red"}) },
+		"tsunami":   FilterTsunami,
+		"magnitude": func(eqs []models.Earthquake) []models.Earthquake { return FilterByMagnitude(eqs, 9, 10) },
+		"sig":       func(eqs []models.Earthquake) []models.Earthquake { return FilterBySignificance(eqs, 1000) },
+		"place":     func(eqs []models.Earthquake) []models.Earthquake { return FilterByPlace(eqs, "Alaska") },
+		"network":   func(eqs []models.Earthquake) []models.Earthquake { return FilterByNetwork(eqs, []string{"ak"}) },
+		"mag-type":  func(eqs []models.Earthquake) []models.Earthquake { return FilterByMagType(eqs, []string{"mw"}) },
+		"exclude-region": func(eqs []models.Earthquake) []models.Earthquake {
+			return FilterExcludeBoxes(eqs, []Box{{MinLat: 19, MaxLat: 20, MinLon: -156, MaxLon: -155}})
+		},
+	}
+
+	// Saved collections must hold "features": [] rather than null when nothing matches
+	for name, filter := range filters {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(models.USGSResponse{Type: "FeatureCollection", Features: filter(earthquakes)})
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !strings.Contains(string(data), `"features":[]`) {
+				t.Errorf("Expected an empty features array, got %s", data)
+			}
+		})
+	}
+}
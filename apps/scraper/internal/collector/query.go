@@ -0,0 +1,391 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/utils"
+)
+
+// maxQuerySpecLimit is the largest number of events USGS returns for a single query
+const maxQuerySpecLimit = 20000
+
+// QuerySpec describes an earthquake query in a YAML or JSON file, for queries with more
+// constraints than are convenient to pass as flags
+type QuerySpec struct {
+	Start     string          `yaml:"start"` // YYYY-MM-DD or RFC 3339
+	End       string          `yaml:"end"`
+	Limit     int             `yaml:"limit"`
+	Magnitude *RangeSpec      `yaml:"magnitude"`
+	Depth     *RangeSpec      `yaml:"depth"` // kilometers
+	Region    *RegionSpec     `yaml:"region"`
+	OrderBy   string          `yaml:"order_by"`
+	EventType *string         `yaml:"event_type"` // nil keeps the command's event type
+	Catalog   string          `yaml:"catalog"`
+	Filters   FilterSpec      `yaml:"filters"`
+	Output    QueryOutputSpec `yaml:"output"`
+}
+
+// RangeSpec is an inclusive range; a nil bound leaves that side open
+type RangeSpec struct {
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+}
+
+// RegionSpec is a latitude/longitude rectangle
+type RegionSpec struct {
+	MinLat float64 `yaml:"min_lat"`
+	MaxLat float64 `yaml:"max_lat"`
+	MinLon float64 `yaml:"min_lon"`
+	MaxLon float64 `yaml:"max_lon"`
+}
+
+// FilterSpec holds the filters applied to the earthquakes a query returns
+type FilterSpec struct {
+	Alert         []string `yaml:"alert"`
+	Tsunami       bool     `yaml:"tsunami"`
+	PlaceContains string   `yaml:"place_contains"`
+	Network       []string `yaml:"network"`
+	ExcludeRegion []string `yaml:"exclude_region"` // minLat,maxLat,minLon,maxLon
+	MinSig        int      `yaml:"min_sig"`
+	MagType       []string `yaml:"mag_type"`
+}
+
+// QueryOutputSpec holds how the results of a query are written
+type QueryOutputSpec struct {
+	Filename string   `yaml:"filename"`
+	Stdout   bool     `yaml:"stdout"`
+	Gzip     bool     `yaml:"gzip"`
+	Compact  bool     `yaml:"compact"`
+	Fields   []string `yaml:"fields"`
+	Summary  bool     `yaml:"summary"`
+}
+
+// LoadQuerySpec reads and validates a query spec. JSON is accepted as well as YAML; unknown
+// keys are rejected so a misspelled constraint is not silently ignored.
+func LoadQuerySpec(path string) (*QuerySpec, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open query spec: %w", err)
+	}
+	defer file.Close()
+
+	spec, err := ParseQuerySpec(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query spec %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// ParseQuerySpec decodes and validates a YAML or JSON query spec
+func ParseQuerySpec(r io.Reader) (*QuerySpec, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var spec QuerySpec
+	if err := decoder.Decode(&spec); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("query spec is empty")
+		}
+		return nil, err
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// parseSpecTime parses a spec time given as a date or an RFC 3339 timestamp
+func parseSpecTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// Validate checks that the spec describes a query USGS can answer
+func (s *QuerySpec) Validate() error {
+	var start, end time.Time
+	var err error
+	if s.Start != "" {
+		if start, err = parseSpecTime(s.Start); err != nil {
+			return fmt.Errorf("invalid start %q: must be YYYY-MM-DD or RFC 3339", s.Start)
+		}
+	}
+	if s.End != "" {
+		if end, err = parseSpecTime(s.End); err != nil {
+			return fmt.Errorf("invalid end %q: must be YYYY-MM-DD or RFC 3339", s.End)
+		}
+	}
+	if s.Start != "" && s.End != "" && !start.Before(end) {
+		return fmt.Errorf("start %s must be before end %s", s.Start, s.End)
+	}
+
+	if s.Limit < 0 || s.Limit > maxQuerySpecLimit {
+		return fmt.Errorf("invalid limit %d: must be between 0 and %d", s.Limit, maxQuerySpecLimit)
+	}
+
+	if err := s.Magnitude.validate("magnitude", -2, 10); err != nil {
+		return err
+	}
+	if err := s.Depth.validate("depth", -100, 1000); err != nil {
+		return err
+	}
+	if r := s.Region; r != nil {
+		if r.MinLat < -90 || r.MaxLat > 90 || r.MinLat > r.MaxLat {
+			return fmt.Errorf("invalid region: latitudes must satisfy -90 <= min_lat <= max_lat <= 90")
+		}
+		if r.MinLon < -180 || r.MaxLon > 180 || r.MinLon > r.MaxLon {
+			return fmt.Errorf("invalid region: longitudes must satisfy -180 <= min_lon <= max_lon <= 180")
+		}
+	}
+
+	for _, level := range s.Filters.Alert {
+		if !IsValidAlertLevel(level) {
+			return fmt.Errorf("invalid alert level: %s (must be one of %s)", level, strings.Join(ValidAlertLevels, ", "))
+		}
+	}
+	for _, value := range s.Filters.ExcludeRegion {
+		if _, err := ParseBox(value); err != nil {
+			return fmt.Errorf("invalid exclude_region: %w", err)
+		}
+	}
+	if s.Filters.MinSig < 0 {
+		return fmt.Errorf("invalid min_sig %d: must not be negative", s.Filters.MinSig)
+	}
+
+	if err := ValidateFields(s.Output.Fields); err != nil {
+		return err
+	}
+	if s.Output.Stdout && s.Output.Filename != "" {
+		return fmt.Errorf("output.filename cannot be combined with output.stdout")
+	}
+	return nil
+}
+
+// validate checks that a range lies within [lowest, highest] and is not inverted
+func (r *RangeSpec) validate(name string, lowest, highest float64) error {
+	if r == nil {
+		return nil
+	}
+	for _, bound := range []*float64{r.Min, r.Max} {
+		if bound != nil && (*bound < lowest || *bound > highest) {
+			return fmt.Errorf("invalid %s %g: must be between %g and %g", name, *bound, lowest, highest)
+		}
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return fmt.Errorf("invalid %s range: min %g is greater than max %g", name, *r.Min, *r.Max)
+	}
+	return nil
+}
+
+// Params returns the USGS query parameters for the spec. A spec without a limit uses
+// defaultLimit.
+func (s *QuerySpec) Params(defaultLimit int) map[string]string {
+	limit := s.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	params := map[string]string{"limit": strconv.Itoa(limit)}
+
+	// Validate has already checked the times
+	if s.Start != "" {
+		start, _ := parseSpecTime(s.Start)
+		params["starttime"] = start.UTC().Format("2006-01-02T15:04:05")
+	}
+	if s.End != "" {
+		end, _ := parseSpecTime(s.End)
+		params["endtime"] = end.UTC().Format("2006-01-02T15:04:05")
+	}
+
+	if m := s.Magnitude; m != nil {
+		if m.Min != nil {
+			params["minmagnitude"] = formatQueryFloat(*m.Min)
+		}
+		if m.Max != nil {
+			params["maxmagnitude"] = formatQueryFloat(*m.Max)
+		}
+	}
+	if d := s.Depth; d != nil {
+		if d.Min != nil {
+			params["mindepth"] = formatQueryFloat(*d.Min)
+		}
+		if d.Max != nil {
+			params["maxdepth"] = formatQueryFloat(*d.Max)
+		}
+	}
+	if r := s.Region; r != nil {
+		params["minlatitude"] = formatQueryFloat(r.MinLat)
+		params["maxlatitude"] = formatQueryFloat(r.MaxLat)
+		params["minlongitude"] = formatQueryFloat(r.MinLon)
+		params["maxlongitude"] = formatQueryFloat(r.MaxLon)
+	}
+	return params
+}
+
+// Configure applies the spec's ordering, event type, catalog, filters, fields and summary to
+// a collector. They are added to whatever the collector was already configured with.
+func (s *QuerySpec) Configure(c *EarthquakeCollector) error {
+	if s.OrderBy != "" {
+		if err := c.SetOrderBy(s.OrderBy); err != nil {
+			return err
+		}
+	}
+	if s.EventType != nil {
+		c.SetEventType(*s.EventType)
+	}
+	if s.Catalog != "" {
+		if err := c.SetCatalog(s.Catalog); err != nil {
+			return err
+		}
+	}
+
+	if err := c.AddFilters(s.Filters); err != nil {
+		return fmt.Errorf("invalid exclude_region: %w", err)
+	}
+
+	if len(s.Output.Fields) > 0 {
+		if err := c.SetFields(s.Output.Fields); err != nil {
+			return err
+		}
+	}
+	if s.Output.Summary {
+		c.EnableSummary()
+	}
+	return nil
+}
+
+// CollectQuery collects the earthquakes described by a query spec and saves them
+func (c *EarthquakeCollector) CollectQuery(ctx context.Context, spec *QuerySpec, defaultLimit int, filename string) error {
+	query := spec.Params(defaultLimit)
+	query["query"] = "spec"
+	filename = c.outputFilename(filename, query)
+
+	earthquakes, err := c.CollectQueryData(ctx, spec, defaultLimit)
+	if err != nil {
+		return utils.ErrorContext(err, query)
+	}
+
+	return c.save(earthquakes, filename, query)
+}
+
+// CollectQueryData collects the earthquakes described by a query spec and returns the data without saving
+func (c *EarthquakeCollector) CollectQueryData(ctx context.Context, spec *QuerySpec, defaultLimit int) (*models.USGSResponse, error) {
+	params := spec.Params(defaultLimit)
+	c.printf("Collecting earthquakes for query spec (limit: %s)...\n", params["limit"])
+
+	earthquakes, err := c.usgsClient.GetEarthquakes(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch earthquakes for query spec: %w", err)
+	}
+
+	c.printf("Found %d earthquakes\n", len(earthquakes.Features))
+	c.applyFilters(earthquakes)
+	return earthquakes, nil
+}
+
+// AddFilters registers the filters set in filters. Their settings are also recorded for
+// deterministic names, so the same query with different filters is saved under its own name.
+// It fails only on an exclude region that does not parse.
+func (c *EarthquakeCollector) AddFilters(filters FilterSpec) error {
+	if len(filters.Alert) > 0 {
+		c.AddFilter(func(earthquakes []models.Earthquake) []models.Earthquake {
+			return FilterByAlert(earthquakes, filters.Alert)
+		})
+	}
+	if filters.Tsunami {
+		c.AddFilter(FilterTsunami)
+	}
+	if filters.PlaceContains != "" {
+		c.AddFilter(func(earthquakes []models.Earthquake) []models.Earthquake {
+			return FilterByPlace(earthquakes, filters.PlaceContains)
+		})
+	}
+	if len(filters.Network) > 0 {
+		c.AddFilter(func(earthquakes []models.Earthquake) []models.Earthquake {
+			return FilterByNetwork(earthquakes, filters.Network)
+		})
+	}
+	if len(filters.ExcludeRegion) > 0 {
+		boxes := make([]Box, 0, len(filters.ExcludeRegion))
+		for _, value := range filters.ExcludeRegion {
+			box, err := ParseBox(value)
+			if err != nil {
+				return err
+			}
+			boxes = append(boxes, box)
+		}
+		c.AddFilter(func(earthquakes []models.Earthquake) []models.Earthquake {
+			return FilterExcludeBoxes(earthquakes, boxes)
+		})
+	}
+	if filters.MinSig > 0 {
+		c.AddFilter(func(earthquakes []models.Earthquake) []models.Earthquake {
+			return FilterBySignificance(earthquakes, filters.MinSig)
+		})
+	}
+	if len(filters.MagType) > 0 {
+		c.AddFilter(func(earthquakes []models.Earthquake) []models.Earthquake {
+			return FilterByMagType(earthquakes, filters.MagType)
+		})
+	}
+
+	// Filters added twice, e.g. by flags and a query spec, both apply
+	for key, value := range filters.options() {
+		if previous, ok := c.queryOptions[key]; ok {
+			value = previous + "&" + value
+		}
+		c.queryOptions[key] = value
+	}
+	return nil
+}
+
+// options describes the set filters as query options, with list values sorted and lowercased
+// where the filters ignore case and order
+func (f FilterSpec) options() map[string]string {
+	options := make(map[string]string)
+	list := func(values []string, fold bool) string {
+		sorted := make([]string, len(values))
+		for i, value := range values {
+			sorted[i] = strings.TrimSpace(value)
+			if fold {
+				sorted[i] = strings.ToLower(sorted[i])
+			}
+		}
+		sort.Strings(sorted)
+		return strings.Join(sorted, ",")
+	}
+
+	if len(f.Alert) > 0 {
+		options["filter_alert"] = list(f.Alert, true)
+	}
+	if f.Tsunami {
+		options["filter_tsunami"] = "true"
+	}
+	if f.PlaceContains != "" {
+		options["filter_place"] = strings.ToLower(f.PlaceContains)
+	}
+	if len(f.Network) > 0 {
+		options["filter_network"] = list(f.Network, true)
+	}
+	if len(f.ExcludeRegion) > 0 {
+		options["filter_exclude_region"] = strings.Join(f.ExcludeRegion, ";")
+	}
+	if f.MinSig > 0 {
+		options["filter_min_sig"] = strconv.Itoa(f.MinSig)
+	}
+	if len(f.MagType) > 0 {
+		options["filter_mag_type"] = list(f.MagType, true)
+	}
+	return options
+}
@@ -0,0 +1,138 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/api"
+	"quakewatch-scraper/internal/models"
+)
+
+func TestLoadQuerySpec(t *testing.T) {
+	spec, err := LoadQuerySpec("testdata/query.yaml")
+	if err != nil {
+		t.Fatalf("LoadQuerySpec() error = %v", err)
+	}
+
+	want := map[string]string{
+		"starttime":    "2024-01-01T00:00:00",
+		"endtime":      "2024-02-01T00:00:00",
+		"limit":        "500",
+		"minmagnitude": "4.5",
+		"maxdepth":     "70",
+		"minlatitude":  "30",
+		"maxlatitude":  "46",
+		"minlongitude": "129",
+		"maxlongitude": "146",
+	}
+	params := spec.Params(1000)
+	if len(params) != len(want) {
+		t.Errorf("Params() = %v, want %v", params, want)
+	}
+	for key, value := range want {
+		if params[key] != value {
+			t.Errorf("Params()[%q] = %q, want %q", key, params[key], value)
+		}
+	}
+
+	if spec.OrderBy != "magnitude" || spec.Catalog != "us" {
+		t.Errorf("Unexpected order_by %q or catalog %q", spec.OrderBy, spec.Catalog)
+	}
+	if !spec.Filters.Tsunami || spec.Filters.PlaceContains != "japan" || spec.Filters.MinSig != 400 {
+		t.Errorf("Unexpected filters: %+v", spec.Filters)
+	}
+	if spec.Output.Filename != "japan_2024_01" || !spec.Output.Gzip || len(spec.Output.Fields) != 4 {
+		t.Errorf("Unexpected output: %+v", spec.Output)
+	}
+}
+
+func TestParseQuerySpec_JSON(t *testing.T) {
+	spec, err := ParseQuerySpec(strings.NewReader(`{"start": "2024-03-01", "end": "2024-03-02", "magnitude": {"min": 2, "max": 5}}`))
+	if err != nil {
+		t.Fatalf("ParseQuerySpec() error = %v", err)
+	}
+
+	params := spec.Params(100)
+	if params["starttime"] != "2024-03-01T00:00:00" || params["minmagnitude"] != "2" || params["maxmagnitude"] != "5" || params["limit"] != "100" {
+		t.Errorf("Unexpected params: %v", params)
+	}
+}
+
+func TestParseQuerySpec_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{"empty", ``, "empty"},
+		{"unknown key", `min_magnitude: 4`, "min_magnitude"},
+		{"bad start", `start: yesterday`, "invalid start"},
+		{"start after end", "start: 2024-02-01\nend: 2024-01-01", "must be before end"},
+		{"negative limit", `limit: -1`, "invalid limit"},
+		{"limit too large", `limit: 50000`, "invalid limit"},
+		{"inverted magnitude", "magnitude:\n  min: 6\n  max: 4", "invalid magnitude range"},
+		{"depth out of range", "depth:\n  max: 5000", "invalid depth"},
+		{"bad latitude", "region:\n  min_lat: -100\n  max_lat: 10\n  min_lon: 0\n  max_lon: 10", "latitudes"},
+		{"bad alert", "filters:\n  alert: [purple]", "invalid alert level"},
+		{"bad exclude region", "filters:\n  exclude_region: ['1,2,3']", "invalid exclude_region"},
+		{"unknown field", "output:\n  fields: [nope]", "unknown field"},
+		{"stdout and filename", "output:\n  stdout: true\n  filename: out", "cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQuerySpec(strings.NewReader(tt.spec))
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Error %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEarthquakeCollector_CollectQueryData(t *testing.T) {
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		json.NewEncoder(w).Encode(models.USGSResponse{
+			Type: "FeatureCollection",
+			Features: []models.Earthquake{
+				testEarthquake("near", models.EarthquakeProperties{Mag: models.Float64(5), Place: "20 km E of Iwaki, Japan", Tsunami: 1, Sig: 500}),
+				testEarthquake("weak", models.EarthquakeProperties{Mag: models.Float64(5), Place: "Honshu, Japan", Tsunami: 1, Sig: 100}),
+				testEarthquake("far", models.EarthquakeProperties{Mag: models.Float64(5), Place: "Kuril Islands", Tsunami: 1, Sig: 500}),
+			},
+		})
+	}))
+	defer server.Close()
+
+	spec, err := LoadQuerySpec("testdata/query.yaml")
+	if err != nil {
+		t.Fatalf("LoadQuerySpec() error = %v", err)
+	}
+
+	collector := NewEarthquakeCollector(api.NewUSGSClient(server.URL, 5*time.Second), nil)
+	collector.SetProgressOutput(io.Discard)
+	if err := spec.Configure(collector); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	earthquakes, err := collector.CollectQueryData(context.Background(), spec, 1000)
+	if err != nil {
+		t.Fatalf("CollectQueryData() error = %v", err)
+	}
+	assertIDs(t, earthquakes.Features, "near")
+
+	for key, value := range map[string]string{"minmagnitude": "4.5", "maxdepth": "70", "orderby": "magnitude", "catalog": "us"} {
+		if got := strings.Join(query[key], ","); got != value {
+			t.Errorf("Query parameter %s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package collector
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// summaryTopPlaces is the number of strongest earthquakes listed in a summary
+const summaryTopPlaces = 3
+
+// TotalSeismicEnergy returns the seismic energy released by the earthquakes in joules, summed
+// from each magnitude with the Gutenberg-Richter relation log10(E) = 1.5M + 4.8. Earthquakes
+// without a magnitude are skipped.
+func TotalSeismicEnergy(earthquakes []models.Earthquake) float64 {
+	var total float64
+	for _, eq := range earthquakes {
+		if mag, ok := eq.Properties.Magnitude(); ok {
+			total += math.Pow(10, 1.5*mag+4.8)
+		}
+	}
+	return total
+}
+
+// energyMagnitude returns the magnitude of a single earthquake releasing energy joules
+func energyMagnitude(energy float64) float64 {
+	return (math.Log10(energy) - 4.8) / 1.5
+}
+
+// PlaceMagnitude pairs an earthquake location with its magnitude
+type PlaceMagnitude struct {
+	Place     string  `json:"place"`
+	Magnitude float64 `json:"magnitude"`
+}
+
+// Summary holds aggregate statistics for a set of earthquakes
+type Summary struct {
+	Count         int              `json:"count"`
+	MinMagnitude  float64          `json:"min_magnitude"`
+	MeanMagnitude float64          `json:"mean_magnitude"`
+	MaxMagnitude  float64          `json:"max_magnitude"`
+	Earliest      time.Time        `json:"earliest"`
+	Latest        time.Time        `json:"latest"`
+	TsunamiCount  int              `json:"tsunami_count"`
+	TopPlaces     []PlaceMagnitude `json:"top_places"`
+	EnergyJoules  float64          `json:"energy_joules"` // see TotalSeismicEnergy
+}
+
+// Summarize computes aggregate statistics for the given earthquakes. The magnitude statistics
+// and top places only cover earthquakes with a magnitude.
+func Summarize(earthquakes []models.Earthquake) Summary {
+	summary := Summary{Count: len(earthquakes)}
+	if len(earthquakes) == 0 {
+		return summary
+	}
+
+	var totalMag float64
+	places := make([]PlaceMagnitude, 0, len(earthquakes))
+
+	for i, eq := range earthquakes {
+		eventTime := time.UnixMilli(eq.Properties.Time)
+		if mag, ok := eq.Properties.Magnitude(); ok {
+			if len(places) == 0 || mag < summary.MinMagnitude {
+				summary.MinMagnitude = mag
+			}
+			if len(places) == 0 || mag > summary.MaxMagnitude {
+				summary.MaxMagnitude = mag
+			}
+			totalMag += mag
+			places = append(places, PlaceMagnitude{Place: eq.Properties.Place, Magnitude: mag})
+		}
+
+		if i == 0 || eventTime.Before(summary.Earliest) {
+			summary.Earliest = eventTime
+		}
+		if i == 0 || eventTime.After(summary.Latest) {
+			summary.Latest = eventTime
+		}
+		if eq.Properties.Tsunami != 0 {
+			summary.TsunamiCount++
+		}
+	}
+
+	if len(places) > 0 {
+		summary.MeanMagnitude = totalMag / float64(len(places))
+	}
+	summary.EnergyJoules = TotalSeismicEnergy(earthquakes)
+
+	sort.SliceStable(places, func(i, j int) bool {
+		return places[i].Magnitude > places[j].Magnitude
+	})
+	if len(places) > summaryTopPlaces {
+		places = places[:summaryTopPlaces]
+	}
+	summary.TopPlaces = places
+
+	return summary
+}
+
+// Write renders the summary as human-readable text
+func (s Summary) Write(w io.Writer) {
+	fmt.Fprintf(w, "Summary:\n")
+	fmt.Fprintf(w, "  Count: %d\n", s.Count)
+	if s.Count == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "  Magnitude: min %.1f, mean %.2f, max %.1f\n", s.MinMagnitude, s.MeanMagnitude, s.MaxMagnitude)
+	fmt.Fprintf(w, "  Time span: %s to %s (%s)\n",
+		s.Earliest.UTC().Format("2006-01-02 15:04:05"),
+		s.Latest.UTC().Format("2006-01-02 15:04:05"),
+		s.Latest.Sub(s.Earliest).Round(time.Second))
+	if s.EnergyJoules > 0 {
+		fmt.Fprintf(w, "  Seismic energy: %.3g J (as much as one M%.1f)\n", s.EnergyJoules, energyMagnitude(s.EnergyJoules))
+	}
+	fmt.Fprintf(w, "  Tsunami-flagged: %d\n", s.TsunamiCount)
+	fmt.Fprintf(w, "  Strongest:\n")
+	for i, place := range s.TopPlaces {
+		fmt.Fprintf(w, "    %d. M%.1f %s\n", i+1, place.Magnitude, place.Place)
+	}
+}
@@ -0,0 +1,102 @@
+package collector
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+func TestSummarize(t *testing.T) {
+	base := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	earthquakes := []models.Earthquake{
+		testEarthquake("a", models.EarthquakeProperties{Mag: models.Float64(2.0), Place: "A", Time: base.UnixMilli()}),
+		testEarthquake("b", models.EarthquakeProperties{Mag: models.Float64(6.5), Place: "B", Time: base.Add(2 * time.Hour).UnixMilli(), Tsunami: 1}),
+		testEarthquake("c", models.EarthquakeProperties{Mag: models.Float64(4.0), Place: "C", Time: base.Add(time.Hour).UnixMilli()}),
+		testEarthquake("d", models.EarthquakeProperties{Mag: models.Float64(3.5), Place: "D", Time: base.Add(-time.Hour).UnixMilli()}),
+	}
+
+	summary := Summarize(earthquakes)
+
+	if summary.Count != 4 || summary.TsunamiCount != 1 {
+		t.Errorf("Count = %d, TsunamiCount = %d, want 4 and 1", summary.Count, summary.TsunamiCount)
+	}
+	if summary.MinMagnitude != 2.0 || summary.MaxMagnitude != 6.5 || summary.MeanMagnitude != 4.0 {
+		t.Errorf("Magnitudes = %.1f/%.2f/%.1f, want 2.0/4.00/6.5", summary.MinMagnitude, summary.MeanMagnitude, summary.MaxMagnitude)
+	}
+	if !summary.Earliest.Equal(base.Add(-time.Hour)) || !summary.Latest.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("Time span = %v to %v", summary.Earliest, summary.Latest)
+	}
+
+	want := []string{"B", "C", "D"}
+	if len(summary.TopPlaces) != len(want) {
+		t.Fatalf("TopPlaces = %v, want %v", summary.TopPlaces, want)
+	}
+	for i, place := range summary.TopPlaces {
+		if place.Place != want[i] {
+			t.Errorf("TopPlaces[%d] = %s, want %s", i, place.Place, want[i])
+		}
+	}
+
+	if empty := Summarize(nil); empty.Count != 0 || len(empty.TopPlaces) != 0 {
+		t.Errorf("Expected empty summary, got %+v", empty)
+	}
+}
+
+func TestTotalSeismicEnergy(t *testing.T) {
+	quake := func(mag *float64) models.Earthquake {
+		return testEarthquake("", models.EarthquakeProperties{Mag: mag})
+	}
+
+	tests := []struct {
+		name        string
+		earthquakes []models.Earthquake
+		want        float64
+	}{
+		{"none", nil, 0},
+		{"M0", []models.Earthquake{quake(models.Float64(0))}, math.Pow(10, 4.8)},
+		{"M6", []models.Earthquake{quake(models.Float64(6))}, math.Pow(10, 13.8)},
+		{"two M5", []models.Earthquake{quake(models.Float64(5)), quake(models.Float64(5))}, 2 * math.Pow(10, 12.3)},
+		{"unknown magnitude skipped", []models.Earthquake{quake(nil), quake(models.Float64(4)), quake(nil)}, math.Pow(10, 10.8)},
+		{"only unknown magnitudes", []models.Earthquake{quake(nil)}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TotalSeismicEnergy(tt.earthquakes)
+			if math.Abs(got-tt.want) > 1e-9*tt.want {
+				t.Errorf("TotalSeismicEnergy() = %g, want %g", got, tt.want)
+			}
+		})
+	}
+
+	// One magnitude step is about 31.6 times the energy, so a single M6 outweighs thirty M5s
+	m6 := TotalSeismicEnergy([]models.Earthquake{quake(models.Float64(6))})
+	var m5s []models.Earthquake
+	for i := 0; i < 30; i++ {
+		m5s = append(m5s, quake(models.Float64(5)))
+	}
+	if ratio := m6 / TotalSeismicEnergy(m5s[:1]); math.Abs(ratio-math.Pow(10, 1.5)) > 1e-9 {
+		t.Errorf("Energy ratio of M6 to M5 = %g, want 10^1.5", ratio)
+	}
+	if TotalSeismicEnergy(m5s) >= m6 {
+		t.Error("Expected one M6 to release more energy than thirty M5s")
+	}
+}
+
+func TestSummary_WriteEnergy(t *testing.T) {
+	summary := Summarize([]models.Earthquake{
+		testEarthquake("a", models.EarthquakeProperties{Mag: models.Float64(6)}),
+		testEarthquake("b", models.EarthquakeProperties{}),
+	})
+	if math.Abs(summary.EnergyJoules-math.Pow(10, 13.8)) > 1 {
+		t.Errorf("EnergyJoules = %g, want 10^13.8", summary.EnergyJoules)
+	}
+
+	var out strings.Builder
+	summary.Write(&out)
+	if !strings.Contains(out.String(), "Seismic energy: 6.31e+13 J (as much as one M6.0)") {
+		t.Errorf("Expected the energy line in the summary, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+const (
+	// watchWindow is how far back an earthquake may have occurred to still count as new
+	watchWindow = time.Hour
+
+	// watchRetention is how long seen earthquake IDs are remembered. Events older than
+	// the watch window are never reported, so they no longer need to be tracked.
+	watchRetention = 2 * watchWindow
+)
+
+// Watcher polls for recent earthquakes and reports each earthquake only the first time it is seen
+type Watcher struct {
+	collector *EarthquakeCollector
+	limit     int
+	seen      map[string]time.Time
+	lastPoll  time.Time
+}
+
+// NewWatcher creates a new watcher on top of an earthquake collector
+func NewWatcher(collector *EarthquakeCollector, limit int) *Watcher {
+	return &Watcher{
+		collector: collector,
+		limit:     limit,
+		seen:      make(map[string]time.Time),
+	}
+}
+
+// Poll fetches recent earthquakes and returns the ones that have not been seen before.
+// After the first poll only earthquakes updated since the previous poll are requested.
+func (w *Watcher) Poll(ctx context.Context) ([]models.Earthquake, error) {
+	pollTime := time.Now()
+
+	var earthquakes *models.USGSResponse
+	var err error
+	if w.lastPoll.IsZero() {
+		earthquakes, err = w.collector.CollectRecentData(ctx, w.limit)
+	} else {
+		earthquakes, err = w.collector.CollectUpdatedAfterData(ctx, w.lastPoll, w.limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.lastPoll = pollTime
+
+	cutoff := pollTime.Add(-watchWindow)
+
+	var fresh []models.Earthquake
+	for _, eq := range earthquakes.Features {
+		if _, ok := w.seen[eq.ID]; ok {
+			continue
+		}
+		// Old events that were merely revised have not newly appeared
+		if eq.Properties.GetTime().Before(cutoff) {
+			continue
+		}
+		w.seen[eq.ID] = eq.Properties.GetTime()
+		fresh = append(fresh, eq)
+	}
+
+	w.prune(pollTime.Add(-watchRetention))
+	return fresh, nil
+}
+
+// Run polls at the given interval until the context is cancelled, passing each new earthquake to emit
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, emit func(models.Earthquake) error) error {
+	if interval <= 0 {
+		return fmt.Errorf("watch interval must be positive, got %v", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fresh, err := w.Poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			// A failed poll should not end the watch; try again on the next tick
+			w.collector.printf("Poll failed: %v\n", err)
+		}
+
+		for _, eq := range fresh {
+			if err := emit(eq); err != nil {
+				return fmt.Errorf("failed to emit earthquake %s: %w", eq.ID, err)
+			}
+		}
+		if err == nil {
+			// A restarted watch resumes from the seen cache; failing to write it should not end the watch
+			if err := w.collector.SaveSeenCache(); err != nil {
+				w.collector.printf("Warning: %v\n", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// prune forgets earthquakes that occurred before the cutoff
+func (w *Watcher) prune(cutoff time.Time) {
+	for id, eventTime := range w.seen {
+		if eventTime.Before(cutoff) {
+			delete(w.seen, id)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/api"
+	"quakewatch-scraper/internal/models"
+)
+
+func TestWatcher_PollReturnsOnlyNewEarthquakes(t *testing.T) {
+	now := time.Now().UnixMilli()
+
+	// Each poll returns a growing result set, like a live feed
+	polls := [][]models.Earthquake{
+		{
+			testEarthquake("a", models.EarthquakeProperties{Mag: models.Float64(2.0), Time: now}),
+		},
+		{
+			testEarthquake("a", models.EarthquakeProperties{Mag: models.Float64(2.0), Time: now}),
+			testEarthquake("b", models.EarthquakeProperties{Mag: models.Float64(3.0), Time: now}),
+		},
+		{
+			testEarthquake("a", models.EarthquakeProperties{Mag: models.Float64(2.0), Time: now}),
+			testEarthquake("b", models.EarthquakeProperties{Mag: models.Float64(3.0), Time: now}),
+			testEarthquake("c", models.EarthquakeProperties{Mag: models.Float64(1.0), Time: now}),
+			testEarthquake("d", models.EarthquakeProperties{Mag: models.Float64(4.0), Time: now}),
+			testEarthquake("old", models.EarthquakeProperties{Mag: models.Float64(5.0), Time: now - 3*time.Hour.Milliseconds()}),
+		},
+	}
+
+	call := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only the first poll should query the full recent window
+		updatedAfter := r.URL.Query().Get("updatedafter")
+		if call == 0 && updatedAfter != "" {
+			t.Errorf("Expected first poll without updatedafter, got %q", updatedAfter)
+		}
+		if call > 0 && updatedAfter == "" {
+			t.Errorf("Expected poll %d to set updatedafter", call+1)
+		}
+
+		features := polls[len(polls)-1]
+		if call < len(polls) {
+			features = polls[call]
+		}
+		call++
+
+		json.NewEncoder(w).Encode(models.USGSResponse{
+			Type:     "FeatureCollection",
+			Features: features,
+		})
+	}))
+	defer server.Close()
+
+	collector := NewEarthquakeCollector(api.NewUSGSClient(server.URL, 5*time.Second), nil)
+	collector.SetProgressOutput(io.Discard)
+	collector.AddFilter(func(earthquakes []models.Earthquake) []models.Earthquake {
+		return FilterByMagnitude(earthquakes, 1.5, 10.0)
+	})
+
+	watcher := NewWatcher(collector, 100)
+
+	expected := [][]string{
+		{"a"},
+		{"b"},
+		{"d"},
+		{},
+	}
+
+	for i, want := range expected {
+		fresh, err := watcher.Poll(context.Background())
+		if err != nil {
+			t.Fatalf("Poll %d failed: %v", i+1, err)
+		}
+		assertIDs(t, fresh, want...)
+	}
+}
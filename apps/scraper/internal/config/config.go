@@ -1,9 +1,13 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -11,31 +15,50 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	API        APIConfig        `mapstructure:"api"`
-	Storage    StorageConfig    `mapstructure:"storage"`
-	Logging    LoggingConfig    `mapstructure:"logging"`
-	Collection CollectionConfig `mapstructure:"collection"`
-	Database   DatabaseConfig   `mapstructure:"database"`
-	Interval   IntervalConfig   `mapstructure:"interval"`
+	API           APIConfig           `mapstructure:"api"`
+	Storage       StorageConfig       `mapstructure:"storage"`
+	Logging       LoggingConfig       `mapstructure:"logging"`
+	Collection    CollectionConfig    `mapstructure:"collection"`
+	Database      DatabaseConfig      `mapstructure:"database"`
+	Interval      IntervalConfig      `mapstructure:"interval"`
+	Notifications NotificationsConfig `mapstructure:"notifications"`
 }
 
 // APIConfig contains API-related configuration
 type APIConfig struct {
-	USGS USGSConfig `mapstructure:"usgs"`
-	EMSC EMSCConfig `mapstructure:"emsc"`
+	USGS      USGSConfig `mapstructure:"usgs"`
+	EMSC      EMSCConfig `mapstructure:"emsc"`
+	UserAgent string     `mapstructure:"user_agent"`
 }
 
 // USGSConfig contains USGS API configuration
 type USGSConfig struct {
 	BaseURL   string        `mapstructure:"base_url"`
+	BaseURLs  []string      `mapstructure:"base_urls"` // tried in order; replaces base_url when set
 	Timeout   time.Duration `mapstructure:"timeout"`
 	RateLimit int           `mapstructure:"rate_limit"`
 }
 
+// URLs returns the USGS endpoints to query in order of preference: base_urls if set, otherwise
+// base_url alone
+func (c USGSConfig) URLs() []string {
+	urls := make([]string, 0, len(c.BaseURLs))
+	for _, u := range c.BaseURLs {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		urls = append(urls, c.BaseURL)
+	}
+	return urls
+}
+
 // EMSCConfig contains EMSC API configuration
 type EMSCConfig struct {
-	BaseURL string        `mapstructure:"base_url"`
-	Timeout time.Duration `mapstructure:"timeout"`
+	BaseURL   string        `mapstructure:"base_url"`
+	EventsURL string        `mapstructure:"events_url"`
+	Timeout   time.Duration `mapstructure:"timeout"`
 }
 
 // StorageConfig contains storage-related configuration
@@ -43,28 +66,46 @@ type StorageConfig struct {
 	OutputDir      string `mapstructure:"output_dir"`
 	EarthquakesDir string `mapstructure:"earthquakes_dir"`
 	FaultsDir      string `mapstructure:"faults_dir"`
+	Layout         string `mapstructure:"layout"`
 }
 
-// LoggingConfig contains logging configuration
+// LoggingConfig contains logging configuration.
+// AuditLog is a file each CLI invocation is appended to as a JSON line; empty disables auditing.
 type LoggingConfig struct {
-	Level  string `mapstructure:"level"`
-	Format string `mapstructure:"format"`
-	Output string `mapstructure:"output"`
+	Level    string `mapstructure:"level"`
+	Format   string `mapstructure:"format"`
+	Output   string `mapstructure:"output"`
+	AuditLog string `mapstructure:"audit_log"`
 }
 
 // CollectionConfig contains data collection configuration
 type CollectionConfig struct {
-	DefaultLimit  int           `mapstructure:"default_limit"`
-	MaxLimit      int           `mapstructure:"max_limit"`
-	RetryAttempts int           `mapstructure:"retry_attempts"`
-	RetryDelay    time.Duration `mapstructure:"retry_delay"`
+	DefaultLimit   int           `mapstructure:"default_limit"`
+	MaxLimit       int           `mapstructure:"max_limit"`
+	RetryAttempts  int           `mapstructure:"retry_attempts"`
+	RetryDelay     time.Duration `mapstructure:"retry_delay"`
+	MaxRetryDelay  time.Duration `mapstructure:"max_retry_delay"`
+	Timeout        time.Duration `mapstructure:"timeout"`
+	MaxConcurrency int           `mapstructure:"max_concurrency"`
+}
+
+// NotificationsConfig contains webhook notification configuration.
+// Notifications are disabled unless a webhook URL is set.
+type NotificationsConfig struct {
+	WebhookURL   string        `mapstructure:"webhook_url"`
+	MinMagnitude float64       `mapstructure:"min_magnitude"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	SentCache    string        `mapstructure:"sent_cache"` // file of notified earthquake IDs; defaults to .notifications_sent in the output directory
+	DedupeTTL    time.Duration `mapstructure:"dedupe_ttl"` // how long a notified earthquake is not notified again
 }
 
 // IntervalConfig contains interval scraping configuration
 type IntervalConfig struct {
 	DefaultInterval     time.Duration `mapstructure:"default_interval"`
+	Jitter              time.Duration `mapstructure:"jitter"` // random delay of up to this much before each execution
 	MaxRuntime          time.Duration `mapstructure:"max_runtime"`
 	MaxExecutions       int           `mapstructure:"max_executions"`
+	MaxTotalRetries     int           `mapstructure:"max_total_retries"`
 	BackoffStrategy     string        `mapstructure:"backoff_strategy"`
 	MaxBackoff          time.Duration `mapstructure:"max_backoff"`
 	ContinueOnError     bool          `mapstructure:"continue_on_error"`
@@ -73,6 +114,7 @@ type IntervalConfig struct {
 	DaemonMode          bool          `mapstructure:"daemon_mode"`
 	PIDFile             string        `mapstructure:"pid_file"`
 	LogFile             string        `mapstructure:"log_file"`
+	StatusFile          string        `mapstructure:"status_file"`
 }
 
 // DefaultConfig returns the default configuration
@@ -85,14 +127,16 @@ func DefaultConfig() *Config {
 				RateLimit: 60,
 			},
 			EMSC: EMSCConfig{
-				BaseURL: "https://www.emsc-csem.org/javascript",
-				Timeout: 30 * time.Second,
+				BaseURL:   "https://www.emsc-csem.org/javascript",
+				EventsURL: "https://www.seismicportal.eu/fdsnws/event/1",
+				Timeout:   30 * time.Second,
 			},
 		},
 		Storage: StorageConfig{
 			OutputDir:      "./data",
 			EarthquakesDir: "earthquakes",
 			FaultsDir:      "faults",
+			Layout:         "flat",
 		},
 		Logging: LoggingConfig{
 			Level:  "info",
@@ -100,10 +144,13 @@ func DefaultConfig() *Config {
 			Output: "stdout",
 		},
 		Collection: CollectionConfig{
-			DefaultLimit:  1000,
-			MaxLimit:      10000,
-			RetryAttempts: 3,
-			RetryDelay:    5 * time.Second,
+			DefaultLimit:   1000,
+			MaxLimit:       10000,
+			RetryAttempts:  3,
+			RetryDelay:     5 * time.Second,
+			MaxRetryDelay:  time.Minute,
+			Timeout:        10 * time.Minute,
+			MaxConcurrency: 4,
 		},
 		Database: DatabaseConfig{
 			Enabled:           false,
@@ -125,6 +172,7 @@ func DefaultConfig() *Config {
 			DefaultInterval:     1 * time.Hour,
 			MaxRuntime:          24 * time.Hour,
 			MaxExecutions:       1000,
+			MaxTotalRetries:     0,
 			BackoffStrategy:     "exponential",
 			MaxBackoff:          30 * time.Minute,
 			ContinueOnError:     true,
@@ -133,6 +181,13 @@ func DefaultConfig() *Config {
 			DaemonMode:          false,
 			PIDFile:             "/var/run/quakewatch-scraper.pid",
 			LogFile:             "/var/log/quakewatch-scraper.log",
+			StatusFile:          "/var/run/quakewatch-scraper.status.json",
+		},
+		Notifications: NotificationsConfig{
+			WebhookURL:   "",
+			MinMagnitude: 5.0,
+			Timeout:      10 * time.Second,
+			DedupeTTL:    24 * time.Hour,
 		},
 	}
 }
@@ -150,8 +205,12 @@ func LoadConfig(configPath string) (*Config, error) {
 		viper.AddConfigPath(".")
 	}
 
-	// Try to read the config file
-	if err := viper.ReadInConfig(); err != nil {
+	// Try to read the config file, retrying while it exists but cannot be read
+	retries, delay, err := configReadRetry()
+	if err != nil {
+		return nil, err
+	}
+	if err := readConfigWithRetry(readConfig, retries, delay, time.Sleep); err != nil {
 		// Check if it's a config file not found error
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			// Config file not found, check if user wants to create one
@@ -171,7 +230,88 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	return &config, nil
+	// Let environment variables override the file, so secrets need not be committed
+	return config.ApplyEnv()
+}
+
+// Environment variables controlling how LoadConfig retries a config file that exists but cannot be
+// read, e.g. on a network mount that is briefly unavailable at boot
+const (
+	ConfigReadRetriesEnv    = "QUAKEWATCH_CONFIG_READ_RETRIES"     // retries after the first attempt; 0 disables retrying
+	ConfigReadRetryDelayEnv = "QUAKEWATCH_CONFIG_READ_RETRY_DELAY" // delay before the first retry; it doubles after each retry
+)
+
+// Defaults for ConfigReadRetriesEnv and ConfigReadRetryDelayEnv
+const (
+	defaultConfigReadRetries    = 3
+	defaultConfigReadRetryDelay = 500 * time.Millisecond
+)
+
+// readConfig reads the config file; tests replace it to simulate unreadable files
+var readConfig = viper.ReadInConfig
+
+// configReadRetry returns the retries and initial delay for reading the config file
+func configReadRetry() (int, time.Duration, error) {
+	retries := defaultConfigReadRetries
+	if value := os.Getenv(ConfigReadRetriesEnv); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid %s: %q (must be a non-negative integer)", ConfigReadRetriesEnv, value)
+		}
+		retries = n
+	}
+
+	delay := defaultConfigReadRetryDelay
+	if value := os.Getenv(ConfigReadRetryDelayEnv); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return 0, 0, fmt.Errorf("invalid %s: %q (must be a non-negative duration)", ConfigReadRetryDelayEnv, value)
+		}
+		delay = d
+	}
+	return retries, delay, nil
+}
+
+// readConfigWithRetry calls read until it succeeds, the file is found not to exist or not to be
+// valid YAML, or it has been retried retries times, sleeping between attempts
+func readConfigWithRetry(read func() error, retries int, delay time.Duration, sleep func(time.Duration)) error {
+	for attempt := 0; ; attempt++ {
+		err := read()
+		if err == nil || attempt == retries || isConfigNotFound(err) || isConfigParseError(err) {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Config file unreadable: %v (retrying in %v)\n", err, delay)
+		sleep(delay)
+		delay *= 2
+	}
+}
+
+// isConfigNotFound reports whether reading the config file failed because there is no such file,
+// which retrying will not change
+func isConfigNotFound(err error) bool {
+	var notFound viper.ConfigFileNotFoundError
+	return errors.As(err, &notFound) || errors.Is(err, fs.ErrNotExist)
+}
+
+// isConfigParseError reports whether the config file was read but is not valid, which retrying cannot fix
+func isConfigParseError(err error) bool {
+	var parseErr viper.ConfigParseError
+	return errors.As(err, &parseErr)
+}
+
+// ReloadConfig re-reads the configuration file of a long-running process, by default the file
+// LoadConfig last read. Unlike LoadConfig it never prompts: a missing file is an error.
+func ReloadConfig(configPath string) (*Config, error) {
+	if configPath == "" {
+		configPath = viper.ConfigFileUsed()
+	}
+	if configPath == "" {
+		return nil, fmt.Errorf("no configuration file to reload")
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return LoadConfig(configPath)
 }
 
 // handleMissingConfig handles the case when no config file is found
@@ -190,7 +330,7 @@ func handleMissingConfig(configPath string) (*Config, error) {
 
 	// User chose not to create config, use defaults
 	fmt.Println("Using default configuration.")
-	return DefaultConfig(), nil
+	return DefaultConfig().ApplyEnv()
 }
 
 // createInteractiveConfig creates a configuration file through user interaction
@@ -410,23 +550,32 @@ func SaveConfig(config *Config, configPath string) error {
 
 	// Set the configuration values
 	viper.Set("api.usgs.base_url", config.API.USGS.BaseURL)
+	if len(config.API.USGS.BaseURLs) > 0 {
+		viper.Set("api.usgs.base_urls", config.API.USGS.BaseURLs)
+	}
 	viper.Set("api.usgs.timeout", config.API.USGS.Timeout)
 	viper.Set("api.usgs.rate_limit", config.API.USGS.RateLimit)
 	viper.Set("api.emsc.base_url", config.API.EMSC.BaseURL)
+	viper.Set("api.emsc.events_url", config.API.EMSC.EventsURL)
 	viper.Set("api.emsc.timeout", config.API.EMSC.Timeout)
+	viper.Set("api.user_agent", config.API.UserAgent)
 
 	viper.Set("storage.output_dir", config.Storage.OutputDir)
 	viper.Set("storage.earthquakes_dir", config.Storage.EarthquakesDir)
 	viper.Set("storage.faults_dir", config.Storage.FaultsDir)
+	viper.Set("storage.layout", config.Storage.Layout)
 
 	viper.Set("logging.level", config.Logging.Level)
 	viper.Set("logging.format", config.Logging.Format)
 	viper.Set("logging.output", config.Logging.Output)
+	viper.Set("logging.audit_log", config.Logging.AuditLog)
 
 	viper.Set("collection.default_limit", config.Collection.DefaultLimit)
 	viper.Set("collection.max_limit", config.Collection.MaxLimit)
 	viper.Set("collection.retry_attempts", config.Collection.RetryAttempts)
 	viper.Set("collection.retry_delay", config.Collection.RetryDelay)
+	viper.Set("collection.max_retry_delay", config.Collection.MaxRetryDelay)
+	viper.Set("collection.timeout", config.Collection.Timeout)
 
 	viper.Set("database.enabled", config.Database.Enabled)
 	viper.Set("database.type", config.Database.Type)
@@ -438,6 +587,13 @@ func SaveConfig(config *Config, configPath string) error {
 	viper.Set("database.ssl_mode", config.Database.SSLMode)
 	viper.Set("database.max_connections", config.Database.MaxConnections)
 	viper.Set("database.connection_timeout", config.Database.ConnectionTimeout)
+	viper.Set("database.migrations_path", config.Database.MigrationsPath)
+
+	viper.Set("notifications.webhook_url", config.Notifications.WebhookURL)
+	viper.Set("notifications.min_magnitude", config.Notifications.MinMagnitude)
+	viper.Set("notifications.timeout", config.Notifications.Timeout)
+	viper.Set("notifications.sent_cache", config.Notifications.SentCache)
+	viper.Set("notifications.dedupe_ttl", config.Notifications.DedupeTTL)
 
 	// Ensure the directory exists
 	configDir := filepath.Dir(getConfigPath(configPath))
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadConfig_RetriesTransientReadError(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv(ConfigReadRetryDelayEnv, "0s")
+
+	path := writeTestConfig(t)
+	calls := 0
+	readConfig = func() error {
+		calls++
+		if calls == 1 {
+			// A network mount that is not ready yet
+			return &fs.PathError{Op: "open", Path: path, Err: syscall.EIO}
+		}
+		return viper.ReadInConfig()
+	}
+	t.Cleanup(func() { readConfig = viper.ReadInConfig })
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 reads, got %d", calls)
+	}
+	if !cfg.Database.Enabled || cfg.Database.Database != "yaml-db" {
+		t.Errorf("Database = %+v, want the values from the file", cfg.Database)
+	}
+}
+
+func TestLoadConfig_NoRetriesWhenDisabled(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv(ConfigReadRetriesEnv, "0")
+
+	calls := 0
+	readConfig = func() error {
+		calls++
+		return &fs.PathError{Op: "open", Path: "config.yaml", Err: syscall.EIO}
+	}
+	t.Cleanup(func() { readConfig = viper.ReadInConfig })
+
+	if _, err := LoadConfig(writeTestConfig(t)); !errors.Is(err, syscall.EIO) {
+		t.Errorf("LoadConfig() error = %v, want the read error", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected a single read, got %d", calls)
+	}
+}
+
+func TestReadConfigWithRetry(t *testing.T) {
+	transient := &fs.PathError{Op: "open", Path: "config.yaml", Err: syscall.EIO}
+
+	tests := []struct {
+		name       string
+		errs       []error // returned by successive reads; reads past the end succeed
+		wantErr    error
+		wantDelays []time.Duration
+	}{
+		{"first read succeeds", nil, nil, nil},
+		{"transient then success", []error{transient}, nil, []time.Duration{10 * time.Millisecond}},
+		{"retries exhausted", []error{transient, transient, transient, transient}, transient, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}},
+		{"file does not exist", []error{&fs.PathError{Op: "open", Path: "config.yaml", Err: fs.ErrNotExist}}, fs.ErrNotExist, nil},
+		{"no config file in search paths", []error{viper.ConfigFileNotFoundError{}}, viper.ConfigFileNotFoundError{}, nil},
+		{"invalid YAML", []error{viper.ConfigParseError{}}, viper.ConfigParseError{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			read := func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			}
+			var delays []time.Duration
+			sleep := func(d time.Duration) { delays = append(delays, d) }
+
+			err := readConfigWithRetry(read, 2, 10*time.Millisecond, sleep)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("readConfigWithRetry() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("readConfigWithRetry() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(delays) != len(tt.wantDelays) {
+				t.Fatalf("Delays = %v, want %v", delays, tt.wantDelays)
+			}
+			for i := range delays {
+				if delays[i] != tt.wantDelays[i] {
+					t.Errorf("Delay %d = %v, want %v", i+1, delays[i], tt.wantDelays[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConfigReadRetry(t *testing.T) {
+	t.Setenv(ConfigReadRetriesEnv, "")
+	t.Setenv(ConfigReadRetryDelayEnv, "")
+	if retries, delay, err := configReadRetry(); err != nil || retries != defaultConfigReadRetries || delay != defaultConfigReadRetryDelay {
+		t.Errorf("configReadRetry() = %d, %v, %v, want the defaults", retries, delay, err)
+	}
+
+	t.Setenv(ConfigReadRetriesEnv, "5")
+	t.Setenv(ConfigReadRetryDelayEnv, "2s")
+	if retries, delay, err := configReadRetry(); err != nil || retries != 5 || delay != 2*time.Second {
+		t.Errorf("configReadRetry() = %d, %v, %v, want 5 and 2s", retries, delay, err)
+	}
+
+	for env, value := range map[string]string{ConfigReadRetriesEnv: "-1", ConfigReadRetryDelayEnv: "soon"} {
+		t.Setenv(ConfigReadRetriesEnv, "")
+		t.Setenv(ConfigReadRetryDelayEnv, "")
+		t.Setenv(env, value)
+		if _, _, err := configReadRetry(); err == nil {
+			t.Errorf("Expected an error for %s=%s", env, value)
+		}
+	}
+}
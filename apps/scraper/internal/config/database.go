@@ -5,6 +5,8 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"github.com/spf13/viper"
 )
 
 // DatabaseConfig holds database configuration
@@ -23,6 +25,32 @@ type DatabaseConfig struct {
 	ConnMaxIdleTime   time.Duration `mapstructure:"conn_max_idle_time"`
 	MaxConnections    int           `mapstructure:"max_connections"`
 	ConnectionTimeout time.Duration `mapstructure:"connection_timeout"`
+	MigrationsPath    string        `mapstructure:"migrations_path"`
+}
+
+// databaseEnvBindings maps database config keys to the environment variables read by NewDatabaseConfig
+var databaseEnvBindings = map[string]string{
+	"database.host":               "DB_HOST",
+	"database.port":               "DB_PORT",
+	"database.username":           "DB_USER",
+	"database.password":           "DB_PASSWORD",
+	"database.database":           "DB_NAME",
+	"database.ssl_mode":           "DB_SSL_MODE",
+	"database.max_open_conns":     "DB_MAX_OPEN_CONNS",
+	"database.max_idle_conns":     "DB_MAX_IDLE_CONNS",
+	"database.conn_max_lifetime":  "DB_CONN_MAX_LIFETIME",
+	"database.conn_max_idle_time": "DB_CONN_MAX_IDLE_TIME",
+	"database.migrations_path":    "DB_MIGRATIONS_PATH",
+}
+
+// bindDatabaseEnv makes the DB_* environment variables override the database values read by v
+func bindDatabaseEnv(v *viper.Viper) error {
+	for key, env := range databaseEnvBindings {
+		if err := v.BindEnv(key, env); err != nil {
+			return fmt.Errorf("failed to bind %s to %s: %w", env, key, err)
+		}
+	}
+	return nil
 }
 
 // NewDatabaseConfig creates a new database configuration from environment variables
@@ -44,6 +72,7 @@ func NewDatabaseConfig() *DatabaseConfig {
 		MaxIdleConns:    maxIdleConns,
 		ConnMaxLifetime: connMaxLifetime,
 		ConnMaxIdleTime: connMaxIdleTime,
+		MigrationsPath:  getEnv("DB_MIGRATIONS_PATH", ""),
 	}
 }
 
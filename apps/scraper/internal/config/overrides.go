@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ApplyOverrides returns a copy of the configuration with key=value overrides applied. Keys are
+// dotted paths matching the config file (e.g. collection.max_limit) and values are parsed into
+// the field's type the same way values from the config file are.
+func (c *Config) ApplyOverrides(overrides []string) (*Config, error) {
+	if len(overrides) == 0 {
+		return c, nil
+	}
+
+	settings := c.Settings()
+	keys := make(map[string]bool)
+	collectKeys(settings, "", keys)
+
+	v := viper.New()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, fmt.Errorf("failed to load configuration for overrides: %w", err)
+	}
+
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid override %q: expected key=value", override)
+		}
+		if !keys[key] {
+			return nil, fmt.Errorf("unknown configuration key %q (see 'config show' for valid keys)", key)
+		}
+		v.Set(key, value)
+	}
+
+	var overridden Config
+	if err := v.Unmarshal(&overridden); err != nil {
+		return nil, fmt.Errorf("failed to apply overrides: %w", err)
+	}
+
+	return &overridden, nil
+}
+
+// EnvPrefix prefixes the environment variables that override configuration keys, e.g.
+// QUAKEWATCH_STORAGE_OUTPUT_DIR for storage.output_dir
+const EnvPrefix = "QUAKEWATCH"
+
+// ApplyEnv returns a copy of the configuration with environment overrides applied: the DB_*
+// variables for the database settings and EnvPrefix variables for any key. LoadConfig applies
+// them over the config file; apply them to the defaults when there is no file.
+func (c *Config) ApplyEnv() (*Config, error) {
+	v := viper.New()
+	if err := v.MergeConfigMap(c.Settings()); err != nil {
+		return nil, fmt.Errorf("failed to load configuration for environment overrides: %w", err)
+	}
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	if err := bindDatabaseEnv(v); err != nil {
+		return nil, err
+	}
+
+	var overridden Config
+	if err := v.Unmarshal(&overridden); err != nil {
+		return nil, fmt.Errorf("failed to apply environment overrides: %w", err)
+	}
+
+	return &overridden, nil
+}
+
+// collectKeys records the dotted path of every leaf setting
+func collectKeys(settings map[string]interface{}, prefix string, keys map[string]bool) {
+	for key, value := range settings {
+		if nested, ok := value.(map[string]interface{}); ok {
+			collectKeys(nested, prefix+key+".", keys)
+			continue
+		}
+		keys[prefix+key] = true
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig_ApplyOverrides(t *testing.T) {
+	base := DefaultConfig()
+
+	cfg, err := base.ApplyOverrides([]string{
+		"collection.max_limit=50000",
+		"api.usgs.timeout=60s",
+		"interval.continue_on_error=false",
+		"notifications.min_magnitude=6.5",
+		"api.usgs.base_url=http://localhost:8080/fdsnws/event/1?x=y",
+	})
+	if err != nil {
+		t.Fatalf("ApplyOverrides failed: %v", err)
+	}
+
+	if cfg.Collection.MaxLimit != 50000 {
+		t.Errorf("Expected max_limit 50000, got %d", cfg.Collection.MaxLimit)
+	}
+	if cfg.API.USGS.Timeout != 60*time.Second {
+		t.Errorf("Expected USGS timeout 60s, got %v", cfg.API.USGS.Timeout)
+	}
+	if cfg.Interval.ContinueOnError {
+		t.Error("Expected continue_on_error to be false")
+	}
+	if cfg.Notifications.MinMagnitude != 6.5 {
+		t.Errorf("Expected min_magnitude 6.5, got %v", cfg.Notifications.MinMagnitude)
+	}
+	if cfg.API.USGS.BaseURL != "http://localhost:8080/fdsnws/event/1?x=y" {
+		t.Errorf("Expected value to be split on the first '=', got %q", cfg.API.USGS.BaseURL)
+	}
+
+	// Untouched settings are preserved and the original is not modified
+	if cfg.Collection.DefaultLimit != base.Collection.DefaultLimit || cfg.Interval.MaxBackoff != base.Interval.MaxBackoff {
+		t.Errorf("Expected other settings to be preserved, got %+v", cfg.Collection)
+	}
+	if base.Collection.MaxLimit == 50000 {
+		t.Error("Expected the original configuration to be unchanged")
+	}
+}
+
+func TestConfig_ApplyOverridesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		wantErr  string
+	}{
+		{name: "Unknown key", override: "collection.max_limt=10", wantErr: "unknown configuration key"},
+		{name: "Section instead of field", override: "collection=10", wantErr: "unknown configuration key"},
+		{name: "Missing value separator", override: "collection.max_limit", wantErr: "expected key=value"},
+		{name: "Wrong type", override: "collection.max_limit=lots", wantErr: "failed to apply overrides"},
+		{name: "Bad duration", override: "api.usgs.timeout=soon", wantErr: "failed to apply overrides"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DefaultConfig().ApplyOverrides([]string{tt.override})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUSGSConfig_URLs(t *testing.T) {
+	base := DefaultConfig()
+	if urls := base.API.USGS.URLs(); len(urls) != 1 || urls[0] != base.API.USGS.BaseURL {
+		t.Errorf("URLs() = %v, want only base_url", urls)
+	}
+
+	cfg, err := base.ApplyOverrides([]string{"api.usgs.base_urls=http://primary, http://mirror"})
+	if err != nil {
+		t.Fatalf("ApplyOverrides failed: %v", err)
+	}
+	urls := cfg.API.USGS.URLs()
+	if strings.Join(urls, " ") != "http://primary http://mirror" {
+		t.Errorf("URLs() = %v, want base_urls in order", urls)
+	}
+}
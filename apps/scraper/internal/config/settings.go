@@ -0,0 +1,100 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// redactedValue replaces secrets in printed configuration
+const redactedValue = "********"
+
+// secretKeys are the settings that hold secrets, matched against the last segment of a key
+var secretKeys = []string{"password", "webhook_url", "token", "api_key", "apikey", "secret"}
+
+// IsSecretKey reports whether a setting such as database.password or notifications.webhook_url
+// holds a secret that must not be printed or logged
+func IsSecretKey(key string) bool {
+	name := strings.ToLower(key)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	for _, secret := range secretKeys {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactURL masks the credentials of a URL such as a database DSN; other values are returned unchanged
+func RedactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil || u.Host == "" {
+		return value
+	}
+	// url.User would percent-encode the mask, so it is inserted after the scheme instead
+	u.User = nil
+	return strings.Replace(u.String(), "//", "//"+redactedValue+"@", 1)
+}
+
+// Redacted returns a copy of the configuration with secrets and URL credentials masked
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redactStruct(reflect.ValueOf(&redacted).Elem())
+	return &redacted
+}
+
+// redactStruct masks the secret settings of a config struct in place
+func redactStruct(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		value := v.Field(i)
+		if !value.CanSet() {
+			continue
+		}
+
+		key := strings.Split(t.Field(i).Tag.Get("mapstructure"), ",")[0]
+		switch {
+		case value.Kind() == reflect.Struct:
+			redactStruct(value)
+		case value.Kind() == reflect.String && value.String() != "" && IsSecretKey(key):
+			value.SetString(redactedValue)
+		case value.Kind() == reflect.String:
+			value.SetString(RedactURL(value.String()))
+		}
+	}
+}
+
+// Settings returns the configuration as nested maps keyed like the config file
+func (c *Config) Settings() map[string]interface{} {
+	return structSettings(reflect.ValueOf(*c))
+}
+
+// structSettings converts a config struct into a map using its mapstructure tags
+func structSettings(v reflect.Value) map[string]interface{} {
+	settings := make(map[string]interface{}, v.NumField())
+	t := v.Type()
+
+	for i := 0; i < v.NumField(); i++ {
+		field := t.Field(i)
+		key := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+
+		value := v.Field(i)
+		switch {
+		case value.Type() == reflect.TypeOf(time.Duration(0)):
+			// Render durations the way they are written in the config file
+			settings[key] = time.Duration(value.Int()).String()
+		case value.Kind() == reflect.Struct:
+			settings[key] = structSettings(value)
+		default:
+			settings[key] = value.Interface()
+		}
+	}
+
+	return settings
+}
@@ -2,14 +2,38 @@ package models
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
+// magTypeFamilies lists the USGS magnitude type variants measured on each magnitude scale,
+// so e.g. Mww and Mwc compare as moment magnitudes
+var magTypeFamilies = map[string][]string{
+	"mw":    {"mww", "mwc", "mwb", "mwr", "mwp", "mi"},
+	"ms":    {"ms_20", "ms_vx"},
+	"mb_lg": {"mblg", "lg"},
+	"ml":    {"mlr", "mlv"},
+	"md":    {"mc"},
+}
+
 // USGSResponse represents the top-level response from USGS API
 type USGSResponse struct {
 	Type     string       `json:"type"`
 	Metadata Metadata     `json:"metadata"`
 	Features []Earthquake `json:"features"`
+
+	// CollectionInfo records how a saved collection was obtained. It is a GeoJSON foreign
+	// member, so consumers that do not know it ignore it.
+	CollectionInfo *CollectionInfo `json:"collection_info,omitempty"`
+}
+
+// CollectionInfo describes the request that produced a saved earthquake collection
+type CollectionInfo struct {
+	Query       map[string]string `json:"query,omitempty"`
+	SourceURL   string            `json:"source_url,omitempty"`
+	CollectedAt time.Time         `json:"collected_at"`
+	ToolVersion string            `json:"tool_version"`
 }
 
 // Metadata contains information about the API response
@@ -32,7 +56,7 @@ type Earthquake struct {
 
 // EarthquakeProperties contains the properties of an earthquake
 type EarthquakeProperties struct {
-	Mag     float64  `json:"mag"`
+	Mag     *float64 `json:"mag"` // nil when the source reports no magnitude
 	Place   string   `json:"place"`
 	Time    int64    `json:"time"`
 	Updated int64    `json:"updated"`
@@ -75,15 +99,172 @@ func (e *EarthquakeProperties) GetUpdated() time.Time {
 	return time.Unix(e.Updated/1000, 0)
 }
 
+// Magnitude returns the magnitude and whether the source reported one
+func (e *EarthquakeProperties) Magnitude() (float64, bool) {
+	if e.Mag == nil {
+		return 0, false
+	}
+	return *e.Mag, true
+}
+
 // IsSignificant returns true if the earthquake magnitude is 4.5 or greater
 func (e *EarthquakeProperties) IsSignificant() bool {
-	return e.Mag >= 4.5
+	mag, ok := e.Magnitude()
+	return ok && mag >= 4.5
 }
 
-// GetMagnitude returns the magnitude as a string with type
+// GetMagnitude returns the magnitude as a string with type, or "unknown" if there is none
 func (e *EarthquakeProperties) GetMagnitude() string {
+	mag, ok := e.Magnitude()
+	if !ok {
+		return "unknown"
+	}
 	if e.MagType != "" {
-		return fmt.Sprintf("%.1f %s", e.Mag, e.MagType)
+		return fmt.Sprintf("%.1f %s", mag, e.MagType)
+	}
+	return fmt.Sprintf("%.1f", mag)
+}
+
+// NormalizeMagType returns the magnitude scale a USGS magnitude type belongs to, in lower case.
+// Unknown types are returned lower-cased unchanged.
+func NormalizeMagType(magType string) string {
+	magType = strings.ToLower(strings.TrimSpace(magType))
+	for family, variants := range magTypeFamilies {
+		if slices.Contains(variants, magType) {
+			return family
+		}
+	}
+	return magType
+}
+
+// FlatEarthquake is a single-level view of an earthquake for tabular output.
+// Optional values are zero when USGS does not report them, except the time, update time and
+// magnitude: these are nil, so they render as empty cells rather than 1970-01-01 or magnitude 0.
+type FlatEarthquake struct {
+	ID        string     `json:"id"`
+	Time      *time.Time `json:"time"`
+	Updated   *time.Time `json:"updated"`
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	Depth     float64    `json:"depth"`
+	Magnitude *float64   `json:"mag"`
+	MagType   string     `json:"mag_type"`
+	Place     string     `json:"place"`
+	Alert     string     `json:"alert"`
+	Status    string     `json:"status"`
+	Tsunami   int        `json:"tsunami"`
+	Sig       int        `json:"sig"`
+	Felt      int        `json:"felt"`
+	CDI       float64    `json:"cdi"`
+	MMI       float64    `json:"mmi"`
+	Nst       int        `json:"nst"`
+	Dmin      float64    `json:"dmin"`
+	RMS       float64    `json:"rms"`
+	Gap       float64    `json:"gap"`
+	Net       string     `json:"net"`
+	Code      string     `json:"code"`
+	IDs       string     `json:"ids"`
+	Sources   string     `json:"sources"`
+	Types     string     `json:"types"`
+	EventType string     `json:"type"`
+	Title     string     `json:"title"`
+	URL       string     `json:"url"`
+	Detail    string     `json:"detail"`
+}
+
+// Longitude returns the longitude, or 0 if the geometry has no coordinates
+func (g *Geometry) Longitude() float64 {
+	return g.coordinate(0)
+}
+
+// Latitude returns the latitude, or 0 if the geometry has fewer than two coordinates
+func (g *Geometry) Latitude() float64 {
+	return g.coordinate(1)
+}
+
+// Depth returns the depth in kilometers, or 0 if the geometry has no depth
+func (g *Geometry) Depth() float64 {
+	return g.coordinate(2)
+}
+
+// coordinate returns the coordinate at index i, or 0 if it is missing
+func (g *Geometry) coordinate(i int) float64 {
+	if i < len(g.Coordinates) {
+		return g.Coordinates[i]
+	}
+	return 0
+}
+
+// Flatten returns a flat view of the earthquake with coordinates split out and optional values defaulted
+func (e *Earthquake) Flatten() FlatEarthquake {
+	p := e.Properties
+	return FlatEarthquake{
+		ID:        e.ID,
+		Time:      timeValue(p.Time),
+		Updated:   timeValue(p.Updated),
+		Latitude:  e.Geometry.Latitude(),
+		Longitude: e.Geometry.Longitude(),
+		Depth:     e.Geometry.Depth(),
+		Magnitude: copyFloat(p.Mag),
+		MagType:   p.MagType,
+		Place:     p.Place,
+		Alert:     p.Alert,
+		Status:    p.Status,
+		Tsunami:   p.Tsunami,
+		Sig:       p.Sig,
+		Felt:      intValue(p.Felt),
+		CDI:       floatValue(p.CDI),
+		MMI:       floatValue(p.MMI),
+		Nst:       intValue(p.Nst),
+		Dmin:      floatValue(p.Dmin),
+		RMS:       floatValue(p.RMS),
+		Gap:       floatValue(p.Gap),
+		Net:       p.Net,
+		Code:      p.Code,
+		IDs:       p.IDs,
+		Sources:   p.Sources,
+		Types:     p.Types,
+		EventType: p.Type,
+		Title:     p.Title,
+		URL:       p.URL,
+		Detail:    p.Detail,
+	}
+}
+
+// timeValue converts a USGS time in milliseconds, where 0 means the time is not reported
+func timeValue(millis int64) *time.Time {
+	if millis == 0 {
+		return nil
+	}
+	t := time.UnixMilli(millis).UTC()
+	return &t
+}
+
+// intValue dereferences an optional int, defaulting to 0
+func intValue(v *int) int {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// Float64 returns a pointer to v, for setting optional values such as a magnitude
+func Float64(v float64) *float64 {
+	return &v
+}
+
+// copyFloat copies an optional float so the flat view does not share it with the earthquake
+func copyFloat(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	return Float64(*v)
+}
+
+// floatValue dereferences an optional float, defaulting to 0
+func floatValue(v *float64) float64 {
+	if v == nil {
+		return 0
 	}
-	return fmt.Sprintf("%.1f", e.Mag)
+	return *v
 }
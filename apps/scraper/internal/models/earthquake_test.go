@@ -0,0 +1,138 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEarthquake_Flatten(t *testing.T) {
+	felt := 12
+	rms := 0.35
+	eventTime := time.Date(2024, 1, 15, 8, 30, 0, 0, time.UTC)
+
+	eq := Earthquake{
+		ID: "us7000abcd",
+		Properties: EarthquakeProperties{
+			Mag:     Float64(5.4),
+			MagType: "mww",
+			Place:   "10 km N of Somewhere",
+			Time:    eventTime.UnixMilli(),
+			Felt:    &felt,
+			RMS:     &rms,
+			Type:    "earthquake",
+		},
+		Geometry: Geometry{Type: "Point", Coordinates: []float64{-122.5, 37.8, 8.2}},
+	}
+
+	flat := eq.Flatten()
+	if flat.ID != "us7000abcd" || flat.Magnitude == nil || *flat.Magnitude != 5.4 || flat.MagType != "mww" || flat.EventType != "earthquake" {
+		t.Errorf("Unexpected scalar fields: %+v", flat)
+	}
+	if flat.Longitude != -122.5 || flat.Latitude != 37.8 || flat.Depth != 8.2 {
+		t.Errorf("Coordinates = %v/%v/%v, want -122.5/37.8/8.2", flat.Longitude, flat.Latitude, flat.Depth)
+	}
+	if flat.Time == nil || !flat.Time.Equal(eventTime) {
+		t.Errorf("Time = %v, want %v", flat.Time, eventTime)
+	}
+	if flat.Felt != 12 || flat.RMS != 0.35 {
+		t.Errorf("Felt/RMS = %d/%v, want 12/0.35", flat.Felt, flat.RMS)
+	}
+	// Missing optional values default to zero
+	if flat.CDI != 0 || flat.Nst != 0 || flat.Gap != 0 {
+		t.Errorf("Expected zero defaults, got CDI=%v Nst=%d Gap=%v", flat.CDI, flat.Nst, flat.Gap)
+	}
+	if flat.Updated != nil {
+		t.Errorf("Updated = %v, want it missing", flat.Updated)
+	}
+}
+
+func TestEarthquake_FlattenMissingTimeAndMagnitude(t *testing.T) {
+	flat := (&Earthquake{ID: "unknown"}).Flatten()
+
+	// Unknown values are empty cells, not the Unix epoch or magnitude 0
+	data, err := json.Marshal(flat)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for _, cell := range []string{`"time":null`, `"updated":null`, `"mag":null`} {
+		if !strings.Contains(string(data), cell) {
+			t.Errorf("Expected %s in %s", cell, data)
+		}
+	}
+	if strings.Contains(string(data), "1970") {
+		t.Errorf("Expected no epoch time in %s", data)
+	}
+}
+
+func TestEarthquake_FlattenWithoutDepth(t *testing.T) {
+	eq := Earthquake{
+		ID:       "no-depth",
+		Geometry: Geometry{Type: "Point", Coordinates: []float64{139.7, 35.7}},
+	}
+
+	flat := eq.Flatten()
+	if flat.Longitude != 139.7 || flat.Latitude != 35.7 {
+		t.Errorf("Coordinates = %v/%v, want 139.7/35.7", flat.Longitude, flat.Latitude)
+	}
+	if flat.Depth != 0 {
+		t.Errorf("Depth = %v, want 0", flat.Depth)
+	}
+
+	// Missing geometry must not panic
+	empty := (&Earthquake{}).Flatten()
+	if empty.Latitude != 0 || empty.Longitude != 0 || empty.Depth != 0 {
+		t.Errorf("Expected zero coordinates, got %+v", empty)
+	}
+}
+
+func TestNormalizeMagType(t *testing.T) {
+	tests := map[string]string{
+		"Mww":   "mw",
+		"mwc":   "mw",
+		"Mi":    "mw",
+		"ms_20": "ms",
+		"mb":    "mb",
+		"mb_Lg": "mb_lg",
+		"ML":    "ml",
+		" md ":  "md",
+		"Mfa":   "mfa",
+		"":      "",
+	}
+	for magType, want := range tests {
+		if got := NormalizeMagType(magType); got != want {
+			t.Errorf("NormalizeMagType(%q) = %q, want %q", magType, got, want)
+		}
+	}
+}
+
+func TestEarthquakeProperties_NullMagnitude(t *testing.T) {
+	var eq Earthquake
+	if err := json.Unmarshal([]byte(`{"type":"Feature","id":"us1","properties":{"mag":null,"place":"Somewhere"}}`), &eq); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if eq.Properties.Mag != nil {
+		t.Fatalf("Mag = %v, want nil for a null magnitude", *eq.Properties.Mag)
+	}
+	if _, ok := eq.Properties.Magnitude(); ok || eq.Properties.IsSignificant() || eq.Properties.GetMagnitude() != "unknown" {
+		t.Errorf("Expected an unknown magnitude, got %q", eq.Properties.GetMagnitude())
+	}
+
+	// The magnitude is written back as null, not 0
+	data, err := json.Marshal(eq)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"mag":null`) {
+		t.Errorf("Expected a null magnitude, got %s", data)
+	}
+
+	// A reported magnitude of 0 stays known
+	if err := json.Unmarshal([]byte(`{"properties":{"mag":0}}`), &eq); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if mag, ok := eq.Properties.Magnitude(); !ok || mag != 0 {
+		t.Errorf("Magnitude() = %v, %v, want 0, true", mag, ok)
+	}
+}
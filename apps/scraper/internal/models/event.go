@@ -0,0 +1,47 @@
+package models
+
+// EventDetail is the USGS detail GeoJSON of a single event: the event's summary properties
+// plus every product contributed for it, such as moment tensors, phase data and ShakeMaps
+type EventDetail struct {
+	Type       string                `json:"type"`
+	Properties EventDetailProperties `json:"properties"`
+	Geometry   Geometry              `json:"geometry"`
+	ID         string                `json:"id"`
+}
+
+// EventDetailProperties holds the summary properties of an event and its products, keyed by
+// product type (e.g. "moment-tensor", "phase-data", "shakemap")
+type EventDetailProperties struct {
+	EarthquakeProperties
+	Products map[string][]EventProduct `json:"products"`
+}
+
+// EventProduct is one version of a product contributed for an event
+type EventProduct struct {
+	ID              string                    `json:"id"`
+	Type            string                    `json:"type"`
+	Code            string                    `json:"code"`
+	Source          string                    `json:"source"`
+	Status          string                    `json:"status"`
+	UpdateTime      int64                     `json:"updateTime"`
+	PreferredWeight int                       `json:"preferredWeight"`
+	Properties      map[string]string         `json:"properties"`
+	Contents        map[string]ProductContent `json:"contents"`
+}
+
+// ProductContent is a file belonging to a product
+type ProductContent struct {
+	ContentType  string `json:"contentType"`
+	LastModified int64  `json:"lastModified"`
+	Length       int64  `json:"length"`
+	URL          string `json:"url"`
+}
+
+// ProductTypes returns the number of products of each type the event has
+func (d *EventDetail) ProductTypes() map[string]int {
+	counts := make(map[string]int, len(d.Properties.Products))
+	for productType, products := range d.Properties.Products {
+		counts[productType] = len(products)
+	}
+	return counts
+}
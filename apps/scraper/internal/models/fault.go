@@ -1,6 +1,13 @@
 package models
 
-import "fmt"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
 
 // Fault represents the top-level fault data structure from EMSC
 type Fault struct {
@@ -30,6 +37,93 @@ type FaultProperties struct {
 	MaxMagnitude *float64 `json:"max_magnitude,omitempty"`
 	Description  string   `json:"description,omitempty"`
 	Source       string   `json:"source,omitempty"`
+
+	// RawProperties holds the properties without a field above, so attributes only some
+	// sources send survive a round trip
+	RawProperties map[string]json.RawMessage `json:"-"`
+}
+
+// faultProperties has the fields of FaultProperties without its JSON methods
+type faultProperties FaultProperties
+
+// faultPropertyKeys are the property names mapped to FaultProperties fields
+var faultPropertyKeys = jsonFieldNames(reflect.TypeOf(faultProperties{}))
+
+// jsonFieldNames returns the JSON names of the encoded fields of a struct type
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// UnmarshalJSON decodes the mapped properties into their fields and keeps the rest in RawProperties
+func (f *FaultProperties) UnmarshalJSON(data []byte) error {
+	var mapped faultProperties
+	if err := json.Unmarshal(data, &mapped); err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for key := range all {
+		if faultPropertyKeys[key] {
+			delete(all, key)
+		}
+	}
+	if len(all) > 0 {
+		mapped.RawProperties = all
+	}
+	*f = FaultProperties(mapped)
+	return nil
+}
+
+// MarshalJSON encodes the mapped fields followed by RawProperties, sorted by name. Raw properties
+// named like a mapped field are left out.
+func (f FaultProperties) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(faultProperties(f))
+	if err != nil || len(f.RawProperties) == 0 {
+		return data, err
+	}
+
+	keys := make([]string, 0, len(f.RawProperties))
+	for key := range f.RawProperties {
+		if !faultPropertyKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1])
+	for _, key := range keys {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.RawProperties[key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid raw property %s: %w", key, err)
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
 }
 
 // FaultGeometry represents the geographical geometry of a fault
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFaultProperties_RawPropertiesRoundTrip(t *testing.T) {
+	input := `{"id":"f-1","name":"North Fault","type":"fault","dip":45,"net_slip_rate":"0.5-1.2","segments":[1,2],"name_local":"Nordstörung"}`
+
+	var props FaultProperties
+	if err := json.Unmarshal([]byte(input), &props); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if props.ID != "f-1" || props.Name != "North Fault" || props.Dip == nil || *props.Dip != 45 {
+		t.Errorf("Mapped fields = %+v, want id f-1, name North Fault and dip 45", props)
+	}
+	wantRaw := map[string]string{"net_slip_rate": `"0.5-1.2"`, "segments": `[1,2]`, "name_local": `"Nordstörung"`}
+	if len(props.RawProperties) != len(wantRaw) {
+		t.Fatalf("RawProperties = %v, want only the unmapped %v", props.RawProperties, wantRaw)
+	}
+	for key, want := range wantRaw {
+		if got := string(props.RawProperties[key]); got != want {
+			t.Errorf("RawProperties[%s] = %s, want %s", key, got, want)
+		}
+	}
+
+	data, err := json.Marshal(props)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var roundTripped FaultProperties
+	if err := json.Unmarshal(data, &roundTripped); err != nil {
+		t.Fatalf("Unmarshal() of %s error = %v", data, err)
+	}
+	if !reflect.DeepEqual(roundTripped, props) {
+		t.Errorf("Round trip = %+v, want %+v", roundTripped, props)
+	}
+
+	var original, encoded map[string]interface{}
+	json.Unmarshal([]byte(input), &original)
+	json.Unmarshal(data, &encoded)
+	if !reflect.DeepEqual(encoded, original) {
+		t.Errorf("Marshal() = %s, want the properties of %s", data, input)
+	}
+}
+
+func TestFaultProperties_NoRawProperties(t *testing.T) {
+	var props FaultProperties
+	if err := json.Unmarshal([]byte(`{"id":"f-2","name":"South Fault","type":"fault"}`), &props); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if props.RawProperties != nil {
+		t.Errorf("RawProperties = %v, want nil without unmapped properties", props.RawProperties)
+	}
+
+	// A raw property named like a mapped field does not duplicate the key
+	props.RawProperties = map[string]json.RawMessage{"name": json.RawMessage(`"Shadow"`)}
+	data, err := json.Marshal(props)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := `{"id":"f-2","name":"South Fault","type":"fault"}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+}
@@ -20,19 +20,21 @@ type IntervalExecution struct {
 
 // IntervalStatus represents the current status of an interval scheduler
 type IntervalStatus struct {
-	IsRunning      bool          `json:"is_running"`
-	StartTime      time.Time     `json:"start_time,omitempty"`
-	LastExecution  time.Time     `json:"last_execution,omitempty"`
-	NextExecution  time.Time     `json:"next_execution,omitempty"`
-	Executions     int64         `json:"executions"`
-	Failures       int64         `json:"failures"`
-	SuccessRate    float64       `json:"success_rate"`
-	TotalRuntime   time.Duration `json:"total_runtime"`
-	AverageRuntime time.Duration `json:"average_runtime"`
-	Command        string        `json:"command"`
-	Interval       time.Duration `json:"interval"`
-	MaxExecutions  int           `json:"max_executions"`
-	MaxRuntime     time.Duration `json:"max_runtime"`
+	IsRunning       bool          `json:"is_running"`
+	StartTime       time.Time     `json:"start_time,omitempty"`
+	LastExecution   time.Time     `json:"last_execution,omitempty"`
+	NextExecution   time.Time     `json:"next_execution,omitempty"`
+	Executions      int64         `json:"executions"`
+	Failures        int64         `json:"failures"`
+	Retries         int64         `json:"retries"`
+	SuccessRate     float64       `json:"success_rate"`
+	TotalRuntime    time.Duration `json:"total_runtime"`
+	AverageRuntime  time.Duration `json:"average_runtime"`
+	Command         string        `json:"command"`
+	Interval        time.Duration `json:"interval"`
+	MaxExecutions   int           `json:"max_executions"`
+	MaxRuntime      time.Duration `json:"max_runtime"`
+	MaxTotalRetries int           `json:"max_total_retries,omitempty"`
 }
 
 // IntervalConfig represents the configuration for interval execution
@@ -5,7 +5,8 @@ import (
 	"time"
 )
 
-// BackoffStrategy defines the interface for backoff strategies
+// BackoffStrategy defines the interface for backoff strategies. Reset is called after a
+// successful execution so strategies that keep state start again from their base delay.
 type BackoffStrategy interface {
 	GetDelay(attempt int) time.Duration
 	Reset()
@@ -63,3 +64,39 @@ func (e *ExponentialBackoff) GetDelay(attempt int) time.Duration {
 func (e *ExponentialBackoff) Reset() {
 	// No state to reset
 }
+
+// FibonacciBackoff implements a backoff strategy whose delays follow the Fibonacci sequence
+type FibonacciBackoff struct {
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+func NewFibonacciBackoff(baseDelay, maxDelay time.Duration) *FibonacciBackoff {
+	return &FibonacciBackoff{
+		baseDelay: baseDelay,
+		maxDelay:  maxDelay,
+	}
+}
+
+func (f *FibonacciBackoff) GetDelay(attempt int) time.Duration {
+	if attempt <= 0 {
+		return 0
+	}
+
+	prev, curr := time.Duration(0), f.baseDelay
+	for i := 1; i < attempt; i++ {
+		prev, curr = curr, prev+curr
+		if curr >= f.maxDelay {
+			return f.maxDelay
+		}
+	}
+
+	if curr > f.maxDelay {
+		curr = f.maxDelay
+	}
+	return curr
+}
+
+func (f *FibonacciBackoff) Reset() {
+	// No state to reset
+}
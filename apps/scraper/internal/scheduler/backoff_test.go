@@ -0,0 +1,35 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFibonacciBackoff_GetDelay(t *testing.T) {
+	backoff := NewFibonacciBackoff(time.Second, 30*time.Second)
+
+	expected := []time.Duration{
+		1 * time.Second,
+		1 * time.Second,
+		2 * time.Second,
+		3 * time.Second,
+		5 * time.Second,
+		8 * time.Second,
+		13 * time.Second,
+		21 * time.Second,
+		30 * time.Second,
+		30 * time.Second,
+	}
+
+	for i, want := range expected {
+		attempt := i + 1
+		if got := backoff.GetDelay(attempt); got != want {
+			t.Errorf("GetDelay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+
+	// Very high attempts must stay capped rather than overflow
+	if got := backoff.GetDelay(1000); got != 30*time.Second {
+		t.Errorf("GetDelay(1000) = %v, want %v", got, 30*time.Second)
+	}
+}
@@ -1,27 +1,67 @@
 package scheduler
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 )
 
+// defaultProcessName is the daemon's process name when the executable path cannot be determined
+const defaultProcessName = "quakewatch-scraper"
+
+// maxCommLength is the length Linux truncates /proc/<pid>/comm to
+const maxCommLength = 15
+
+// stopPollInterval is how often Terminate checks whether the daemon has exited
+const stopPollInterval = 100 * time.Millisecond
+
+// ErrDaemonNotRunning is returned when stopping a daemon that is not running
+var ErrDaemonNotRunning = errors.New("daemon is not running")
+
 // DaemonManager handles daemon process management
 type DaemonManager struct {
 	pidFile string
 	logFile string
 	logger  *log.Logger
+	// executable is the resolved path of this program's binary, empty when it cannot be determined
+	executable string
+	// processNames are the names the daemon may run under: the binary's and, when started
+	// through a symlink, the link's
+	processNames []string
+	kill         func(pid int, sig syscall.Signal) error
 }
 
 // NewDaemonManager creates a new daemon manager
 func NewDaemonManager(pidFile, logFile string, logger *log.Logger) *DaemonManager {
+	var executable string
+	processNames := []string{defaultProcessName}
+	if path, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+		executable = path
+		processNames = []string{filepath.Base(path)}
+	}
+	if len(os.Args) > 0 {
+		if name := filepath.Base(os.Args[0]); name != processNames[0] {
+			processNames = append(processNames, name)
+		}
+	}
+
 	return &DaemonManager{
-		pidFile: pidFile,
-		logFile: logFile,
-		logger:  logger,
+		pidFile:      pidFile,
+		logFile:      logFile,
+		logger:       logger,
+		executable:   executable,
+		processNames: processNames,
+		kill:         syscall.Kill,
 	}
 }
 
@@ -87,10 +127,11 @@ func (d *DaemonManager) setupDaemon() error {
 	return nil
 }
 
-// setupSignalHandlers sets up signal handlers for graceful shutdown
+// setupSignalHandlers sets up signal handlers for graceful shutdown. SIGHUP is left to the
+// interval command, which reloads the configuration on it.
 func (d *DaemonManager) setupSignalHandlers() {
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
 
 	go func() {
 		sig := <-sigChan
@@ -111,7 +152,8 @@ func (d *DaemonManager) Stop() error {
 	return nil
 }
 
-// IsRunning checks if the daemon is running by checking the PID file
+// IsRunning checks if the daemon is running by checking the PID file. A PID file whose
+// process has exited, or whose PID now belongs to an unrelated program, is stale and is removed.
 func (d *DaemonManager) IsRunning() bool {
 	if d.pidFile == "" {
 		return false
@@ -122,20 +164,102 @@ func (d *DaemonManager) IsRunning() bool {
 		return false
 	}
 
-	pid, err := strconv.Atoi(string(data))
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || !processAlive(pid) || !d.isDaemonProcess(pid) {
+		d.logger.Printf("Removing stale PID file: %s", d.pidFile)
+		if err := d.RemovePID(); err != nil && !os.IsNotExist(err) {
+			d.logger.Printf("Warning: failed to remove stale PID file: %v", err)
+		}
+		return false
+	}
+
+	return true
+}
+
+// readPID reads the process ID from the PID file
+func (d *DaemonManager) readPID() (int, error) {
+	data, err := os.ReadFile(d.pidFile)
 	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// Terminate stops a daemon started from another process: it sends SIGTERM to the PID in the
+// PID file, waits up to timeout for the process to exit and removes the PID file. It returns
+// the daemon's PID, or ErrDaemonNotRunning if the PID file does not belong to a live daemon.
+func (d *DaemonManager) Terminate(timeout time.Duration) (int, error) {
+	if !d.IsRunning() {
+		return 0, ErrDaemonNotRunning
+	}
+
+	pid, err := d.readPID()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read PID file: %w", err)
+	}
+
+	if err := d.kill(pid, syscall.SIGTERM); err != nil {
+		return pid, fmt.Errorf("failed to signal daemon (PID %d): %w", pid, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			return pid, fmt.Errorf("daemon (PID %d) did not exit within %s", pid, timeout)
+		}
+		time.Sleep(stopPollInterval)
+	}
+
+	// The daemon normally removes its own PID file on shutdown
+	if err := d.RemovePID(); err != nil && !os.IsNotExist(err) {
+		return pid, fmt.Errorf("failed to remove PID file: %w", err)
+	}
+
+	return pid, nil
+}
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
 		return false
 	}
 
-	// Check if process exists
 	process, err := os.FindProcess(pid)
 	if err != nil {
 		return false
 	}
 
 	// Send signal 0 to check if process is running
-	err = process.Signal(syscall.Signal(0))
-	return err == nil
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// isDaemonProcess reports whether pid runs this daemon's executable, so a recycled PID is not
+// mistaken for a running daemon. The binary behind /proc/<pid>/exe is compared first, which also
+// holds when the daemon was started through a symlink. Where it cannot be read, e.g. for another
+// user's process, the process name is compared instead, and where /proc is unavailable the
+// process is assumed to match.
+func (d *DaemonManager) isDaemonProcess(pid int) bool {
+	procDir := filepath.Join("/proc", strconv.Itoa(pid))
+	if d.executable != "" {
+		if exe, err := os.Readlink(filepath.Join(procDir, "exe")); err == nil {
+			// A binary replaced since the daemon started is reported with a " (deleted)" suffix
+			return strings.TrimSuffix(exe, " (deleted)") == d.executable
+		}
+	}
+
+	comm, err := os.ReadFile(filepath.Join(procDir, "comm"))
+	if err != nil {
+		return true
+	}
+	for _, name := range d.processNames {
+		if len(name) > maxCommLength {
+			name = name[:maxCommLength]
+		}
+		if strings.TrimSpace(string(comm)) == name {
+			return true
+		}
+	}
+	return false
 }
 
 // WritePID writes the current process ID to the PID file
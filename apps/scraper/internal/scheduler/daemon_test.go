@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDaemonManager_IsRunning(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process name checks rely on /proc")
+	}
+
+	comm, err := os.ReadFile("/proc/self/comm")
+	if err != nil {
+		t.Fatalf("Failed to read /proc/self/comm: %v", err)
+	}
+	selfName := strings.TrimSpace(string(comm))
+	selfExe, err := os.Readlink("/proc/self/exe")
+	if err != nil {
+		t.Fatalf("Failed to read /proc/self/exe: %v", err)
+	}
+	selfPID := strconv.Itoa(os.Getpid())
+
+	// A PID that has just exited and has not been recycled yet
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Fatalf("Failed to run helper process: %v", err)
+	}
+	exitedPID := strconv.Itoa(exited.Process.Pid)
+
+	tests := []struct {
+		name        string
+		pid         string
+		executable  string
+		processName string
+		wantRunning bool
+	}{
+		{name: "Live daemon process", pid: selfPID, executable: selfExe, processName: selfName, wantRunning: true},
+		{name: "Recycled PID of another program", pid: selfPID, executable: "/usr/local/bin/quakewatch-scraper", processName: "quakewatch-scraper", wantRunning: false},
+		{name: "Unknown executable, matching name", pid: selfPID, processName: selfName, wantRunning: true},
+		{name: "Unknown executable, other name", pid: selfPID, processName: "quakewatch-scraper", wantRunning: false},
+		{name: "Exited process", pid: exitedPID, executable: selfExe, processName: selfName, wantRunning: false},
+		{name: "Unparseable PID", pid: "not-a-pid", executable: selfExe, processName: selfName, wantRunning: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "scraper.pid")
+			if err := os.WriteFile(path, []byte(tt.pid+"\n"), 0644); err != nil {
+				t.Fatalf("Failed to write PID file: %v", err)
+			}
+
+			d := NewDaemonManager(path, "", log.New(io.Discard, "", 0))
+			d.executable = tt.executable
+			d.processNames = []string{tt.processName}
+
+			if got := d.IsRunning(); got != tt.wantRunning {
+				t.Fatalf("Expected IsRunning %v, got %v", tt.wantRunning, got)
+			}
+
+			_, err := os.Stat(path)
+			if tt.wantRunning && err != nil {
+				t.Errorf("Expected PID file to be kept, stat error: %v", err)
+			}
+			if !tt.wantRunning && !os.IsNotExist(err) {
+				t.Errorf("Expected stale PID file to be removed, stat error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDaemonManager_IsRunningThroughSymlink(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process name checks rely on /proc")
+	}
+
+	// A daemon started through a symlink is named after the link, not the binary it points to
+	dir := t.TempDir()
+	sleepPath := resolvedExecutable(t, "sleep")
+	link := filepath.Join(dir, "qw-daemon")
+	if err := os.Symlink(sleepPath, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	daemon := exec.Command(link, "60")
+	if err := daemon.Start(); err != nil {
+		t.Fatalf("Failed to start helper process: %v", err)
+	}
+	defer func() {
+		daemon.Process.Kill()
+		daemon.Wait()
+	}()
+
+	path := filepath.Join(dir, "scraper.pid")
+	if err := os.WriteFile(path, []byte(strconv.Itoa(daemon.Process.Pid)), 0644); err != nil {
+		t.Fatalf("Failed to write PID file: %v", err)
+	}
+
+	d := NewDaemonManager(path, "", log.New(io.Discard, "", 0))
+	d.executable = sleepPath
+	d.processNames = []string{filepath.Base(sleepPath)}
+	if !d.IsRunning() {
+		t.Error("Expected the daemon started through a symlink to be running")
+	}
+}
+
+// resolvedExecutable returns the path of the named program with all symlinks resolved
+func resolvedExecutable(t *testing.T, name string) string {
+	t.Helper()
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		t.Skipf("%s not available: %v", name, err)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatalf("Failed to resolve %s: %v", path, err)
+	}
+	return resolved
+}
+
+func TestDaemonManager_Terminate(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process name checks rely on /proc")
+	}
+
+	// Stand-in daemon process
+	sleepPath := resolvedExecutable(t, "sleep")
+	daemon := exec.Command(sleepPath, "60")
+	if err := daemon.Start(); err != nil {
+		t.Fatalf("Failed to start helper process: %v", err)
+	}
+	defer daemon.Process.Kill()
+
+	path := filepath.Join(t.TempDir(), "scraper.pid")
+	if err := os.WriteFile(path, []byte(strconv.Itoa(daemon.Process.Pid)), 0644); err != nil {
+		t.Fatalf("Failed to write PID file: %v", err)
+	}
+
+	d := NewDaemonManager(path, "", log.New(io.Discard, "", 0))
+	d.executable = sleepPath
+	d.processNames = []string{"sleep"}
+
+	var signaled []int
+	var signals []syscall.Signal
+	d.kill = func(pid int, sig syscall.Signal) error {
+		signaled = append(signaled, pid)
+		signals = append(signals, sig)
+		// Stop the helper and reap it so it does not linger as a zombie
+		daemon.Process.Kill()
+		daemon.Wait()
+		return nil
+	}
+
+	pid, err := d.Terminate(5 * time.Second)
+	if err != nil {
+		t.Fatalf("Terminate() error = %v", err)
+	}
+	if pid != daemon.Process.Pid || len(signaled) != 1 || signaled[0] != pid || signals[0] != syscall.SIGTERM {
+		t.Errorf("Expected one SIGTERM to PID %d, got PIDs %v signals %v", daemon.Process.Pid, signaled, signals)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected PID file to be removed, stat error: %v", err)
+	}
+
+	// With the daemon gone there is nothing to stop
+	if _, err := d.Terminate(time.Second); !errors.Is(err, ErrDaemonNotRunning) {
+		t.Errorf("Expected ErrDaemonNotRunning, got %v", err)
+	}
+	if len(signaled) != 1 {
+		t.Errorf("Expected no signal without a running daemon, got %d", len(signaled))
+	}
+}
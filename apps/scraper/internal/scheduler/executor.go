@@ -13,6 +13,7 @@ type CommandExecutor struct {
 	logger     *log.Logger
 	retryCount int
 	executor   func(ctx context.Context, args []string) error
+	retryHook  func() error
 }
 
 // NewCommandExecutor creates a new command executor
@@ -53,6 +54,12 @@ func (e *CommandExecutor) ExecuteWithRetry(ctx context.Context, command string,
 
 	for attempt := 0; attempt <= e.retryCount; attempt++ {
 		if attempt > 0 {
+			if e.retryHook != nil {
+				if err := e.retryHook(); err != nil {
+					return fmt.Errorf("not retrying: %w (last error: %v)", err, lastErr)
+				}
+			}
+
 			delay := e.backoff.GetDelay(attempt)
 			e.logger.Printf("Retry attempt %d after %v delay", attempt, delay)
 
@@ -92,6 +99,12 @@ func (e *CommandExecutor) SetRetryCount(count int) {
 	e.retryCount = count
 }
 
+// SetRetryHook sets a function called before every retry. If it returns an error the
+// command is not retried and ExecuteWithRetry returns that error.
+func (e *CommandExecutor) SetRetryHook(hook func() error) {
+	e.retryHook = hook
+}
+
 // SetBackoffStrategy sets the backoff strategy
 func (e *CommandExecutor) SetBackoffStrategy(strategy BackoffStrategy) {
 	e.backoff = strategy
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+// doublingBackoff doubles its delay on every call until Reset, recording the delays it hands out
+type doublingBackoff struct {
+	base   time.Duration
+	next   time.Duration
+	delays []time.Duration
+}
+
+func (d *doublingBackoff) GetDelay(attempt int) time.Duration {
+	if d.next == 0 {
+		d.next = d.base
+	}
+	delay := d.next
+	d.next *= 2
+	d.delays = append(d.delays, delay)
+	return delay
+}
+
+func (d *doublingBackoff) Reset() {
+	d.next = 0
+}
+
+func TestCommandExecutor_ResetsBackoffOnSuccess(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+
+	// Every execution fails once and then succeeds
+	calls := 0
+	executor := NewCommandExecutorWithFunction(logger, func(ctx context.Context, args []string) error {
+		calls++
+		if calls%2 == 1 {
+			return errors.New("api unavailable")
+		}
+		return nil
+	})
+	backoff := &doublingBackoff{base: time.Millisecond}
+	executor.SetBackoffStrategy(backoff)
+
+	for i := 0; i < 3; i++ {
+		if err := executor.ExecuteWithRetry(context.Background(), "quakewatch-scraper", nil); err != nil {
+			t.Fatalf("ExecuteWithRetry() error = %v", err)
+		}
+	}
+
+	// Each retry after a success starts again from the base delay
+	want := []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond}
+	if len(backoff.delays) != len(want) {
+		t.Fatalf("delays = %v, want %v", backoff.delays, want)
+	}
+	for i := range want {
+		if backoff.delays[i] != want[i] {
+			t.Errorf("delays = %v, want %v", backoff.delays, want)
+			break
+		}
+	}
+}
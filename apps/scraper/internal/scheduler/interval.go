@@ -2,25 +2,42 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"quakewatch-scraper/internal/config"
+	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/utils"
 )
 
+// ErrRetryBudgetExhausted is returned when a run has used up its max_total_retries budget
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
 // IntervalScheduler manages the execution of commands at specified intervals
 type IntervalScheduler struct {
-	config    *config.IntervalConfig
+	// config may be swapped by Reload while the scheduler runs, so it is read through settings
+	config   *config.IntervalConfig
+	configMu sync.RWMutex
+	reloaded chan struct{}
+
 	executor  *CommandExecutor
 	logger    *log.Logger
+	jitter    func(max time.Duration) time.Duration
 	stopChan  chan struct{}
 	doneChan  chan struct{}
 	daemon    *DaemonManager
 	metrics   *Metrics
 	mu        sync.RWMutex
 	isRunning bool
+
+	// command, startTime and nextExecution describe the current run for the status file
+	command       string
+	startTime     time.Time
+	nextExecution time.Time
 }
 
 // NewIntervalScheduler creates a new interval scheduler
@@ -29,6 +46,8 @@ func NewIntervalScheduler(cfg *config.IntervalConfig, logger *log.Logger) *Inter
 		config:   cfg,
 		executor: NewCommandExecutor(logger),
 		logger:   logger,
+		jitter:   utils.FullJitter,
+		reloaded: make(chan struct{}, 1),
 		stopChan: make(chan struct{}),
 		doneChan: make(chan struct{}),
 		daemon:   NewDaemonManager(cfg.PIDFile, cfg.LogFile, logger),
@@ -36,6 +55,42 @@ func NewIntervalScheduler(cfg *config.IntervalConfig, logger *log.Logger) *Inter
 	}
 }
 
+// settings returns a copy of the current configuration
+func (s *IntervalScheduler) settings() config.IntervalConfig {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return *s.config
+}
+
+// Reload applies the settings of cfg that can change while the scheduler runs: the interval,
+// jitter, max executions, the retry budget and continue-on-error. A new interval takes effect
+// from the next tick. The runtime limit, backoff, health checks and daemon files keep their values.
+func (s *IntervalScheduler) Reload(cfg *config.IntervalConfig) error {
+	if cfg.DefaultInterval <= 0 {
+		return fmt.Errorf("invalid interval %v: must be positive", cfg.DefaultInterval)
+	}
+
+	s.configMu.Lock()
+	updated := *s.config
+	updated.DefaultInterval = cfg.DefaultInterval
+	updated.Jitter = cfg.Jitter
+	updated.MaxExecutions = cfg.MaxExecutions
+	updated.MaxTotalRetries = cfg.MaxTotalRetries
+	updated.ContinueOnError = cfg.ContinueOnError
+	s.config = &updated
+	s.configMu.Unlock()
+
+	s.logger.Printf("Configuration reloaded: Interval: %v, Jitter: %v, Max Executions: %d, Max Total Retries: %d, Continue On Error: %v",
+		updated.DefaultInterval, updated.Jitter, updated.MaxExecutions, updated.MaxTotalRetries, updated.ContinueOnError)
+
+	// Wake the run loop to reset its ticker; a pending reload already covers this one
+	select {
+	case s.reloaded <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
 // Start begins the interval execution of the specified command
 func (s *IntervalScheduler) Start(ctx context.Context, command string, args []string) error {
 	s.mu.Lock()
@@ -46,31 +101,48 @@ func (s *IntervalScheduler) Start(ctx context.Context, command string, args []st
 	s.isRunning = true
 	s.mu.Unlock()
 
+	cfg := s.settings()
 	s.logger.Printf("Starting interval scheduler with command: %s", command)
-	s.logger.Printf("Interval: %v, Max Runtime: %v, Max Executions: %d",
-		s.config.DefaultInterval, s.config.MaxRuntime, s.config.MaxExecutions)
+	s.logger.Printf("Interval: %v, Jitter: %v, Max Runtime: %v, Max Executions: %d",
+		cfg.DefaultInterval, cfg.Jitter, cfg.MaxRuntime, cfg.MaxExecutions)
 
 	// Create context with timeout if max runtime is specified
 	var cancel context.CancelFunc
-	if s.config.MaxRuntime > 0 {
-		ctx, cancel = context.WithTimeout(ctx, s.config.MaxRuntime)
+	if cfg.MaxRuntime > 0 {
+		ctx, cancel = context.WithTimeout(ctx, cfg.MaxRuntime)
 		defer cancel()
 	}
 
 	// Start health monitoring if enabled
-	if s.config.HealthCheckInterval > 0 {
-		healthMonitor := NewHealthMonitor(s.config.HealthCheckInterval, s.logger, s.metrics)
+	if cfg.HealthCheckInterval > 0 {
+		healthMonitor := NewHealthMonitor(cfg.HealthCheckInterval, s.logger, s.metrics)
 		go healthMonitor.Start(ctx)
 	}
 
+	s.executor.SetRetryHook(s.consumeRetry)
+
+	s.command = strings.TrimSpace(command + " " + strings.Join(args, " "))
+	s.startTime = time.Now()
+	s.nextExecution = s.startTime.Add(cfg.DefaultInterval)
+	defer s.writeStatus(false)
+
 	executionCount := 0
-	ticker := time.NewTicker(s.config.DefaultInterval)
+	ticker := time.NewTicker(cfg.DefaultInterval)
 	defer ticker.Stop()
 
-	// Execute immediately on start
+	// Execute immediately on start, after the jitter delay
+	if !s.waitJitter(ctx) {
+		s.mu.Lock()
+		s.isRunning = false
+		s.mu.Unlock()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return nil
+	}
 	if err := s.executeCommand(ctx, command, args, executionCount); err != nil {
 		s.logger.Printf("Initial execution failed: %v", err)
-		if !s.config.ContinueOnError {
+		if err := s.stopError(err); err != nil {
 			s.mu.Lock()
 			s.isRunning = false
 			s.mu.Unlock()
@@ -90,16 +162,29 @@ func (s *IntervalScheduler) Start(ctx context.Context, command string, args []st
 			s.logger.Printf("Stop signal received, stopping scheduler")
 			return nil
 
-		case <-ticker.C:
+		case <-s.reloaded:
+			interval := s.settings().DefaultInterval
+			ticker.Reset(interval)
+			s.nextExecution = time.Now().Add(interval)
+			s.writeStatus(true)
+
+		case tick := <-ticker.C:
+			cfg := s.settings()
+			s.nextExecution = tick.Add(cfg.DefaultInterval)
+
 			// Check if we've reached the maximum number of executions
-			if s.config.MaxExecutions > 0 && executionCount >= s.config.MaxExecutions {
-				s.logger.Printf("Reached maximum executions (%d), stopping scheduler", s.config.MaxExecutions)
+			if cfg.MaxExecutions > 0 && executionCount >= cfg.MaxExecutions {
+				s.logger.Printf("Reached maximum executions (%d), stopping scheduler", cfg.MaxExecutions)
 				return nil
 			}
 
+			// A cancelled or stopped wait is handled on the next pass through the loop
+			if !s.waitJitter(ctx) {
+				continue
+			}
 			if err := s.executeCommand(ctx, command, args, executionCount); err != nil {
 				s.logger.Printf("Execution %d failed: %v", executionCount, err)
-				if !s.config.ContinueOnError {
+				if err := s.stopError(err); err != nil {
 					s.mu.Lock()
 					s.isRunning = false
 					s.mu.Unlock()
@@ -111,6 +196,28 @@ func (s *IntervalScheduler) Start(ctx context.Context, command string, args []st
 	}
 }
 
+// waitJitter sleeps a random delay of up to the configured jitter, so schedulers started on the
+// same boundary do not all poll at once. It returns false if the run is cancelled or stopped
+// while waiting.
+func (s *IntervalScheduler) waitJitter(ctx context.Context) bool {
+	delay := s.jitter(s.settings().Jitter)
+	if delay <= 0 {
+		return true
+	}
+
+	s.logger.Printf("Delaying execution by %v (jitter)", delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-s.stopChan:
+		return false
+	}
+}
+
 // executeCommand executes a single command with proper error handling and backoff
 func (s *IntervalScheduler) executeCommand(ctx context.Context, command string, args []string, attempt int) error {
 	s.logger.Printf("Executing command (attempt %d): %s", attempt, command)
@@ -121,6 +228,7 @@ func (s *IntervalScheduler) executeCommand(ctx context.Context, command string,
 
 	// Update metrics
 	s.metrics.RecordExecution(executionTime, err)
+	s.writeStatus(true)
 
 	if err != nil {
 		s.logger.Printf("Command execution failed after %v: %v", executionTime, err)
@@ -131,6 +239,39 @@ func (s *IntervalScheduler) executeCommand(ctx context.Context, command string,
 	return nil
 }
 
+// stopError decides whether a failed execution stops the scheduler, returning the error to stop
+// with or nil to keep going. An exhausted retry budget stops the run even with continue-on-error.
+func (s *IntervalScheduler) stopError(err error) error {
+	cfg := s.settings()
+	if s.retryBudgetExhausted() {
+		if !errors.Is(err, ErrRetryBudgetExhausted) {
+			err = fmt.Errorf("%w after %d retries: %v", ErrRetryBudgetExhausted, cfg.MaxTotalRetries, err)
+		}
+		return err
+	}
+	if !cfg.ContinueOnError {
+		return err
+	}
+	return nil
+}
+
+// consumeRetry takes one retry from the run's budget, failing once max_total_retries have been used
+func (s *IntervalScheduler) consumeRetry() error {
+	if s.retryBudgetExhausted() {
+		maxTotalRetries := s.settings().MaxTotalRetries
+		s.logger.Printf("Retry budget of %d exhausted", maxTotalRetries)
+		return fmt.Errorf("%w after %d retries", ErrRetryBudgetExhausted, maxTotalRetries)
+	}
+	s.metrics.RecordRetry()
+	return nil
+}
+
+// retryBudgetExhausted reports whether the run has used all of its retries
+func (s *IntervalScheduler) retryBudgetExhausted() bool {
+	maxTotalRetries := s.settings().MaxTotalRetries
+	return maxTotalRetries > 0 && s.metrics.GetRetries() >= int64(maxTotalRetries)
+}
+
 // Stop gracefully stops the scheduler
 func (s *IntervalScheduler) Stop() error {
 	s.mu.Lock()
@@ -183,6 +324,48 @@ func (s *IntervalScheduler) StartDaemon(ctx context.Context, command string, arg
 	return nil
 }
 
+// Status returns a snapshot of the scheduler's metrics and configuration
+func (s *IntervalScheduler) Status() *models.IntervalStatus {
+	return s.status(s.IsRunning())
+}
+
+// status builds the status snapshot. It takes the running state as an argument so it can be
+// called on shutdown without taking the scheduler lock, which Stop holds while waiting.
+func (s *IntervalScheduler) status(running bool) *models.IntervalStatus {
+	cfg := s.settings()
+	status := &models.IntervalStatus{
+		IsRunning:       running,
+		StartTime:       s.startTime,
+		LastExecution:   s.metrics.GetLastExecution(),
+		Executions:      s.metrics.GetExecutions(),
+		Failures:        s.metrics.GetFailures(),
+		Retries:         s.metrics.GetRetries(),
+		SuccessRate:     s.metrics.GetSuccessRate(),
+		TotalRuntime:    s.metrics.GetTotalRuntime(),
+		AverageRuntime:  s.metrics.GetAverageRuntime(),
+		Command:         s.command,
+		Interval:        cfg.DefaultInterval,
+		MaxExecutions:   cfg.MaxExecutions,
+		MaxRuntime:      cfg.MaxRuntime,
+		MaxTotalRetries: cfg.MaxTotalRetries,
+	}
+	if running {
+		status.NextExecution = s.nextExecution
+	}
+	return status
+}
+
+// writeStatus persists the status snapshot to the configured status file, if any
+func (s *IntervalScheduler) writeStatus(running bool) {
+	statusFile := s.settings().StatusFile
+	if statusFile == "" {
+		return
+	}
+	if err := WriteStatus(statusFile, s.status(running)); err != nil {
+		s.logger.Printf("Warning: failed to write status file: %v", err)
+	}
+}
+
 // GetMetrics returns the current metrics
 func (s *IntervalScheduler) GetMetrics() *Metrics {
 	return s.metrics
@@ -0,0 +1,201 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/config"
+	"quakewatch-scraper/internal/utils"
+)
+
+func TestIntervalScheduler_RetryBudget(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	cfg := &config.IntervalConfig{
+		DefaultInterval: time.Millisecond,
+		ContinueOnError: true,
+		MaxTotalRetries: 5,
+	}
+
+	calls := 0
+	executor := NewCommandExecutorWithFunction(logger, func(ctx context.Context, args []string) error {
+		calls++
+		return errors.New("api unavailable")
+	})
+	executor.SetBackoffStrategy(&NoBackoff{})
+
+	s := NewIntervalScheduler(cfg, logger)
+	s.SetExecutor(executor)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.Start(ctx, "quakewatch-scraper", []string{"earthquakes", "recent"})
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("Expected ErrRetryBudgetExhausted, got %v", err)
+	}
+
+	// The first execution uses 3 retries, the second uses the remaining 2 and is refused a third
+	if retries := s.GetMetrics().GetRetries(); retries != 5 {
+		t.Errorf("Expected 5 retries, got %d", retries)
+	}
+	if calls != 7 {
+		t.Errorf("Expected 7 attempts, got %d", calls)
+	}
+	if executions := s.GetMetrics().GetExecutions(); executions != 2 {
+		t.Errorf("Expected 2 executions, got %d", executions)
+	}
+}
+
+func TestIntervalScheduler_NoRetryBudget(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	cfg := &config.IntervalConfig{
+		DefaultInterval: time.Millisecond,
+		ContinueOnError: true,
+		MaxExecutions:   4,
+	}
+
+	executor := NewCommandExecutorWithFunction(logger, func(ctx context.Context, args []string) error {
+		return errors.New("api unavailable")
+	})
+	executor.SetBackoffStrategy(&NoBackoff{})
+
+	s := NewIntervalScheduler(cfg, logger)
+	s.SetExecutor(executor)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Without a budget, continue-on-error keeps going until max executions
+	if err := s.Start(ctx, "quakewatch-scraper", []string{"earthquakes", "recent"}); err != nil {
+		t.Fatalf("Expected the run to finish, got %v", err)
+	}
+	if retries := s.GetMetrics().GetRetries(); retries != 12 {
+		t.Errorf("Expected 12 retries, got %d", retries)
+	}
+}
+
+func TestIntervalScheduler_Reload(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	cfg := &config.IntervalConfig{
+		DefaultInterval: time.Hour,
+		ContinueOnError: true,
+		MaxExecutions:   3,
+	}
+
+	executed := make(chan struct{}, 10)
+	executor := NewCommandExecutorWithFunction(logger, func(ctx context.Context, args []string) error {
+		executed <- struct{}{}
+		return nil
+	})
+
+	s := NewIntervalScheduler(cfg, logger)
+	s.SetExecutor(executor)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- s.Start(ctx, "quakewatch-scraper", []string{"earthquakes", "recent"}) }()
+
+	// The initial execution runs at once; the next would be an hour away
+	<-executed
+	if err := s.Reload(&config.IntervalConfig{DefaultInterval: time.Millisecond, ContinueOnError: true, MaxExecutions: 3}); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Reloaded interval was not applied")
+	}
+	if executions := s.GetMetrics().GetExecutions(); executions != 3 {
+		t.Errorf("Expected 3 executions, got %d", executions)
+	}
+	if interval := s.Status().Interval; interval != time.Millisecond {
+		t.Errorf("Status interval = %v, want the reloaded 1ms", interval)
+	}
+
+	if err := s.Reload(&config.IntervalConfig{}); err == nil {
+		t.Error("Expected an error for a zero interval")
+	}
+}
+
+func TestIntervalScheduler_Jitter(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	const bound = 40 * time.Millisecond
+	cfg := &config.IntervalConfig{
+		DefaultInterval: 10 * time.Millisecond,
+		Jitter:          bound,
+		ContinueOnError: true,
+		MaxExecutions:   5,
+	}
+
+	var executions []time.Time
+	executor := NewCommandExecutorWithFunction(logger, func(ctx context.Context, args []string) error {
+		executions = append(executions, time.Now())
+		return nil
+	})
+
+	s := NewIntervalScheduler(cfg, logger)
+	s.SetExecutor(executor)
+
+	// Record each delay drawn, and when it was drawn, around the real jitter source
+	var delays []time.Duration
+	var drawn []time.Time
+	s.jitter = func(max time.Duration) time.Duration {
+		if max != bound {
+			t.Errorf("Jitter bound = %v, want %v", max, bound)
+		}
+		delay := utils.FullJitter(max)
+		delays = append(delays, delay)
+		drawn = append(drawn, time.Now())
+		return delay
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Start(ctx, "quakewatch-scraper", []string{"earthquakes", "recent"}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if len(executions) != 5 || len(delays) != 5 {
+		t.Fatalf("Expected 5 executions each after a jitter delay, got %d executions and %d delays", len(executions), len(delays))
+	}
+	for i, delay := range delays {
+		if delay < 0 || delay >= bound {
+			t.Errorf("Delay %d = %v, want within [0, %v)", i, delay, bound)
+		}
+		if offset := executions[i].Sub(drawn[i]); offset < delay {
+			t.Errorf("Execution %d ran %v after its delay was drawn, want at least %v", i, offset, delay)
+		}
+	}
+}
+
+func TestIntervalScheduler_JitterCancelled(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	cfg := &config.IntervalConfig{DefaultInterval: time.Hour, Jitter: time.Hour}
+
+	executor := NewCommandExecutorWithFunction(logger, func(ctx context.Context, args []string) error {
+		t.Error("Command executed while waiting out the jitter")
+		return nil
+	})
+	s := NewIntervalScheduler(cfg, logger)
+	s.SetExecutor(executor)
+	s.jitter = func(max time.Duration) time.Duration { return max }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Start(ctx, "quakewatch-scraper", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Start() error = %v, want the context deadline", err)
+	}
+	if s.IsRunning() {
+		t.Error("Expected the scheduler to stop")
+	}
+}
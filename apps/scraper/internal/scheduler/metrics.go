@@ -1,17 +1,42 @@
 package scheduler
 
 import (
+	"math"
+	"slices"
 	"sync"
 	"time"
 )
 
+// maxDurationSamples bounds how many recent execution durations are kept for quantiles
+const maxDurationSamples = 1000
+
 // Metrics tracks execution statistics and performance
 type Metrics struct {
 	executions    int64
 	failures      int64
+	retries       int64
 	lastExecution time.Time
 	totalRuntime  time.Duration
-	mu            sync.RWMutex
+	minRuntime    time.Duration
+	maxRuntime    time.Duration
+
+	// durations holds the most recent execution durations as a ring buffer; next is where the
+	// following one goes once it is full
+	durations []time.Duration
+	next      int
+
+	mu sync.RWMutex
+}
+
+// DurationStats summarizes execution durations. Count, Sum, Min and Max cover every execution;
+// the quantiles cover the most recent ones.
+type DurationStats struct {
+	Count int64
+	Sum   time.Duration
+	Min   time.Duration
+	Max   time.Duration
+	P50   time.Duration
+	P95   time.Duration
 }
 
 // NewMetrics creates a new metrics instance
@@ -24,6 +49,19 @@ func (m *Metrics) RecordExecution(duration time.Duration, err error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.executions == 0 || duration < m.minRuntime {
+		m.minRuntime = duration
+	}
+	if duration > m.maxRuntime {
+		m.maxRuntime = duration
+	}
+	if len(m.durations) < maxDurationSamples {
+		m.durations = append(m.durations, duration)
+	} else {
+		m.durations[m.next] = duration
+		m.next = (m.next + 1) % maxDurationSamples
+	}
+
 	m.executions++
 	m.lastExecution = time.Now()
 	m.totalRuntime += duration
@@ -33,6 +71,14 @@ func (m *Metrics) RecordExecution(duration time.Duration, err error) {
 	}
 }
 
+// RecordRetry records a retry of a failed execution
+func (m *Metrics) RecordRetry() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retries++
+}
+
 // GetExecutions returns the total number of executions
 func (m *Metrics) GetExecutions() int64 {
 	m.mu.RLock()
@@ -47,11 +93,18 @@ func (m *Metrics) GetFailures() int64 {
 	return m.failures
 }
 
+// GetRetries returns the total number of retries
+func (m *Metrics) GetRetries() int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.retries
+}
+
 // GetSuccessRate returns the success rate as a percentage
 func (m *Metrics) GetSuccessRate() float64 {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	
+
 	if m.executions == 0 {
 		return 0.0
 	}
@@ -78,7 +131,7 @@ func (m *Metrics) GetTotalRuntime() time.Duration {
 func (m *Metrics) GetAverageRuntime() time.Duration {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	
+
 	if m.executions == 0 {
 		return 0
 	}
@@ -86,6 +139,72 @@ func (m *Metrics) GetAverageRuntime() time.Duration {
 	return m.totalRuntime / time.Duration(m.executions)
 }
 
+// GetDurationStats returns the distribution of execution durations
+func (m *Metrics) GetDurationStats() DurationStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.durationStats()
+}
+
+// durationStats computes the duration distribution; the caller must hold the lock
+func (m *Metrics) durationStats() DurationStats {
+	stats := DurationStats{
+		Count: m.executions,
+		Sum:   m.totalRuntime,
+		Min:   m.minRuntime,
+		Max:   m.maxRuntime,
+	}
+	if len(m.durations) == 0 {
+		return stats
+	}
+
+	sorted := slices.Clone(m.durations)
+	slices.Sort(sorted)
+	stats.P50 = quantile(sorted, 0.50)
+	stats.P95 = quantile(sorted, 0.95)
+	return stats
+}
+
+// quantile returns the q-quantile of sorted durations using the nearest-rank method
+func quantile(sorted []time.Duration, q float64) time.Duration {
+	rank := int(math.Ceil(q * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+// GetAllMetrics returns a snapshot of all metrics keyed by name, suitable for encoding as JSON
+func (m *Metrics) GetAllMetrics() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snapshot := map[string]interface{}{
+		"executions":              m.executions,
+		"failures":                m.failures,
+		"retries":                 m.retries,
+		"success_rate":            0.0,
+		"total_runtime_seconds":   m.totalRuntime.Seconds(),
+		"average_runtime_seconds": 0.0,
+		"last_execution":          nil,
+	}
+	if m.executions > 0 {
+		snapshot["success_rate"] = float64(m.executions-m.failures) / float64(m.executions) * 100.0
+		snapshot["average_runtime_seconds"] = (m.totalRuntime / time.Duration(m.executions)).Seconds()
+	}
+	if !m.lastExecution.IsZero() {
+		snapshot["last_execution"] = m.lastExecution.UTC().Format(time.RFC3339)
+	}
+
+	stats := m.durationStats()
+	snapshot["runtime_seconds"] = map[string]interface{}{
+		"count": stats.Count,
+		"sum":   stats.Sum.Seconds(),
+		"min":   stats.Min.Seconds(),
+		"max":   stats.Max.Seconds(),
+		"p50":   stats.P50.Seconds(),
+		"p95":   stats.P95.Seconds(),
+	}
+	return snapshot
+}
+
 // Reset resets all metrics
 func (m *Metrics) Reset() {
 	m.mu.Lock()
@@ -93,6 +212,11 @@ func (m *Metrics) Reset() {
 
 	m.executions = 0
 	m.failures = 0
+	m.retries = 0
 	m.lastExecution = time.Time{}
 	m.totalRuntime = 0
+	m.minRuntime = 0
+	m.maxRuntime = 0
+	m.durations = nil
+	m.next = 0
 }
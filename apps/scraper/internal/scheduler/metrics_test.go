@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetrics_DurationStats(t *testing.T) {
+	metrics := NewMetrics()
+
+	// 1s..100s in a shuffled order, so the quantiles do not depend on recording order
+	for i := 0; i < 100; i++ {
+		metrics.RecordExecution(time.Duration((i*37)%100+1)*time.Second, nil)
+	}
+
+	stats := metrics.GetDurationStats()
+	want := DurationStats{
+		Count: 100,
+		Sum:   5050 * time.Second,
+		Min:   time.Second,
+		Max:   100 * time.Second,
+		P50:   50 * time.Second,
+		P95:   95 * time.Second,
+	}
+	if stats != want {
+		t.Errorf("GetDurationStats() = %+v, want %+v", stats, want)
+	}
+
+	runtime, ok := metrics.GetAllMetrics()["runtime_seconds"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected runtime_seconds in the snapshot")
+	}
+	if runtime["p95"] != 95.0 || runtime["count"] != int64(100) {
+		t.Errorf("Unexpected runtime_seconds: %v", runtime)
+	}
+}
+
+func TestMetrics_DurationStatsKeepsRecentSamples(t *testing.T) {
+	metrics := NewMetrics()
+
+	// Old slow executions fall out of the quantile window but not out of min and max
+	for i := 0; i < maxDurationSamples; i++ {
+		metrics.RecordExecution(time.Minute, nil)
+	}
+	for i := 0; i < maxDurationSamples; i++ {
+		metrics.RecordExecution(time.Second, nil)
+	}
+
+	stats := metrics.GetDurationStats()
+	if stats.P95 != time.Second || stats.Max != time.Minute || stats.Count != 2*maxDurationSamples {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	metrics.Reset()
+	if stats := metrics.GetDurationStats(); stats != (DurationStats{}) {
+		t.Errorf("Expected empty stats after Reset, got %+v", stats)
+	}
+}
@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// WriteStatus writes the scheduler status to path, replacing the previous file atomically
+func WriteStatus(path string, status *models.IntervalStatus) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create status directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode status: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write status file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace status file: %w", err)
+	}
+
+	return nil
+}
+
+// ReadStatus reads a status file written by WriteStatus
+func ReadStatus(path string) (*models.IntervalStatus, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status file: %w", err)
+	}
+
+	var status models.IntervalStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to decode status file: %w", err)
+	}
+
+	return &status, nil
+}
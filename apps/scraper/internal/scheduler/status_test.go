@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/config"
+)
+
+func TestWriteStatus_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "status.json")
+
+	cfg := &config.IntervalConfig{DefaultInterval: 5 * time.Minute, MaxExecutions: 10, MaxRuntime: time.Hour}
+	s := NewIntervalScheduler(cfg, log.New(io.Discard, "", 0))
+	s.command = "quakewatch-scraper earthquakes recent --limit 50"
+	s.startTime = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.metrics.RecordExecution(2*time.Second, nil)
+	s.metrics.RecordExecution(4*time.Second, errors.New("boom"))
+	s.nextExecution = s.startTime.Add(5 * time.Minute)
+
+	want := s.status(true)
+	if err := WriteStatus(path, want); err != nil {
+		t.Fatalf("WriteStatus failed: %v", err)
+	}
+
+	got, err := ReadStatus(path)
+	if err != nil {
+		t.Fatalf("ReadStatus failed: %v", err)
+	}
+
+	if got.Executions != 2 || got.Failures != 1 {
+		t.Errorf("Expected 2 executions and 1 failure, got %d and %d", got.Executions, got.Failures)
+	}
+	if got.SuccessRate != 50 {
+		t.Errorf("Expected success rate 50, got %v", got.SuccessRate)
+	}
+	if got.AverageRuntime != 3*time.Second || got.TotalRuntime != 6*time.Second {
+		t.Errorf("Expected runtimes 6s/3s, got %v/%v", got.TotalRuntime, got.AverageRuntime)
+	}
+	if !got.StartTime.Equal(want.StartTime) || !got.LastExecution.Equal(want.LastExecution) {
+		t.Errorf("Expected times %v/%v, got %v/%v", want.StartTime, want.LastExecution, got.StartTime, got.LastExecution)
+	}
+	if !got.IsRunning || !got.NextExecution.Equal(s.nextExecution) {
+		t.Errorf("Expected a running status with next execution %v, got %v (running %v)", s.nextExecution, got.NextExecution, got.IsRunning)
+	}
+	if got.Command != want.Command || got.Interval != cfg.DefaultInterval || got.MaxExecutions != 10 || got.MaxRuntime != time.Hour {
+		t.Errorf("Unexpected configuration fields: %+v", got)
+	}
+
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected temporary file to be renamed away, stat error: %v", err)
+	}
+}
+
+func TestReadStatus_Missing(t *testing.T) {
+	if _, err := ReadStatus(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing status file")
+	}
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/storage"
+)
+
+// EarthquakeSource is the storage backend the API reads earthquakes from
+type EarthquakeSource interface {
+	QueryEarthquakes(ctx context.Context, query storage.EarthquakeQuery) ([]models.Earthquake, error)
+}
+
+// Handler serves collected data as JSON:
+//
+//	GET /earthquakes?min_mag=&max_mag=&start=&end=&bbox=&limit=
+//
+// returns a GeoJSON FeatureCollection of the matching earthquakes, most recent first
+type Handler struct {
+	source       EarthquakeSource
+	defaultLimit int
+	maxLimit     int
+	mux          *http.ServeMux
+}
+
+// NewHandler creates a handler reading from source. Requests without a limit return at most
+// defaultLimit earthquakes, and no request returns more than maxLimit.
+func NewHandler(source EarthquakeSource, defaultLimit, maxLimit int) *Handler {
+	h := &Handler{
+		source:       source,
+		defaultLimit: defaultLimit,
+		maxLimit:     maxLimit,
+		mux:          http.NewServeMux(),
+	}
+	h.mux.HandleFunc("GET /earthquakes", h.handleEarthquakes)
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// handleEarthquakes answers GET /earthquakes
+func (h *Handler) handleEarthquakes(w http.ResponseWriter, r *http.Request) {
+	query, err := h.parseEarthquakeQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	earthquakes, err := h.source.QueryEarthquakes(r.Context(), query)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to query earthquakes: %w", err))
+		return
+	}
+	if earthquakes == nil {
+		earthquakes = []models.Earthquake{}
+	}
+
+	writeJSON(w, http.StatusOK, "application/geo+json", &models.USGSResponse{
+		Type: "FeatureCollection",
+		Metadata: models.Metadata{
+			Generated: time.Now().UnixMilli(),
+			URL:       r.URL.String(),
+			Title:     "QuakeWatch earthquakes",
+			Status:    http.StatusOK,
+			Count:     len(earthquakes),
+		},
+		Features: earthquakes,
+	})
+}
+
+// parseEarthquakeQuery reads and validates the /earthquakes query parameters
+func (h *Handler) parseEarthquakeQuery(r *http.Request) (storage.EarthquakeQuery, error) {
+	params := r.URL.Query()
+	query := storage.EarthquakeQuery{Limit: h.defaultLimit}
+
+	for name, target := range map[string]**float64{"min_mag": &query.MinMagnitude, "max_mag": &query.MaxMagnitude} {
+		if value := params.Get(name); value != "" {
+			mag, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s: %s", name, value)
+			}
+			*target = &mag
+		}
+	}
+	if query.MinMagnitude != nil && query.MaxMagnitude != nil && *query.MinMagnitude > *query.MaxMagnitude {
+		return query, fmt.Errorf("min_mag must not exceed max_mag")
+	}
+
+	var err error
+	if query.StartTime, err = parseTime(params.Get("start")); err != nil {
+		return query, fmt.Errorf("invalid start: %w", err)
+	}
+	if query.EndTime, err = parseTime(params.Get("end")); err != nil {
+		return query, fmt.Errorf("invalid end: %w", err)
+	}
+	if !query.StartTime.IsZero() && !query.EndTime.IsZero() && !query.EndTime.After(query.StartTime) {
+		return query, fmt.Errorf("end must be after start")
+	}
+
+	if value := params.Get("bbox"); value != "" {
+		bbox, err := parseBBox(value)
+		if err != nil {
+			return query, err
+		}
+		query.BBox = bbox
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return query, fmt.Errorf("invalid limit: %s (must be a positive integer)", value)
+		}
+		query.Limit = limit
+	}
+	if h.maxLimit > 0 && (query.Limit == 0 || query.Limit > h.maxLimit) {
+		query.Limit = h.maxLimit
+	}
+	return query, nil
+}
+
+// parseTime parses an RFC 3339 time or a YYYY-MM-DD date; an empty value is the zero time
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s (use YYYY-MM-DD or RFC 3339)", value)
+	}
+	return t, nil
+}
+
+// parseBBox parses a bounding box given as minLon,minLat,maxLon,maxLat
+func parseBBox(value string) (*storage.BoundingBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid bbox: %s (must be minLon,minLat,maxLon,maxLat)", value)
+	}
+
+	var coords [4]float64
+	for i, part := range parts {
+		coord, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bbox: %s (must be minLon,minLat,maxLon,maxLat)", value)
+		}
+		coords[i] = coord
+	}
+
+	bbox := &storage.BoundingBox{MinLon: coords[0], MinLat: coords[1], MaxLon: coords[2], MaxLat: coords[3]}
+	if err := bbox.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bbox: %w", err)
+	}
+	return bbox, nil
+}
+
+// writeError writes an error as a JSON object with an "error" member
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, "application/json", map[string]string{"error": err.Error()})
+}
+
+// writeJSON writes v as the response body with the given status and content type
+func writeJSON(w http.ResponseWriter, status int, contentType string, v interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/storage"
+)
+
+// fakeSource records the last query and returns fixed earthquakes or an error
+type fakeSource struct {
+	query       storage.EarthquakeQuery
+	earthquakes []models.Earthquake
+	err         error
+}
+
+func (f *fakeSource) QueryEarthquakes(ctx context.Context, query storage.EarthquakeQuery) ([]models.Earthquake, error) {
+	f.query = query
+	return f.earthquakes, f.err
+}
+
+// earthquake builds an earthquake at a point with a magnitude and time
+func earthquake(id string, mag float64, t time.Time, lon, lat float64) models.Earthquake {
+	return models.Earthquake{
+		Type:       "Feature",
+		ID:         id,
+		Properties: models.EarthquakeProperties{Mag: models.Float64(mag), Time: t.UnixMilli()},
+		Geometry:   models.Geometry{Type: "Point", Coordinates: []float64{lon, lat, 10}},
+	}
+}
+
+func TestHandler_Earthquakes(t *testing.T) {
+	outputDir := t.TempDir()
+	jsonStorage := storage.NewJSONStorage(outputDir)
+	day := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	err := jsonStorage.SaveEarthquakes(&models.USGSResponse{
+		Type: "FeatureCollection",
+		Features: []models.Earthquake{
+			earthquake("ca-small", 2.0, day, -122, 37),
+			earthquake("ca-large", 5.0, day.Add(time.Hour), -121, 36),
+			earthquake("jp-large", 6.0, day.Add(2*time.Hour), 142, 38),
+			earthquake("ca-old", 5.5, day.AddDate(0, -1, 0), -120, 35),
+		},
+	}, "sample")
+	if err != nil {
+		t.Fatalf("SaveEarthquakes() error = %v", err)
+	}
+	handler := NewHandler(jsonStorage, 1000, 10000)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"all, most recent first", "", []string{"jp-large", "ca-large", "ca-small", "ca-old"}},
+		{"min magnitude", "?min_mag=5", []string{"jp-large", "ca-large", "ca-old"}},
+		{"time range", "?start=2024-01-01&end=2024-02-01", []string{"jp-large", "ca-large", "ca-small"}},
+		{"bounding box", "?bbox=-125,32,-114,42", []string{"ca-large", "ca-small", "ca-old"}},
+		{"combined", "?min_mag=4&start=2024-01-01T00:00:00Z&bbox=-125,32,-114,42", []string{"ca-large"}},
+		{"limit", "?limit=1", []string{"jp-large"}},
+		{"no matches", "?min_mag=9", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/earthquakes"+tt.query, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Status = %d, body %s", rec.Code, rec.Body)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/geo+json" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+
+			var response models.USGSResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Type != "FeatureCollection" || response.Metadata.Count != len(tt.want) || response.Features == nil {
+				t.Errorf("Unexpected collection: type %q, count %d", response.Type, response.Metadata.Count)
+			}
+			var got []string
+			for _, eq := range response.Features {
+				got = append(got, eq.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Got %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_EarthquakesInvalidParameters(t *testing.T) {
+	source := &fakeSource{}
+	handler := NewHandler(source, 1000, 10000)
+
+	for _, query := range []string{
+		"?min_mag=big",
+		"?min_mag=5&max_mag=4",
+		"?start=yesterday",
+		"?start=2024-02-01&end=2024-01-01",
+		"?bbox=1,2,3",
+		"?bbox=-200,0,0,10",
+		"?bbox=10,0,0,10",
+		"?limit=0",
+		"?limit=ten",
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/earthquakes"+query, nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+			continue
+		}
+		var body map[string]string
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body["error"] == "" {
+			t.Errorf("%s: expected a JSON error, got %v (%v)", query, body, err)
+		}
+	}
+}
+
+func TestHandler_EarthquakesLimits(t *testing.T) {
+	source := &fakeSource{}
+	handler := NewHandler(source, 100, 500)
+
+	for query, want := range map[string]int{"": 100, "?limit=20": 20, "?limit=100000": 500} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/earthquakes"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: status = %d", query, rec.Code)
+		}
+		if source.query.Limit != want {
+			t.Errorf("%q: limit = %d, want %d", query, source.query.Limit, want)
+		}
+	}
+}
+
+func TestHandler_EarthquakesErrors(t *testing.T) {
+	handler := NewHandler(&fakeSource{err: errors.New("connection refused")}, 1000, 10000)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/earthquakes", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Status = %d, want 500", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/earthquakes", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", rec.Code)
+	}
+}
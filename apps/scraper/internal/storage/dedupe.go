@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// DedupeResult describes the consolidation of the files of one data type into a single file
+type DedupeResult struct {
+	Filename string   // the consolidated file, relative to the data type's directory
+	Files    []string // the files that were merged
+	Records  int      // records read from the merged files
+	Unique   int      // records in the consolidated file
+	Removed  []string // merged files deleted afterwards
+}
+
+// Dedupe merges every file of a data type into one file holding each record once: the most
+// recently updated revision of an earthquake, or the copy of a fault from the newest file. An
+// empty filename names the file after the data type and the current time. With removeOriginals,
+// the merged files and their manifests are deleted once the consolidated file is written.
+func (s *JSONStorage) Dedupe(ctx context.Context, dataType, filename string, removeOriginals bool) (*DedupeResult, error) {
+	files, err := s.ListFiles(dataType)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s files to deduplicate", dataType)
+	}
+
+	if filename == "" {
+		filename = fmt.Sprintf("%s_deduped_%s", dataType, time.Now().Format(filenameTimestampLayout))
+	}
+	// Resolve the name once so the file checked is the file written, whatever the layout and format.
+	// Writing over one of the inputs would lose it if the originals are then removed.
+	name := s.saveFilename(dataType, filename)
+	if _, err := os.Stat(filepath.Join(s.outputDir, dataType, name)); err == nil {
+		return nil, fmt.Errorf("file %s already exists", name)
+	}
+
+	result := &DedupeResult{Filename: name, Files: files}
+	query := map[string]string{"query": "dedupe", "files": strconv.Itoa(len(files))}
+	switch dataType {
+	case "earthquakes":
+		merged, records, err := s.mergeEarthquakeFiles(ctx, files)
+		if err != nil {
+			return nil, err
+		}
+		result.Records, result.Unique = records, len(merged.Features)
+		err = s.saveEarthquakesAs(merged, name, query)
+		if err != nil {
+			return nil, err
+		}
+	case "faults":
+		merged, records, err := s.mergeFaultFiles(ctx, files)
+		if err != nil {
+			return nil, err
+		}
+		result.Records, result.Unique = records, len(merged.Features)
+		err = s.saveJSONAs("faults", name, merged, len(merged.Features), query, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown data type: %s", dataType)
+	}
+
+	if !removeOriginals {
+		return result, nil
+	}
+	for _, file := range files {
+		if err := removeDataFile(filepath.Join(s.outputDir, dataType, file)); err != nil {
+			return result, fmt.Errorf("failed to remove %s file %s: %w", dataType, file, err)
+		}
+		result.Removed = append(result.Removed, file)
+	}
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// revision builds an earthquake revision with the given update time and magnitude
+func revision(id string, updated int64, mag float64) models.Earthquake {
+	return models.Earthquake{
+		Type:       "Feature",
+		ID:         id,
+		Properties: models.EarthquakeProperties{Mag: models.Float64(mag), Updated: updated},
+		Geometry:   models.Geometry{Type: "Point", Coordinates: []float64{1, 2, 3}},
+	}
+}
+
+func TestJSONStorage_Dedupe(t *testing.T) {
+	outputDir := t.TempDir()
+	storage := NewJSONStorage(outputDir)
+
+	files := map[string][]models.Earthquake{
+		"earthquakes_2024-01-01_00-00-00": {revision("us1", 100, 4.0), revision("us2", 100, 3.0)},
+		"earthquakes_2024-01-02_00-00-00": {revision("us2", 300, 3.4), revision("us3", 100, 5.0)},
+		// An older copy in a newer file must not replace the revised one
+		"earthquakes_2024-01-03_00-00-00": {revision("us1", 200, 4.2), revision("us2", 200, 3.2)},
+	}
+	for name, features := range files {
+		if err := storage.SaveEarthquakes(&models.USGSResponse{Type: "FeatureCollection", Features: features}, name); err != nil {
+			t.Fatalf("SaveEarthquakes() error = %v", err)
+		}
+	}
+
+	result, err := storage.Dedupe(context.Background(), "earthquakes", "consolidated", true)
+	if err != nil {
+		t.Fatalf("Dedupe() error = %v", err)
+	}
+	if len(result.Files) != 3 || result.Records != 6 || result.Unique != 3 || len(result.Removed) != 3 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	remaining, err := storage.ListFiles("earthquakes")
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(remaining) != 1 || remaining[0] != "consolidated.json" {
+		t.Fatalf("Expected only the consolidated file to remain, got %v", remaining)
+	}
+
+	consolidated, err := storage.LoadEarthquakes("consolidated")
+	if err != nil {
+		t.Fatalf("LoadEarthquakes() error = %v", err)
+	}
+	want := map[string]float64{"us1": 4.2, "us2": 3.4, "us3": 5.0}
+	for _, eq := range consolidated.Features {
+		if mag, _ := eq.Properties.Magnitude(); mag != want[eq.ID] {
+			t.Errorf("%s has magnitude %v, want the newest revision's %v", eq.ID, mag, want[eq.ID])
+		}
+		delete(want, eq.ID)
+	}
+	if len(want) > 0 {
+		t.Errorf("Missing earthquakes: %v", want)
+	}
+
+	// The consolidated file is never written over an existing one
+	if _, err := storage.Dedupe(context.Background(), "earthquakes", "consolidated", true); err == nil {
+		t.Error("Expected an error when the consolidated file already exists")
+	}
+}
+
+func TestJSONStorage_DedupeExistingTarget(t *testing.T) {
+	earthquakes := &models.USGSResponse{Type: "FeatureCollection", Features: []models.Earthquake{revision("us1", 100, 4.0)}}
+	today := filepath.FromSlash(time.Now().Format(dateLayoutDirs))
+
+	tests := []struct {
+		name     string
+		setup    func(storage *JSONStorage)
+		existing string // a file named like the consolidated one, but elsewhere or in another format
+		want     string
+	}{
+		{
+			name:     "Date layout, same name on another day",
+			setup:    func(storage *JSONStorage) { storage.SetLayout(LayoutDate) },
+			existing: filepath.Join("2020", "01", "01", "consolidated.json"),
+			want:     filepath.Join(today, "consolidated.json"),
+		},
+		{
+			name:     "NDJSON, same name as JSON",
+			setup:    func(storage *JSONStorage) { storage.SetFormat(FormatNDJSON) },
+			existing: "consolidated.json",
+			want:     "consolidated.ndjson",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			path := filepath.Join(outputDir, "earthquakes", tt.existing)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("MkdirAll() error = %v", err)
+			}
+			data, _ := json.Marshal(earthquakes)
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			storage := NewJSONStorage(outputDir)
+			tt.setup(storage)
+
+			// The check looks at the file that is about to be written, not at any file of that name
+			result, err := storage.Dedupe(context.Background(), "earthquakes", "consolidated", false)
+			if err != nil {
+				t.Fatalf("Dedupe() error = %v", err)
+			}
+			if result.Filename != tt.want {
+				t.Errorf("Filename = %q, want %q", result.Filename, tt.want)
+			}
+			if _, err := os.Stat(filepath.Join(outputDir, "earthquakes", tt.want)); err != nil {
+				t.Errorf("Expected the consolidated file: %v", err)
+			}
+
+			// Once written, it is never written over
+			if _, err := storage.Dedupe(context.Background(), "earthquakes", "consolidated", false); err == nil {
+				t.Error("Expected an error when the consolidated file already exists")
+			}
+		})
+	}
+}
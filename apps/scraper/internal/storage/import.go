@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// ImportResult summarizes an import of JSON data files into the database
+type ImportResult struct {
+	Files    int
+	Inserted int
+	Updated  int
+}
+
+// ImportJSON loads every JSON data file of a data type from source and upserts its records into the database.
+// Files are imported one transaction each, so a failure leaves earlier files imported.
+func (s *PostgreSQLStorage) ImportJSON(ctx context.Context, source *JSONStorage, dataType string) (*ImportResult, error) {
+	files, err := source.ListFiles(dataType)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	for _, filename := range files {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		var inserted, updated int
+		switch dataType {
+		case "earthquakes":
+			earthquakes, err := source.LoadEarthquakes(filename)
+			if err != nil {
+				return result, fmt.Errorf("failed to load %s: %w", filename, err)
+			}
+			inserted, updated, err = s.UpsertEarthquakes(ctx, earthquakes)
+			if err != nil {
+				return result, fmt.Errorf("failed to import %s: %w", filename, err)
+			}
+		case "faults":
+			faults, err := source.LoadFaults(filename)
+			if err != nil {
+				return result, fmt.Errorf("failed to load %s: %w", filename, err)
+			}
+			inserted, updated, err = s.UpsertFaults(ctx, faults)
+			if err != nil {
+				return result, fmt.Errorf("failed to import %s: %w", filename, err)
+			}
+		}
+
+		result.Files++
+		result.Inserted += inserted
+		result.Updated += updated
+	}
+
+	return result, nil
+}
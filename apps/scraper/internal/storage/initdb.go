@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"quakewatch-scraper/internal/config"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// MaintenanceDatabase is the database connected to while the configured database is created or dropped
+const MaintenanceDatabase = "postgres"
+
+// duplicateDatabase is the PostgreSQL error code for CREATE DATABASE on a name that already exists
+const duplicateDatabase = "42P04"
+
+// EnsureDatabase creates the configured database if it does not exist, connecting to the
+// maintenance database to do so. With recreate, an existing database is dropped first, which
+// fails while other sessions are connected to it. It reports whether the database was created.
+func EnsureDatabase(ctx context.Context, cfg *config.DatabaseConfig, recreate bool) (bool, error) {
+	if err := cfg.Validate(); err != nil {
+		return false, fmt.Errorf("invalid database config: %w", err)
+	}
+
+	db, err := openMaintenanceDatabase(cfg)
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	var exists bool
+	if err := db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)`, cfg.Database); err != nil {
+		return false, fmt.Errorf("failed to check for database %s: %w", cfg.Database, err)
+	}
+
+	if exists && !recreate {
+		return false, nil
+	}
+	if exists {
+		if _, err := db.ExecContext(ctx, "DROP DATABASE "+pq.QuoteIdentifier(cfg.Database)); err != nil {
+			return false, fmt.Errorf("failed to drop database %s: %w", cfg.Database, err)
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, "CREATE DATABASE "+pq.QuoteIdentifier(cfg.Database)); err != nil {
+		// Another process created it between the check and the CREATE
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == duplicateDatabase {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create database %s: %w", cfg.Database, err)
+	}
+	return true, nil
+}
+
+// openMaintenanceDatabase connects to the maintenance database with the configured credentials
+func openMaintenanceDatabase(cfg *config.DatabaseConfig) (*sqlx.DB, error) {
+	maintenance := *cfg
+	maintenance.Database = MaintenanceDatabase
+
+	db, err := sqlx.Connect("postgres", maintenance.GetDSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to maintenance database %s: %w", MaintenanceDatabase, err)
+	}
+	return db, nil
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"quakewatch-scraper/internal/config"
+
+	"github.com/lib/pq"
+)
+
+func TestEnsureDatabase_InvalidConfig(t *testing.T) {
+	if _, err := EnsureDatabase(context.Background(), &config.DatabaseConfig{Host: "localhost", Port: 5432, User: "postgres"}, false); err == nil {
+		t.Error("Expected an error for a config without a database name")
+	}
+}
+
+func TestEnsureDatabase_Integration(t *testing.T) {
+	// Skip if not running integration tests
+	if os.Getenv("INTEGRATION_TESTS") != "true" {
+		t.Skip("Skipping integration test. Set INTEGRATION_TESTS=true to run")
+	}
+
+	ctx := context.Background()
+	cfg := &config.DatabaseConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "postgres",
+		Password: "postgres",
+		Database: "quakewatch_init_test",
+		SSLMode:  "disable",
+	}
+
+	maintenance, err := openMaintenanceDatabase(cfg)
+	if err != nil {
+		t.Fatalf("Failed to connect to maintenance database: %v", err)
+	}
+	defer maintenance.Close()
+	dropTestDatabase := func() {
+		if _, err := maintenance.Exec("DROP DATABASE IF EXISTS " + pq.QuoteIdentifier(cfg.Database)); err != nil {
+			t.Fatalf("Failed to drop test database: %v", err)
+		}
+	}
+	dropTestDatabase()
+	t.Cleanup(dropTestDatabase)
+
+	// Missing: the database is created
+	created, err := EnsureDatabase(ctx, cfg, false)
+	if err != nil || !created {
+		t.Fatalf("EnsureDatabase() = %t, %v, want the missing database created", created, err)
+	}
+
+	migrateUp := func() {
+		t.Helper()
+		manager, err := NewMigrationManager(cfg)
+		if err != nil {
+			t.Fatalf("Failed to create migration manager: %v", err)
+		}
+		defer manager.Close()
+		if err := manager.MigrateUp(); err != nil {
+			t.Fatalf("Failed to run migrations: %v", err)
+		}
+	}
+	migrateUp()
+
+	// Initialized: nothing changes and the data survives
+	db, err := OpenDatabase(cfg)
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO collection_logs (source, data_type, start_time, status) VALUES ('test', 'earthquakes', NOW(), 'success')`); err != nil {
+		t.Fatalf("Failed to insert collection log: %v", err)
+	}
+	db.Close()
+
+	created, err = EnsureDatabase(ctx, cfg, false)
+	if err != nil || created {
+		t.Fatalf("EnsureDatabase() = %t, %v, want the existing database kept", created, err)
+	}
+	migrateUp()
+
+	countLogs := func() int {
+		t.Helper()
+		db, err := OpenDatabase(cfg)
+		if err != nil {
+			t.Fatalf("Failed to open test database: %v", err)
+		}
+		defer db.Close()
+		var count int
+		if err := db.Get(&count, "SELECT COUNT(*) FROM collection_logs"); err != nil {
+			t.Fatalf("Failed to count collection logs: %v", err)
+		}
+		return count
+	}
+	if count := countLogs(); count != 1 {
+		t.Errorf("Expected the collection log to survive a repeated init, got %d", count)
+	}
+
+	// Recreate: the database is dropped and created empty
+	created, err = EnsureDatabase(ctx, cfg, true)
+	if err != nil || !created {
+		t.Fatalf("EnsureDatabase(recreate) = %t, %v, want the database recreated", created, err)
+	}
+	migrateUp()
+	if count := countLogs(); count != 0 {
+		t.Errorf("Expected an empty database after recreating, got %d collection logs", count)
+	}
+}
@@ -11,6 +11,7 @@ type Storage interface {
 	SaveEarthquakes(ctx context.Context, earthquakes *models.USGSResponse) error
 	LoadEarthquakes(ctx context.Context, limit int, offset int) (*models.USGSResponse, error)
 	GetEarthquakeByID(ctx context.Context, usgsID string) (*models.Earthquake, error)
+	QueryEarthquakes(ctx context.Context, query EarthquakeQuery) ([]models.Earthquake, error)
 	GetEarthquakesByTimeRange(ctx context.Context, startTime, endTime int64) ([]models.Earthquake, error)
 	GetEarthquakesByMagnitudeRange(ctx context.Context, minMag, maxMag float64) ([]models.Earthquake, error)
 	GetEarthquakesByLocation(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]models.Earthquake, error)
@@ -1,93 +1,344 @@
 package storage
 
 import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/utils"
 )
 
+// gzipExtension is appended to the names of compressed data files
+const gzipExtension = ".gz"
+
+// Layout controls how data files are arranged inside each data type directory
+type Layout string
+
+const (
+	// LayoutFlat keeps all files directly in the data type directory
+	LayoutFlat Layout = "flat"
+	// LayoutDate nests files under year/month/day directories of their collection date
+	LayoutDate Layout = "date"
+)
+
+// Format is how records are encoded in a data file
+type Format string
+
+const (
+	// FormatJSON writes each file as a single JSON document
+	FormatJSON Format = "json"
+	// FormatNDJSON writes one compact JSON object per line: each feature of a collection, or each
+	// record of a field selection
+	FormatNDJSON Format = "ndjson"
+)
+
+// dataExtensions lists the extensions of data files, compressed or not
+var dataExtensions = []string{".json", ".json" + gzipExtension, ".ndjson", ".ndjson" + gzipExtension}
+
+// ParseFormat parses a data file format name; an empty name is JSON
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatNDJSON:
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("invalid format: %s (must be json or ndjson)", name)
+	}
+}
+
+// extension returns the extension of uncompressed files in the format
+func (f Format) extension() string {
+	if f == FormatNDJSON {
+		return ".ndjson"
+	}
+	return ".json"
+}
+
+// dateLayoutDirs is the directory structure used by LayoutDate
+const dateLayoutDirs = "2006/01/02"
+
+// ParseLayout parses a storage layout name; an empty name is the flat layout
+func ParseLayout(name string) (Layout, error) {
+	switch Layout(name) {
+	case "", LayoutFlat:
+		return LayoutFlat, nil
+	case LayoutDate:
+		return LayoutDate, nil
+	default:
+		return "", fmt.Errorf("invalid storage layout: %s (must be flat or date)", name)
+	}
+}
+
 // JSONStorage handles saving data to JSON files
 type JSONStorage struct {
 	outputDir string
+	layout    Layout
+	compress  bool
+	compact   bool
+	format    Format
+
+	// toolVersion is recorded in the collection_info of saved earthquake files; empty leaves it out
+	toolVersion string
+
+	// createFile opens data files for writing; writeRetryDelay is the first pause before
+	// retrying a write that failed transiently
+	createFile      func(name string) (io.WriteCloser, error)
+	writeRetryDelay time.Duration
 }
 
 // NewJSONStorage creates a new JSON storage instance
 func NewJSONStorage(outputDir string) *JSONStorage {
 	return &JSONStorage{
-		outputDir: outputDir,
+		outputDir:       outputDir,
+		layout:          LayoutFlat,
+		format:          FormatJSON,
+		createFile:      createOSFile,
+		writeRetryDelay: defaultWriteRetryDelay,
 	}
 }
 
+// SetLayout sets how new files are arranged. With LayoutDate, listing and loading also
+// search the nested date directories.
+func (s *JSONStorage) SetLayout(layout Layout) {
+	s.layout = layout
+}
+
+// SetCompression makes new files be written gzip-compressed as .json.gz
+func (s *JSONStorage) SetCompression(compress bool) {
+	s.compress = compress
+}
+
+// SetCompact makes new files be written as minified JSON instead of indented JSON
+func (s *JSONStorage) SetCompact(compact bool) {
+	s.compact = compact
+}
+
+// SetFormat sets how new files are encoded. NDJSON files are named .ndjson and are read back
+// by the same loaders as JSON files.
+func (s *JSONStorage) SetFormat(format Format) {
+	s.format = format
+}
+
+// SetCollectionInfo makes saved earthquake collections embed a collection_info object with the
+// query, source URL, collection time and the given tool version. NDJSON files have no envelope
+// to hold it, so they only record the query in their manifest.
+func (s *JSONStorage) SetCollectionInfo(toolVersion string) {
+	s.toolVersion = toolVersion
+}
+
 // SaveEarthquakes saves earthquake data to a JSON file
 func (s *JSONStorage) SaveEarthquakes(earthquakes *models.USGSResponse, filename string) error {
+	return s.SaveEarthquakesWithQuery(earthquakes, filename, nil)
+}
+
+// SaveEarthquakesWithQuery saves earthquake data to a JSON file, recording the query that
+// produced it in the file's manifest
+func (s *JSONStorage) SaveEarthquakesWithQuery(earthquakes *models.USGSResponse, filename string, query map[string]string) error {
+	return s.saveEarthquakesAs(earthquakes, s.saveFilename("earthquakes", filename), query)
+}
+
+// saveEarthquakesAs saves earthquake data under name, a path inside the earthquakes directory as
+// returned by saveFilename
+func (s *JSONStorage) saveEarthquakesAs(earthquakes *models.USGSResponse, name string, query map[string]string) error {
+	collectedAt := time.Now().UTC()
+	if s.toolVersion != "" {
+		// Copy the envelope so the caller's response is left as it was
+		withInfo := *earthquakes
+		withInfo.CollectionInfo = &models.CollectionInfo{
+			Query:       query,
+			SourceURL:   earthquakes.Metadata.URL,
+			CollectedAt: collectedAt,
+			ToolVersion: s.toolVersion,
+		}
+		earthquakes = &withInfo
+	}
+	return s.saveJSONAs("earthquakes", name, earthquakes, len(earthquakes.Features), query, collectedAt)
+}
+
+// SaveEarthquakeRecords saves earthquakes reduced to selected fields as a JSON array of objects,
+// recording the query that produced them in the file's manifest
+func (s *JSONStorage) SaveEarthquakeRecords(records []map[string]interface{}, filename string, query map[string]string) error {
+	return s.saveJSON("earthquakes", filename, records, len(records), query)
+}
+
+// SaveFaults saves fault data to a JSON file
+func (s *JSONStorage) SaveFaults(faults *models.Fault, filename string) error {
+	return s.saveJSON("faults", filename, faults, len(faults.Features), nil)
+}
+
+// SaveEventDetail saves the detail GeoJSON of a single event to a JSON file in the events directory
+func (s *JSONStorage) SaveEventDetail(detail *models.EventDetail, filename string) error {
+	return s.saveJSON("events", filename, detail, 1, map[string]string{"eventid": detail.ID})
+}
+
+// saveFilename returns the name a data file is saved under, generating a timestamped one if filename is empty
+func (s *JSONStorage) saveFilename(dataType, filename string) string {
+	extension := s.format.extension()
+	filename = strings.TrimSuffix(filename, gzipExtension)
 	if filename == "" {
-		timestamp := time.Now().Format("2006-01-02_15-04-05")
-		filename = fmt.Sprintf("earthquakes_%s.json", timestamp)
-	} else if !strings.HasSuffix(filename, ".json") {
-		filename += ".json"
+		timestamp := time.Now().Format(filenameTimestampLayout)
+		filename = fmt.Sprintf("%s_%s%s", dataType, timestamp, extension)
+	} else if !strings.HasSuffix(filename, extension) {
+		filename += extension
 	}
 
-	filePath := filepath.Join(s.outputDir, "earthquakes", filename)
+	if s.compress {
+		filename += gzipExtension
+	}
+	if s.layout == LayoutDate {
+		filename = filepath.Join(filepath.FromSlash(time.Now().Format(dateLayoutDirs)), filename)
+	}
+	return filename
+}
+
+// saveJSON encodes data as JSON into a file of the given data type, indenting it unless compact
+// output is enabled and compressing it if enabled, and writes a manifest with the file's hash,
+// record count and query next to it
+func (s *JSONStorage) saveJSON(dataType, filename string, data interface{}, records int, query map[string]string) error {
+	return s.saveJSONAt(dataType, filename, data, records, query, time.Now())
+}
+
+// saveJSONAt is saveJSON recording collectedAt as the collection time in the manifest.
+// Failures are storage collection errors.
+func (s *JSONStorage) saveJSONAt(dataType, filename string, data interface{}, records int, query map[string]string, collectedAt time.Time) error {
+	return s.saveJSONAs(dataType, s.saveFilename(dataType, filename), data, records, query, collectedAt)
+}
+
+// saveJSONAs is saveJSONAt writing to name, a path inside the data type's directory as returned
+// by saveFilename
+func (s *JSONStorage) saveJSONAs(dataType, name string, data interface{}, records int, query map[string]string, collectedAt time.Time) error {
+	if err := s.writeDataFile(filepath.Join(s.outputDir, dataType, name), data, records, query, collectedAt); err != nil {
+		return utils.NewCollectionError(utils.ErrorTypeStorage, false, err)
+	}
+	return nil
+}
+
+// writeDataFile writes a data file and its manifest
+func (s *JSONStorage) writeDataFile(filePath string, data interface{}, records int, query map[string]string, collectedAt time.Time) error {
 
 	// Ensure directory exists
 	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	file, err := os.Create(filePath)
+	var sum string
+	err := s.retryWrite(func() error {
+		var err error
+		sum, err = s.writeJSONFile(filePath, data)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return err
 	}
-	defer file.Close()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
+	return writeManifest(filePath, Manifest{
+		File:        filepath.Base(filePath),
+		SHA256:      sum,
+		Records:     records,
+		Query:       query,
+		CollectedAt: collectedAt.UTC(),
+	})
+}
 
-	if err := encoder.Encode(earthquakes); err != nil {
-		return fmt.Errorf("failed to encode JSON: %w", err)
+// writeJSONFile encodes data into a new file at filePath and returns the SHA-256 of the bytes
+// written, i.e. after compression. The data is written to a temporary file that replaces filePath
+// only once it is complete, so a failed or interrupted write never leaves a partial data file.
+func (s *JSONStorage) writeJSONFile(filePath string, data interface{}) (string, error) {
+	tmpPath := filePath + tempExtension
+	sum, err := s.writeTempFile(tmpPath, data)
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", err
 	}
-
-	return nil
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to replace file: %w", err)
+	}
+	return sum, nil
 }
 
-// SaveFaults saves fault data to a JSON file
-func (s *JSONStorage) SaveFaults(faults *models.Fault, filename string) error {
-	if filename == "" {
-		timestamp := time.Now().Format("2006-01-02_15-04-05")
-		filename = fmt.Sprintf("faults_%s.json", timestamp)
-	} else if !strings.HasSuffix(filename, ".json") {
-		filename += ".json"
+// writeTempFile encodes data into tmpPath and returns the SHA-256 of the bytes written
+func (s *JSONStorage) writeTempFile(tmpPath string, data interface{}) (string, error) {
+	file, err := s.createFile(tmpPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
 	}
+	defer file.Close()
 
-	filePath := filepath.Join(s.outputDir, "faults", filename)
+	hash := sha256.New()
+	var w io.Writer = io.MultiWriter(file, hash)
+	var gz *gzip.Writer
+	if s.compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+	if s.format == FormatNDJSON {
+		if err := WriteNDJSON(w, data); err != nil {
+			return "", err
+		}
+	} else {
+		encoder := json.NewEncoder(w)
+		if !s.compact {
+			encoder.SetIndent("", "  ")
+		}
+		if err := encoder.Encode(data); err != nil {
+			return "", fmt.Errorf("failed to encode JSON: %w", err)
+		}
 	}
 
-	file, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return "", fmt.Errorf("failed to compress file: %w", err)
+		}
+	}
+
+	// Network filesystems may only report write errors on close
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
 	}
-	defer file.Close()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
 
-	if err := encoder.Encode(faults); err != nil {
-		return fmt.Errorf("failed to encode JSON: %w", err)
+// isDataFile reports whether a filename is a plain or compressed JSON or NDJSON data file
+func isDataFile(filename string) bool {
+	for _, extension := range dataExtensions {
+		if strings.HasSuffix(filename, extension) {
+			return true
+		}
 	}
+	return false
+}
 
-	return nil
+// isNDJSONFile reports whether a data file is NDJSON
+func isNDJSONFile(filename string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(filename, gzipExtension), ".ndjson")
 }
 
-// ListFiles lists all JSON files in a specific data type directory
+// trimDataExtension removes the data file extension from a filename
+func trimDataExtension(filename string) string {
+	for _, extension := range dataExtensions {
+		if strings.HasSuffix(filename, extension) {
+			return strings.TrimSuffix(filename, extension)
+		}
+	}
+	return filename
+}
+
+// ListFiles lists all JSON files (plain or gzip-compressed) in a specific data type directory.
+// With the date layout, files in nested date directories are listed by their relative path.
 func (s *JSONStorage) ListFiles(dataType string) ([]string, error) {
 	var dir string
 	switch dataType {
@@ -99,6 +350,10 @@ func (s *JSONStorage) ListFiles(dataType string) ([]string, error) {
 		return nil, fmt.Errorf("unknown data type: %s", dataType)
 	}
 
+	if s.layout == LayoutDate {
+		return listNestedFiles(dir)
+	}
+
 	files, err := os.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -109,7 +364,7 @@ func (s *JSONStorage) ListFiles(dataType string) ([]string, error) {
 
 	var filenames []string
 	for _, file := range files {
-		if !file.IsDir() && filepath.Ext(file.Name()) == ".json" {
+		if !file.IsDir() && isDataFile(file.Name()) {
 			filenames = append(filenames, file.Name())
 		}
 	}
@@ -117,48 +372,220 @@ func (s *JSONStorage) ListFiles(dataType string) ([]string, error) {
 	return filenames, nil
 }
 
+// listNestedFiles lists the data files below dir by their path relative to it, in lexical
+// order so date directories come out oldest first
+func listNestedFiles(dir string) ([]string, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return []string{}, nil
+	}
+
+	var filenames []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isDataFile(entry.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		filenames = append(filenames, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	return filenames, nil
+}
+
 // LoadEarthquakes loads earthquake data from a JSON file
 func (s *JSONStorage) LoadEarthquakes(filename string) (*models.USGSResponse, error) {
-	if !strings.HasSuffix(filename, ".json") {
-		filename += ".json"
+	var earthquakes models.USGSResponse
+	if err := s.loadJSON("earthquakes", filename, &earthquakes); err != nil {
+		return nil, err
 	}
+	return &earthquakes, nil
+}
 
-	filePath := filepath.Join(s.outputDir, "earthquakes", filename)
-
-	file, err := os.Open(filePath)
+// LoadAllEarthquakes loads every earthquake file and merges them into one response,
+// keeping the most recently updated copy of events that appear in several files
+func (s *JSONStorage) LoadAllEarthquakes(ctx context.Context) (*models.USGSResponse, error) {
+	files, err := s.ListFiles("earthquakes")
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
 
-	var earthquakes models.USGSResponse
-	if err := json.NewDecoder(file).Decode(&earthquakes); err != nil {
-		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	merged, _, err := s.mergeEarthquakeFiles(ctx, files)
+	return merged, err
+}
+
+// mergeEarthquakeFiles loads earthquake files and merges them, keeping the most recently updated
+// revision of each earthquake. It also returns the number of records read.
+func (s *JSONStorage) mergeEarthquakeFiles(ctx context.Context, files []string) (*models.USGSResponse, int, error) {
+	merged := &models.USGSResponse{Type: "FeatureCollection"}
+	index := make(map[string]int)
+	records := 0
+	for _, filename := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+
+		earthquakes, err := s.LoadEarthquakes(filename)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to load %s: %w", filename, err)
+		}
+
+		records += len(earthquakes.Features)
+		for _, eq := range earthquakes.Features {
+			if i, ok := index[eq.ID]; ok {
+				if eq.Properties.Updated > merged.Features[i].Properties.Updated {
+					merged.Features[i] = eq
+				}
+				continue
+			}
+			index[eq.ID] = len(merged.Features)
+			merged.Features = append(merged.Features, eq)
+		}
 	}
 
-	return &earthquakes, nil
+	merged.Metadata.Count = len(merged.Features)
+	return merged, records, nil
 }
 
 // LoadFaults loads fault data from a JSON file
 func (s *JSONStorage) LoadFaults(filename string) (*models.Fault, error) {
-	if !strings.HasSuffix(filename, ".json") {
-		filename += ".json"
+	var faults models.Fault
+	if err := s.loadJSON("faults", filename, &faults); err != nil {
+		return nil, err
+	}
+	return &faults, nil
+}
+
+// LoadAllFaults loads every fault file and merges them into one collection. Faults are
+// deduplicated by their properties ID, keeping the copy from the newest file; faults
+// without an ID are all kept.
+func (s *JSONStorage) LoadAllFaults(ctx context.Context) (*models.Fault, error) {
+	files, err := s.ListFiles("faults")
+	if err != nil {
+		return nil, err
 	}
 
-	filePath := filepath.Join(s.outputDir, "faults", filename)
+	merged, _, err := s.mergeFaultFiles(ctx, files)
+	return merged, err
+}
+
+// mergeFaultFiles loads fault files and merges them, keeping the copy of each fault from the
+// last file it appears in. It also returns the number of records read.
+func (s *JSONStorage) mergeFaultFiles(ctx context.Context, files []string) (*models.Fault, int, error) {
+	merged := &models.Fault{Type: "FeatureCollection"}
+	index := make(map[string]int)
+	records := 0
+	for _, filename := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+
+		faults, err := s.LoadFaults(filename)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to load %s: %w", filename, err)
+		}
+
+		records += len(faults.Features)
+		for _, fault := range faults.Features {
+			id := fault.Properties.ID
+			if id == "" {
+				merged.Features = append(merged.Features, fault)
+				continue
+			}
+			// Files are listed oldest first, so a later copy replaces an earlier one
+			if i, ok := index[id]; ok {
+				merged.Features[i] = fault
+				continue
+			}
+			index[id] = len(merged.Features)
+			merged.Features = append(merged.Features, fault)
+		}
+	}
+
+	return merged, records, nil
+}
+
+// resolveFilename finds the file for a name given with or without its extension,
+// preferring a plain .json file over a compressed one. With the date layout, a bare
+// name that is not at the top level is looked up in the nested date directories.
+func (s *JSONStorage) resolveFilename(dataType, filename string) string {
+	candidates := []string{filename}
+	if !isDataFile(filename) {
+		candidates = make([]string, len(dataExtensions))
+		for i, extension := range dataExtensions {
+			candidates[i] = filename + extension
+		}
+	}
+
+	for _, candidate := range candidates {
+		if _, err := os.Stat(filepath.Join(s.outputDir, dataType, candidate)); err == nil {
+			return candidate
+		}
+	}
+
+	if s.layout == LayoutDate && !strings.ContainsRune(filename, filepath.Separator) {
+		if nested := s.findNestedFile(dataType, candidates); nested != "" {
+			return nested
+		}
+	}
+	return candidates[0]
+}
+
+// findNestedFile returns the relative path of the newest nested file named like one of
+// the candidates, or an empty string if there is none
+func (s *JSONStorage) findNestedFile(dataType string, candidates []string) string {
+	files, err := listNestedFiles(filepath.Join(s.outputDir, dataType))
+	if err != nil {
+		return ""
+	}
+
+	for _, candidate := range candidates {
+		for i := len(files) - 1; i >= 0; i-- {
+			if filepath.Base(files[i]) == candidate {
+				return files[i]
+			}
+		}
+	}
+	return ""
+}
+
+// loadJSON decodes a data file into v, transparently decompressing .json.gz files
+func (s *JSONStorage) loadJSON(dataType, filename string, v interface{}) error {
+	filename = s.resolveFilename(dataType, filename)
+	filePath := filepath.Join(s.outputDir, dataType, filename)
 
 	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
-	var faults models.Fault
-	if err := json.NewDecoder(file).Decode(&faults); err != nil {
-		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	var r io.Reader = file
+	if strings.HasSuffix(filename, gzipExtension) {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to decompress file: %w", err)
+		}
+		defer gz.Close()
+		r = gz
 	}
 
-	return &faults, nil
+	if isNDJSONFile(filename) {
+		return ReadNDJSON(r, v)
+	}
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode JSON: %w", err)
+	}
+
+	return nil
 }
 
 // GetFileStats returns statistics about a specific file
@@ -196,6 +623,132 @@ func (s *JSONStorage) GetFileStats(dataType, filename string) (map[string]interf
 	return stats, nil
 }
 
+// ValidateFile checks that a data file decodes into the expected model and that every record has
+// the fields it needs. It returns the number of records and the problems found; an error means
+// the file could not be checked.
+func (s *JSONStorage) ValidateFile(ctx context.Context, dataType, filename string) (int, []utils.ValidationError, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	switch dataType {
+	case "earthquakes":
+		earthquakes, err := s.LoadEarthquakes(filename)
+		if err != nil {
+			return 0, nil, err
+		}
+		validator := utils.NewDefaultDataValidator()
+		validator.AddRule(&utils.CoordinateRule{})
+		return len(earthquakes.Features), validator.ValidateResponse(earthquakes).Errors, nil
+	case "faults":
+		faults, err := s.LoadFaults(filename)
+		if err != nil {
+			return 0, nil, err
+		}
+		return len(faults.Features), validateFaults(faults), nil
+	default:
+		return 0, nil, fmt.Errorf("unknown data type: %s", dataType)
+	}
+}
+
+// validateFaults checks that each fault has an ID and a line of at least two points
+func validateFaults(faults *models.Fault) []utils.ValidationError {
+	var errs []utils.ValidationError
+
+	if faults.Type != "FeatureCollection" {
+		errs = append(errs, utils.ValidationError{
+			Field:   "type",
+			Message: fmt.Sprintf("is %q, expected \"FeatureCollection\"", faults.Type),
+		})
+	}
+
+	for _, fault := range faults.Features {
+		id := fault.ID
+		if id == "" {
+			id = fault.Properties.ID
+		}
+		if id == "" {
+			errs = append(errs, utils.ValidationError{Field: "id", Message: "is missing"})
+		}
+		if len(fault.Geometry.Coordinates) < 2 {
+			errs = append(errs, utils.ValidationError{
+				ID:      id,
+				Field:   "geometry.coordinates",
+				Message: fmt.Sprintf("has %d points, expected at least 2", len(fault.Geometry.Coordinates)),
+			})
+		}
+	}
+
+	return errs
+}
+
+// FileInfo describes a stored data file for listings
+type FileInfo struct {
+	Filename     string
+	DataType     string
+	Size         int64
+	ModTime      time.Time
+	Count        int
+	Oldest       time.Time
+	Newest       time.Time
+	MinMagnitude float64
+	MaxMagnitude float64
+}
+
+// DescribeFile gathers size, record count and, for earthquakes, the event time and magnitude ranges of a file.
+// The returned info carries the file size even if the contents cannot be decoded.
+func (s *JSONStorage) DescribeFile(dataType, filename string) (*FileInfo, error) {
+	info := &FileInfo{Filename: filename, DataType: dataType}
+
+	stat, err := os.Stat(filepath.Join(s.outputDir, dataType, s.resolveFilename(dataType, filename)))
+	if err != nil {
+		return info, fmt.Errorf("failed to stat file: %w", err)
+	}
+	info.Size = stat.Size()
+	info.ModTime = stat.ModTime()
+
+	switch dataType {
+	case "earthquakes":
+		earthquakes, err := s.LoadEarthquakes(filename)
+		if err != nil {
+			return info, err
+		}
+		info.Count = len(earthquakes.Features)
+		magnitudes := 0
+		for i, eq := range earthquakes.Features {
+			eventTime := time.UnixMilli(eq.Properties.Time)
+			if i == 0 || eventTime.Before(info.Oldest) {
+				info.Oldest = eventTime
+			}
+			if i == 0 || eventTime.After(info.Newest) {
+				info.Newest = eventTime
+			}
+
+			mag, ok := eq.Properties.Magnitude()
+			if !ok {
+				continue
+			}
+			if magnitudes == 0 || mag < info.MinMagnitude {
+				info.MinMagnitude = mag
+			}
+			if magnitudes == 0 || mag > info.MaxMagnitude {
+				info.MaxMagnitude = mag
+			}
+			magnitudes++
+		}
+	case "faults":
+		faults, err := s.LoadFaults(filename)
+		if err != nil {
+			return info, err
+		}
+		info.Count = len(faults.Features)
+	default:
+		return info, fmt.Errorf("unknown data type: %s", dataType)
+	}
+
+	return info, nil
+}
+
 // PurgeAll deletes all JSON files from both earthquakes and faults directories
 func (s *JSONStorage) PurgeAll() error {
 	// Purge earthquake files
@@ -206,7 +759,7 @@ func (s *JSONStorage) PurgeAll() error {
 
 	for _, filename := range earthquakeFiles {
 		filePath := filepath.Join(s.outputDir, "earthquakes", filename)
-		if err := os.Remove(filePath); err != nil {
+		if err := removeDataFile(filePath); err != nil {
 			return fmt.Errorf("failed to remove earthquake file %s: %w", filename, err)
 		}
 	}
@@ -219,7 +772,7 @@ func (s *JSONStorage) PurgeAll() error {
 
 	for _, filename := range faultFiles {
 		filePath := filepath.Join(s.outputDir, "faults", filename)
-		if err := os.Remove(filePath); err != nil {
+		if err := removeDataFile(filePath); err != nil {
 			return fmt.Errorf("failed to remove fault file %s: %w", filename, err)
 		}
 	}
@@ -227,6 +780,93 @@ func (s *JSONStorage) PurgeAll() error {
 	return nil
 }
 
+// filenameTimestampLayout is the timestamp format embedded in generated filenames
+const filenameTimestampLayout = "2006-01-02_15-04-05"
+
+// FileTimestamp returns when a data file was collected, parsed from the timestamp embedded
+// in generated filenames or taken from the file's modification time for custom filenames
+func (s *JSONStorage) FileTimestamp(dataType, filename string) (time.Time, error) {
+	name := trimDataExtension(filename)
+	if len(name) >= len(filenameTimestampLayout) {
+		stamp := name[len(name)-len(filenameTimestampLayout):]
+		if t, err := time.ParseInLocation(filenameTimestampLayout, stamp, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(s.outputDir, dataType, filename))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return info.ModTime(), nil
+}
+
+// NewestFile returns the most recently collected file of a data type and its timestamp,
+// or an empty name if there are no files
+func (s *JSONStorage) NewestFile(dataType string) (string, time.Time, error) {
+	files, err := s.ListFiles(dataType)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	var newest string
+	var newestTime time.Time
+	for _, filename := range files {
+		timestamp, err := s.FileTimestamp(dataType, filename)
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to determine age of %s file %s: %w", dataType, filename, err)
+		}
+		if newest == "" || timestamp.After(newestTime) {
+			newest, newestTime = filename, timestamp
+		}
+	}
+
+	return newest, newestTime, nil
+}
+
+// FilesOlderThan lists the JSON files of a specific data type collected before the cutoff
+func (s *JSONStorage) FilesOlderThan(dataType string, cutoff time.Time) ([]string, error) {
+	files, err := s.ListFiles(dataType)
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []string
+	for _, filename := range files {
+		timestamp, err := s.FileTimestamp(dataType, filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine age of %s file %s: %w", dataType, filename, err)
+		}
+		if timestamp.Before(cutoff) {
+			stale = append(stale, filename)
+		}
+	}
+
+	return stale, nil
+}
+
+// PurgeOlderThan deletes the JSON files of a specific data type collected before the cutoff
+// and returns the names of the deleted files. Once ctx is done no further files are deleted.
+func (s *JSONStorage) PurgeOlderThan(ctx context.Context, dataType string, cutoff time.Time) ([]string, error) {
+	stale, err := s.FilesOlderThan(dataType, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale %s files: %w", dataType, err)
+	}
+
+	for i, filename := range stale {
+		if err := ctx.Err(); err != nil {
+			return stale[:i], err
+		}
+
+		filePath := filepath.Join(s.outputDir, dataType, filename)
+		if err := removeDataFile(filePath); err != nil {
+			return stale[:i], fmt.Errorf("failed to remove %s file %s: %w", dataType, filename, err)
+		}
+	}
+
+	return stale, nil
+}
+
 // PurgeByType deletes all JSON files of a specific data type
 func (s *JSONStorage) PurgeByType(dataType string) error {
 	files, err := s.ListFiles(dataType)
@@ -236,7 +876,7 @@ func (s *JSONStorage) PurgeByType(dataType string) error {
 
 	for _, filename := range files {
 		filePath := filepath.Join(s.outputDir, dataType, filename)
-		if err := os.Remove(filePath); err != nil {
+		if err := removeDataFile(filePath); err != nil {
 			return fmt.Errorf("failed to remove %s file %s: %w", dataType, filename, err)
 		}
 	}
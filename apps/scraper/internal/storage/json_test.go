@@ -0,0 +1,562 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// writeTestFile creates an empty data file and returns its path
+func writeTestFile(t *testing.T, outputDir, dataType, filename string) string {
+	t.Helper()
+
+	path := filepath.Join(outputDir, dataType, filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	return path
+}
+
+func TestJSONStorage_PurgeOlderThan(t *testing.T) {
+	outputDir := t.TempDir()
+	storage := NewJSONStorage(outputDir)
+
+	now := time.Now()
+	old := now.AddDate(0, 0, -45)
+	recent := now.AddDate(0, 0, -2)
+
+	writeTestFile(t, outputDir, "earthquakes", "earthquakes_"+old.Format(filenameTimestampLayout)+".json")
+	writeTestFile(t, outputDir, "earthquakes", "earthquakes_"+recent.Format(filenameTimestampLayout)+".json")
+
+	// Custom filenames carry no timestamp, so their modification time is used
+	oldCustom := writeTestFile(t, outputDir, "earthquakes", "custom_old.json")
+	if err := os.Chtimes(oldCustom, old, old); err != nil {
+		t.Fatalf("Failed to set file times: %v", err)
+	}
+	writeTestFile(t, outputDir, "earthquakes", "custom_new.json")
+
+	cutoff := now.AddDate(0, 0, -30)
+
+	// Dry-run listing must not delete anything
+	stale, err := storage.FilesOlderThan("earthquakes", cutoff)
+	if err != nil {
+		t.Fatalf("FilesOlderThan() error = %v", err)
+	}
+	if len(stale) != 2 {
+		t.Fatalf("Expected 2 stale files, got %v", stale)
+	}
+	files, _ := storage.ListFiles("earthquakes")
+	if len(files) != 4 {
+		t.Fatalf("Expected listing to leave 4 files, got %d", len(files))
+	}
+
+	// A cancelled purge deletes nothing
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if removed, err := storage.PurgeOlderThan(ctx, "earthquakes", cutoff); !errors.Is(err, context.Canceled) || len(removed) != 0 {
+		t.Errorf("PurgeOlderThan() = %v, %v, want nothing removed and context.Canceled", removed, err)
+	}
+
+	removed, err := storage.PurgeOlderThan(context.Background(), "earthquakes", cutoff)
+	if err != nil {
+		t.Fatalf("PurgeOlderThan() error = %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("Expected 2 removed files, got %v", removed)
+	}
+
+	remaining, err := storage.ListFiles("earthquakes")
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	sort.Strings(remaining)
+
+	expected := []string{"custom_new.json", "earthquakes_" + recent.Format(filenameTimestampLayout) + ".json"}
+	if len(remaining) != len(expected) || remaining[0] != expected[0] || remaining[1] != expected[1] {
+		t.Errorf("Remaining files = %v, want %v", remaining, expected)
+	}
+}
+
+func TestJSONStorage_FileTimestamp(t *testing.T) {
+	outputDir := t.TempDir()
+	storage := NewJSONStorage(outputDir)
+
+	want := time.Date(2024, 1, 15, 8, 30, 0, 0, time.Local)
+	got, err := storage.FileTimestamp("earthquakes", "earthquakes_2024-01-15_08-30-00.json")
+	if err != nil {
+		t.Fatalf("FileTimestamp() error = %v", err)
+	}
+	if !got.Equal(want) {
+		t.Errorf("FileTimestamp() = %v, want %v", got, want)
+	}
+
+	// Missing custom files cannot be dated
+	if _, err := storage.FileTimestamp("earthquakes", "missing.json"); err == nil {
+		t.Error("Expected error for missing file without timestamp")
+	}
+}
+
+func TestJSONStorage_SaveAndLoadEarthquakes(t *testing.T) {
+	storage := NewJSONStorage(t.TempDir())
+
+	earthquakes := &models.USGSResponse{
+		Type: "FeatureCollection",
+		Features: []models.Earthquake{
+			{Type: "Feature", ID: "test-1", Properties: models.EarthquakeProperties{Mag: models.Float64(4.2)}},
+		},
+	}
+
+	if err := storage.SaveEarthquakes(earthquakes, "roundtrip"); err != nil {
+		t.Fatalf("SaveEarthquakes() error = %v", err)
+	}
+
+	loaded, err := storage.LoadEarthquakes("roundtrip")
+	if err != nil {
+		t.Fatalf("LoadEarthquakes() error = %v", err)
+	}
+	if len(loaded.Features) != 1 || loaded.Features[0].ID != "test-1" {
+		t.Errorf("Loaded features = %+v, want test-1", loaded.Features)
+	}
+}
+
+func TestJSONStorage_CollectionInfo(t *testing.T) {
+	earthquakes := &models.USGSResponse{
+		Type:     "FeatureCollection",
+		Metadata: models.Metadata{URL: "https://earthquake.usgs.gov/fdsnws/event/1/query?format=geojson&minmagnitude=2.5"},
+		Features: []models.Earthquake{{Type: "Feature", ID: "info-1"}},
+	}
+	query := map[string]string{"minmagnitude": "2.5", "limit": "100"}
+
+	storage := NewJSONStorage(t.TempDir())
+	if err := storage.SaveEarthquakesWithQuery(earthquakes, "plain", query); err != nil {
+		t.Fatalf("SaveEarthquakesWithQuery() error = %v", err)
+	}
+	loaded, err := storage.LoadEarthquakes("plain")
+	if err != nil {
+		t.Fatalf("LoadEarthquakes() error = %v", err)
+	}
+	if loaded.CollectionInfo != nil {
+		t.Errorf("Expected no collection_info by default, got %+v", loaded.CollectionInfo)
+	}
+
+	storage.SetCollectionInfo("9.9.9")
+	before := time.Now().UTC()
+	if err := storage.SaveEarthquakesWithQuery(earthquakes, "info", query); err != nil {
+		t.Fatalf("SaveEarthquakesWithQuery() error = %v", err)
+	}
+	if earthquakes.CollectionInfo != nil {
+		t.Error("Expected the caller's response to be left unchanged")
+	}
+
+	loaded, err = storage.LoadEarthquakes("info")
+	if err != nil {
+		t.Fatalf("LoadEarthquakes() error = %v", err)
+	}
+	info := loaded.CollectionInfo
+	if info == nil {
+		t.Fatal("Expected collection_info in the saved file")
+	}
+	if !reflect.DeepEqual(info.Query, query) {
+		t.Errorf("Query = %v, want %v", info.Query, query)
+	}
+	if info.SourceURL != earthquakes.Metadata.URL {
+		t.Errorf("SourceURL = %q, want %q", info.SourceURL, earthquakes.Metadata.URL)
+	}
+	if info.ToolVersion != "9.9.9" {
+		t.Errorf("ToolVersion = %q, want 9.9.9", info.ToolVersion)
+	}
+	if info.CollectedAt.Before(before.Add(-time.Second)) || info.CollectedAt.After(time.Now().Add(time.Second)) {
+		t.Errorf("CollectedAt = %v, want the time of the save", info.CollectedAt)
+	}
+	if len(loaded.Features) != 1 || loaded.Features[0].ID != "info-1" {
+		t.Errorf("Loaded features = %+v, want info-1", loaded.Features)
+	}
+
+	manifest, err := storage.LoadManifest("earthquakes", "info")
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	if !manifest.CollectedAt.Equal(info.CollectedAt) {
+		t.Errorf("Manifest collected_at %v differs from collection_info %v", manifest.CollectedAt, info.CollectedAt)
+	}
+}
+
+func TestJSONStorage_CompressedRoundTrip(t *testing.T) {
+	outputDir := t.TempDir()
+	storage := NewJSONStorage(outputDir)
+	storage.SetCompression(true)
+
+	earthquakes := &models.USGSResponse{
+		Type: "FeatureCollection",
+		Features: []models.Earthquake{
+			{Type: "Feature", ID: "gz-1", Properties: models.EarthquakeProperties{Mag: models.Float64(5.1)}},
+		},
+	}
+	faults := &models.Fault{Type: "FeatureCollection", Features: []models.FaultFeature{{Type: "Feature", ID: "f-1"}}}
+
+	if err := storage.SaveEarthquakes(earthquakes, "archive"); err != nil {
+		t.Fatalf("SaveEarthquakes() error = %v", err)
+	}
+	if err := storage.SaveFaults(faults, ""); err != nil {
+		t.Fatalf("SaveFaults() error = %v", err)
+	}
+
+	// The file on disk must really be gzip-compressed
+	raw, err := os.ReadFile(filepath.Join(outputDir, "earthquakes", "archive.json.gz"))
+	if err != nil {
+		t.Fatalf("Expected compressed file: %v", err)
+	}
+	if len(raw) < 2 || raw[0] != 0x1f || raw[1] != 0x8b {
+		t.Errorf("File does not start with the gzip magic number")
+	}
+
+	// Loading works without compression enabled and with or without the extension
+	reader := NewJSONStorage(outputDir)
+	for _, name := range []string{"archive", "archive.json.gz"} {
+		loaded, err := reader.LoadEarthquakes(name)
+		if err != nil {
+			t.Fatalf("LoadEarthquakes(%q) error = %v", name, err)
+		}
+		if len(loaded.Features) != 1 || loaded.Features[0].ID != "gz-1" {
+			t.Errorf("LoadEarthquakes(%q) features = %+v", name, loaded.Features)
+		}
+	}
+
+	faultFiles, err := reader.ListFiles("faults")
+	if err != nil || len(faultFiles) != 1 {
+		t.Fatalf("ListFiles(faults) = %v, %v", faultFiles, err)
+	}
+	loadedFaults, err := reader.LoadFaults(faultFiles[0])
+	if err != nil {
+		t.Fatalf("LoadFaults() error = %v", err)
+	}
+	if len(loadedFaults.Features) != 1 || loadedFaults.Features[0].ID != "f-1" {
+		t.Errorf("Loaded faults = %+v", loadedFaults.Features)
+	}
+
+	// Generated compressed names still carry a parseable timestamp
+	if _, err := reader.FileTimestamp("faults", faultFiles[0]); err != nil {
+		t.Errorf("FileTimestamp() error = %v", err)
+	}
+}
+
+func TestJSONStorage_CompactOutput(t *testing.T) {
+	earthquakes := &models.USGSResponse{
+		Type: "FeatureCollection",
+		Features: []models.Earthquake{
+			{Type: "Feature", ID: "c-1", Properties: models.EarthquakeProperties{Mag: models.Float64(3.3)}},
+			{Type: "Feature", ID: "c-2", Properties: models.EarthquakeProperties{Mag: models.Float64(4.4)}},
+		},
+	}
+	faults := &models.Fault{Type: "FeatureCollection", Features: []models.FaultFeature{{Type: "Feature", ID: "f-1"}}}
+
+	sizes := make(map[bool][2]int64)
+	for _, compact := range []bool{false, true} {
+		outputDir := t.TempDir()
+		storage := NewJSONStorage(outputDir)
+		storage.SetCompact(compact)
+
+		if err := storage.SaveEarthquakes(earthquakes, "eq"); err != nil {
+			t.Fatalf("SaveEarthquakes() error = %v", err)
+		}
+		if err := storage.SaveFaults(faults, "faults"); err != nil {
+			t.Fatalf("SaveFaults() error = %v", err)
+		}
+
+		var got [2]int64
+		for i, path := range []string{filepath.Join(outputDir, "earthquakes", "eq.json"), filepath.Join(outputDir, "faults", "faults.json")} {
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("Stat(%s) error = %v", path, err)
+			}
+			got[i] = info.Size()
+		}
+		sizes[compact] = got
+
+		loaded, err := storage.LoadEarthquakes("eq")
+		if err != nil || len(loaded.Features) != 2 {
+			t.Fatalf("LoadEarthquakes() = %v, %v", loaded, err)
+		}
+	}
+
+	for i, dataType := range []string{"earthquakes", "faults"} {
+		if sizes[true][i] >= sizes[false][i] {
+			t.Errorf("Expected compact %s file to be smaller: compact %d bytes, indented %d bytes", dataType, sizes[true][i], sizes[false][i])
+		}
+	}
+}
+
+func TestJSONStorage_DateLayout(t *testing.T) {
+	outputDir := t.TempDir()
+	storage := NewJSONStorage(outputDir)
+	storage.SetLayout(LayoutDate)
+
+	// Files from earlier days, and a legacy flat file
+	writeTestFile(t, outputDir, "earthquakes", filepath.Join("2024", "01", "15", "earthquakes_2024-01-15_08-00-00.json"))
+	writeTestFile(t, outputDir, "earthquakes", filepath.Join("2023", "12", "31", "earthquakes_2023-12-31_23-00-00.json.gz"))
+	writeTestFile(t, outputDir, "earthquakes", "legacy.json")
+
+	earthquakes := &models.USGSResponse{
+		Type:     "FeatureCollection",
+		Features: []models.Earthquake{{Type: "Feature", ID: "nested-1"}},
+	}
+	if err := storage.SaveEarthquakes(earthquakes, "today"); err != nil {
+		t.Fatalf("SaveEarthquakes() error = %v", err)
+	}
+	todayPath := filepath.Join(time.Now().Format("2006"), time.Now().Format("01"), time.Now().Format("02"), "today.json")
+	if _, err := os.Stat(filepath.Join(outputDir, "earthquakes", todayPath)); err != nil {
+		t.Fatalf("Expected file nested by date: %v", err)
+	}
+
+	files, err := storage.ListFiles("earthquakes")
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	want := []string{
+		filepath.Join("2023", "12", "31", "earthquakes_2023-12-31_23-00-00.json.gz"),
+		filepath.Join("2024", "01", "15", "earthquakes_2024-01-15_08-00-00.json"),
+		todayPath,
+		"legacy.json",
+	}
+	sort.Strings(want)
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("ListFiles() = %v, want %v", files, want)
+	}
+
+	// Files load by listed path or by bare name
+	for _, name := range []string{todayPath, "today", "today.json"} {
+		loaded, err := storage.LoadEarthquakes(name)
+		if err != nil {
+			t.Fatalf("LoadEarthquakes(%q) error = %v", name, err)
+		}
+		if len(loaded.Features) != 1 || loaded.Features[0].ID != "nested-1" {
+			t.Errorf("LoadEarthquakes(%q) features = %+v", name, loaded.Features)
+		}
+	}
+
+	// Timestamps come from the file name, not the directories
+	timestamp, err := storage.FileTimestamp("earthquakes", want[0])
+	if err != nil || timestamp.Year() != 2023 {
+		t.Errorf("FileTimestamp() = %v, %v, want a 2023 timestamp", timestamp, err)
+	}
+
+	// The flat layout does not see nested files
+	if flat, _ := NewJSONStorage(outputDir).ListFiles("earthquakes"); !reflect.DeepEqual(flat, []string{"legacy.json"}) {
+		t.Errorf("Flat ListFiles() = %v, want [legacy.json]", flat)
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	for name, want := range map[string]Layout{"": LayoutFlat, "flat": LayoutFlat, "date": LayoutDate} {
+		if got, err := ParseLayout(name); err != nil || got != want {
+			t.Errorf("ParseLayout(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseLayout("hourly"); err == nil {
+		t.Error("Expected error for unknown layout")
+	}
+}
+
+func TestJSONStorage_DescribeFile(t *testing.T) {
+	storage := NewJSONStorage(t.TempDir())
+
+	base := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	earthquakes := &models.USGSResponse{
+		Type: "FeatureCollection",
+		Features: []models.Earthquake{
+			{ID: "a", Properties: models.EarthquakeProperties{Mag: models.Float64(4.5), Time: base.UnixMilli()}},
+			{ID: "b", Properties: models.EarthquakeProperties{Mag: models.Float64(1.2), Time: base.Add(time.Hour).UnixMilli()}},
+		},
+	}
+	if err := storage.SaveEarthquakes(earthquakes, "described"); err != nil {
+		t.Fatalf("SaveEarthquakes() error = %v", err)
+	}
+
+	info, err := storage.DescribeFile("earthquakes", "described.json")
+	if err != nil {
+		t.Fatalf("DescribeFile() error = %v", err)
+	}
+	if info.Count != 2 || info.Size == 0 {
+		t.Errorf("Count/Size = %d/%d, want 2 and non-zero", info.Count, info.Size)
+	}
+	if !info.Oldest.Equal(base) || !info.Newest.Equal(base.Add(time.Hour)) {
+		t.Errorf("Event range = %v to %v", info.Oldest, info.Newest)
+	}
+	if info.MinMagnitude != 1.2 || info.MaxMagnitude != 4.5 {
+		t.Errorf("Magnitude range = %v to %v, want 1.2 to 4.5", info.MinMagnitude, info.MaxMagnitude)
+	}
+}
+
+func TestJSONStorage_LoadAllEarthquakes(t *testing.T) {
+	storage := NewJSONStorage(t.TempDir())
+
+	files := map[string][]models.Earthquake{
+		"first": {
+			{ID: "a", Properties: models.EarthquakeProperties{Mag: models.Float64(2.0), Updated: 100}},
+			{ID: "b", Properties: models.EarthquakeProperties{Mag: models.Float64(3.0), Updated: 100}},
+		},
+		"second": {
+			{ID: "b", Properties: models.EarthquakeProperties{Mag: models.Float64(3.4), Updated: 200}},
+			{ID: "c", Properties: models.EarthquakeProperties{Mag: models.Float64(4.0), Updated: 100}},
+		},
+		"third": {
+			{ID: "a", Properties: models.EarthquakeProperties{Mag: models.Float64(1.9), Updated: 50}},
+			{ID: "c", Properties: models.EarthquakeProperties{Mag: models.Float64(4.0), Updated: 100}},
+			{ID: "d", Properties: models.EarthquakeProperties{Mag: models.Float64(5.0), Updated: 100}},
+		},
+	}
+	for name, features := range files {
+		if err := storage.SaveEarthquakes(&models.USGSResponse{Type: "FeatureCollection", Features: features}, name); err != nil {
+			t.Fatalf("SaveEarthquakes(%q) error = %v", name, err)
+		}
+	}
+
+	merged, err := storage.LoadAllEarthquakes(context.Background())
+	if err != nil {
+		t.Fatalf("LoadAllEarthquakes() error = %v", err)
+	}
+	if len(merged.Features) != 4 || merged.Metadata.Count != 4 {
+		t.Fatalf("Merged %d earthquakes (metadata count %d), want 4", len(merged.Features), merged.Metadata.Count)
+	}
+
+	// The most recently updated copy of a duplicated event wins
+	mags := make(map[string]float64)
+	for _, eq := range merged.Features {
+		mags[eq.ID], _ = eq.Properties.Magnitude()
+	}
+	want := map[string]float64{"a": 2.0, "b": 3.4, "c": 4.0, "d": 5.0}
+	for id, mag := range want {
+		if mags[id] != mag {
+			t.Errorf("Magnitude of %s = %v, want %v", id, mags[id], mag)
+		}
+	}
+}
+
+func TestJSONStorage_FaultRawProperties(t *testing.T) {
+	storage := NewJSONStorage(t.TempDir())
+
+	faults := &models.Fault{Type: "FeatureCollection", Features: []models.FaultFeature{{
+		Type: "Feature",
+		Properties: models.FaultProperties{
+			ID:            "raw-1",
+			Name:          "Raw fault",
+			RawProperties: map[string]json.RawMessage{"activity_class": json.RawMessage(`"holocene"`)},
+		},
+	}}}
+	for _, compress := range []bool{false, true} {
+		storage.SetCompression(compress)
+		if err := storage.SaveFaults(faults, "raw"); err != nil {
+			t.Fatalf("SaveFaults() error = %v", err)
+		}
+		loaded, err := storage.LoadFaults("raw")
+		if err != nil {
+			t.Fatalf("LoadFaults() error = %v", err)
+		}
+		if len(loaded.Features) != 1 {
+			t.Fatalf("Expected 1 fault, got %d", len(loaded.Features))
+		}
+		if got := string(loaded.Features[0].Properties.RawProperties["activity_class"]); got != `"holocene"` {
+			t.Errorf("activity_class = %s, want \"holocene\" (compress %t)", got, compress)
+		}
+	}
+}
+
+func TestJSONStorage_LoadAllFaults(t *testing.T) {
+	storage := NewJSONStorage(t.TempDir())
+
+	older := &models.Fault{Type: "FeatureCollection", Features: []models.FaultFeature{
+		{Type: "Feature", Properties: models.FaultProperties{ID: "shared", Name: "Old name"}},
+		{Type: "Feature", Properties: models.FaultProperties{ID: "only-old"}},
+	}}
+	newer := &models.Fault{Type: "FeatureCollection", Features: []models.FaultFeature{
+		{Type: "Feature", Properties: models.FaultProperties{ID: "shared", Name: "New name"}},
+		{Type: "Feature", Properties: models.FaultProperties{ID: "only-new"}},
+	}}
+	if err := storage.SaveFaults(older, "faults_2024-01-01_00-00-00"); err != nil {
+		t.Fatalf("SaveFaults() error = %v", err)
+	}
+	if err := storage.SaveFaults(newer, "faults_2024-02-01_00-00-00"); err != nil {
+		t.Fatalf("SaveFaults() error = %v", err)
+	}
+
+	merged, err := storage.LoadAllFaults(context.Background())
+	if err != nil {
+		t.Fatalf("LoadAllFaults() error = %v", err)
+	}
+	if len(merged.Features) != 3 {
+		t.Fatalf("Expected 3 unique faults, got %d", len(merged.Features))
+	}
+
+	shared := 0
+	for _, fault := range merged.Features {
+		if fault.Properties.ID == "shared" {
+			shared++
+			if fault.Properties.Name != "New name" {
+				t.Errorf("Expected the newest copy of the shared fault, got %q", fault.Properties.Name)
+			}
+		}
+	}
+	if shared != 1 {
+		t.Errorf("Expected the shared fault once, got %d", shared)
+	}
+}
+
+func TestJSONStorage_ValidateFile(t *testing.T) {
+	storage := NewJSONStorage("testdata/validate")
+
+	records, errs, err := storage.ValidateFile(context.Background(), "earthquakes", "malformed.json")
+	if err != nil {
+		t.Fatalf("ValidateFile() error = %v", err)
+	}
+	if records != 4 {
+		t.Errorf("ValidateFile() records = %d, want 4", records)
+	}
+
+	type problem struct{ id, field string }
+	want := []problem{
+		{"", "id"},
+		{"bad-latitude", "geometry.latitude"},
+		{"no-time", "geometry.coordinates"},
+		{"no-time", "properties.time"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("ValidateFile() = %v, want %d problems", errs, len(want))
+	}
+	for i, w := range want {
+		if errs[i].ID != w.id || errs[i].Field != w.field {
+			t.Errorf("Problem %d = %s/%s, want %s/%s", i, errs[i].ID, errs[i].Field, w.id, w.field)
+		}
+	}
+
+	// A file that does not decode cannot be validated
+	if _, _, err := storage.ValidateFile(context.Background(), "earthquakes", "truncated.json"); err == nil {
+		t.Error("Expected error for truncated file")
+	}
+
+	// Valid files report no problems
+	valid := NewJSONStorage(t.TempDir())
+	if err := valid.SaveFaults(&models.Fault{
+		Type: "FeatureCollection",
+		Features: []models.FaultFeature{{
+			ID:       "f-1",
+			Geometry: models.FaultGeometry{Type: "LineString", Coordinates: [][]float64{{1, 2}, {3, 4}}},
+		}},
+	}, "faults"); err != nil {
+		t.Fatalf("SaveFaults() error = %v", err)
+	}
+	if records, errs, err := valid.ValidateFile(context.Background(), "faults", "faults"); err != nil || records != 1 || len(errs) != 0 {
+		t.Errorf("ValidateFile() on valid faults = %d, %v, %v", records, errs, err)
+	}
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// manifestExtension is appended to a data file's name to name its manifest
+const manifestExtension = ".sha256"
+
+// Manifest records what a data file contained when it was saved
+type Manifest struct {
+	File        string            `json:"file"`
+	SHA256      string            `json:"sha256"`
+	Records     int               `json:"records"`
+	Query       map[string]string `json:"query,omitempty"`
+	CollectedAt time.Time         `json:"collected_at"`
+}
+
+// VerifyStatus is the outcome of checking a data file against its manifest
+type VerifyStatus string
+
+const (
+	// VerifyOK means the file still matches its manifest
+	VerifyOK VerifyStatus = "ok"
+	// VerifyModified means the file decodes but its contents changed since it was saved
+	VerifyModified VerifyStatus = "modified"
+	// VerifyCorrupt means the file changed and can no longer be decoded, or its manifest is unreadable
+	VerifyCorrupt VerifyStatus = "corrupt"
+	// VerifyNoManifest means the file has no manifest, e.g. because it predates manifests
+	VerifyNoManifest VerifyStatus = "no-manifest"
+)
+
+// VerifyResult describes the outcome of verifying a single data file
+type VerifyResult struct {
+	File   string
+	Status VerifyStatus
+	Detail string
+}
+
+// writeManifest writes the manifest of a saved data file next to it
+func writeManifest(filePath string, manifest Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(filePath+manifestExtension, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// readManifest reads the manifest of a data file
+func readManifest(filePath string) (*Manifest, error) {
+	data, err := os.ReadFile(filePath + manifestExtension)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// hashFile returns the hex-encoded SHA-256 of a file's contents
+func hashFile(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// removeDataFile deletes a data file together with its manifest, if it has one
+func removeDataFile(filePath string) error {
+	if err := os.Remove(filePath); err != nil {
+		return err
+	}
+	if err := os.Remove(filePath + manifestExtension); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// LoadManifest loads the manifest saved alongside a data file
+func (s *JSONStorage) LoadManifest(dataType, filename string) (*Manifest, error) {
+	manifest, err := readManifest(filepath.Join(s.outputDir, dataType, s.resolveFilename(dataType, filename)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// VerifyFile recomputes a data file's hash and compares it against the file's manifest. Problems
+// with the file are reported in the result; the error is only set if the file cannot be read.
+func (s *JSONStorage) VerifyFile(dataType, filename string) (*VerifyResult, error) {
+	result := &VerifyResult{File: filename}
+	filePath := filepath.Join(s.outputDir, dataType, s.resolveFilename(dataType, filename))
+
+	sum, err := hashFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := readManifest(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			result.Status = VerifyNoManifest
+			return result, nil
+		}
+		result.Status = VerifyCorrupt
+		result.Detail = err.Error()
+		return result, nil
+	}
+
+	if sum == manifest.SHA256 {
+		result.Status = VerifyOK
+		result.Detail = fmt.Sprintf("%d records", manifest.Records)
+		return result, nil
+	}
+
+	// The file changed; tell edits apart from damage by whether it still decodes. Files saved
+	// with selected fields are not GeoJSON and always count as corrupt once changed.
+	info, err := s.DescribeFile(dataType, filename)
+	if err != nil {
+		result.Status = VerifyCorrupt
+		result.Detail = fmt.Sprintf("sha256 mismatch and contents unreadable: %v", err)
+	} else {
+		result.Status = VerifyModified
+		result.Detail = fmt.Sprintf("sha256 mismatch, %d records (manifest: %d)", info.Count, manifest.Records)
+	}
+	return result, nil
+}
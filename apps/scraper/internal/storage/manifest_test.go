@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+func TestJSONStorage_SaveWritesManifest(t *testing.T) {
+	outputDir := t.TempDir()
+	storage := NewJSONStorage(outputDir)
+
+	earthquakes := &models.USGSResponse{
+		Type:     "FeatureCollection",
+		Features: []models.Earthquake{{ID: "us1"}, {ID: "us2"}},
+	}
+	query := map[string]string{"query": "recent", "limit": "10"}
+	before := time.Now().UTC().Add(-time.Second)
+	if err := storage.SaveEarthquakesWithQuery(earthquakes, "batch.json", query); err != nil {
+		t.Fatalf("SaveEarthquakesWithQuery() error = %v", err)
+	}
+
+	manifest, err := storage.LoadManifest("earthquakes", "batch.json")
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	sum, err := hashFile(filepath.Join(outputDir, "earthquakes", "batch.json"))
+	if err != nil {
+		t.Fatalf("hashFile() error = %v", err)
+	}
+	if manifest.File != "batch.json" || manifest.SHA256 != sum || manifest.Records != 2 {
+		t.Errorf("Unexpected manifest %+v (file sha256 %s)", manifest, sum)
+	}
+	if manifest.Query["query"] != "recent" || manifest.Query["limit"] != "10" {
+		t.Errorf("Expected query in manifest, got %v", manifest.Query)
+	}
+	if manifest.CollectedAt.Before(before) {
+		t.Errorf("Expected collection time after %v, got %v", before, manifest.CollectedAt)
+	}
+
+	// Compressed files are hashed as stored
+	storage.SetCompression(true)
+	if err := storage.SaveFaults(&models.Fault{Features: []models.FaultFeature{{}}}, "faults.json"); err != nil {
+		t.Fatalf("SaveFaults() error = %v", err)
+	}
+	result, err := storage.VerifyFile("faults", "faults.json.gz")
+	if err != nil {
+		t.Fatalf("VerifyFile() error = %v", err)
+	}
+	if result.Status != VerifyOK {
+		t.Errorf("Expected compressed file to verify, got %s: %s", result.Status, result.Detail)
+	}
+}
+
+func TestJSONStorage_VerifyFile(t *testing.T) {
+	outputDir := t.TempDir()
+	storage := NewJSONStorage(outputDir)
+
+	earthquakes := &models.USGSResponse{Features: []models.Earthquake{{ID: "us1"}}}
+	for _, name := range []string{"intact.json", "modified.json", "corrupt.json"} {
+		if err := storage.SaveEarthquakes(earthquakes, name); err != nil {
+			t.Fatalf("SaveEarthquakes() error = %v", err)
+		}
+	}
+	dir := filepath.Join(outputDir, "earthquakes")
+	if err := os.WriteFile(filepath.Join(dir, "modified.json"), []byte(`{"features":[{"id":"us1"},{"id":"us2"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "corrupt.json"), []byte(`{"features":[{"id":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, outputDir, "earthquakes", "legacy.json")
+
+	tests := []struct {
+		file string
+		want VerifyStatus
+	}{
+		{"intact.json", VerifyOK},
+		{"modified.json", VerifyModified},
+		{"corrupt.json", VerifyCorrupt},
+		{"legacy.json", VerifyNoManifest},
+	}
+	for _, tt := range tests {
+		result, err := storage.VerifyFile("earthquakes", tt.file)
+		if err != nil {
+			t.Fatalf("VerifyFile(%s) error = %v", tt.file, err)
+		}
+		if result.Status != tt.want {
+			t.Errorf("VerifyFile(%s) = %s (%s), want %s", tt.file, result.Status, result.Detail, tt.want)
+		}
+	}
+
+	// Purging a file removes its manifest as well
+	if err := storage.PurgeByType("earthquakes"); err != nil {
+		t.Fatalf("PurgeByType() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "intact.json"+manifestExtension)); !os.IsNotExist(err) {
+		t.Errorf("Expected manifest to be purged, got %v", err)
+	}
+}
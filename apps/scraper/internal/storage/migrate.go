@@ -1,55 +1,145 @@
 package storage
 
 import (
+	"context"
+	"embed"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"quakewatch-scraper/internal/config"
 
 	migrate "github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 )
 
+// embeddedMigrations holds the SQL migrations compiled into the binary
+//
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
 // MigrationManager handles database migrations
 type MigrationManager struct {
-	db     *sqlx.DB
-	config *config.DatabaseConfig
+	db             *sqlx.DB
+	config         *config.DatabaseConfig
+	migrationsPath string
 }
 
-// NewMigrationManager creates a new migration manager
+// NewMigrationManager creates a new migration manager. Migrations embedded in the binary are used
+// unless a migrations directory is configured.
 func NewMigrationManager(config *config.DatabaseConfig) (*MigrationManager, error) {
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid database config: %w", err)
 	}
 
+	var migrationsPath string
+	if config.MigrationsPath != "" {
+		var err error
+		if migrationsPath, err = ResolveMigrationsPath(config.MigrationsPath); err != nil {
+			return nil, err
+		}
+	}
+
 	db, err := sqlx.Connect("postgres", config.GetDSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	return &MigrationManager{
-		db:     db,
-		config: config,
+		db:             db,
+		config:         config,
+		migrationsPath: migrationsPath,
 	}, nil
 }
 
-// MigrateUp runs all pending migrations
-func (m *MigrationManager) MigrateUp() error {
-	driver, err := postgres.WithInstance(m.db.DB, &postgres.Config{})
+// ResolveMigrationsPath finds the migrations directory. Relative paths are looked up next to the
+// executable first, so an installed binary works from any directory, and then in the working directory.
+func ResolveMigrationsPath(path string) (string, error) {
+	exeDir := ""
+	if exe, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		exeDir = filepath.Dir(exe)
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	return resolveMigrationsPath(path, exeDir, workDir)
+}
+
+// resolveMigrationsPath resolves path against each base directory in turn and returns the first existing directory
+func resolveMigrationsPath(path string, baseDirs ...string) (string, error) {
+	var candidates []string
+	if filepath.IsAbs(path) {
+		candidates = []string{path}
+	} else {
+		for _, dir := range baseDirs {
+			if dir != "" {
+				candidates = append(candidates, filepath.Join(dir, path))
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		info, err := os.Stat(candidate)
+		if err == nil && info.IsDir() {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("migrations directory not found at %s (set database.migrations_path or --migrations-path)",
+		strings.Join(candidates, " or "))
+}
+
+// newMigrator creates a migrator reading from the configured migrations directory or the embedded
+// migrations. The migrator runs on a connection of its own: closing it releases that connection
+// and leaves the manager's pool open for the next call, which a driver built on the pool would not.
+func (m *MigrationManager) newMigrator() (*migrate.Migrate, error) {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
 	if err != nil {
-		return fmt.Errorf("failed to create postgres driver: %w", err)
+		conn.Close()
+		return nil, fmt.Errorf("failed to create postgres driver: %w", err)
 	}
 
-	migrator, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		"postgres",
-		driver,
-	)
+	var migrator *migrate.Migrate
+	if m.migrationsPath != "" {
+		migrator, err = migrate.NewWithDatabaseInstance("file://"+filepath.ToSlash(m.migrationsPath), "postgres", driver)
+	} else {
+		source, sourceErr := iofs.New(embeddedMigrations, "migrations")
+		if sourceErr != nil {
+			driver.Close()
+			return nil, fmt.Errorf("failed to read embedded migrations: %w", sourceErr)
+		}
+		migrator, err = migrate.NewWithInstance("iofs", source, "postgres", driver)
+	}
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("failed to create migrator: %w", err)
+	}
+
+	return migrator, nil
+}
+
+// MigrateUp runs all pending migrations
+func (m *MigrationManager) MigrateUp() error {
+	migrator, err := m.newMigrator()
 	if err != nil {
-		return fmt.Errorf("failed to create migrator: %w", err)
+		return err
 	}
 	defer migrator.Close()
 
@@ -63,18 +153,9 @@ func (m *MigrationManager) MigrateUp() error {
 
 // MigrateDown rolls back all migrations
 func (m *MigrationManager) MigrateDown() error {
-	driver, err := postgres.WithInstance(m.db.DB, &postgres.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to create postgres driver: %w", err)
-	}
-
-	migrator, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		"postgres",
-		driver,
-	)
+	migrator, err := m.newMigrator()
 	if err != nil {
-		return fmt.Errorf("failed to create migrator: %w", err)
+		return err
 	}
 	defer migrator.Close()
 
@@ -88,18 +169,9 @@ func (m *MigrationManager) MigrateDown() error {
 
 // MigrateToVersion migrates to a specific version
 func (m *MigrationManager) MigrateToVersion(version uint) error {
-	driver, err := postgres.WithInstance(m.db.DB, &postgres.Config{})
+	migrator, err := m.newMigrator()
 	if err != nil {
-		return fmt.Errorf("failed to create postgres driver: %w", err)
-	}
-
-	migrator, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		"postgres",
-		driver,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create migrator: %w", err)
+		return err
 	}
 	defer migrator.Close()
 
@@ -113,18 +185,9 @@ func (m *MigrationManager) MigrateToVersion(version uint) error {
 
 // GetVersion returns the current migration version
 func (m *MigrationManager) GetVersion() (uint, bool, error) {
-	driver, err := postgres.WithInstance(m.db.DB, &postgres.Config{})
-	if err != nil {
-		return 0, false, fmt.Errorf("failed to create postgres driver: %w", err)
-	}
-
-	migrator, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		"postgres",
-		driver,
-	)
+	migrator, err := m.newMigrator()
 	if err != nil {
-		return 0, false, fmt.Errorf("failed to create migrator: %w", err)
+		return 0, false, err
 	}
 	defer migrator.Close()
 
@@ -138,18 +201,9 @@ func (m *MigrationManager) GetVersion() (uint, bool, error) {
 
 // ForceVersion forces the migration version
 func (m *MigrationManager) ForceVersion(version uint) error {
-	driver, err := postgres.WithInstance(m.db.DB, &postgres.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to create postgres driver: %w", err)
-	}
-
-	migrator, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		"postgres",
-		driver,
-	)
+	migrator, err := m.newMigrator()
 	if err != nil {
-		return fmt.Errorf("failed to create migrator: %w", err)
+		return err
 	}
 	defer migrator.Close()
 
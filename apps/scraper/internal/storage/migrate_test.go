@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"quakewatch-scraper/internal/config"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	source, err := iofs.New(embeddedMigrations, "migrations")
+	if err != nil {
+		t.Fatalf("iofs.New() error = %v", err)
+	}
+	defer source.Close()
+
+	// Every embedded migration must have both directions
+	version, err := source.First()
+	for err == nil {
+		if _, _, upErr := source.ReadUp(version); upErr != nil {
+			t.Errorf("Migration %d has no up file: %v", version, upErr)
+		}
+		if _, _, downErr := source.ReadDown(version); downErr != nil {
+			t.Errorf("Migration %d has no down file: %v", version, downErr)
+		}
+		version, err = source.Next(version)
+	}
+	if !os.IsNotExist(err) {
+		t.Errorf("Unexpected error walking migrations: %v", err)
+	}
+}
+
+func TestMigrationManager_Integration(t *testing.T) {
+	// Skip if not running integration tests
+	if os.Getenv("INTEGRATION_TESTS") != "true" {
+		t.Skip("Skipping integration test. Set INTEGRATION_TESTS=true to run")
+	}
+
+	// No migrations path, so the embedded migrations are used from any working directory
+	manager, err := NewMigrationManager(&config.DatabaseConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "postgres",
+		Password: "postgres",
+		Database: "quakewatch_test",
+		SSLMode:  "disable",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create migration manager: %v", err)
+	}
+	defer manager.Close()
+
+	if err := manager.MigrateUp(); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	version, dirty, err := manager.GetVersion()
+	if err != nil {
+		t.Fatalf("Failed to get migration version: %v", err)
+	}
+	if version < 1 || dirty {
+		t.Errorf("Version = %d (dirty %t), want at least 1 and clean", version, dirty)
+	}
+
+	for _, table := range []string{"earthquakes", "faults", "collection_logs"} {
+		var exists bool
+		if err := manager.db.Get(&exists, "SELECT to_regclass($1) IS NOT NULL", table); err != nil {
+			t.Fatalf("Failed to check table %s: %v", table, err)
+		}
+		if !exists {
+			t.Errorf("Table %s was not created", table)
+		}
+	}
+
+	// Migrating again changes nothing, as db init re-run on a migrated database expects
+	if err := manager.MigrateUp(); err != nil {
+		t.Fatalf("Failed to re-run migrations: %v", err)
+	}
+	again, _, err := manager.GetVersion()
+	if err != nil {
+		t.Fatalf("Failed to get migration version after re-running: %v", err)
+	}
+	if again != version {
+		t.Errorf("Version after re-running = %d, want %d", again, version)
+	}
+}
+
+// fakePostgres is a database/sql driver answering the queries the postgres migration driver makes
+// to read the version, standing in for a server where none is available
+type fakePostgres struct{}
+
+func (fakePostgres) Open(string) (driver.Conn, error) { return fakePostgresConn{}, nil }
+
+type fakePostgresConn struct{}
+
+func (fakePostgresConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (fakePostgresConn) Close() error                        { return nil }
+func (fakePostgresConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+func (fakePostgresConn) Ping(context.Context) error          { return nil }
+
+func (fakePostgresConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (fakePostgresConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.Contains(query, "CURRENT_DATABASE()"):
+		return &fakePostgresRows{values: []driver.Value{"quakewatch_test"}}, nil
+	case strings.Contains(query, "CURRENT_SCHEMA()"):
+		return &fakePostgresRows{values: []driver.Value{"public"}}, nil
+	case strings.Contains(query, "information_schema.tables"):
+		return &fakePostgresRows{values: []driver.Value{int64(1)}}, nil
+	case strings.HasPrefix(query, "SELECT version, dirty"):
+		return &fakePostgresRows{values: []driver.Value{int64(3), false}}, nil
+	default:
+		return &fakePostgresRows{}, nil
+	}
+}
+
+// fakePostgresRows holds a single row, or none when values is empty
+type fakePostgresRows struct {
+	values []driver.Value
+	read   bool
+}
+
+func (r *fakePostgresRows) Columns() []string {
+	return make([]string, len(r.values))
+}
+
+func (r *fakePostgresRows) Close() error { return nil }
+
+func (r *fakePostgresRows) Next(dest []driver.Value) error {
+	if r.read || len(r.values) == 0 {
+		return io.EOF
+	}
+	r.read = true
+	copy(dest, r.values)
+	return nil
+}
+
+func init() {
+	sql.Register("fakepostgres", fakePostgres{})
+}
+
+func TestMigrationManager_ConsecutiveCalls(t *testing.T) {
+	db, err := sqlx.Open("fakepostgres", "")
+	if err != nil {
+		t.Fatalf("sqlx.Open() error = %v", err)
+	}
+	manager := &MigrationManager{db: db}
+	defer manager.Close()
+
+	// Closing the migrator of one call must leave the pool usable by the next
+	for call := 1; call <= 2; call++ {
+		version, dirty, err := manager.GetVersion()
+		if err != nil {
+			t.Fatalf("GetVersion() call %d error = %v", call, err)
+		}
+		if version != 3 || dirty {
+			t.Errorf("GetVersion() call %d = %d (dirty %t), want 3 and clean", call, version, dirty)
+		}
+	}
+	if err := db.Ping(); err != nil {
+		t.Errorf("Expected the pool to stay open, Ping() error = %v", err)
+	}
+}
+
+func TestResolveMigrationsPath(t *testing.T) {
+	exeDir := t.TempDir()
+	workDir := t.TempDir()
+
+	if err := os.Mkdir(filepath.Join(exeDir, "migrations"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(workDir, "migrations"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(workDir, "local"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, "file"), nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "Relative next to executable wins", path: "migrations", want: filepath.Join(exeDir, "migrations")},
+		{name: "Relative falls back to working directory", path: "local", want: filepath.Join(workDir, "local")},
+		{name: "Absolute path", path: filepath.Join(workDir, "local"), want: filepath.Join(workDir, "local")},
+		{name: "Missing directory", path: "missing", wantErr: true},
+		{name: "Not a directory", path: "file", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveMigrationsPath(tt.path, exeDir, workDir)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("resolveMigrationsPath(%q) = %q, want error", tt.path, got)
+				}
+				if !strings.Contains(err.Error(), "migrations directory not found at "+filepath.Join(exeDir, tt.path)) {
+					t.Errorf("Error %q does not name the missing path", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveMigrationsPath(%q) error = %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveMigrationsPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS collection_logs;
+DROP TABLE IF EXISTS faults;
+DROP TABLE IF EXISTS earthquakes;
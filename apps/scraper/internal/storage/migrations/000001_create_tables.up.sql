@@ -0,0 +1,73 @@
+CREATE TABLE IF NOT EXISTS earthquakes (
+    id SERIAL PRIMARY KEY,
+    usgs_id VARCHAR(255) UNIQUE NOT NULL,
+    magnitude DECIMAL(4,2) NOT NULL,
+    magnitude_type TEXT,
+    place TEXT NOT NULL,
+    time TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated TIMESTAMP WITH TIME ZONE NOT NULL,
+    url TEXT,
+    detail_url TEXT,
+    felt_count INTEGER,
+    cdi DOUBLE PRECISION,
+    mmi DOUBLE PRECISION,
+    alert TEXT,
+    status TEXT,
+    tsunami BOOLEAN NOT NULL DEFAULT FALSE,
+    significance INTEGER,
+    network TEXT,
+    code TEXT,
+    ids TEXT,
+    sources TEXT,
+    types TEXT,
+    nst INTEGER,
+    dmin DOUBLE PRECISION,
+    rms DOUBLE PRECISION,
+    gap DOUBLE PRECISION,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    depth DOUBLE PRECISION,
+    title TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_earthquakes_time ON earthquakes(time);
+CREATE INDEX IF NOT EXISTS idx_earthquakes_magnitude ON earthquakes(magnitude);
+CREATE INDEX IF NOT EXISTS idx_earthquakes_location ON earthquakes(latitude, longitude);
+
+CREATE TABLE IF NOT EXISTS faults (
+    id SERIAL PRIMARY KEY,
+    fault_id VARCHAR(255) UNIQUE NOT NULL,
+    name TEXT NOT NULL,
+    fault_type TEXT,
+    slip_rate DOUBLE PRECISION,
+    slip_type TEXT,
+    dip DOUBLE PRECISION,
+    rake DOUBLE PRECISION,
+    length DOUBLE PRECISION,
+    width DOUBLE PRECISION,
+    max_magnitude DOUBLE PRECISION,
+    description TEXT,
+    source TEXT,
+    geometry_type TEXT,
+    coordinates JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_faults_type ON faults(fault_type);
+
+CREATE TABLE IF NOT EXISTS collection_logs (
+    id SERIAL PRIMARY KEY,
+    data_type VARCHAR(50) NOT NULL,
+    source VARCHAR(100) NOT NULL,
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE,
+    records_collected INTEGER DEFAULT 0,
+    status TEXT NOT NULL,
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_logs_data_type ON collection_logs(data_type, created_at);
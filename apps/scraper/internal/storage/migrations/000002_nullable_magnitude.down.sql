@@ -0,0 +1,2 @@
+UPDATE earthquakes SET magnitude = 0 WHERE magnitude IS NULL;
+ALTER TABLE earthquakes ALTER COLUMN magnitude SET NOT NULL;
@@ -0,0 +1,2 @@
+-- Events reported without a magnitude are stored as NULL rather than 0
+ALTER TABLE earthquakes ALTER COLUMN magnitude DROP NOT NULL;
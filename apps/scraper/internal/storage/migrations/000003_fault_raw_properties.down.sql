@@ -0,0 +1 @@
+ALTER TABLE faults DROP COLUMN IF EXISTS raw_properties;
@@ -0,0 +1,2 @@
+-- Fault properties without a dedicated column, kept as reported by the source
+ALTER TABLE faults ADD COLUMN IF NOT EXISTS raw_properties JSONB;
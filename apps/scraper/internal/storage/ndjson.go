@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// maxNDJSONLine is the longest line ReadNDJSON accepts; fault geometries can be large
+const maxNDJSONLine = 16 * 1024 * 1024
+
+// WriteNDJSON writes data as newline-delimited JSON: one line per feature of an earthquake or
+// fault collection, one per record of a field selection, or a single line for anything else
+func WriteNDJSON(w io.Writer, data interface{}) error {
+	encoder := json.NewEncoder(w)
+	encode := func(v interface{}) error {
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("failed to encode NDJSON: %w", err)
+		}
+		return nil
+	}
+
+	switch data := data.(type) {
+	case *models.USGSResponse:
+		for _, earthquake := range data.Features {
+			if err := encode(earthquake); err != nil {
+				return err
+			}
+		}
+	case *models.Fault:
+		for _, fault := range data.Features {
+			if err := encode(fault); err != nil {
+				return err
+			}
+		}
+	case []map[string]interface{}:
+		for _, record := range data {
+			if err := encode(record); err != nil {
+				return err
+			}
+		}
+	default:
+		return encode(data)
+	}
+	return nil
+}
+
+// ReadNDJSON reads newline-delimited features into an earthquake or fault collection. Blank
+// lines are skipped.
+func ReadNDJSON(r io.Reader, v interface{}) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxNDJSONLine)
+
+	var decode func(line []byte) error
+	switch v := v.(type) {
+	case *models.USGSResponse:
+		v.Type = "FeatureCollection"
+		v.Features = []models.Earthquake{}
+		decode = func(line []byte) error {
+			var earthquake models.Earthquake
+			if err := json.Unmarshal(line, &earthquake); err != nil {
+				return err
+			}
+			v.Features = append(v.Features, earthquake)
+			v.Metadata.Count = len(v.Features)
+			return nil
+		}
+	case *models.Fault:
+		v.Type = "FeatureCollection"
+		v.Features = []models.FaultFeature{}
+		decode = func(line []byte) error {
+			var fault models.FaultFeature
+			if err := json.Unmarshal(line, &fault); err != nil {
+				return err
+			}
+			v.Features = append(v.Features, fault)
+			return nil
+		}
+	default:
+		return fmt.Errorf("cannot read NDJSON into %T", v)
+	}
+
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := decode(line); err != nil {
+			return fmt.Errorf("failed to decode NDJSON line %d: %w", n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read NDJSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"quakewatch-scraper/internal/models"
+)
+
+func TestJSONStorage_NDJSONRoundTrip(t *testing.T) {
+	earthquakes := &models.USGSResponse{
+		Type: "FeatureCollection",
+		Features: []models.Earthquake{
+			{Type: "Feature", ID: "nd-1", Properties: models.EarthquakeProperties{Mag: models.Float64(3.3), Place: "line\nbreak"}},
+			{Type: "Feature", ID: "nd-2", Properties: models.EarthquakeProperties{Mag: models.Float64(4.4)}},
+		},
+	}
+	faults := &models.Fault{Type: "FeatureCollection", Features: []models.FaultFeature{{Type: "Feature", ID: "f-1"}}}
+
+	for _, compress := range []bool{false, true} {
+		outputDir := t.TempDir()
+		storage := NewJSONStorage(outputDir)
+		storage.SetFormat(FormatNDJSON)
+		storage.SetCompression(compress)
+
+		if err := storage.SaveEarthquakes(earthquakes, "stream"); err != nil {
+			t.Fatalf("SaveEarthquakes() error = %v", err)
+		}
+		if err := storage.SaveFaults(faults, ""); err != nil {
+			t.Fatalf("SaveFaults() error = %v", err)
+		}
+
+		want := "stream.ndjson"
+		if compress {
+			want += gzipExtension
+		}
+		files, err := storage.ListFiles("earthquakes")
+		if err != nil || len(files) != 1 || files[0] != want {
+			t.Fatalf("ListFiles() = %v, %v, want [%s]", files, err, want)
+		}
+
+		// Loading works with a reader in the default format, with or without the extension
+		reader := NewJSONStorage(outputDir)
+		for _, name := range []string{"stream", want} {
+			loaded, err := reader.LoadEarthquakes(name)
+			if err != nil {
+				t.Fatalf("LoadEarthquakes(%q) error = %v", name, err)
+			}
+			if len(loaded.Features) != 2 || loaded.Features[0].Properties.Place != "line\nbreak" || loaded.Features[1].ID != "nd-2" {
+				t.Errorf("LoadEarthquakes(%q) features = %+v", name, loaded.Features)
+			}
+			if loaded.Type != "FeatureCollection" || loaded.Metadata.Count != 2 {
+				t.Errorf("LoadEarthquakes(%q) = type %q, count %d", name, loaded.Type, loaded.Metadata.Count)
+			}
+		}
+
+		loadedFaults, err := reader.LoadAllFaults(context.Background())
+		if err != nil || len(loadedFaults.Features) != 1 || loadedFaults.Features[0].ID != "f-1" {
+			t.Errorf("LoadAllFaults() = %+v, %v", loadedFaults, err)
+		}
+
+		faultFiles, _ := reader.ListFiles("faults")
+		if len(faultFiles) != 1 {
+			t.Fatalf("Expected one fault file, got %v", faultFiles)
+		}
+		if _, err := reader.FileTimestamp("faults", faultFiles[0]); err != nil {
+			t.Errorf("FileTimestamp() error = %v", err)
+		}
+	}
+}
+
+func TestJSONStorage_NDJSONLines(t *testing.T) {
+	outputDir := t.TempDir()
+	storage := NewJSONStorage(outputDir)
+	storage.SetFormat(FormatNDJSON)
+
+	earthquakes := &models.USGSResponse{
+		Type: "FeatureCollection",
+		Features: []models.Earthquake{
+			{Type: "Feature", ID: "nd-1"},
+			{Type: "Feature", ID: "nd-2"},
+			{Type: "Feature", ID: "nd-3"},
+		},
+	}
+	if err := storage.SaveEarthquakes(earthquakes, "lines"); err != nil {
+		t.Fatalf("SaveEarthquakes() error = %v", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(outputDir, "earthquakes", "lines.ndjson"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	lines := bytes.Split(bytes.TrimSuffix(raw, []byte("\n")), []byte("\n"))
+	if len(lines) != 3 {
+		t.Fatalf("Expected one line per feature, got %d:\n%s", len(lines), raw)
+	}
+	for i, line := range lines {
+		var earthquake models.Earthquake
+		if err := json.Unmarshal(line, &earthquake); err != nil {
+			t.Fatalf("Line %d is not a JSON object: %v", i+1, err)
+		}
+		if earthquake.ID != earthquakes.Features[i].ID {
+			t.Errorf("Line %d has ID %q, want %q", i+1, earthquake.ID, earthquakes.Features[i].ID)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"": FormatJSON, "json": FormatJSON, "ndjson": FormatNDJSON} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("csv"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -2,21 +2,28 @@ package storage
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"quakewatch-scraper/internal/config"
 	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/utils"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+// DefaultCopyThreshold is the batch size above which earthquakes are loaded with COPY
+const DefaultCopyThreshold = 1000
+
 // PostgreSQLStorage implements the Storage interface for PostgreSQL
 type PostgreSQLStorage struct {
-	db     *sqlx.DB
-	config *config.DatabaseConfig
+	db            *sqlx.DB
+	config        *config.DatabaseConfig
+	copyThreshold int
 }
 
 // NewPostgreSQLStorage creates a new PostgreSQL storage instance
@@ -25,50 +32,64 @@ func NewPostgreSQLStorage(config *config.DatabaseConfig) (*PostgreSQLStorage, er
 		return nil, fmt.Errorf("invalid database config: %w", err)
 	}
 
-	db, err := sqlx.Connect("postgres", config.GetDSN())
+	db, err := OpenDatabase(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, err
 	}
 
-	// Configure connection pool
-	db.SetMaxOpenConns(config.MaxOpenConns)
-	db.SetMaxIdleConns(config.MaxIdleConns)
-	db.SetConnMaxLifetime(config.ConnMaxLifetime)
-	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
-
 	// Test connection
 	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		db.Close()
+		return nil, utils.NewCollectionError(utils.ErrorTypeStorage, true, fmt.Errorf("failed to ping database: %w", err))
 	}
 
 	return &PostgreSQLStorage{
-		db:     db,
-		config: config,
+		db:            db,
+		config:        config,
+		copyThreshold: DefaultCopyThreshold,
 	}, nil
 }
 
-// SaveEarthquakes saves earthquake data to the database
-func (s *PostgreSQLStorage) SaveEarthquakes(ctx context.Context, earthquakes *models.USGSResponse) error {
-	if earthquakes == nil || len(earthquakes.Features) == 0 {
-		return nil
-	}
+// SetCopyThreshold sets the batch size above which UpsertEarthquakes switches from per-row
+// upserts to a COPY into a staging table
+func (s *PostgreSQLStorage) SetCopyThreshold(threshold int) {
+	s.copyThreshold = threshold
+}
 
-	tx, err := s.db.BeginTxx(ctx, nil)
+// OpenDatabase opens a PostgreSQL connection pool configured from the database config.
+// The connection is established lazily, so callers should ping to verify connectivity.
+func OpenDatabase(config *config.DatabaseConfig) (*sqlx.DB, error) {
+	db, err := sqlx.Open("postgres", config.GetDSN())
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
-	defer tx.Rollback()
 
-	query := `
-		INSERT INTO earthquakes (
-			usgs_id, magnitude, magnitude_type, place, time, updated, url, detail_url,
-			felt_count, cdi, mmi, alert, status, tsunami, significance, network, code,
-			ids, sources, types, nst, dmin, rms, gap, latitude, longitude, depth, title
-		) VALUES (
-			:usgs_id, :magnitude, :magnitude_type, :place, :time, :updated, :url, :detail_url,
-			:felt_count, :cdi, :mmi, :alert, :status, :tsunami, :significance, :network, :code,
-			:ids, :sources, :types, :nst, :dmin, :rms, :gap, :latitude, :longitude, :depth, :title
-		) ON CONFLICT (usgs_id) DO UPDATE SET
+	// Configure connection pool
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+
+	return db, nil
+}
+
+// SaveEarthquakes saves earthquake data to the database
+func (s *PostgreSQLStorage) SaveEarthquakes(ctx context.Context, earthquakes *models.USGSResponse) error {
+	_, _, err := s.UpsertEarthquakes(ctx, earthquakes)
+	return err
+}
+
+// earthquakeColumns lists the columns written for each earthquake, in COPY order
+var earthquakeColumns = []string{
+	"usgs_id", "magnitude", "magnitude_type", "place", "time", "updated", "url", "detail_url",
+	"felt_count", "cdi", "mmi", "alert", "status", "tsunami", "significance", "network", "code",
+	"ids", "sources", "types", "nst", "dmin", "rms", "gap", "latitude", "longitude", "depth", "title",
+}
+
+// earthquakeUpsertClause updates an existing earthquake from the conflicting row and reports
+// whether the row was inserted
+const earthquakeUpsertClause = `
+		ON CONFLICT (usgs_id) DO UPDATE SET
 			magnitude = EXCLUDED.magnitude,
 			magnitude_type = EXCLUDED.magnitude_type,
 			place = EXCLUDED.place,
@@ -96,70 +117,182 @@ func (s *PostgreSQLStorage) SaveEarthquakes(ctx context.Context, earthquakes *mo
 			depth = EXCLUDED.depth,
 			title = EXCLUDED.title,
 			updated_at = NOW()
+		RETURNING (xmax = 0) AS inserted
 	`
 
-	for _, earthquake := range earthquakes.Features {
-		// Extract coordinates
-		var latitude, longitude, depth float64
-		if len(earthquake.Geometry.Coordinates) >= 3 {
-			longitude = earthquake.Geometry.Coordinates[0]
-			latitude = earthquake.Geometry.Coordinates[1]
-			depth = earthquake.Geometry.Coordinates[2]
-		} else if len(earthquake.Geometry.Coordinates) >= 2 {
-			longitude = earthquake.Geometry.Coordinates[0]
-			latitude = earthquake.Geometry.Coordinates[1]
+// UpsertEarthquakes saves earthquake data to the database and reports how many rows were inserted and how many updated.
+// Batches larger than the copy threshold are loaded with COPY; smaller ones are upserted row by row.
+func (s *PostgreSQLStorage) UpsertEarthquakes(ctx context.Context, earthquakes *models.USGSResponse) (inserted, updated int, err error) {
+	if earthquakes == nil || len(earthquakes.Features) == 0 {
+		return 0, 0, nil
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if len(earthquakes.Features) > s.copyThreshold {
+		inserted, updated, err = copyUpsertEarthquakes(ctx, tx, earthquakes.Features)
+	} else {
+		inserted, updated, err = upsertEarthquakeRows(ctx, tx, earthquakes.Features)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return inserted, updated, nil
+}
+
+// upsertEarthquakeRows upserts earthquakes one row at a time with a prepared statement
+func upsertEarthquakeRows(ctx context.Context, tx *sqlx.Tx, earthquakes []models.Earthquake) (inserted, updated int, err error) {
+	query := fmt.Sprintf(`
+		INSERT INTO earthquakes (%s) VALUES (:%s)
+		%s`, strings.Join(earthquakeColumns, ", "), strings.Join(earthquakeColumns, ", :"), earthquakeUpsertClause)
+
+	stmt, err := tx.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to prepare earthquake insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, earthquake := range earthquakes {
+		var isNew bool
+		if err := stmt.QueryRowxContext(ctx, earthquakeParams(earthquake)).Scan(&isNew); err != nil {
+			return 0, 0, fmt.Errorf("failed to insert earthquake %s: %w", earthquake.ID, err)
+		}
+		if isNew {
+			inserted++
+		} else {
+			updated++
 		}
+	}
 
-		// Convert tsunami int to boolean
-		tsunami := earthquake.Properties.Tsunami > 0
+	return inserted, updated, nil
+}
 
-		params := map[string]interface{}{
-			"usgs_id":        earthquake.ID,
-			"magnitude":      earthquake.Properties.Mag,
-			"magnitude_type": earthquake.Properties.MagType,
-			"place":          earthquake.Properties.Place,
-			"time":           earthquake.Properties.GetTime(),
-			"updated":        earthquake.Properties.GetUpdated(),
-			"url":            earthquake.Properties.URL,
-			"detail_url":     earthquake.Properties.Detail,
-			"felt_count":     earthquake.Properties.Felt,
-			"cdi":            earthquake.Properties.CDI,
-			"mmi":            earthquake.Properties.MMI,
-			"alert":          earthquake.Properties.Alert,
-			"status":         earthquake.Properties.Status,
-			"tsunami":        tsunami,
-			"significance":   earthquake.Properties.Sig,
-			"network":        earthquake.Properties.Net,
-			"code":           earthquake.Properties.Code,
-			"ids":            earthquake.Properties.IDs,
-			"sources":        earthquake.Properties.Sources,
-			"types":          earthquake.Properties.Types,
-			"nst":            earthquake.Properties.Nst,
-			"dmin":           earthquake.Properties.Dmin,
-			"rms":            earthquake.Properties.RMS,
-			"gap":            earthquake.Properties.Gap,
-			"latitude":       latitude,
-			"longitude":      longitude,
-			"depth":          depth,
-			"title":          earthquake.Properties.Title,
+// copyUpsertEarthquakes streams earthquakes into a temporary staging table with COPY and upserts
+// them into earthquakes with a single statement. When a batch contains the same event more than
+// once, the copy with the latest update time wins.
+func copyUpsertEarthquakes(ctx context.Context, tx *sqlx.Tx, earthquakes []models.Earthquake) (inserted, updated int, err error) {
+	columns := strings.Join(earthquakeColumns, ", ")
+
+	staging := fmt.Sprintf(`CREATE TEMP TABLE earthquakes_staging ON COMMIT DROP AS SELECT %s FROM earthquakes WITH NO DATA`, columns)
+	if _, err := tx.ExecContext(ctx, staging); err != nil {
+		return 0, 0, fmt.Errorf("failed to create staging table: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("earthquakes_staging", earthquakeColumns...))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to prepare earthquake copy: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, earthquake := range earthquakes {
+		params := earthquakeParams(earthquake)
+		values := make([]interface{}, len(earthquakeColumns))
+		for i, column := range earthquakeColumns {
+			values[i] = params[column]
 		}
+		if _, err := stmt.ExecContext(ctx, values...); err != nil {
+			return 0, 0, fmt.Errorf("failed to copy earthquake %s: %w", earthquake.ID, err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to flush earthquake copy: %w", err)
+	}
 
-		_, err := tx.NamedExecContext(ctx, query, params)
-		if err != nil {
-			return fmt.Errorf("failed to insert earthquake %s: %w", earthquake.ID, err)
+	query := fmt.Sprintf(`
+		INSERT INTO earthquakes (%s)
+		SELECT DISTINCT ON (usgs_id) %s FROM earthquakes_staging ORDER BY usgs_id, updated DESC
+		%s`, columns, columns, earthquakeUpsertClause)
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to upsert staged earthquakes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var isNew bool
+		if err := rows.Scan(&isNew); err != nil {
+			return 0, 0, fmt.Errorf("failed to read upsert result: %w", err)
+		}
+		if isNew {
+			inserted++
+		} else {
+			updated++
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to upsert staged earthquakes: %w", err)
+	}
+
+	return inserted, updated, nil
+}
+
+// earthquakeParams maps an earthquake to its column values
+func earthquakeParams(earthquake models.Earthquake) map[string]interface{} {
+	// Extract coordinates
+	var latitude, longitude, depth float64
+	if len(earthquake.Geometry.Coordinates) >= 3 {
+		longitude = earthquake.Geometry.Coordinates[0]
+		latitude = earthquake.Geometry.Coordinates[1]
+		depth = earthquake.Geometry.Coordinates[2]
+	} else if len(earthquake.Geometry.Coordinates) >= 2 {
+		longitude = earthquake.Geometry.Coordinates[0]
+		latitude = earthquake.Geometry.Coordinates[1]
+	}
 
-	return tx.Commit()
+	// Convert tsunami int to boolean
+	tsunami := earthquake.Properties.Tsunami > 0
+
+	return map[string]interface{}{
+		"usgs_id":        earthquake.ID,
+		"magnitude":      earthquake.Properties.Mag,
+		"magnitude_type": earthquake.Properties.MagType,
+		"place":          earthquake.Properties.Place,
+		"time":           earthquake.Properties.GetTime(),
+		"updated":        earthquake.Properties.GetUpdated(),
+		"url":            earthquake.Properties.URL,
+		"detail_url":     earthquake.Properties.Detail,
+		"felt_count":     earthquake.Properties.Felt,
+		"cdi":            earthquake.Properties.CDI,
+		"mmi":            earthquake.Properties.MMI,
+		"alert":          earthquake.Properties.Alert,
+		"status":         earthquake.Properties.Status,
+		"tsunami":        tsunami,
+		"significance":   earthquake.Properties.Sig,
+		"network":        earthquake.Properties.Net,
+		"code":           earthquake.Properties.Code,
+		"ids":            earthquake.Properties.IDs,
+		"sources":        earthquake.Properties.Sources,
+		"types":          earthquake.Properties.Types,
+		"nst":            earthquake.Properties.Nst,
+		"dmin":           earthquake.Properties.Dmin,
+		"rms":            earthquake.Properties.RMS,
+		"gap":            earthquake.Properties.Gap,
+		"latitude":       latitude,
+		"longitude":      longitude,
+		"depth":          depth,
+		"title":          earthquake.Properties.Title,
+	}
 }
 
+// earthquakeSelectColumns are the columns scanEarthquakes reads
+const earthquakeSelectColumns = `
+			id, usgs_id, magnitude, magnitude_type, place, time, updated, url, detail_url,
+			felt_count, cdi, mmi, alert, status, tsunami, significance, network, code,
+			ids, sources, types, nst, dmin, rms, gap, latitude, longitude, depth, title`
+
 // LoadEarthquakes loads earthquakes from the database
 func (s *PostgreSQLStorage) LoadEarthquakes(ctx context.Context, limit int, offset int) (*models.USGSResponse, error) {
 	query := `
-		SELECT 
-			id, usgs_id, magnitude, magnitude_type, place, time, updated, url, detail_url,
-			felt_count, cdi, mmi, alert, status, tsunami, significance, network, code,
-			ids, sources, types, nst, dmin, rms, gap, latitude, longitude, depth, title
+		SELECT ` + earthquakeSelectColumns + `
 		FROM earthquakes 
 		ORDER BY time DESC 
 		LIMIT $1 OFFSET $2
@@ -171,38 +304,100 @@ func (s *PostgreSQLStorage) LoadEarthquakes(ctx context.Context, limit int, offs
 	}
 	defer rows.Close()
 
-	var earthquakes []models.Earthquake
+	earthquakes, err := scanEarthquakes(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.USGSResponse{
+		Type:     "FeatureCollection",
+		Features: earthquakes,
+	}, nil
+}
+
+// QueryEarthquakes returns the earthquakes matching the query, most recent first
+func (s *PostgreSQLStorage) QueryEarthquakes(ctx context.Context, query EarthquakeQuery) ([]models.Earthquake, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if query.MinMagnitude != nil {
+		where("magnitude >= $%d", *query.MinMagnitude)
+	}
+	if query.MaxMagnitude != nil {
+		where("magnitude <= $%d", *query.MaxMagnitude)
+	}
+	if !query.StartTime.IsZero() {
+		where("time >= $%d", query.StartTime)
+	}
+	if !query.EndTime.IsZero() {
+		where("time < $%d", query.EndTime)
+	}
+	if query.BBox != nil {
+		where("longitude >= $%d", query.BBox.MinLon)
+		where("longitude <= $%d", query.BBox.MaxLon)
+		where("latitude >= $%d", query.BBox.MinLat)
+		where("latitude <= $%d", query.BBox.MaxLat)
+	}
+
+	sqlQuery := "SELECT " + earthquakeSelectColumns + " FROM earthquakes"
+	if len(conditions) > 0 {
+		sqlQuery += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	sqlQuery += " ORDER BY time DESC"
+	if query.Limit > 0 {
+		args = append(args, query.Limit)
+		sqlQuery += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.db.QueryxContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query earthquakes: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEarthquakes(rows)
+}
+
+// scanEarthquakes reads earthquakeSelectColumns rows into earthquakes; it never returns a nil slice
+func scanEarthquakes(rows *sqlx.Rows) ([]models.Earthquake, error) {
+	earthquakes := []models.Earthquake{}
 	for rows.Next() {
+		// Columns without a NOT NULL constraint are scanned into nullable types; NULL strings
+		// become empty strings, a NULL significance becomes 0 and a NULL magnitude stays unknown
 		var eq struct {
-			ID            int       `db:"id"`
-			USGSID        string    `db:"usgs_id"`
-			Magnitude     float64   `db:"magnitude"`
-			MagnitudeType string    `db:"magnitude_type"`
-			Place         string    `db:"place"`
-			Time          time.Time `db:"time"`
-			Updated       time.Time `db:"updated"`
-			URL           string    `db:"url"`
-			DetailURL     string    `db:"detail_url"`
-			FeltCount     *int      `db:"felt_count"`
-			CDI           *float64  `db:"cdi"`
-			MMI           *float64  `db:"mmi"`
-			Alert         string    `db:"alert"`
-			Status        string    `db:"status"`
-			Tsunami       bool      `db:"tsunami"`
-			Significance  int       `db:"significance"`
-			Network       string    `db:"network"`
-			Code          string    `db:"code"`
-			IDs           string    `db:"ids"`
-			Sources       string    `db:"sources"`
-			Types         string    `db:"types"`
-			Nst           *int      `db:"nst"`
-			Dmin          *float64  `db:"dmin"`
-			RMS           *float64  `db:"rms"`
-			Gap           *float64  `db:"gap"`
-			Latitude      float64   `db:"latitude"`
-			Longitude     float64   `db:"longitude"`
-			Depth         *float64  `db:"depth"`
-			Title         string    `db:"title"`
+			ID            int            `db:"id"`
+			USGSID        string         `db:"usgs_id"`
+			Magnitude     *float64       `db:"magnitude"`
+			MagnitudeType sql.NullString `db:"magnitude_type"`
+			Place         string         `db:"place"`
+			Time          time.Time      `db:"time"`
+			Updated       time.Time      `db:"updated"`
+			URL           sql.NullString `db:"url"`
+			DetailURL     sql.NullString `db:"detail_url"`
+			FeltCount     *int           `db:"felt_count"`
+			CDI           *float64       `db:"cdi"`
+			MMI           *float64       `db:"mmi"`
+			Alert         sql.NullString `db:"alert"`
+			Status        sql.NullString `db:"status"`
+			Tsunami       bool           `db:"tsunami"`
+			Significance  sql.NullInt64  `db:"significance"`
+			Network       sql.NullString `db:"network"`
+			Code          sql.NullString `db:"code"`
+			IDs           sql.NullString `db:"ids"`
+			Sources       sql.NullString `db:"sources"`
+			Types         sql.NullString `db:"types"`
+			Nst           *int           `db:"nst"`
+			Dmin          *float64       `db:"dmin"`
+			RMS           *float64       `db:"rms"`
+			Gap           *float64       `db:"gap"`
+			Latitude      float64        `db:"latitude"`
+			Longitude     float64        `db:"longitude"`
+			Depth         *float64       `db:"depth"`
+			Title         sql.NullString `db:"title"`
 		}
 
 		if err := rows.StructScan(&eq); err != nil {
@@ -223,27 +418,27 @@ func (s *PostgreSQLStorage) LoadEarthquakes(ctx context.Context, limit int, offs
 				Place:   eq.Place,
 				Time:    eq.Time.UnixMilli(),
 				Updated: eq.Updated.UnixMilli(),
-				URL:     eq.URL,
-				Detail:  eq.DetailURL,
+				URL:     eq.URL.String,
+				Detail:  eq.DetailURL.String,
 				Felt:    eq.FeltCount,
 				CDI:     eq.CDI,
 				MMI:     eq.MMI,
-				Alert:   eq.Alert,
-				Status:  eq.Status,
+				Alert:   eq.Alert.String,
+				Status:  eq.Status.String,
 				Tsunami: tsunami,
-				Sig:     eq.Significance,
-				Net:     eq.Network,
-				Code:    eq.Code,
-				IDs:     eq.IDs,
-				Sources: eq.Sources,
-				Types:   eq.Types,
+				Sig:     int(eq.Significance.Int64),
+				Net:     eq.Network.String,
+				Code:    eq.Code.String,
+				IDs:     eq.IDs.String,
+				Sources: eq.Sources.String,
+				Types:   eq.Types.String,
 				Nst:     eq.Nst,
 				Dmin:    eq.Dmin,
 				RMS:     eq.RMS,
 				Gap:     eq.Gap,
-				MagType: eq.MagnitudeType,
+				MagType: eq.MagnitudeType.String,
 				Type:    "earthquake",
-				Title:   eq.Title,
+				Title:   eq.Title.String,
 			},
 			Geometry: models.Geometry{
 				Type:        "Point",
@@ -258,32 +453,38 @@ func (s *PostgreSQLStorage) LoadEarthquakes(ctx context.Context, limit int, offs
 
 		earthquakes = append(earthquakes, earthquake)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read earthquakes: %w", err)
+	}
 
-	return &models.USGSResponse{
-		Type:     "FeatureCollection",
-		Features: earthquakes,
-	}, nil
+	return earthquakes, nil
 }
 
 // SaveFaults saves fault data to the database
 func (s *PostgreSQLStorage) SaveFaults(ctx context.Context, faults *models.Fault) error {
+	_, _, err := s.UpsertFaults(ctx, faults)
+	return err
+}
+
+// UpsertFaults saves fault data to the database and reports how many rows were inserted and how many updated
+func (s *PostgreSQLStorage) UpsertFaults(ctx context.Context, faults *models.Fault) (inserted, updated int, err error) {
 	if faults == nil || len(faults.Features) == 0 {
-		return nil
+		return 0, 0, nil
 	}
 
 	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
 	query := `
 		INSERT INTO faults (
 			fault_id, name, fault_type, slip_rate, slip_type, dip, rake, length, width,
-			max_magnitude, description, source, geometry_type, coordinates
+			max_magnitude, description, source, geometry_type, coordinates, raw_properties
 		) VALUES (
 			:fault_id, :name, :fault_type, :slip_rate, :slip_type, :dip, :rake, :length, :width,
-			:max_magnitude, :description, :source, :geometry_type, :coordinates
+			:max_magnitude, :description, :source, :geometry_type, :coordinates, :raw_properties
 		) ON CONFLICT (fault_id) DO UPDATE SET
 			name = EXCLUDED.name,
 			fault_type = EXCLUDED.fault_type,
@@ -298,39 +499,65 @@ func (s *PostgreSQLStorage) SaveFaults(ctx context.Context, faults *models.Fault
 			source = EXCLUDED.source,
 			geometry_type = EXCLUDED.geometry_type,
 			coordinates = EXCLUDED.coordinates,
+			raw_properties = EXCLUDED.raw_properties,
 			updated_at = NOW()
+		RETURNING (xmax = 0) AS inserted
 	`
 
+	stmt, err := tx.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to prepare fault insert: %w", err)
+	}
+	defer stmt.Close()
+
 	for _, fault := range faults.Features {
 		coordinates, err := json.Marshal(fault.Geometry.Coordinates)
 		if err != nil {
-			return fmt.Errorf("failed to marshal coordinates for fault %s: %w", fault.Properties.ID, err)
+			return 0, 0, fmt.Errorf("failed to marshal coordinates for fault %s: %w", fault.Properties.ID, err)
+		}
+		// Faults without extra properties store NULL
+		var rawProperties interface{}
+		if len(fault.Properties.RawProperties) > 0 {
+			raw, err := json.Marshal(fault.Properties.RawProperties)
+			if err != nil {
+				return 0, 0, fmt.Errorf("failed to marshal raw properties for fault %s: %w", fault.Properties.ID, err)
+			}
+			rawProperties = raw
 		}
 
 		params := map[string]interface{}{
-			"fault_id":      fault.Properties.ID,
-			"name":          fault.Properties.Name,
-			"fault_type":    fault.Properties.Type,
-			"slip_rate":     fault.Properties.SlipRate,
-			"slip_type":     fault.Properties.SlipType,
-			"dip":           fault.Properties.Dip,
-			"rake":          fault.Properties.Rake,
-			"length":        fault.Properties.Length,
-			"width":         fault.Properties.Width,
-			"max_magnitude": fault.Properties.MaxMagnitude,
-			"description":   fault.Properties.Description,
-			"source":        fault.Properties.Source,
-			"geometry_type": fault.Geometry.Type,
-			"coordinates":   coordinates,
+			"fault_id":       fault.Properties.ID,
+			"name":           fault.Properties.Name,
+			"fault_type":     fault.Properties.Type,
+			"slip_rate":      fault.Properties.SlipRate,
+			"slip_type":      fault.Properties.SlipType,
+			"dip":            fault.Properties.Dip,
+			"rake":           fault.Properties.Rake,
+			"length":         fault.Properties.Length,
+			"width":          fault.Properties.Width,
+			"max_magnitude":  fault.Properties.MaxMagnitude,
+			"description":    fault.Properties.Description,
+			"source":         fault.Properties.Source,
+			"geometry_type":  fault.Geometry.Type,
+			"coordinates":    coordinates,
+			"raw_properties": rawProperties,
 		}
 
-		_, err = tx.NamedExecContext(ctx, query, params)
-		if err != nil {
-			return fmt.Errorf("failed to insert fault %s: %w", fault.Properties.ID, err)
+		var isNew bool
+		if err := stmt.QueryRowxContext(ctx, params).Scan(&isNew); err != nil {
+			return 0, 0, fmt.Errorf("failed to insert fault %s: %w", fault.Properties.ID, err)
+		}
+		if isNew {
+			inserted++
+		} else {
+			updated++
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return inserted, updated, nil
 }
 
 // LoadFaults loads faults from the database
@@ -338,7 +565,7 @@ func (s *PostgreSQLStorage) LoadFaults(ctx context.Context, limit int, offset in
 	query := `
 		SELECT 
 			id, fault_id, name, fault_type, slip_rate, slip_type, dip, rake, length, width,
-			max_magnitude, description, source, geometry_type, coordinates
+			max_magnitude, description, source, geometry_type, coordinates, raw_properties
 		FROM faults 
 		ORDER BY name 
 		LIMIT $1 OFFSET $2
@@ -352,22 +579,24 @@ func (s *PostgreSQLStorage) LoadFaults(ctx context.Context, limit int, offset in
 
 	var faultFeatures []models.FaultFeature
 	for rows.Next() {
+		// Nullable text columns are scanned into sql.NullString; NULL becomes an empty string
 		var f struct {
 			ID           int             `db:"id"`
 			FaultID      string          `db:"fault_id"`
 			Name         string          `db:"name"`
-			FaultType    string          `db:"fault_type"`
+			FaultType    sql.NullString  `db:"fault_type"`
 			SlipRate     *float64        `db:"slip_rate"`
-			SlipType     string          `db:"slip_type"`
+			SlipType     sql.NullString  `db:"slip_type"`
 			Dip          *float64        `db:"dip"`
 			Rake         *float64        `db:"rake"`
 			Length       *float64        `db:"length"`
 			Width        *float64        `db:"width"`
 			MaxMagnitude *float64        `db:"max_magnitude"`
-			Description  string          `db:"description"`
-			Source       string          `db:"source"`
-			GeometryType string          `db:"geometry_type"`
+			Description  sql.NullString  `db:"description"`
+			Source       sql.NullString  `db:"source"`
+			GeometryType sql.NullString  `db:"geometry_type"`
 			Coordinates  json.RawMessage `db:"coordinates"`
+			RawProps     json.RawMessage `db:"raw_properties"`
 		}
 
 		if err := rows.StructScan(&f); err != nil {
@@ -378,26 +607,33 @@ func (s *PostgreSQLStorage) LoadFaults(ctx context.Context, limit int, offset in
 		if err := json.Unmarshal(f.Coordinates, &coordinates); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal coordinates for fault %s: %w", f.FaultID, err)
 		}
+		var rawProperties map[string]json.RawMessage
+		if f.RawProps != nil {
+			if err := json.Unmarshal(f.RawProps, &rawProperties); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal raw properties for fault %s: %w", f.FaultID, err)
+			}
+		}
 
 		faultFeature := models.FaultFeature{
 			Type: "Feature",
 			ID:   f.FaultID,
 			Properties: models.FaultProperties{
-				ID:           f.FaultID,
-				Name:         f.Name,
-				Type:         f.FaultType,
-				SlipRate:     f.SlipRate,
-				SlipType:     f.SlipType,
-				Dip:          f.Dip,
-				Rake:         f.Rake,
-				Length:       f.Length,
-				Width:        f.Width,
-				MaxMagnitude: f.MaxMagnitude,
-				Description:  f.Description,
-				Source:       f.Source,
+				ID:            f.FaultID,
+				Name:          f.Name,
+				Type:          f.FaultType.String,
+				SlipRate:      f.SlipRate,
+				SlipType:      f.SlipType.String,
+				Dip:           f.Dip,
+				Rake:          f.Rake,
+				Length:        f.Length,
+				Width:         f.Width,
+				MaxMagnitude:  f.MaxMagnitude,
+				Description:   f.Description.String,
+				Source:        f.Source.String,
+				RawProperties: rawProperties,
 			},
 			Geometry: models.FaultGeometry{
-				Type:        f.GeometryType,
+				Type:        f.GeometryType.String,
 				Coordinates: coordinates,
 			},
 		}
@@ -436,6 +672,51 @@ func (s *PostgreSQLStorage) LogCollection(ctx context.Context, dataType, source
 	return nil
 }
 
+// CountOlderThan counts the earthquakes and collection logs that PruneOlderThan would delete
+func (s *PostgreSQLStorage) CountOlderThan(ctx context.Context, cutoff time.Time) (earthquakes, logs int, err error) {
+	if err := s.db.GetContext(ctx, &earthquakes, "SELECT COUNT(*) FROM earthquakes WHERE time < $1", cutoff); err != nil {
+		return 0, 0, fmt.Errorf("failed to count old earthquakes: %w", err)
+	}
+	if err := s.db.GetContext(ctx, &logs, "SELECT COUNT(*) FROM collection_logs WHERE start_time < $1", cutoff); err != nil {
+		return 0, 0, fmt.Errorf("failed to count old collection logs: %w", err)
+	}
+	return earthquakes, logs, nil
+}
+
+// PruneOlderThan deletes earthquakes that occurred before cutoff and collection logs started
+// before it, returning the number of rows deleted from both tables
+func (s *PostgreSQLStorage) PruneOlderThan(ctx context.Context, cutoff time.Time) (deleted int, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queries := []struct {
+		table string
+		query string
+	}{
+		{table: "earthquakes", query: "DELETE FROM earthquakes WHERE time < $1"},
+		{table: "collection logs", query: "DELETE FROM collection_logs WHERE start_time < $1"},
+	}
+	for _, q := range queries {
+		result, err := tx.ExecContext(ctx, q.query, cutoff)
+		if err != nil {
+			return 0, fmt.Errorf("failed to prune %s: %w", q.table, err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to count pruned %s: %w", q.table, err)
+		}
+		deleted += int(rows)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return deleted, nil
+}
+
 // GetStatistics returns database statistics
 func (s *PostgreSQLStorage) GetStatistics(ctx context.Context) (*Statistics, error) {
 	query := `
@@ -467,19 +748,20 @@ func (s *PostgreSQLStorage) GetEarthquakeByID(ctx context.Context, usgsID string
 	return nil, fmt.Errorf("not implemented")
 }
 
+// GetEarthquakesByTimeRange returns the earthquakes between two Unix millisecond times, start
+// inclusive and end exclusive
 func (s *PostgreSQLStorage) GetEarthquakesByTimeRange(ctx context.Context, startTime, endTime int64) ([]models.Earthquake, error) {
-	// TODO: Implement
-	return nil, fmt.Errorf("not implemented")
+	return s.QueryEarthquakes(ctx, EarthquakeQuery{StartTime: time.UnixMilli(startTime), EndTime: time.UnixMilli(endTime)})
 }
 
+// GetEarthquakesByMagnitudeRange returns the earthquakes with a magnitude between minMag and maxMag inclusive
 func (s *PostgreSQLStorage) GetEarthquakesByMagnitudeRange(ctx context.Context, minMag, maxMag float64) ([]models.Earthquake, error) {
-	// TODO: Implement
-	return nil, fmt.Errorf("not implemented")
+	return s.QueryEarthquakes(ctx, EarthquakeQuery{MinMagnitude: &minMag, MaxMagnitude: &maxMag})
 }
 
+// GetEarthquakesByLocation returns the earthquakes within a latitude/longitude rectangle
 func (s *PostgreSQLStorage) GetEarthquakesByLocation(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]models.Earthquake, error) {
-	// TODO: Implement
-	return nil, fmt.Errorf("not implemented")
+	return s.QueryEarthquakes(ctx, EarthquakeQuery{BBox: &BoundingBox{MinLon: minLon, MinLat: minLat, MaxLon: maxLon, MaxLat: maxLat}})
 }
 
 func (s *PostgreSQLStorage) GetSignificantEarthquakes(ctx context.Context, startTime, endTime int64) ([]models.Earthquake, error) {
@@ -2,6 +2,9 @@ package storage
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -10,10 +13,13 @@ import (
 	"quakewatch-scraper/internal/models"
 )
 
-func TestPostgreSQLStorage_Integration(t *testing.T) {
+// newIntegrationStorage connects to the integration test database, skipping unless INTEGRATION_TESTS=true
+func newIntegrationStorage(tb testing.TB) *PostgreSQLStorage {
+	tb.Helper()
+
 	// Skip if not running integration tests
 	if os.Getenv("INTEGRATION_TESTS") != "true" {
-		t.Skip("Skipping integration test. Set INTEGRATION_TESTS=true to run")
+		tb.Skip("Skipping integration test. Set INTEGRATION_TESTS=true to run")
 	}
 
 	// Create test configuration
@@ -29,9 +35,41 @@ func TestPostgreSQLStorage_Integration(t *testing.T) {
 	// Create storage instance
 	storage, err := NewPostgreSQLStorage(config)
 	if err != nil {
-		t.Fatalf("Failed to create PostgreSQL storage: %v", err)
+		tb.Fatalf("Failed to create PostgreSQL storage: %v", err)
+	}
+	tb.Cleanup(func() { storage.Close() })
+
+	return storage
+}
+
+// testEarthquakes builds n earthquakes with IDs prefixed by prefix
+func testEarthquakes(prefix string, n int) []models.Earthquake {
+	now := time.Now().UnixMilli()
+	earthquakes := make([]models.Earthquake, n)
+	for i := range earthquakes {
+		earthquakes[i] = models.Earthquake{
+			Type: "Feature",
+			ID:   fmt.Sprintf("%s-%d", prefix, i),
+			Properties: models.EarthquakeProperties{
+				Mag:     models.Float64(float64(i%70) / 10),
+				Place:   "Test Location",
+				Time:    now,
+				Updated: now,
+				Status:  "reviewed",
+				Net:     "us",
+				Title:   "Test Earthquake",
+			},
+			Geometry: models.Geometry{
+				Type:        "Point",
+				Coordinates: []float64{-122.4194, 37.7749, 10.0},
+			},
+		}
 	}
-	defer storage.Close()
+	return earthquakes
+}
+
+func TestPostgreSQLStorage_Integration(t *testing.T) {
+	storage := newIntegrationStorage(t)
 
 	// Test earthquake operations
 	t.Run("EarthquakeOperations", func(t *testing.T) {
@@ -47,6 +85,36 @@ func TestPostgreSQLStorage_Integration(t *testing.T) {
 	t.Run("Statistics", func(t *testing.T) {
 		testStatistics(t, storage)
 	})
+
+	// Test importing JSON files
+	t.Run("ImportJSON", func(t *testing.T) {
+		testImportJSON(t, storage)
+	})
+
+	// Test the COPY path used for large batches
+	t.Run("CopyUpsert", func(t *testing.T) {
+		testCopyUpsert(t, storage)
+	})
+
+	// Test pruning by retention window
+	t.Run("PruneOlderThan", func(t *testing.T) {
+		testPruneOlderThan(t, storage)
+	})
+
+	// Test loading rows with NULL text columns
+	t.Run("NullColumns", func(t *testing.T) {
+		testNullColumns(t, storage)
+	})
+
+	// Test storing events without a magnitude
+	t.Run("NullMagnitude", func(t *testing.T) {
+		testNullMagnitude(t, storage)
+	})
+
+	// Test filtered earthquake queries
+	t.Run("QueryEarthquakes", func(t *testing.T) {
+		testQueryEarthquakes(t, storage)
+	})
 }
 
 func testEarthquakeOperations(t *testing.T, storage *PostgreSQLStorage) {
@@ -60,7 +128,7 @@ func testEarthquakeOperations(t *testing.T, storage *PostgreSQLStorage) {
 				Type: "Feature",
 				ID:   "test-earthquake-1",
 				Properties: models.EarthquakeProperties{
-					Mag:     5.5,
+					Mag:     models.Float64(5.5),
 					Place:   "Test Location",
 					Time:    time.Now().UnixMilli(),
 					Updated: time.Now().UnixMilli(),
@@ -99,8 +167,8 @@ func testEarthquakeOperations(t *testing.T, storage *PostgreSQLStorage) {
 	for _, eq := range loaded.Features {
 		if eq.ID == "test-earthquake-1" {
 			found = true
-			if eq.Properties.Mag != 5.5 {
-				t.Errorf("Expected magnitude 5.5, got %f", eq.Properties.Mag)
+			if eq.Properties.GetMagnitude() != "5.5" {
+				t.Errorf("Expected magnitude 5.5, got %s", eq.Properties.GetMagnitude())
 			}
 			break
 		}
@@ -122,9 +190,10 @@ func testFaultOperations(t *testing.T, storage *PostgreSQLStorage) {
 				Type: "Feature",
 				ID:   "test-fault-1",
 				Properties: models.FaultProperties{
-					ID:   "test-fault-1",
-					Name: "Test Fault",
-					Type: "strike-slip",
+					ID:            "test-fault-1",
+					Name:          "Test Fault",
+					Type:          "strike-slip",
+					RawProperties: map[string]json.RawMessage{"activity_class": json.RawMessage(`"holocene"`)},
 				},
 				Geometry: models.FaultGeometry{
 					Type: "LineString",
@@ -161,6 +230,9 @@ func testFaultOperations(t *testing.T, storage *PostgreSQLStorage) {
 			if fault.Properties.Name != "Test Fault" {
 				t.Errorf("Expected name 'Test Fault', got %s", fault.Properties.Name)
 			}
+			if got := string(fault.Properties.RawProperties["activity_class"]); got != `"holocene"` {
+				t.Errorf("Expected raw property activity_class \"holocene\", got %s", got)
+			}
 			break
 		}
 	}
@@ -189,6 +261,168 @@ func testStatistics(t *testing.T, storage *PostgreSQLStorage) {
 	}
 }
 
+func testImportJSON(t *testing.T, storage *PostgreSQLStorage) {
+	ctx := context.Background()
+	source := NewJSONStorage("testdata/import")
+
+	// The fixture holds 4 records in 2 files, one event appearing in both
+	result, err := storage.ImportJSON(ctx, source, "earthquakes")
+	if err != nil {
+		t.Fatalf("Failed to import earthquakes: %v", err)
+	}
+	if result.Files != 2 || result.Inserted+result.Updated != 4 || result.Updated < 1 {
+		t.Errorf("First import = %+v, want 2 files, 4 records and the duplicate updated", result)
+	}
+
+	// Importing again only updates existing rows
+	result, err = storage.ImportJSON(ctx, source, "earthquakes")
+	if err != nil {
+		t.Fatalf("Failed to re-import earthquakes: %v", err)
+	}
+	if result.Inserted != 0 || result.Updated != 4 {
+		t.Errorf("Second import = %+v, want 0 inserted and 4 updated", result)
+	}
+
+	// The later file wins for the duplicated event
+	loaded, err := storage.LoadEarthquakes(ctx, 1000, 0)
+	if err != nil {
+		t.Fatalf("Failed to load earthquakes: %v", err)
+	}
+	for _, eq := range loaded.Features {
+		if eq.ID == "import-test-2" && eq.Properties.GetMagnitude() != "3.2" {
+			t.Errorf("Expected magnitude 3.2 for import-test-2, got %s", eq.Properties.GetMagnitude())
+		}
+	}
+}
+
+func testCopyUpsert(t *testing.T, storage *PostgreSQLStorage) {
+	ctx := context.Background()
+	storage.SetCopyThreshold(0)
+	defer storage.SetCopyThreshold(DefaultCopyThreshold)
+
+	// A batch containing the same event twice keeps the later update
+	features := testEarthquakes("copy-test", 3)
+	duplicate := features[1]
+	duplicate.Properties.Mag = models.Float64(6.1)
+	duplicate.Properties.Updated++
+	features = append(features, duplicate)
+
+	inserted, updated, err := storage.UpsertEarthquakes(ctx, &models.USGSResponse{Features: features})
+	if err != nil {
+		t.Fatalf("Failed to copy earthquakes: %v", err)
+	}
+	if inserted != 3 || updated != 0 {
+		t.Errorf("First copy = %d inserted, %d updated, want 3 and 0", inserted, updated)
+	}
+
+	loaded, err := storage.LoadEarthquakes(ctx, 1000, 0)
+	if err != nil {
+		t.Fatalf("Failed to load earthquakes: %v", err)
+	}
+	for _, eq := range loaded.Features {
+		if eq.ID == "copy-test-1" && eq.Properties.GetMagnitude() != "6.1" {
+			t.Errorf("Expected magnitude 6.1 for copy-test-1, got %s", eq.Properties.GetMagnitude())
+		}
+	}
+
+	// Copying the same events again only updates them
+	inserted, updated, err = storage.UpsertEarthquakes(ctx, &models.USGSResponse{Features: features[:3]})
+	if err != nil {
+		t.Fatalf("Failed to re-copy earthquakes: %v", err)
+	}
+	if inserted != 0 || updated != 3 {
+		t.Errorf("Second copy = %d inserted, %d updated, want 0 and 3", inserted, updated)
+	}
+}
+
+func testPruneOlderThan(t *testing.T, storage *PostgreSQLStorage) {
+	ctx := context.Background()
+	now := time.Now()
+	oldTime := now.AddDate(-2, 0, 0)
+
+	features := testEarthquakes("prune-test", 2)
+	features[0].Properties.Time = oldTime.UnixMilli()
+	if err := storage.SaveEarthquakes(ctx, &models.USGSResponse{Features: features}); err != nil {
+		t.Fatalf("Failed to save earthquakes: %v", err)
+	}
+	if err := storage.LogCollection(ctx, "earthquakes", "prune-test", oldTime.UnixMilli(), 1, "completed", ""); err != nil {
+		t.Fatalf("Failed to log old collection: %v", err)
+	}
+	if err := storage.LogCollection(ctx, "earthquakes", "prune-test", now.UnixMilli(), 1, "completed", ""); err != nil {
+		t.Fatalf("Failed to log new collection: %v", err)
+	}
+
+	cutoff := now.AddDate(-1, 0, 0)
+	earthquakes, logs, err := storage.CountOlderThan(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("Failed to count old records: %v", err)
+	}
+	if earthquakes < 1 || logs < 1 {
+		t.Errorf("Expected at least one old earthquake and log, got %d and %d", earthquakes, logs)
+	}
+
+	deleted, err := storage.PruneOlderThan(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	if deleted != earthquakes+logs {
+		t.Errorf("Expected %d deleted rows, got %d", earthquakes+logs, deleted)
+	}
+
+	// Only the old earthquake is gone
+	loaded, err := storage.LoadEarthquakes(ctx, 1000, 0)
+	if err != nil {
+		t.Fatalf("Failed to load earthquakes: %v", err)
+	}
+	remaining := make(map[string]bool)
+	for _, eq := range loaded.Features {
+		remaining[eq.ID] = true
+	}
+	if remaining["prune-test-0"] {
+		t.Error("Expected the old earthquake to be pruned")
+	}
+	if !remaining["prune-test-1"] {
+		t.Error("Expected the recent earthquake to be kept")
+	}
+
+	earthquakes, logs, err = storage.CountOlderThan(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("Failed to count old records: %v", err)
+	}
+	if earthquakes != 0 || logs != 0 {
+		t.Errorf("Expected no old records after pruning, got %d earthquakes and %d logs", earthquakes, logs)
+	}
+}
+
+// BenchmarkUpsertEarthquakes compares per-row upserts with the COPY path on a 10,000 event batch.
+// Run with: INTEGRATION_TESTS=true go test ./internal/storage -run '^$' -bench UpsertEarthquakes
+func BenchmarkUpsertEarthquakes(b *testing.B) {
+	storage := newIntegrationStorage(b)
+	ctx := context.Background()
+	batch := &models.USGSResponse{Features: testEarthquakes("bench", 10000)}
+
+	paths := []struct {
+		name      string
+		threshold int
+	}{
+		{name: "Rows", threshold: len(batch.Features)},
+		{name: "Copy", threshold: 0},
+	}
+
+	for _, path := range paths {
+		b.Run(path.name, func(b *testing.B) {
+			storage.SetCopyThreshold(path.threshold)
+			defer storage.SetCopyThreshold(DefaultCopyThreshold)
+
+			for i := 0; i < b.N; i++ {
+				if _, _, err := storage.UpsertEarthquakes(ctx, batch); err != nil {
+					b.Fatalf("Upsert failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
 func TestDatabaseConfig_Validation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -278,3 +512,117 @@ func TestDatabaseConfig_ConnectionStrings(t *testing.T) {
 		t.Errorf("GetDSN() = %v, want %v", dsn, expectedDSN)
 	}
 }
+
+func testNullColumns(t *testing.T, storage *PostgreSQLStorage) {
+	ctx := context.Background()
+
+	features := testEarthquakes("null-test", 1)
+	features[0].Properties.Alert = "green"
+	if err := storage.SaveEarthquakes(ctx, &models.USGSResponse{Features: features}); err != nil {
+		t.Fatalf("Failed to save earthquakes: %v", err)
+	}
+
+	// Rows written by other tools may leave the nullable columns unset
+	_, err := storage.db.ExecContext(ctx, `
+		UPDATE earthquakes SET alert = NULL, status = NULL, network = NULL, code = NULL,
+			magnitude_type = NULL, url = NULL, detail_url = NULL, ids = NULL, sources = NULL,
+			types = NULL, title = NULL, significance = NULL
+		WHERE usgs_id = $1`, "null-test-0")
+	if err != nil {
+		t.Fatalf("Failed to null columns: %v", err)
+	}
+
+	loaded, err := storage.LoadEarthquakes(ctx, 1000, 0)
+	if err != nil {
+		t.Fatalf("Failed to load earthquakes with NULL columns: %v", err)
+	}
+	for _, eq := range loaded.Features {
+		if eq.ID != "null-test-0" {
+			continue
+		}
+		if eq.Properties.Alert != "" || eq.Properties.Status != "" || eq.Properties.Title != "" || eq.Properties.Sig != 0 {
+			t.Errorf("Expected zero values for NULL columns, got %+v", eq.Properties)
+		}
+		return
+	}
+	t.Error("Earthquake with NULL columns not found in loaded data")
+}
+
+func testQueryEarthquakes(t *testing.T, storage *PostgreSQLStorage) {
+	ctx := context.Background()
+
+	// Place the earthquakes in a box no other test writes to
+	features := testEarthquakes("query-test", 4)
+	base := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := range features {
+		features[i].Properties.Mag = models.Float64(float64(i + 3))
+		features[i].Properties.Time = base.Add(time.Duration(i) * time.Hour).UnixMilli()
+		features[i].Geometry.Coordinates = []float64{170 + float64(i)/10, -50, 10}
+	}
+	if err := storage.SaveEarthquakes(ctx, &models.USGSResponse{Features: features}); err != nil {
+		t.Fatalf("Failed to save earthquakes: %v", err)
+	}
+
+	minMag := 4.0
+	query := EarthquakeQuery{
+		MinMagnitude: &minMag,
+		StartTime:    base,
+		EndTime:      base.Add(3 * time.Hour),
+		BBox:         &BoundingBox{MinLon: 169.5, MinLat: -51, MaxLon: 171, MaxLat: -49},
+	}
+	earthquakes, err := storage.QueryEarthquakes(ctx, query)
+	if err != nil {
+		t.Fatalf("Failed to query earthquakes: %v", err)
+	}
+	if len(earthquakes) != 2 || earthquakes[0].ID != "query-test-2" || earthquakes[1].ID != "query-test-1" {
+		t.Errorf("Expected query-test-2 and query-test-1, got %+v", earthquakes)
+	}
+
+	query.Limit = 1
+	if earthquakes, err = storage.QueryEarthquakes(ctx, query); err != nil || len(earthquakes) != 1 {
+		t.Errorf("Expected one earthquake with a limit, got %d (%v)", len(earthquakes), err)
+	}
+}
+
+func testNullMagnitude(t *testing.T, storage *PostgreSQLStorage) {
+	ctx := context.Background()
+
+	features := testEarthquakes("null-mag-test", 2)
+	features[0].Properties.Mag = nil
+	features[1].Properties.Mag = models.Float64(0)
+	if err := storage.SaveEarthquakes(ctx, &models.USGSResponse{Features: features}); err != nil {
+		t.Fatalf("Failed to save earthquakes: %v", err)
+	}
+
+	var stored sql.NullFloat64
+	err := storage.db.GetContext(ctx, &stored, "SELECT magnitude FROM earthquakes WHERE usgs_id = $1", "null-mag-test-0")
+	if err != nil {
+		t.Fatalf("Failed to read magnitude: %v", err)
+	}
+	if stored.Valid {
+		t.Errorf("Expected a NULL magnitude column, got %v", stored.Float64)
+	}
+
+	loaded, err := storage.LoadEarthquakes(ctx, 1000, 0)
+	if err != nil {
+		t.Fatalf("Failed to load earthquakes: %v", err)
+	}
+	found := 0
+	for _, eq := range loaded.Features {
+		switch eq.ID {
+		case "null-mag-test-0":
+			found++
+			if eq.Properties.Mag != nil {
+				t.Errorf("Expected an unknown magnitude, got %v", *eq.Properties.Mag)
+			}
+		case "null-mag-test-1":
+			found++
+			if mag, ok := eq.Properties.Magnitude(); !ok || mag != 0 {
+				t.Errorf("Expected magnitude 0 to stay known, got %s", eq.Properties.GetMagnitude())
+			}
+		}
+	}
+	if found != 2 {
+		t.Errorf("Found %d of the 2 saved earthquakes", found)
+	}
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// BoundingBox is a longitude/latitude rectangle in degrees
+type BoundingBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// Validate checks that the box lies within valid coordinates and its minimums do not exceed its maximums
+func (b BoundingBox) Validate() error {
+	if b.MinLat < -90 || b.MaxLat > 90 || b.MinLon < -180 || b.MaxLon > 180 {
+		return fmt.Errorf("bounding box %v,%v,%v,%v is outside valid coordinates", b.MinLon, b.MinLat, b.MaxLon, b.MaxLat)
+	}
+	if b.MinLat > b.MaxLat || b.MinLon > b.MaxLon {
+		return fmt.Errorf("bounding box minimums must not exceed its maximums")
+	}
+	return nil
+}
+
+// Contains reports whether a point lies within the box, edges included
+func (b BoundingBox) Contains(lon, lat float64) bool {
+	return lon >= b.MinLon && lon <= b.MaxLon && lat >= b.MinLat && lat <= b.MaxLat
+}
+
+// EarthquakeQuery selects earthquakes. Unset fields do not filter; StartTime is inclusive and
+// EndTime exclusive. A Limit of 0 returns every match.
+type EarthquakeQuery struct {
+	MinMagnitude *float64
+	MaxMagnitude *float64
+	StartTime    time.Time
+	EndTime      time.Time
+	BBox         *BoundingBox
+	Limit        int
+}
+
+// Matches reports whether an earthquake satisfies the query's filters
+func (q EarthquakeQuery) Matches(eq models.Earthquake) bool {
+	// Like a SQL comparison with NULL, an unknown magnitude fails any magnitude filter
+	mag, known := eq.Properties.Magnitude()
+	if q.MinMagnitude != nil && (!known || mag < *q.MinMagnitude) {
+		return false
+	}
+	if q.MaxMagnitude != nil && (!known || mag > *q.MaxMagnitude) {
+		return false
+	}
+	if !q.StartTime.IsZero() && eq.Properties.Time < q.StartTime.UnixMilli() {
+		return false
+	}
+	if !q.EndTime.IsZero() && eq.Properties.Time >= q.EndTime.UnixMilli() {
+		return false
+	}
+	if q.BBox != nil {
+		if len(eq.Geometry.Coordinates) < 2 || !q.BBox.Contains(eq.Geometry.Coordinates[0], eq.Geometry.Coordinates[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// QueryEarthquakes returns the earthquakes across every data file that match the query, most
+// recent first. Each earthquake appears once, in its most recently updated revision.
+func (s *JSONStorage) QueryEarthquakes(ctx context.Context, query EarthquakeQuery) ([]models.Earthquake, error) {
+	all, err := s.LoadAllEarthquakes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []models.Earthquake{}
+	for _, eq := range all.Features {
+		if query.Matches(eq) {
+			matches = append(matches, eq)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Properties.Time > matches[j].Properties.Time
+	})
+	if query.Limit > 0 && len(matches) > query.Limit {
+		matches = matches[:query.Limit]
+	}
+	return matches, nil
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+func TestJSONStorage_QueryEarthquakes(t *testing.T) {
+	storage := NewJSONStorage(t.TempDir())
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(id string, mag float64, hours int, lon, lat float64) models.Earthquake {
+		return models.Earthquake{
+			Type:       "Feature",
+			ID:         id,
+			Properties: models.EarthquakeProperties{Mag: models.Float64(mag), Time: base.Add(time.Duration(hours) * time.Hour).UnixMilli()},
+			Geometry:   models.Geometry{Type: "Point", Coordinates: []float64{lon, lat}},
+		}
+	}
+	err := storage.SaveEarthquakes(&models.USGSResponse{Features: []models.Earthquake{
+		at("a", 3, 0, 10, 10),
+		at("b", 5, 1, 10, 10),
+		at("c", 6, 2, 50, 50),
+		at("d", 4, 3, 10, 10),
+	}}, "sample")
+	if err != nil {
+		t.Fatalf("SaveEarthquakes() error = %v", err)
+	}
+
+	minMag, maxMag := 4.0, 5.5
+	tests := []struct {
+		name  string
+		query EarthquakeQuery
+		want  []string
+	}{
+		{"everything, most recent first", EarthquakeQuery{}, []string{"d", "c", "b", "a"}},
+		{"magnitude range", EarthquakeQuery{MinMagnitude: &minMag, MaxMagnitude: &maxMag}, []string{"d", "b"}},
+		{"end is exclusive", EarthquakeQuery{StartTime: base, EndTime: base.Add(2 * time.Hour)}, []string{"b", "a"}},
+		{"bounding box", EarthquakeQuery{BBox: &BoundingBox{MinLon: 0, MinLat: 0, MaxLon: 20, MaxLat: 20}}, []string{"d", "b", "a"}},
+		{"limit", EarthquakeQuery{Limit: 2}, []string{"d", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			earthquakes, err := storage.QueryEarthquakes(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("QueryEarthquakes() error = %v", err)
+			}
+			var got []string
+			for _, eq := range earthquakes {
+				got = append(got, eq.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// backupExtension is appended to a data file's name to name the copy RepairFile keeps of the original
+const backupExtension = ".bak"
+
+// RepairFile rewrites a data file keeping only the features that decode and are complete, e.g. a
+// file cut off part way through a write. In a JSON file, features after one that is not valid
+// JSON cannot be recovered; in an NDJSON file only the invalid lines are dropped. The original is
+// kept next to the file with a .bak extension and the manifest, if any, is updated. It returns the
+// number of features dropped; an intact file is left untouched.
+func (s *JSONStorage) RepairFile(ctx context.Context, dataType, filename string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	var complete func(raw json.RawMessage) bool
+	var repaired interface{}
+	switch dataType {
+	case "earthquakes":
+		complete = completeEarthquake
+		repaired = &models.USGSResponse{}
+	case "faults":
+		complete = completeFault
+		repaired = &models.Fault{}
+	default:
+		return 0, fmt.Errorf("unknown data type: %s", dataType)
+	}
+
+	filename = s.resolveFilename(dataType, filename)
+	filePath := filepath.Join(s.outputDir, dataType, filename)
+	original, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	data := original
+	compressed := strings.HasSuffix(filename, gzipExtension)
+	if compressed {
+		if data, err = decompressPartial(original); err != nil {
+			return 0, err
+		}
+	}
+
+	salvage := salvageFeatures
+	format := FormatJSON
+	if isNDJSONFile(filename) {
+		salvage = salvageNDJSON
+		format = FormatNDJSON
+	}
+	members, features, removed, intact, err := salvage(data, complete)
+	if err != nil {
+		return 0, err
+	}
+	if intact && removed == 0 {
+		return 0, nil
+	}
+
+	// Round-trip through the model so the file is written like any other saved file
+	if members["features"], err = json.Marshal(features); err != nil {
+		return 0, fmt.Errorf("failed to encode features: %w", err)
+	}
+	if _, ok := members["type"]; !ok {
+		members["type"] = json.RawMessage(`"FeatureCollection"`)
+	}
+	encoded, err := json.Marshal(members)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode repaired file: %w", err)
+	}
+	if err := json.Unmarshal(encoded, repaired); err != nil {
+		return 0, fmt.Errorf("failed to decode repaired file: %w", err)
+	}
+
+	if err := os.WriteFile(filePath+backupExtension, original, 0644); err != nil {
+		return 0, fmt.Errorf("failed to back up file: %w", err)
+	}
+
+	// writeJSONFile replaces the file only once the repaired copy is complete, so a failed
+	// write cannot make things worse
+	writer := *s
+	writer.compress = compressed
+	writer.format = format
+	var sum string
+	err = s.retryWrite(func() error {
+		var err error
+		sum, err = writer.writeJSONFile(filePath, repaired)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if manifest, err := readManifest(filePath); err == nil {
+		manifest.SHA256 = sum
+		manifest.Records = len(features)
+		if err := writeManifest(filePath, *manifest); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// decompressPartial decompresses gzip data, returning what could be decompressed if the data is cut off
+func decompressPartial(data []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress file: %w", err)
+	}
+	defer gz.Close()
+
+	decompressed, err := io.ReadAll(gz)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to decompress file: %w", err)
+	}
+	return decompressed, nil
+}
+
+// salvageFeatures reads a GeoJSON feature collection as far as it is valid JSON. It returns the
+// top-level members read, the features that are complete, how many features were dropped and
+// whether the whole document could be read.
+func salvageFeatures(data []byte, complete func(raw json.RawMessage) bool) (map[string]json.RawMessage, []json.RawMessage, int, bool, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, nil, 0, false, fmt.Errorf("file is not a GeoJSON feature collection")
+	}
+
+	members := make(map[string]json.RawMessage)
+	features := []json.RawMessage{}
+	removed := 0
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return members, features, removed, false, nil
+		}
+		key, _ := token.(string)
+
+		if key != "features" {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				return members, features, removed, false, nil
+			}
+			members[key] = raw
+			continue
+		}
+
+		if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+			return members, features, removed, false, nil
+		}
+		for decoder.More() {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				// A partial feature; nothing after it can be read
+				return members, features, removed + 1, false, nil
+			}
+			if complete(raw) {
+				features = append(features, raw)
+			} else {
+				removed++
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			return members, features, removed, false, nil
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return members, features, removed, false, nil
+	}
+	return members, features, removed, true, nil
+}
+
+// salvageNDJSON reads newline-delimited features, returning the complete ones, how many lines were
+// dropped and whether every line was complete. An NDJSON file has no top-level members.
+func salvageNDJSON(data []byte, complete func(raw json.RawMessage) bool) (map[string]json.RawMessage, []json.RawMessage, int, bool, error) {
+	features := []json.RawMessage{}
+	removed := 0
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) || !complete(line) {
+			removed++
+			continue
+		}
+		features = append(features, json.RawMessage(line))
+	}
+	return make(map[string]json.RawMessage), features, removed, removed == 0, nil
+}
+
+// completeEarthquake reports whether raw decodes into an earthquake with an ID and coordinates
+func completeEarthquake(raw json.RawMessage) bool {
+	var earthquake models.Earthquake
+	if err := json.Unmarshal(raw, &earthquake); err != nil {
+		return false
+	}
+	return earthquake.ID != "" && len(earthquake.Geometry.Coordinates) >= 2
+}
+
+// completeFault reports whether raw decodes into a fault with an ID and a line of at least two points
+func completeFault(raw json.RawMessage) bool {
+	var fault models.FaultFeature
+	if err := json.Unmarshal(raw, &fault); err != nil {
+		return false
+	}
+	return (fault.ID != "" || fault.Properties.ID != "") && len(fault.Geometry.Coordinates) >= 2
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// brokenEarthquakes has two complete features, one without an ID, one whose geometry is not an
+// object and a final feature cut off part way through
+const brokenEarthquakes = `{
+  "type": "FeatureCollection",
+  "metadata": {"generated": 1700000000000, "title": "test", "count": 5},
+  "features": [
+    {"type": "Feature", "id": "us1", "properties": {"mag": 4.5}, "geometry": {"type": "Point", "coordinates": [-122.4, 37.7, 10]}},
+    {"type": "Feature", "properties": {"mag": 3.1}, "geometry": {"type": "Point", "coordinates": [-120.1, 36.2, 5]}},
+    {"type": "Feature", "id": "us3", "properties": {"mag": 2.0}, "geometry": "broken"},
+    {"type": "Feature", "id": "us4", "properties": {"mag": 5.2}, "geometry": {"type": "Point", "coordinates": [142.3, 38.1, 30]}},
+    {"type": "Feature", "id": "us5", "properties": {"mag": 6.`
+
+func TestJSONStorage_RepairFile(t *testing.T) {
+	outputDir := t.TempDir()
+	storage := NewJSONStorage(outputDir)
+
+	// Save a file so it has a manifest, then overwrite it with the broken contents
+	if err := storage.SaveEarthquakes(&models.USGSResponse{Type: "FeatureCollection"}, "broken"); err != nil {
+		t.Fatalf("SaveEarthquakes() error = %v", err)
+	}
+	path := filepath.Join(outputDir, "earthquakes", "broken.json")
+	if err := os.WriteFile(path, []byte(brokenEarthquakes), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	removed, err := storage.RepairFile(context.Background(), "earthquakes", "broken")
+	if err != nil {
+		t.Fatalf("RepairFile() error = %v", err)
+	}
+	if removed != 3 {
+		t.Errorf("RepairFile() removed %d records, want 3", removed)
+	}
+
+	earthquakes, err := storage.LoadEarthquakes("broken")
+	if err != nil {
+		t.Fatalf("LoadEarthquakes() error = %v", err)
+	}
+	if len(earthquakes.Features) != 2 || earthquakes.Features[0].ID != "us1" || earthquakes.Features[1].ID != "us4" {
+		t.Errorf("Expected us1 and us4 to be kept, got %+v", earthquakes.Features)
+	}
+	if earthquakes.Metadata.Title != "test" {
+		t.Errorf("Expected metadata to be kept, got %+v", earthquakes.Metadata)
+	}
+
+	backup, err := os.ReadFile(path + backupExtension)
+	if err != nil || string(backup) != brokenEarthquakes {
+		t.Errorf("Expected the original to be backed up, got %q (%v)", backup, err)
+	}
+
+	result, err := storage.VerifyFile("earthquakes", "broken.json")
+	if err != nil {
+		t.Fatalf("VerifyFile() error = %v", err)
+	}
+	if result.Status != VerifyOK {
+		t.Errorf("Expected the manifest to match the repaired file, got %s: %s", result.Status, result.Detail)
+	}
+
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+// brokenNDJSON has two complete features, one without an ID, one line that is not JSON and a
+// final line cut off part way through
+const brokenNDJSON = `{"type":"Feature","id":"us1","properties":{"mag":4.5},"geometry":{"type":"Point","coordinates":[-122.4,37.7,10]}}
+{"type":"Feature","properties":{"mag":3.1},"geometry":{"type":"Point","coordinates":[-120.1,36.2,5]}}
+not json
+{"type":"Feature","id":"us4","properties":{"mag":5.2},"geometry":{"type":"Point","coordinates":[142.3,38.1,30]}}
+{"type":"Feature","id":"us5","properties":{"mag":6.`
+
+func TestJSONStorage_RepairFileNDJSON(t *testing.T) {
+	outputDir := t.TempDir()
+	storage := NewJSONStorage(outputDir)
+	storage.SetFormat(FormatNDJSON)
+
+	if err := storage.SaveEarthquakes(&models.USGSResponse{Type: "FeatureCollection"}, "broken"); err != nil {
+		t.Fatalf("SaveEarthquakes() error = %v", err)
+	}
+	path := filepath.Join(outputDir, "earthquakes", "broken.ndjson")
+	if err := os.WriteFile(path, []byte(brokenNDJSON), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	// A storage set up for JSON output still repairs the file as NDJSON
+	removed, err := NewJSONStorage(outputDir).RepairFile(context.Background(), "earthquakes", "broken")
+	if err != nil {
+		t.Fatalf("RepairFile() error = %v", err)
+	}
+	if removed != 3 {
+		t.Errorf("RepairFile() removed %d records, want 3", removed)
+	}
+
+	earthquakes, err := storage.LoadEarthquakes("broken")
+	if err != nil {
+		t.Fatalf("LoadEarthquakes() error = %v", err)
+	}
+	if len(earthquakes.Features) != 2 || earthquakes.Features[0].ID != "us1" || earthquakes.Features[1].ID != "us4" {
+		t.Errorf("Expected us1 and us4 to be kept, got %+v", earthquakes.Features)
+	}
+
+	result, err := storage.VerifyFile("earthquakes", "broken.ndjson")
+	if err != nil {
+		t.Fatalf("VerifyFile() error = %v", err)
+	}
+	if result.Status != VerifyOK {
+		t.Errorf("Expected the manifest to match the repaired file, got %s: %s", result.Status, result.Detail)
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+// assertNoTempFiles fails if a temporary file was left behind in dir
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), tempExtension) {
+			t.Errorf("Expected no temporary files, found %s", entry.Name())
+		}
+	}
+}
+
+func TestJSONStorage_RepairFileIntact(t *testing.T) {
+	outputDir := t.TempDir()
+	storage := NewJSONStorage(outputDir)
+
+	earthquakes := &models.USGSResponse{
+		Type: "FeatureCollection",
+		Features: []models.Earthquake{
+			{Type: "Feature", ID: "us1", Geometry: models.Geometry{Type: "Point", Coordinates: []float64{1, 2, 3}}},
+		},
+	}
+	if err := storage.SaveEarthquakes(earthquakes, "intact"); err != nil {
+		t.Fatalf("SaveEarthquakes() error = %v", err)
+	}
+
+	removed, err := storage.RepairFile(context.Background(), "earthquakes", "intact")
+	if err != nil || removed != 0 {
+		t.Fatalf("RepairFile() = %d, %v, want 0 and no error", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "earthquakes", "intact.json"+backupExtension)); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup of an intact file, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// stateDir is the directory inside the output directory that holds resume state files
+const stateDir = ".state"
+
+// unsafeStateChars matches characters not allowed in state file names
+var unsafeStateChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SubQueryState records the outcome of a single sub-query of a split collection
+type SubQueryState struct {
+	Split bool `json:"split,omitempty"`
+	Done  bool `json:"done,omitempty"`
+	// Earthquakes is only read from state files written before results moved to their own file
+	Earthquakes []models.Earthquake `json:"earthquakes,omitempty"`
+}
+
+// subQueryResults is one line of a results file: the earthquakes of a completed sub-query
+type subQueryResults struct {
+	Key         string              `json:"key"`
+	Earthquakes []models.Earthquake `json:"earthquakes"`
+}
+
+// CollectionState tracks completed sub-queries of a collection so an interrupted run can resume.
+// Results of completed sub-queries are appended to a results file next to the state file, one
+// line per sub-query, so resumed runs can skip them entirely while the state file itself only
+// holds the completion of each sub-query and stays small.
+type CollectionState struct {
+	mu         sync.Mutex
+	path       string
+	results    map[string][]models.Earthquake
+	SubQueries map[string]*SubQueryState `json:"sub_queries"`
+}
+
+// CollectionStatePath returns the state file path for a collection name inside the output directory
+func (s *JSONStorage) CollectionStatePath(name string) string {
+	return filepath.Join(s.outputDir, stateDir, unsafeStateChars.ReplaceAllString(name, "_")+".json")
+}
+
+// LoadCollectionState loads the state file at path and its results, starting empty if it does not exist
+func LoadCollectionState(path string) (*CollectionState, error) {
+	state := &CollectionState{
+		path:       path,
+		results:    make(map[string][]models.Earthquake),
+		SubQueries: make(map[string]*SubQueryState),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read collection state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to decode collection state: %w", err)
+	}
+	if state.SubQueries == nil {
+		state.SubQueries = make(map[string]*SubQueryState)
+	}
+	if err := state.loadResults(); err != nil {
+		return nil, err
+	}
+
+	// Move results kept in older state files to the results file
+	for key, sub := range state.SubQueries {
+		if sub.Done && len(sub.Earthquakes) > 0 {
+			if err := state.appendResults(key, sub.Earthquakes); err != nil {
+				return nil, err
+			}
+			sub.Earthquakes = nil
+		}
+	}
+
+	return state, nil
+}
+
+// resultsPath returns the path of the results file kept next to the state file
+func (s *CollectionState) resultsPath() string {
+	return strings.TrimSuffix(s.path, ".json") + ".results.ndjson"
+}
+
+// loadResults reads the results file. A line cut short by an interrupted append is dropped
+// from the file so later appends start on a clean line.
+func (s *CollectionState) loadResults() error {
+	file, err := os.OpenFile(s.resultsPath(), os.O_RDWR, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read collection results: %w", err)
+	}
+	defer file.Close()
+
+	// A results line can be far longer than ReadNDJSON accepts, so lines are decoded as a stream
+	decoder := json.NewDecoder(file)
+	var offset int64
+	for {
+		var line subQueryResults
+		err := decoder.Decode(&line)
+		if err == io.EOF {
+			return nil
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			if err := file.Truncate(offset); err != nil {
+				return fmt.Errorf("failed to truncate collection results: %w", err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode collection results: %w", err)
+		}
+		offset = decoder.InputOffset()
+		s.results[line.Key] = line.Earthquakes
+	}
+}
+
+// appendResults appends the results of a sub-query to the results file
+func (s *CollectionState) appendResults(key string, earthquakes []models.Earthquake) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.Marshal(subQueryResults{Key: key, Earthquakes: earthquakes})
+	if err != nil {
+		return fmt.Errorf("failed to encode collection results: %w", err)
+	}
+
+	file, err := os.OpenFile(s.resultsPath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open collection results: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write collection results: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write collection results: %w", err)
+	}
+
+	s.results[key] = earthquakes
+	return nil
+}
+
+// SubQuery returns the recorded state of a sub-query
+func (s *CollectionState) SubQuery(key string) (split, done bool, earthquakes []models.Earthquake) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.SubQueries[key]
+	if !ok {
+		return false, false, nil
+	}
+	return sub.Split, sub.Done, s.results[key]
+}
+
+// MarkSplit records that a sub-query had to be split into smaller ones
+func (s *CollectionState) MarkSplit(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.SubQueries[key] = &SubQueryState{Split: true}
+	return s.save()
+}
+
+// MarkDone records a completed sub-query together with its results. The results are appended
+// before the sub-query is marked done, so a sub-query recorded as done always has its results.
+func (s *CollectionState) MarkDone(key string, earthquakes []models.Earthquake) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(earthquakes) > 0 || s.results[key] != nil {
+		if err := s.appendResults(key, earthquakes); err != nil {
+			return err
+		}
+	}
+	s.SubQueries[key] = &SubQueryState{Done: true}
+	return s.save()
+}
+
+// Remove deletes the state and results files once the collection has completed
+func (s *CollectionState) Remove() error {
+	for _, path := range []string{s.path, s.resultsPath()} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove collection state: %w", err)
+		}
+	}
+	return nil
+}
+
+// save writes the state file, replacing the previous one atomically
+func (s *CollectionState) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode collection state: %w", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write collection state: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace collection state: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"quakewatch-scraper/internal/models"
+)
+
+func TestCollectionState_Resume(t *testing.T) {
+	path := NewJSONStorage(t.TempDir()).CollectionStatePath("time-range")
+
+	state, err := LoadCollectionState(path)
+	if err != nil {
+		t.Fatalf("LoadCollectionState() error = %v", err)
+	}
+	if err := state.MarkSplit("a"); err != nil {
+		t.Fatalf("MarkSplit() error = %v", err)
+	}
+	if err := state.MarkDone("b", []models.Earthquake{{ID: "eq-b1"}, {ID: "eq-b2"}}); err != nil {
+		t.Fatalf("MarkDone() error = %v", err)
+	}
+	if err := state.MarkDone("c", nil); err != nil {
+		t.Fatalf("MarkDone() error = %v", err)
+	}
+
+	// The state file only records completion; the results live in their own file
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "eq-b1") {
+		t.Errorf("Expected no earthquakes in the state file, got %s", data)
+	}
+
+	state, err = LoadCollectionState(path)
+	if err != nil {
+		t.Fatalf("LoadCollectionState() error = %v", err)
+	}
+	if split, done, _ := state.SubQuery("a"); !split || done {
+		t.Errorf("SubQuery(a) = split %v, done %v, want split", split, done)
+	}
+	if _, done, earthquakes := state.SubQuery("b"); !done || len(earthquakes) != 2 || earthquakes[1].ID != "eq-b2" {
+		t.Errorf("SubQuery(b) = done %v with %v, want done with 2 earthquakes", done, earthquakes)
+	}
+	if _, done, earthquakes := state.SubQuery("c"); !done || len(earthquakes) != 0 {
+		t.Errorf("SubQuery(c) = done %v with %v, want done without earthquakes", done, earthquakes)
+	}
+
+	if err := state.Remove(); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	for _, p := range []string{path, state.resultsPath()} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, stat error: %v", p, err)
+		}
+	}
+}
+
+func TestCollectionState_InterruptedAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	state, err := LoadCollectionState(path)
+	if err != nil {
+		t.Fatalf("LoadCollectionState() error = %v", err)
+	}
+	if err := state.MarkDone("a", []models.Earthquake{{ID: "eq-a"}}); err != nil {
+		t.Fatalf("MarkDone() error = %v", err)
+	}
+
+	// A run killed while appending leaves half a line behind
+	file, err := os.OpenFile(state.resultsPath(), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	file.WriteString(`{"key":"b","earthquakes":[{"id":"eq-`)
+	file.Close()
+
+	state, err = LoadCollectionState(path)
+	if err != nil {
+		t.Fatalf("LoadCollectionState() error = %v", err)
+	}
+	if err := state.MarkDone("b", []models.Earthquake{{ID: "eq-b"}}); err != nil {
+		t.Fatalf("MarkDone() error = %v", err)
+	}
+
+	state, err = LoadCollectionState(path)
+	if err != nil {
+		t.Fatalf("LoadCollectionState() error = %v", err)
+	}
+	for key, want := range map[string]string{"a": "eq-a", "b": "eq-b"} {
+		if _, done, earthquakes := state.SubQuery(key); !done || len(earthquakes) != 1 || earthquakes[0].ID != want {
+			t.Errorf("SubQuery(%s) = done %v with %v, want %s", key, done, earthquakes, want)
+		}
+	}
+}
+
+func TestCollectionState_LegacyResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	// State files used to embed the results of each sub-query
+	legacy := map[string]map[string]*SubQueryState{
+		"sub_queries": {"a": {Done: true, Earthquakes: []models.Earthquake{{ID: "eq-a"}}}},
+	}
+	data, _ := json.Marshal(legacy)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	state, err := LoadCollectionState(path)
+	if err != nil {
+		t.Fatalf("LoadCollectionState() error = %v", err)
+	}
+	if err := state.MarkDone("b", nil); err != nil {
+		t.Fatalf("MarkDone() error = %v", err)
+	}
+
+	state, err = LoadCollectionState(path)
+	if err != nil {
+		t.Fatalf("LoadCollectionState() error = %v", err)
+	}
+	if _, done, earthquakes := state.SubQuery("a"); !done || len(earthquakes) != 1 || earthquakes[0].ID != "eq-a" {
+		t.Errorf("SubQuery(a) = done %v with %v, want the legacy result", done, earthquakes)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "eq-a") {
+		t.Errorf("Expected the legacy result to move out of the state file, got %s", data)
+	}
+}
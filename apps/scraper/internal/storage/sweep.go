@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tempExtension marks files being written; they are renamed into place once complete
+const tempExtension = ".tmp"
+
+// StaleTempAge is how old a temporary file must be before SweepTempFiles treats it as orphaned by
+// a crashed or killed run rather than being written by one still in progress
+const StaleTempAge = 5 * time.Minute
+
+// SweepTempFiles removes the temporary files under the output directory, including its date
+// directories, that were last modified more than olderThan ago, and returns how many it removed.
+// Files it cannot remove are skipped and reported in the returned error.
+func (s *JSONStorage) SweepTempFiles(olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+	removed := 0
+	var failures []error
+
+	err := filepath.WalkDir(s.outputDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			failures = append(failures, err)
+			return nil
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), tempExtension) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// Removed since the directory was read, e.g. renamed into place
+			if !errors.Is(err, fs.ErrNotExist) {
+				failures = append(failures, err)
+			}
+			return nil
+		}
+		if !info.ModTime().Before(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			failures = append(failures, err)
+			return nil
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		failures = append(failures, err)
+	}
+	if len(failures) > 0 {
+		return removed, fmt.Errorf("failed to sweep temporary files: %w", errors.Join(failures...))
+	}
+	return removed, nil
+}
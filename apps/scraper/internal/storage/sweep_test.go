@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJSONStorage_SweepTempFiles(t *testing.T) {
+	outputDir := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+
+	files := []struct {
+		path     string
+		modified time.Time
+		removed  bool
+	}{
+		{"earthquakes/earthquakes_2024-01-15_08-00-00.json.tmp", old, true},
+		{"earthquakes/2024/01/15/earthquakes_2024-01-15_09-00-00.json.gz.tmp", old, true},
+		{"faults/faults_2024-01-15_08-00-00.json.tmp", old, true},
+		{"state/time-range.json.tmp", old, true},
+		// Still being written by a running collection
+		{"earthquakes/earthquakes_2024-01-15_10-00-00.json.tmp", time.Now(), false},
+		// Not temporary
+		{"earthquakes/earthquakes_2024-01-15_07-00-00.json", old, false},
+		{"earthquakes/earthquakes_2024-01-15_07-00-00.json.sha256", old, false},
+	}
+	for _, f := range files {
+		path := filepath.Join(outputDir, f.path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		if err := os.Chtimes(path, f.modified, f.modified); err != nil {
+			t.Fatalf("Chtimes() error = %v", err)
+		}
+	}
+
+	removed, err := NewJSONStorage(outputDir).SweepTempFiles(StaleTempAge)
+	if err != nil {
+		t.Fatalf("SweepTempFiles() error = %v", err)
+	}
+	if removed != 4 {
+		t.Errorf("SweepTempFiles() removed %d files, want 4", removed)
+	}
+	for _, f := range files {
+		_, err := os.Stat(filepath.Join(outputDir, f.path))
+		if exists := err == nil; exists == f.removed {
+			t.Errorf("%s: exists = %t, want removed = %t", f.path, exists, f.removed)
+		}
+	}
+}
+
+func TestJSONStorage_SweepTempFilesMissingDir(t *testing.T) {
+	removed, err := NewJSONStorage(filepath.Join(t.TempDir(), "missing")).SweepTempFiles(StaleTempAge)
+	if err != nil || removed != 0 {
+		t.Errorf("SweepTempFiles() = %d, %v, want nothing to do", removed, err)
+	}
+}
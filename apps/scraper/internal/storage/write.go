@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// writeAttempts is how many times a data file is written before a transient error is given up on
+const writeAttempts = 3
+
+// defaultWriteRetryDelay is the pause before the first retry of a failed write; it doubles after each retry
+const defaultWriteRetryDelay = 200 * time.Millisecond
+
+// transientWriteErrors are errors that network filesystems such as NFS report for conditions
+// that usually clear on their own. Others, like ENOSPC or EACCES, will fail again and are not retried.
+var transientWriteErrors = []error{
+	syscall.EAGAIN,
+	syscall.EINTR,
+	syscall.EBUSY,
+	syscall.ESTALE,
+	syscall.ETIMEDOUT,
+}
+
+// createOSFile creates or truncates a file on disk
+func createOSFile(name string) (io.WriteCloser, error) {
+	return os.Create(name)
+}
+
+// isTransientWriteError reports whether a failed write is worth retrying
+func isTransientWriteError(err error) bool {
+	for _, transient := range transientWriteErrors {
+		if errors.Is(err, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryWrite runs write until it succeeds, fails with an error that is not transient, or has been
+// tried writeAttempts times, so a flaky disk does not discard data that was expensive to fetch
+func (s *JSONStorage) retryWrite(write func() error) error {
+	delay := s.writeRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := write()
+		if err == nil || attempt == writeAttempts || !isTransientWriteError(err) {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// flakyFile fails its first write with err
+type flakyFile struct {
+	io.WriteCloser
+	err    error
+	failed bool
+}
+
+func (f *flakyFile) Write(p []byte) (int, error) {
+	if !f.failed {
+		f.failed = true
+		return 0, f.err
+	}
+	return f.WriteCloser.Write(p)
+}
+
+func TestJSONStorage_RetriesTransientWriteErrors(t *testing.T) {
+	earthquakes := &models.USGSResponse{Features: []models.Earthquake{{ID: "us1"}}}
+
+	tests := []struct {
+		name      string
+		createErr error
+		writeErr  error
+		wantErr   bool
+		wantCalls int
+	}{
+		{"stale handle on create", &os.PathError{Op: "open", Err: syscall.ESTALE}, nil, false, 2},
+		{"EAGAIN on write", nil, syscall.EAGAIN, false, 2},
+		{"disk full", nil, syscall.ENOSPC, true, 1},
+		{"permission denied", &os.PathError{Op: "open", Err: syscall.EACCES}, nil, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := NewJSONStorage(t.TempDir())
+			storage.writeRetryDelay = 0
+
+			calls := 0
+			storage.createFile = func(name string) (io.WriteCloser, error) {
+				calls++
+				if calls > 1 {
+					return createOSFile(name)
+				}
+				if tt.createErr != nil {
+					return nil, tt.createErr
+				}
+				file, err := createOSFile(name)
+				if err != nil {
+					return nil, err
+				}
+				return &flakyFile{WriteCloser: file, err: tt.writeErr}, nil
+			}
+
+			err := storage.SaveEarthquakes(earthquakes, "batch.json")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SaveEarthquakes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Expected %d attempts, got %d", tt.wantCalls, calls)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			loaded, err := storage.LoadEarthquakes("batch.json")
+			if err != nil {
+				t.Fatalf("LoadEarthquakes() error = %v", err)
+			}
+			if len(loaded.Features) != 1 {
+				t.Errorf("Expected 1 earthquake after retry, got %d", len(loaded.Features))
+			}
+			if result, err := storage.VerifyFile("earthquakes", "batch.json"); err != nil || result.Status != VerifyOK {
+				t.Errorf("Expected the manifest to match the retried write, got %+v, %v", result, err)
+			}
+		})
+	}
+
+	// Transient errors that persist are given up on after writeAttempts tries
+	storage := NewJSONStorage(t.TempDir())
+	storage.writeRetryDelay = 0
+	calls := 0
+	storage.createFile = func(name string) (io.WriteCloser, error) {
+		calls++
+		return nil, &os.PathError{Op: "open", Err: syscall.EAGAIN}
+	}
+	if err := storage.SaveEarthquakes(earthquakes, "batch.json"); err == nil {
+		t.Error("Expected error when every attempt fails")
+	}
+	if calls != writeAttempts {
+		t.Errorf("Expected %d attempts, got %d", writeAttempts, calls)
+	}
+}
+
+func TestJSONStorage_FailedWriteLeavesNoFile(t *testing.T) {
+	outputDir := t.TempDir()
+	storage := NewJSONStorage(outputDir)
+	storage.createFile = func(name string) (io.WriteCloser, error) {
+		file, err := createOSFile(name)
+		if err != nil {
+			return nil, err
+		}
+		return &flakyFile{WriteCloser: file, err: syscall.ENOSPC}, nil
+	}
+
+	earthquakes := &models.USGSResponse{Features: []models.Earthquake{{ID: "us1"}}}
+	if err := storage.SaveEarthquakes(earthquakes, "partial"); err == nil {
+		t.Fatal("Expected the write to fail")
+	}
+
+	entries, err := os.ReadDir(filepath.Join(outputDir, "earthquakes"))
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 0 {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("Expected no data or temporary file after a failed write, got %v", names)
+	}
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Audit outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditEntry describes a single CLI invocation in the audit log
+type AuditEntry struct {
+	Command         string            `json:"command"`
+	Args            []string          `json:"args,omitempty"`
+	Flags           map[string]string `json:"flags,omitempty"`
+	StartTime       time.Time         `json:"start_time"`
+	EndTime         time.Time         `json:"end_time"`
+	DurationSeconds float64           `json:"duration_seconds"`
+	Records         int               `json:"records"`
+	Outcome         string            `json:"outcome"`
+	Error           string            `json:"error,omitempty"`
+}
+
+// Finish completes the entry with the end time and the outcome of the invocation
+func (e *AuditEntry) Finish(endTime time.Time, err error) {
+	e.EndTime = endTime
+	e.DurationSeconds = endTime.Sub(e.StartTime).Seconds()
+	e.Outcome = AuditOutcomeSuccess
+	if err != nil {
+		e.Outcome = AuditOutcomeFailure
+		e.Error = err.Error()
+	}
+}
+
+// AuditLogger appends audit entries to a file as newline-delimited JSON.
+// A nil logger discards entries.
+type AuditLogger struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewAuditLogger creates an audit logger writing to path. An empty path disables auditing
+// and returns nil.
+func NewAuditLogger(path string) *AuditLogger {
+	if path == "" {
+		return nil
+	}
+	return &AuditLogger{path: path}
+}
+
+// Log appends an entry to the audit log, creating the file if needed
+func (l *AuditLogger) Log(entry *AuditEntry) error {
+	if l == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAuditLogger_Log(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger := NewAuditLogger(path)
+
+	start := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	success := &AuditEntry{
+		Command:   "quakewatch-scraper earthquakes recent",
+		Flags:     map[string]string{"limit": "10"},
+		StartTime: start,
+		Records:   7,
+	}
+	success.Finish(start.Add(1500*time.Millisecond), nil)
+
+	failure := &AuditEntry{Command: "quakewatch-scraper faults collect", StartTime: start}
+	failure.Finish(start.Add(time.Second), errors.New("API request failed with status: 503"))
+
+	for _, entry := range []*AuditEntry{success, failure} {
+		if err := logger.Log(entry); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Audit line %q is not JSON: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 audit lines, got %d", len(lines))
+	}
+
+	want := map[string]interface{}{
+		"command":          "quakewatch-scraper earthquakes recent",
+		"flags":            map[string]interface{}{"limit": "10"},
+		"start_time":       "2024-01-15T08:00:00Z",
+		"end_time":         "2024-01-15T08:00:01.5Z",
+		"duration_seconds": 1.5,
+		"records":          7.0,
+		"outcome":          AuditOutcomeSuccess,
+	}
+	if len(lines[0]) != len(want) {
+		t.Errorf("Success entry = %v, want keys %v", lines[0], want)
+	}
+	for key, value := range want {
+		if got := lines[0][key]; !jsonEqual(got, value) {
+			t.Errorf("Success entry %s = %v, want %v", key, got, value)
+		}
+	}
+
+	if lines[1]["outcome"] != AuditOutcomeFailure || lines[1]["error"] != "API request failed with status: 503" {
+		t.Errorf("Failure entry = %v, want the failure outcome and error", lines[1])
+	}
+}
+
+func TestAuditLogger_Disabled(t *testing.T) {
+	logger := NewAuditLogger("")
+	if logger != nil {
+		t.Fatalf("Expected nil logger for an empty path, got %v", logger)
+	}
+	if err := logger.Log(&AuditEntry{Command: "quakewatch-scraper"}); err != nil {
+		t.Errorf("Log() on a disabled logger error = %v", err)
+	}
+}
+
+// jsonEqual compares decoded JSON values
+func jsonEqual(a, b interface{}) bool {
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	return string(aJSON) == string(bJSON)
+}
@@ -0,0 +1,49 @@
+package utils
+
+import "context"
+
+// ConcurrencyLimiter bounds how many operations, such as API requests, run at the same time.
+// A nil limiter imposes no limit.
+type ConcurrencyLimiter struct {
+	slots chan struct{}
+}
+
+// NewConcurrencyLimiter creates a limiter allowing max concurrent operations. A max of 0 or
+// less means unlimited and returns nil.
+func NewConcurrencyLimiter(max int) *ConcurrencyLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &ConcurrencyLimiter{slots: make(chan struct{}, max)}
+}
+
+// Acquire waits for a free slot, returning the context's error if it is done first.
+// Every successful Acquire must be paired with a Release.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot taken by Acquire
+func (l *ConcurrencyLimiter) Release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
+
+// Limit returns the maximum number of concurrent operations, or 0 if unlimited
+func (l *ConcurrencyLimiter) Limit() int {
+	if l == nil {
+		return 0
+	}
+	return cap(l.slots)
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimiter_BoundsConcurrency(t *testing.T) {
+	const limit = 3
+	limiter := NewConcurrencyLimiter(limit)
+
+	var inFlight, maxInFlight int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Acquire(context.Background()); err != nil {
+				t.Errorf("Acquire() error = %v", err)
+				return
+			}
+			defer limiter.Release()
+
+			current := atomic.AddInt32(&inFlight, 1)
+			for {
+				seen := atomic.LoadInt32(&maxInFlight)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight > limit {
+		t.Errorf("Expected at most %d concurrent operations, saw %d", limit, maxInFlight)
+	}
+	if limiter.Limit() != limit {
+		t.Errorf("Limit() = %d, want %d", limiter.Limit(), limit)
+	}
+}
+
+func TestConcurrencyLimiter_AcquireCanceled(t *testing.T) {
+	limiter := NewConcurrencyLimiter(1)
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait for a full limiter to time out, got %v", err)
+	}
+}
+
+func TestConcurrencyLimiter_Unlimited(t *testing.T) {
+	limiter := NewConcurrencyLimiter(0)
+	if limiter != nil {
+		t.Fatalf("Expected nil limiter for 0, got %+v", limiter)
+	}
+	for i := 0; i < 100; i++ {
+		if err := limiter.Acquire(context.Background()); err != nil {
+			t.Fatalf("Acquire() on unlimited limiter error = %v", err)
+		}
+	}
+	limiter.Release()
+	if limiter.Limit() != 0 {
+		t.Errorf("Limit() = %d, want 0", limiter.Limit())
+	}
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ErrorType classifies why a collection failed
+type ErrorType string
+
+const (
+	ErrorTypeNetwork   ErrorType = "network"
+	ErrorTypeTimeout   ErrorType = "timeout"
+	ErrorTypeRateLimit ErrorType = "rate_limit"
+	ErrorTypeServer    ErrorType = "server"
+	ErrorTypeClient    ErrorType = "client"
+	ErrorTypeDecode    ErrorType = "decode"
+
+	// Failures outside the API: invalid configuration or arguments, data that could not be
+	// written or read back, and data rejected by validation
+	ErrorTypeConfig     ErrorType = "config"
+	ErrorTypeStorage    ErrorType = "storage"
+	ErrorTypeValidation ErrorType = "validation"
+)
+
+// ContextResponseBody is the context key holding the start of a response body that could not be decoded
+const ContextResponseBody = "response_body"
+
+// CollectionError is a classified failure to fetch data from an API. Context holds details
+// such as the query parameters, added by ErrorContext as the error is passed up.
+type CollectionError struct {
+	Type      ErrorType
+	Retryable bool
+	Context   map[string]string
+	Err       error
+}
+
+// NewCollectionError creates a collection error of the given type
+func NewCollectionError(errorType ErrorType, retryable bool, err error) *CollectionError {
+	return &CollectionError{
+		Type:      errorType,
+		Retryable: retryable,
+		Context:   make(map[string]string),
+		Err:       err,
+	}
+}
+
+func (e *CollectionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CollectionError) Unwrap() error {
+	return e.Err
+}
+
+// ContextString formats the context as sorted key=value pairs. The response body is left
+// out since it may span many lines.
+func (e *CollectionError) ContextString() string {
+	keys := make([]string, 0, len(e.Context))
+	for key := range e.Context {
+		if key != ContextResponseBody {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+e.Context[key])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// ErrorContext adds context to the CollectionError wrapped by err, if any, and returns err.
+// Values already set closer to the failure are kept.
+func ErrorContext(err error, context map[string]string) error {
+	var collectionErr *CollectionError
+	if !errors.As(err, &collectionErr) {
+		return err
+	}
+
+	if collectionErr.Context == nil {
+		collectionErr.Context = make(map[string]string)
+	}
+	for key, value := range context {
+		if _, ok := collectionErr.Context[key]; !ok {
+			collectionErr.Context[key] = value
+		}
+	}
+	return err
+}
+
+// ClassifyRequestError wraps an error returned by an HTTP client as a network or timeout collection error
+func ClassifyRequestError(err error) *CollectionError {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return NewCollectionError(ErrorTypeTimeout, true, err)
+	}
+	if errors.Is(err, context.Canceled) {
+		return NewCollectionError(ErrorTypeNetwork, false, err)
+	}
+	return NewCollectionError(ErrorTypeNetwork, true, err)
+}
+
+// ClassifyStatus wraps an error for a non-OK HTTP status. Rate limiting and server errors are
+// retryable; other client errors are not.
+func ClassifyStatus(statusCode int, err error) *CollectionError {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return NewCollectionError(ErrorTypeRateLimit, true, err)
+	case statusCode >= 500:
+		return NewCollectionError(ErrorTypeServer, true, err)
+	default:
+		return NewCollectionError(ErrorTypeClient, false, err)
+	}
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCollectionError_SurvivesWrapping(t *testing.T) {
+	base := ClassifyStatus(503, errors.New("API returned status 503"))
+	ErrorContext(base, map[string]string{"minmag": "2.5"})
+
+	wrapped := fmt.Errorf("failed to fetch earthquakes: %w", base)
+	wrapped = ErrorContext(wrapped, map[string]string{"query": "magnitude", "minmag": "9"})
+	wrapped = fmt.Errorf("collection failed: %w", wrapped)
+
+	var collectionErr *CollectionError
+	if !errors.As(wrapped, &collectionErr) {
+		t.Fatalf("Expected *CollectionError in %v", wrapped)
+	}
+	if collectionErr.Type != ErrorTypeServer || !collectionErr.Retryable {
+		t.Errorf("Expected retryable server error, got %s retryable=%v", collectionErr.Type, collectionErr.Retryable)
+	}
+	// Context set closer to the failure is kept
+	if got := collectionErr.ContextString(); got != "minmag=2.5 query=magnitude" {
+		t.Errorf("ContextString() = %q", got)
+	}
+}
+
+func TestErrorContext_PlainError(t *testing.T) {
+	err := errors.New("boom")
+	if got := ErrorContext(err, map[string]string{"query": "recent"}); got != err {
+		t.Errorf("Expected plain errors to be returned unchanged, got %v", got)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       *CollectionError
+		errorType ErrorType
+		retryable bool
+	}{
+		{"rate limit", ClassifyStatus(429, errors.New("x")), ErrorTypeRateLimit, true},
+		{"server", ClassifyStatus(502, errors.New("x")), ErrorTypeServer, true},
+		{"client", ClassifyStatus(400, errors.New("x")), ErrorTypeClient, false},
+		{"timeout", ClassifyRequestError(context.DeadlineExceeded), ErrorTypeTimeout, true},
+		{"canceled", ClassifyRequestError(context.Canceled), ErrorTypeNetwork, false},
+		{"network", ClassifyRequestError(errors.New("connection refused")), ErrorTypeNetwork, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Type != tt.errorType || tt.err.Retryable != tt.retryable {
+				t.Errorf("Got %s retryable=%v, want %s retryable=%v", tt.err.Type, tt.err.Retryable, tt.errorType, tt.retryable)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	jitterMu   sync.Mutex
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// FullJitter returns a random delay drawn uniformly from [0, delay).
+// Non-positive delays yield zero so callers never divide by zero.
+func FullJitter(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return 0
+	}
+
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return time.Duration(jitterRand.Int63n(int64(delay)))
+}
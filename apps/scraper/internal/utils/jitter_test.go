@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFullJitter_TinyDelays(t *testing.T) {
+	// Sub-microsecond and non-positive delays must never panic
+	for _, delay := range []time.Duration{-time.Second, 0, 1, 10, 999} {
+		got := FullJitter(delay)
+		if got < 0 || (delay > 0 && got >= delay) || (delay <= 0 && got != 0) {
+			t.Errorf("FullJitter(%v) = %v, out of bounds", delay, got)
+		}
+	}
+}
+
+func TestFullJitter_WithinBounds(t *testing.T) {
+	delay := 250 * time.Millisecond
+
+	for i := 0; i < 1000; i++ {
+		got := FullJitter(delay)
+		if got < 0 || got >= delay {
+			t.Fatalf("FullJitter(%v) = %v, want value in [0, %v)", delay, got, delay)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"io"
 	"os"
 
 	"github.com/sirupsen/logrus"
@@ -46,6 +47,17 @@ func NewLogger(level string, format string) *Logger {
 	}
 }
 
+// SetOutput sets where log entries are written (stdout by default)
+func (l *Logger) SetOutput(w io.Writer) {
+	l.logger.SetOutput(w)
+}
+
+// DebugEnabled reports whether debug messages are logged, so callers can skip collecting
+// details that would be discarded
+func (l *Logger) DebugEnabled() bool {
+	return l != nil && l.logger.IsLevelEnabled(logrus.DebugLevel)
+}
+
 // Debug logs a debug message
 func (l *Logger) Debug(msg string, fields map[string]interface{}) {
 	l.logger.WithFields(fields).Debug(msg)
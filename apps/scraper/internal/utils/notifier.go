@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// notifierMaxRetries is how many times a failed webhook delivery is retried
+const notifierMaxRetries = 2
+
+// NotificationPayload is the JSON body posted to the webhook for each earthquake.
+// The text field makes the payload usable as a Slack incoming webhook message.
+type NotificationPayload struct {
+	Text      string    `json:"text"`
+	ID        string    `json:"id"`
+	Magnitude float64   `json:"magnitude"`
+	Place     string    `json:"place"`
+	Time      time.Time `json:"time"`
+	URL       string    `json:"url"`
+}
+
+// Notifier posts significant earthquakes to a webhook
+type Notifier struct {
+	webhookURL   string
+	minMagnitude float64
+	httpClient   *http.Client
+	retryDelay   time.Duration
+	// sent remembers delivered earthquakes so later collections do not notify them again
+	sent *SeenCache
+}
+
+// NewNotifier creates a notifier for earthquakes at or above minMagnitude
+func NewNotifier(webhookURL string, minMagnitude float64, timeout time.Duration) *Notifier {
+	return &Notifier{
+		webhookURL:   webhookURL,
+		minMagnitude: minMagnitude,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+		retryDelay: time.Second,
+	}
+}
+
+// SetSentCache makes the notifier skip earthquakes it delivered within the cache's TTL, e.g. in
+// an earlier run collecting the same hour, and record the ones it delivers in the cache
+func (n *Notifier) SetSentCache(cache *SeenCache) {
+	n.sent = cache
+}
+
+// Notify posts one message per qualifying earthquake and returns how many were delivered.
+// Delivery failures are collected into the returned error rather than stopping the remaining posts.
+func (n *Notifier) Notify(earthquakes []models.Earthquake) (int, error) {
+	var sent int
+	var errs []error
+
+	now := time.Now()
+	var pruned int
+	if n.sent != nil {
+		pruned = n.sent.Prune(now)
+	}
+
+	for _, eq := range earthquakes {
+		// Events without a magnitude cannot be known to qualify
+		if mag, ok := eq.Properties.Magnitude(); !ok || mag < n.minMagnitude {
+			continue
+		}
+		if n.sent != nil && n.sent.Contains(eq.ID) {
+			continue
+		}
+
+		if err := n.postWithRetry(newNotificationPayload(eq)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to notify %s: %w", eq.ID, err))
+			continue
+		}
+		sent++
+		if n.sent != nil {
+			n.sent.Add(eq.ID, now)
+		}
+	}
+
+	if n.sent != nil && sent+pruned > 0 {
+		if err := n.sent.Save(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// newNotificationPayload builds the webhook message for an earthquake
+func newNotificationPayload(eq models.Earthquake) NotificationPayload {
+	mag, _ := eq.Properties.Magnitude()
+	return NotificationPayload{
+		Text:      fmt.Sprintf("M%.1f earthquake - %s (%s)", mag, eq.Properties.Place, eq.Properties.URL),
+		ID:        eq.ID,
+		Magnitude: mag,
+		Place:     eq.Properties.Place,
+		Time:      time.UnixMilli(eq.Properties.Time).UTC(),
+		URL:       eq.Properties.URL,
+	}
+}
+
+// postWithRetry posts the payload, retrying with jittered delays on failure
+func (n *Notifier) postWithRetry(payload NotificationPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= notifierMaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(FullJitter(n.retryDelay))
+		}
+
+		if lastErr = n.post(body); lastErr == nil {
+			return nil
+		}
+	}
+
+	return lastErr
+}
+
+// post sends a single webhook request
+func (n *Notifier) post(body []byte) error {
+	resp, err := n.httpClient.Post(n.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to make HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook request failed with status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"quakewatch-scraper/internal/models"
+)
+
+func TestNotifier_Notify(t *testing.T) {
+	var payloads []NotificationPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+
+		var payload NotificationPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	eventTime := time.Date(2024, 1, 15, 8, 30, 0, 0, time.UTC)
+	earthquakes := []models.Earthquake{
+		{ID: "small", Properties: models.EarthquakeProperties{Mag: models.Float64(3.2), Place: "Nowhere"}},
+		{ID: "big", Properties: models.EarthquakeProperties{
+			Mag:   models.Float64(6.4),
+			Place: "10 km N of Somewhere",
+			Time:  eventTime.UnixMilli(),
+			URL:   "https://earthquake.usgs.gov/earthquakes/eventpage/big",
+		}},
+	}
+
+	notifier := NewNotifier(server.URL, 5.0, 5*time.Second)
+	sent, err := notifier.Notify(earthquakes)
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if sent != 1 || len(payloads) != 1 {
+		t.Fatalf("Expected 1 notification, sent %d, received %d", sent, len(payloads))
+	}
+
+	got := payloads[0]
+	if got.ID != "big" || got.Magnitude != 6.4 || got.Place != "10 km N of Somewhere" {
+		t.Errorf("Unexpected payload: %+v", got)
+	}
+	if !got.Time.Equal(eventTime) {
+		t.Errorf("Time = %v, want %v", got.Time, eventTime)
+	}
+	if got.URL != "https://earthquake.usgs.gov/earthquakes/eventpage/big" {
+		t.Errorf("URL = %q", got.URL)
+	}
+}
+
+func TestNotifier_UnreachableWebhook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL, 5.0, time.Second)
+	notifier.retryDelay = time.Millisecond
+
+	earthquakes := []models.Earthquake{
+		{ID: "big", Properties: models.EarthquakeProperties{Mag: models.Float64(7.0)}},
+	}
+
+	sent, err := notifier.Notify(earthquakes)
+	if err == nil {
+		t.Error("Expected an error from a failing webhook")
+	}
+	if sent != 0 {
+		t.Errorf("Expected 0 delivered notifications, got %d", sent)
+	}
+}
+
+func TestNotifier_SkipsSentEarthquakes(t *testing.T) {
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload NotificationPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		posted = append(posted, payload.ID)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "sent")
+	earthquakes := []models.Earthquake{
+		{ID: "big", Properties: models.EarthquakeProperties{Mag: models.Float64(6.4)}},
+		{ID: "old", Properties: models.EarthquakeProperties{Mag: models.Float64(5.5)}},
+	}
+
+	notify := func(prepare func(*SeenCache)) int {
+		t.Helper()
+		cache, err := LoadSeenCache(path, time.Hour)
+		if err != nil {
+			t.Fatalf("LoadSeenCache() error = %v", err)
+		}
+		if prepare != nil {
+			prepare(cache)
+		}
+		notifier := NewNotifier(server.URL, 5.0, 5*time.Second)
+		notifier.SetSentCache(cache)
+		sent, err := notifier.Notify(earthquakes)
+		if err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+		return sent
+	}
+
+	// "old" was notified longer ago than the TTL, so it is notified again
+	if sent := notify(func(cache *SeenCache) { cache.Add("old", time.Now().Add(-2*time.Hour)) }); sent != 2 {
+		t.Errorf("First Notify() sent %d, want 2", sent)
+	}
+	// A later run over the same earthquakes notifies nothing
+	if sent := notify(nil); sent != 0 {
+		t.Errorf("Second Notify() sent %d, want 0", sent)
+	}
+	if len(posted) != 2 {
+		t.Errorf("Expected 2 posts in total, got %v", posted)
+	}
+}
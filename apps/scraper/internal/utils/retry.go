@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryStrategy describes how an operation is retried: exponential backoff from
+// BaseDelay, capped at MaxDelay, with full jitter applied to each delay
+type RetryStrategy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	Jitter     bool
+
+	// OnRetry, if set, is called before sleeping for the next attempt
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
+
+// NewRetryStrategy creates a jittered exponential retry strategy
+func NewRetryStrategy(maxRetries int, baseDelay, maxDelay time.Duration) *RetryStrategy {
+	return &RetryStrategy{
+		MaxRetries: maxRetries,
+		BaseDelay:  baseDelay,
+		MaxDelay:   maxDelay,
+		Jitter:     true,
+	}
+}
+
+// Delay returns the backoff delay before the given retry attempt (starting at 1)
+func (s *RetryStrategy) Delay(attempt int) time.Duration {
+	delay := time.Duration(float64(s.BaseDelay) * math.Pow(2, float64(attempt-1)))
+	if s.MaxDelay > 0 && (delay > s.MaxDelay || delay <= 0) {
+		delay = s.MaxDelay
+	}
+	if s.Jitter {
+		delay = FullJitter(delay)
+	}
+	return delay
+}
+
+// RetryAfterError marks a failure where the server asked the client to wait before retrying
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %v)", e.Err, e.RetryAfter)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// ParseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+// It returns false if the header is missing or invalid.
+func ParseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// RetryWithBackoff runs op until it succeeds, the strategy's retries are exhausted or ctx is done.
+// A RetryAfterError overrides the computed delay, capped at MaxDelay.
+func RetryWithBackoff(ctx context.Context, strategy *RetryStrategy, op func() error) error {
+	var lastErr error
+
+	for attempt := 0; attempt <= strategy.MaxRetries; attempt++ {
+		if attempt > 0 {
+			delay := strategy.Delay(attempt)
+
+			var retryAfter *RetryAfterError
+			if errors.As(lastErr, &retryAfter) {
+				delay = retryAfter.RetryAfter
+				if strategy.MaxDelay > 0 && delay > strategy.MaxDelay {
+					delay = strategy.MaxDelay
+				}
+			}
+
+			if strategy.OnRetry != nil {
+				strategy.OnRetry(attempt, delay, lastErr)
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("retry aborted after %d attempts: %w", attempt, ctx.Err())
+			case <-time.After(delay):
+			}
+		}
+
+		if lastErr = op(); lastErr == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed after %d attempts: %w", strategy.MaxRetries+1, lastErr)
+}
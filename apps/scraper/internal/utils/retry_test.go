@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryWithBackoff_HonorsRetryAfter(t *testing.T) {
+	strategy := NewRetryStrategy(3, time.Hour, 2*time.Hour)
+
+	var delays []time.Duration
+	strategy.OnRetry = func(attempt int, delay time.Duration, err error) {
+		delays = append(delays, delay)
+	}
+
+	calls := 0
+	err := RetryWithBackoff(context.Background(), strategy, func() error {
+		calls++
+		if calls < 3 {
+			// The server's hint must win over the hour-long computed backoff
+			return &RetryAfterError{Err: errors.New("rate limited"), RetryAfter: time.Millisecond}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RetryWithBackoff() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+	for _, delay := range delays {
+		if delay != time.Millisecond {
+			t.Errorf("Expected Retry-After delay of 1ms, got %v", delay)
+		}
+	}
+}
+
+func TestRetryWithBackoff_Exhausted(t *testing.T) {
+	strategy := NewRetryStrategy(2, time.Millisecond, 5*time.Millisecond)
+
+	calls := 0
+	failure := errors.New("boom")
+	err := RetryWithBackoff(context.Background(), strategy, func() error {
+		calls++
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected wrapped failure, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryStrategy_DelayIsCapped(t *testing.T) {
+	strategy := NewRetryStrategy(10, time.Second, 10*time.Second)
+	strategy.Jitter = false
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second}
+	for i, want := range expected {
+		if got := strategy.Delay(i + 1); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", i+1, got, want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{header: "", ok: false},
+		{header: "120", want: 2 * time.Minute, ok: true},
+		{header: "-5", ok: false},
+		{header: "Mon, 15 Jan 2024 08:00:30 GMT", want: 30 * time.Second, ok: true},
+		{header: "soon", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseRetryAfter(tt.header, now)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %v, %v, want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSeenTTL is how long a seen cache remembers an ID after it was last seen
+const DefaultSeenTTL = 24 * time.Hour
+
+// SeenCache remembers which IDs were seen in earlier runs. It is stored as a text file with one
+// "<id> <unix seconds>" line per ID, giving when the ID was last seen.
+type SeenCache struct {
+	path string
+	ttl  time.Duration
+	seen map[string]time.Time
+}
+
+// LoadSeenCache reads the seen cache at path. A missing file is an empty cache. IDs are
+// forgotten ttl after they were last seen, once Prune is called.
+func LoadSeenCache(path string, ttl time.Duration) (*SeenCache, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("seen cache TTL must be positive, got %v", ttl)
+	}
+	cache := &SeenCache{path: path, ttl: ttl, seen: make(map[string]time.Time)}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open seen cache: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		id, seconds, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid seen cache line %d in %s: %q", n, path, line)
+		}
+		unix, err := strconv.ParseInt(strings.TrimSpace(seconds), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seen cache line %d in %s: %w", n, path, err)
+		}
+		cache.Add(id, time.Unix(unix, 0))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read seen cache: %w", err)
+	}
+	return cache, nil
+}
+
+// Contains reports whether id has been seen
+func (c *SeenCache) Contains(id string) bool {
+	_, ok := c.seen[id]
+	return ok
+}
+
+// Add records that id was seen at seenAt. An ID seen again is kept until ttl after its latest sighting.
+func (c *SeenCache) Add(id string, seenAt time.Time) {
+	if last, ok := c.seen[id]; !ok || seenAt.After(last) {
+		c.seen[id] = seenAt
+	}
+}
+
+// Len returns the number of remembered IDs
+func (c *SeenCache) Len() int {
+	return len(c.seen)
+}
+
+// Prune forgets the IDs last seen more than the TTL before now and returns how many were dropped
+func (c *SeenCache) Prune(now time.Time) int {
+	cutoff := now.Add(-c.ttl)
+	pruned := 0
+	for id, seenAt := range c.seen {
+		if seenAt.Before(cutoff) {
+			delete(c.seen, id)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// Save writes the cache to its file, replacing it only once the new contents are written
+func (c *SeenCache) Save() error {
+	ids := make([]string, 0, len(c.seen))
+	for id := range c.seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var b strings.Builder
+	for _, id := range ids {
+		fmt.Fprintf(&b, "%s %d\n", id, c.seen[id].Unix())
+	}
+
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create seen cache directory: %w", err)
+		}
+	}
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write seen cache: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace seen cache: %w", err)
+	}
+	return nil
+}
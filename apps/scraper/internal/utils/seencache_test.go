@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSeenCache_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "seen.txt")
+	seenAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	cache, err := LoadSeenCache(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadSeenCache() error = %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Fatalf("Expected a missing file to load as an empty cache, got %d IDs", cache.Len())
+	}
+
+	cache.Add("us7000abcd", seenAt)
+	cache.Add("ci40123456", seenAt.Add(time.Minute))
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := "ci40123456 1705312860\nus7000abcd 1705312800\n"
+	if string(raw) != want {
+		t.Errorf("Cache file = %q, want %q", raw, want)
+	}
+
+	reloaded, err := LoadSeenCache(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadSeenCache() error = %v", err)
+	}
+	if reloaded.Len() != 2 || !reloaded.Contains("us7000abcd") || !reloaded.Contains("ci40123456") || reloaded.Contains("nc1") {
+		t.Errorf("Reloaded cache = %v, want the 2 saved IDs", reloaded.seen)
+	}
+	if got := reloaded.seen["us7000abcd"]; !got.Equal(seenAt) {
+		t.Errorf("Reloaded seen time = %v, want %v", got, seenAt)
+	}
+}
+
+func TestSeenCache_Prune(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	cache, err := LoadSeenCache(filepath.Join(t.TempDir(), "seen.txt"), 2*time.Hour)
+	if err != nil {
+		t.Fatalf("LoadSeenCache() error = %v", err)
+	}
+
+	cache.Add("expired", now.Add(-3*time.Hour))
+	cache.Add("edge", now.Add(-2*time.Hour))
+	cache.Add("fresh", now.Add(-time.Minute))
+	// Seen again recently, so it is kept from its latest sighting
+	cache.Add("resighted", now.Add(-5*time.Hour))
+	cache.Add("resighted", now.Add(-30*time.Minute))
+	cache.Add("resighted", now.Add(-4*time.Hour))
+
+	if pruned := cache.Prune(now); pruned != 1 {
+		t.Errorf("Prune() = %d, want 1", pruned)
+	}
+	for id, want := range map[string]bool{"expired": false, "edge": true, "fresh": true, "resighted": true} {
+		if got := cache.Contains(id); got != want {
+			t.Errorf("Contains(%q) = %t after pruning, want %t", id, got, want)
+		}
+	}
+}
+
+func TestLoadSeenCache_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadSeenCache(filepath.Join(dir, "seen.txt"), 0); err == nil {
+		t.Error("Expected an error for a non-positive TTL")
+	}
+
+	for name, content := range map[string]string{
+		"missing time": "us7000abcd\n",
+		"invalid time": "us7000abcd yesterday\n",
+	} {
+		path := filepath.Join(dir, "corrupt.txt")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		if _, err := LoadSeenCache(path, time.Hour); err == nil {
+			t.Errorf("%s: expected an error for %q", name, content)
+		}
+	}
+}
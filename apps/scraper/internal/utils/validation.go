@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// ValidationError describes a problem found in a single earthquake record
+type ValidationError struct {
+	ID      string `json:"id"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	id := e.ID
+	if id == "" {
+		id = "<no id>"
+	}
+	return fmt.Sprintf("%s: %s %s", id, e.Field, e.Message)
+}
+
+// ValidationRule checks a single earthquake record
+type ValidationRule interface {
+	Name() string
+	Validate(eq models.Earthquake) []ValidationError
+}
+
+// ValidationResult holds the outcome of validating a response
+type ValidationResult struct {
+	Total   int               `json:"total"`
+	Invalid int               `json:"invalid"`
+	Score   float64           `json:"score"`
+	Errors  []ValidationError `json:"errors"`
+}
+
+// DataValidator runs a set of validation rules over earthquake responses
+type DataValidator struct {
+	rules []ValidationRule
+}
+
+// NewDataValidator creates a validator with the given rules
+func NewDataValidator(rules ...ValidationRule) *DataValidator {
+	return &DataValidator{rules: rules}
+}
+
+// NewDefaultDataValidator creates a validator with the standard earthquake rules
+func NewDefaultDataValidator() *DataValidator {
+	return NewDataValidator(&RequiredFieldsRule{})
+}
+
+// AddRule registers an additional validation rule
+func (v *DataValidator) AddRule(rule ValidationRule) {
+	v.rules = append(v.rules, rule)
+}
+
+// ValidateEarthquake runs every rule against a single earthquake
+func (v *DataValidator) ValidateEarthquake(eq models.Earthquake) []ValidationError {
+	var errs []ValidationError
+	for _, rule := range v.rules {
+		errs = append(errs, rule.Validate(eq)...)
+	}
+	return errs
+}
+
+// ValidateResponse validates a response and scores it as the fraction of valid records.
+// A response that is not a GeoJSON FeatureCollection scores zero.
+func (v *DataValidator) ValidateResponse(response *models.USGSResponse) *ValidationResult {
+	result := &ValidationResult{Total: len(response.Features)}
+
+	if response.Type != "FeatureCollection" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "type",
+			Message: fmt.Sprintf("is %q, expected \"FeatureCollection\"", response.Type),
+		})
+		return result
+	}
+
+	for _, eq := range response.Features {
+		if errs := v.ValidateEarthquake(eq); len(errs) > 0 {
+			result.Invalid++
+			result.Errors = append(result.Errors, errs...)
+		}
+	}
+
+	result.Score = 1.0
+	if result.Total > 0 {
+		result.Score = float64(result.Total-result.Invalid) / float64(result.Total)
+	}
+
+	return result
+}
+
+// QualityError is returned when a response scores below the required quality
+type QualityError struct {
+	Result     *ValidationResult
+	MinQuality float64
+}
+
+// maxReportedValidationErrors caps how many record errors a QualityError lists
+const maxReportedValidationErrors = 10
+
+func (e *QualityError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "data quality %.2f is below the minimum of %.2f (%d of %d records invalid)",
+		e.Result.Score, e.MinQuality, e.Result.Invalid, e.Result.Total)
+
+	for i, err := range e.Result.Errors {
+		if i == maxReportedValidationErrors {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(e.Result.Errors)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n  %s", err.Error())
+	}
+
+	return b.String()
+}
+
+// CheckQuality returns a QualityError if the result scores below minQuality
+func CheckQuality(result *ValidationResult, minQuality float64) error {
+	if result.Score < minQuality {
+		return &QualityError{Result: result, MinQuality: minQuality}
+	}
+	return nil
+}
+
+// RequiredFieldsRule checks that the fields every earthquake needs are present
+type RequiredFieldsRule struct{}
+
+func (r *RequiredFieldsRule) Name() string {
+	return "required_fields"
+}
+
+func (r *RequiredFieldsRule) Validate(eq models.Earthquake) []ValidationError {
+	var errs []ValidationError
+
+	if eq.ID == "" {
+		errs = append(errs, ValidationError{Field: "id", Message: "is missing"})
+	}
+	if len(eq.Geometry.Coordinates) < 2 {
+		errs = append(errs, ValidationError{
+			ID:      eq.ID,
+			Field:   "geometry.coordinates",
+			Message: fmt.Sprintf("has %d values, expected at least 2", len(eq.Geometry.Coordinates)),
+		})
+	}
+	if eq.Properties.Time <= 0 {
+		errs = append(errs, ValidationError{ID: eq.ID, Field: "properties.time", Message: "is missing"})
+	}
+
+	return errs
+}
+
+// Plausible depth range in kilometers; USGS reports small negative depths for events above sea level
+const (
+	minDepthKm = -10.0
+	maxDepthKm = 1000.0
+)
+
+// CoordinateRule checks that longitude, latitude and depth are within physical bounds
+type CoordinateRule struct{}
+
+func (r *CoordinateRule) Name() string {
+	return "coordinates"
+}
+
+func (r *CoordinateRule) Validate(eq models.Earthquake) []ValidationError {
+	var errs []ValidationError
+
+	coords := eq.Geometry.Coordinates
+	bounds := []struct {
+		field    string
+		min, max float64
+	}{
+		{field: "longitude", min: -180, max: 180},
+		{field: "latitude", min: -90, max: 90},
+		{field: "depth", min: minDepthKm, max: maxDepthKm},
+	}
+	for i, b := range bounds {
+		if i >= len(coords) {
+			break
+		}
+		if coords[i] < b.min || coords[i] > b.max || math.IsNaN(coords[i]) {
+			errs = append(errs, ValidationError{
+				ID:      eq.ID,
+				Field:   "geometry." + b.field,
+				Message: fmt.Sprintf("is %g, expected between %g and %g", coords[i], b.min, b.max),
+			})
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"quakewatch-scraper/internal/models"
+)
+
+// validationResponse builds a response with the given number of valid and invalid records
+func validationResponse(valid, invalid int) *models.USGSResponse {
+	response := &models.USGSResponse{Type: "FeatureCollection"}
+	for i := 0; i < valid; i++ {
+		response.Features = append(response.Features, models.Earthquake{
+			ID:         fmt.Sprintf("valid-%d", i),
+			Properties: models.EarthquakeProperties{Time: 1705305600000},
+			Geometry:   models.Geometry{Coordinates: []float64{-122.4, 37.7, 10}},
+		})
+	}
+	for i := 0; i < invalid; i++ {
+		response.Features = append(response.Features, models.Earthquake{
+			ID: fmt.Sprintf("invalid-%d", i),
+		})
+	}
+	return response
+}
+
+func TestDataValidator_ValidateResponse(t *testing.T) {
+	validator := NewDefaultDataValidator()
+
+	result := validator.ValidateResponse(validationResponse(3, 1))
+	if result.Total != 4 || result.Invalid != 1 || result.Score != 0.75 {
+		t.Errorf("Result = %+v, want 1 of 4 invalid with score 0.75", result)
+	}
+	// The invalid record lacks both coordinates and time
+	if len(result.Errors) != 2 {
+		t.Errorf("Expected 2 validation errors, got %v", result.Errors)
+	}
+
+	if empty := validator.ValidateResponse(validationResponse(0, 0)); empty.Score != 1.0 {
+		t.Errorf("Empty response score = %v, want 1.0", empty.Score)
+	}
+
+	if wrongType := validator.ValidateResponse(&models.USGSResponse{Type: "Feature"}); wrongType.Score != 0 {
+		t.Errorf("Wrong type score = %v, want 0", wrongType.Score)
+	}
+}
+
+func TestCheckQuality_Threshold(t *testing.T) {
+	validator := NewDefaultDataValidator()
+	result := validator.ValidateResponse(validationResponse(3, 1))
+
+	tests := []struct {
+		name       string
+		minQuality float64
+		wantErr    bool
+	}{
+		{name: "Below score", minQuality: 0.5, wantErr: false},
+		{name: "Exactly at score", minQuality: 0.75, wantErr: false},
+		{name: "Just above score", minQuality: 0.76, wantErr: true},
+		{name: "Perfect required", minQuality: 1.0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckQuality(result, tt.minQuality)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckQuality(%v) error = %v, wantErr %v", tt.minQuality, err, tt.wantErr)
+			}
+
+			var qualityErr *QualityError
+			if tt.wantErr && !errors.As(err, &qualityErr) {
+				t.Errorf("Expected *QualityError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestCoordinateRule_Validate(t *testing.T) {
+	rule := &CoordinateRule{}
+
+	tests := []struct {
+		name       string
+		coords     []float64
+		wantFields []string
+	}{
+		{name: "Valid", coords: []float64{-122.4, 37.7, 10}},
+		{name: "Above sea level", coords: []float64{-155.3, 19.4, -3.5}},
+		{name: "Boundaries", coords: []float64{180, -90, 1000}},
+		{name: "Longitude out of range", coords: []float64{181.5, 37.7, 10}, wantFields: []string{"geometry.longitude"}},
+		{name: "Latitude out of range", coords: []float64{-122.4, 95, 10}, wantFields: []string{"geometry.latitude"}},
+		{name: "Depth beyond Earth", coords: []float64{-122.4, 37.7, 7000}, wantFields: []string{"geometry.depth"}},
+		{name: "Depth too shallow", coords: []float64{-122.4, 37.7, -50}, wantFields: []string{"geometry.depth"}},
+		{name: "All out of range", coords: []float64{-200, -91, 1200}, wantFields: []string{"geometry.longitude", "geometry.latitude", "geometry.depth"}},
+		{name: "Missing depth", coords: []float64{-122.4, 37.7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := rule.Validate(models.Earthquake{ID: "eq", Geometry: models.Geometry{Coordinates: tt.coords}})
+			if len(errs) != len(tt.wantFields) {
+				t.Fatalf("Validate() = %v, want fields %v", errs, tt.wantFields)
+			}
+			for i, field := range tt.wantFields {
+				if errs[i].Field != field {
+					t.Errorf("Error %d field = %q, want %q", i, errs[i].Field, field)
+				}
+			}
+		})
+	}
+}
+
+func TestCoordinateRule_AffectsScore(t *testing.T) {
+	validator := NewDataValidator(&RequiredFieldsRule{}, &CoordinateRule{})
+
+	response := validationResponse(3, 0)
+	response.Features = append(response.Features, models.Earthquake{
+		ID:         "corrupt",
+		Properties: models.EarthquakeProperties{Time: 1705305600000},
+		Geometry:   models.Geometry{Coordinates: []float64{540.2, 37.7, 10}},
+	})
+
+	result := validator.ValidateResponse(response)
+	if result.Invalid != 1 || result.Score != 0.75 {
+		t.Errorf("Result = %+v, want the corrupt record to be invalid with score 0.75", result)
+	}
+}
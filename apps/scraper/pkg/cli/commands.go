@@ -2,40 +2,107 @@ package cli
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
+	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
 
 	"quakewatch-scraper/internal/api"
 	"quakewatch-scraper/internal/collector"
 	"quakewatch-scraper/internal/config"
+	"quakewatch-scraper/internal/models"
 	sched "quakewatch-scraper/internal/scheduler"
 	"quakewatch-scraper/internal/storage"
+	"quakewatch-scraper/internal/utils"
 )
 
 // App represents the main CLI application
 type App struct {
 	rootCmd *cobra.Command
 	cfg     *config.Config
+	db      *sqlx.DB
+	limiter *utils.ConcurrencyLimiter
+	metrics *sched.Metrics
+
+	// stdoutFormat is the shape collections are written to stdout in, from --stdout-format
+	stdoutFormat string
+	// outputFormat is the encoding of collected data, from --format
+	outputFormat storage.Format
+	// logger receives debug details such as HTTP request timings, at the level from --log-level
+	logger *utils.Logger
+
+	// audit logs the invocation described by auditEntry once the command finishes
+	audit      *utils.AuditLogger
+	auditEntry *utils.AuditEntry
+
+	// started is set once cobra has accepted the command line and begins running the command
+	started bool
 }
 
-// outputToStdout outputs data to stdout in JSON format
+// Version is the scraper version shown by the version command and recorded in saved files
+const Version = "1.2.1"
+
+// UserAgent identifies the scraper to the APIs unless --user-agent or api.user_agent overrides it
+const UserAgent = "quakewatch-scraper/" + Version
+
+// Shapes collections can be written to stdout in, selected with --stdout-format
+const (
+	stdoutFormatCollection = "collection"
+	stdoutFormatFeatures   = "features"
+)
+
+// outputToStdout outputs data to stdout in JSON format. With --stdout-format features,
+// earthquake and fault collections are written as a bare array of their features. With
+// --format ndjson, each feature is written as a compact line.
 func (a *App) outputToStdout(data interface{}) error {
+	if a.outputFormat == storage.FormatNDJSON {
+		return storage.WriteNDJSON(os.Stdout, data)
+	}
+	if a.stdoutFormat == stdoutFormatFeatures {
+		data = stdoutFeatures(data)
+	}
+
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(data)
 }
 
+// stdoutFeatures returns the features of a GeoJSON collection, never nil so an empty collection
+// is written as []. Other data is returned unchanged.
+func stdoutFeatures(data interface{}) interface{} {
+	switch collection := data.(type) {
+	case *models.USGSResponse:
+		if collection.Features == nil {
+			return []models.Earthquake{}
+		}
+		return collection.Features
+	case *models.Fault:
+		if collection.Features == nil {
+			return []models.FaultFeature{}
+		}
+		return collection.Features
+	}
+	return data
+}
+
 // NewApp creates a new CLI application
 func NewApp() *App {
 	app := &App{
@@ -44,10 +111,11 @@ func NewApp() *App {
 			Short: "QuakeWatch Data Scraper - Collect earthquake and fault data",
 			Long:  `A Go application for collecting earthquake and fault data from various sources and saving to JSON files.`,
 		},
+		metrics: sched.NewMetrics(),
 	}
 
 	// Set up the PersistentPreRunE after creating the app
-	app.rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+	loadConfiguration := func(cmd *cobra.Command, args []string) error {
 		// Skip configuration loading for version command
 		if cmd.Name() == "version" {
 			return nil
@@ -68,13 +136,77 @@ func NewApp() *App {
 			// For other commands, load configuration without prompting
 			cfg, err := config.LoadConfig(configPath)
 			if err != nil {
-				// If config loading fails, use default configuration
-				app.cfg = config.DefaultConfig()
+				// If config loading fails, use default configuration, but say so: the defaults
+				// may differ in ways that matter, e.g. with the database disabled
+				fmt.Fprintf(os.Stderr, "Warning: %v; using the default configuration\n", err)
+				if app.cfg, err = config.DefaultConfig().ApplyEnv(); err != nil {
+					return err
+				}
 			} else {
 				app.cfg = cfg
 			}
 		}
 
+		// Apply --set overrides on top of the loaded configuration
+		overrides, _ := cmd.Flags().GetStringArray("set")
+		cfg, err := app.cfg.ApplyOverrides(overrides)
+		if err != nil {
+			return err
+		}
+		app.cfg = cfg
+
+		if _, err := storage.ParseLayout(app.cfg.Storage.Layout); err != nil {
+			return err
+		}
+
+		app.stdoutFormat, _ = cmd.Flags().GetString("stdout-format")
+		if app.stdoutFormat != stdoutFormatCollection && app.stdoutFormat != stdoutFormatFeatures {
+			return fmt.Errorf("invalid --stdout-format: %s (must be %s or %s)", app.stdoutFormat, stdoutFormatFeatures, stdoutFormatCollection)
+		}
+
+		// Gzip compresses saved files; data written to stdout is never compressed
+		stdout, _ := cmd.Flags().GetBool("stdout")
+		if compress, _ := cmd.Flags().GetBool("gzip"); compress && stdout {
+			return fmt.Errorf("--gzip cannot be combined with --stdout")
+		}
+
+		// --format is only defined on the collection commands
+		format, _ := cmd.Flags().GetString("format")
+		if app.outputFormat, err = storage.ParseFormat(format); err != nil {
+			return err
+		}
+
+		// Log entries go to stderr to keep stdout reserved for data written with --stdout
+		logLevel := app.cfg.Logging.Level
+		if cmd.Flags().Changed("log-level") {
+			logLevel, _ = cmd.Flags().GetString("log-level")
+		}
+		app.logger = utils.NewLogger(logLevel, app.cfg.Logging.Format)
+		app.logger.SetOutput(os.Stderr)
+
+		app.audit = utils.NewAuditLogger(app.cfg.Logging.AuditLog)
+		app.auditEntry = &utils.AuditEntry{
+			Command:   cmd.CommandPath(),
+			Args:      args,
+			Flags:     auditFlags(cmd),
+			StartTime: time.Now(),
+		}
+
+		return nil
+	}
+	app.rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		app.started = true
+		// Cobra checks required and grouped flags only after this hook; check them first so
+		// they are reported as invalid arguments too
+		if err := cmd.ValidateRequiredFlags(); err != nil {
+			return utils.NewCollectionError(utils.ErrorTypeConfig, false, err)
+		}
+		if err := cmd.ValidateFlagGroups(); err != nil {
+			return utils.NewCollectionError(utils.ErrorTypeConfig, false, err)
+		}
+		if err := loadConfiguration(cmd, args); err != nil {
+			return utils.NewCollectionError(utils.ErrorTypeConfig, false, err)
+		}
 		return nil
 	}
 
@@ -99,22 +231,38 @@ func (a *App) setupCommands() {
 
 	// Add utility commands
 	a.rootCmd.AddCommand(a.newValidateCmd())
+	a.rootCmd.AddCommand(a.newVerifyCmd())
 	a.rootCmd.AddCommand(a.newStatsCmd())
+	a.rootCmd.AddCommand(a.newDiffCmd())
+	a.rootCmd.AddCommand(a.newAggregateCmd())
 	a.rootCmd.AddCommand(a.newListCmd())
 	a.rootCmd.AddCommand(a.newPurgeCmd())
+	a.rootCmd.AddCommand(a.newDedupeCmd())
 	a.rootCmd.AddCommand(a.newHealthCmd())
+	a.rootCmd.AddCommand(a.newServeCmd())
 	a.rootCmd.AddCommand(a.newVersionCmd())
 	a.rootCmd.AddCommand(a.newConfigCmd())
+
+	// Add database commands
+	a.rootCmd.AddCommand(a.newDBCmd())
 }
 
 func (a *App) setupFlags() {
 	a.rootCmd.PersistentFlags().StringP("config", "c", "./configs/config.yaml", "Configuration file path")
-	a.rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose logging")
-	a.rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Suppress output")
-	a.rootCmd.PersistentFlags().String("log-level", "info", "Set log level (error, warn, info, debug)")
+	a.rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose logging, including collection details such as per-filter counts")
+	a.rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Suppress progress output; errors and data written with --stdout are still printed")
+	a.rootCmd.PersistentFlags().String("log-level", "info", "Set log level (error, warn, info, debug), overriding logging.level; debug logs the DNS, connect, TLS, first byte and total time of each API request to stderr")
 	a.rootCmd.PersistentFlags().StringP("output-dir", "o", "./data", "Output directory for JSON files")
 	a.rootCmd.PersistentFlags().Bool("dry-run", false, "Show what would be done without executing")
 	a.rootCmd.PersistentFlags().Bool("stdout", false, "Output data to stdout instead of saving to file")
+	a.rootCmd.PersistentFlags().String("stdout-format", stdoutFormatCollection, "Shape of collections written with --stdout: collection (the full GeoJSON FeatureCollection) or features (a bare array of features)")
+	a.rootCmd.PersistentFlags().Duration("http-timeout", 0, "HTTP timeout for API requests, overriding api.usgs.timeout and api.emsc.timeout")
+	a.rootCmd.PersistentFlags().Int("concurrency", 0, "Maximum API requests in flight at once, overriding collection.max_concurrency")
+	a.rootCmd.PersistentFlags().String("user-agent", "", "User-Agent header for API requests, overriding api.user_agent (default "+UserAgent+")")
+	a.rootCmd.PersistentFlags().StringArray("set", []string{}, "Override a configuration value (key=value, e.g. collection.max_limit=50000); repeatable")
+	a.rootCmd.PersistentFlags().Int("decode-retries", 0, "Re-fetch USGS responses that end before the JSON is complete up to this many times, waiting collection.retry_delay with backoff")
+	a.rootCmd.PersistentFlags().String("metrics-out", "", "Write run metrics (duration, failures, retries) as JSON to this file when the command finishes")
+	a.rootCmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
 }
 
 func (a *App) Run(args []string) error {
@@ -126,8 +274,110 @@ func (a *App) Run(args []string) error {
 	// Set up the command
 	a.rootCmd.SetArgs(args)
 
+	// Release the shared database connection once the command is done
+	defer a.closeDatabase()
+
 	// Execute the command - configuration will be loaded in PreRun
-	return a.rootCmd.Execute()
+	start := time.Now()
+	cmd, err := a.rootCmd.ExecuteC()
+	if err != nil && !a.started {
+		// Unknown commands and flags, bad flag values and missing required flags
+		err = utils.NewCollectionError(utils.ErrorTypeConfig, false, err)
+	}
+	a.metrics.RecordExecution(time.Since(start), err)
+	reportCollectionError(os.Stderr, err)
+
+	// Logged here rather than in a post-run hook, which cobra skips when the command fails
+	if a.auditEntry != nil {
+		a.auditEntry.Finish(time.Now(), err)
+		if auditErr := a.audit.Log(a.auditEntry); auditErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", auditErr)
+		}
+	}
+
+	if metricsOut, _ := cmd.Flags().GetString("metrics-out"); metricsOut != "" {
+		if writeErr := writeMetricsSnapshot(metricsOut, cmd, a.metrics, err); writeErr != nil {
+			if err == nil {
+				return writeErr
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", writeErr)
+		}
+	}
+	return err
+}
+
+// auditFlags returns the flags set on the command line for the audit log, masking the values
+// of configuration overrides for secrets, as config show does, and the credentials of URLs
+func auditFlags(cmd *cobra.Command) map[string]string {
+	flags := make(map[string]string)
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		flags[flag.Name] = config.RedactURL(flag.Value.String())
+	})
+
+	if overrides, err := cmd.Flags().GetStringArray("set"); err == nil && len(overrides) > 0 {
+		masked := make([]string, len(overrides))
+		for i, override := range overrides {
+			if key, value, ok := strings.Cut(override, "="); ok {
+				if config.IsSecretKey(key) {
+					value = "********"
+				}
+				override = key + "=" + config.RedactURL(value)
+			}
+			masked[i] = override
+		}
+		flags["set"] = strings.Join(masked, ",")
+	}
+	return flags
+}
+
+// recordCollected adds collected records to the audit entry of the running command
+func (a *App) recordCollected(count int) {
+	if a.auditEntry != nil {
+		a.auditEntry.Records += count
+	}
+}
+
+// writeMetricsSnapshot writes the run metrics of a finished command as JSON, along with the
+// command and, if it failed, its error and error type
+func writeMetricsSnapshot(path string, cmd *cobra.Command, metrics *sched.Metrics, runErr error) error {
+	snapshot := metrics.GetAllMetrics()
+	snapshot["command"] = cmd.CommandPath()
+	if runErr != nil {
+		snapshot["error"] = runErr.Error()
+		var collectionErr *utils.CollectionError
+		if errors.As(runErr, &collectionErr) {
+			snapshot["error_type"] = collectionErr.Type
+		}
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+// reportCollectionError prints the classification and context of a failed API request
+func reportCollectionError(w io.Writer, err error) {
+	var collectionErr *utils.CollectionError
+	if !errors.As(err, &collectionErr) {
+		return
+	}
+
+	retryable := "no"
+	if collectionErr.Retryable {
+		retryable = "yes"
+	}
+	fmt.Fprintf(w, "Error type: %s (retryable: %s)\n", collectionErr.Type, retryable)
+	if details := collectionErr.ContextString(); details != "" {
+		fmt.Fprintf(w, "Query: %s\n", details)
+	}
+	if body, ok := collectionErr.Context[utils.ContextResponseBody]; ok {
+		fmt.Fprintf(w, "Response body (first 4 KB at most):\n%s\n", body)
+	}
 }
 
 // newEarthquakeCmd creates the earthquake command
@@ -138,39 +388,106 @@ func (a *App) newEarthquakeCmd() *cobra.Command {
 		Long:  `Collect earthquake data from USGS API`,
 	}
 
+	// Filters shared by all earthquake subcommands
+	cmd.PersistentFlags().StringSlice("alert", []string{}, "Only keep earthquakes with these PAGER alert levels (green, yellow, orange, red)")
+	cmd.PersistentFlags().Bool("tsunami", false, "Only keep earthquakes flagged for tsunami potential")
+	cmd.PersistentFlags().String("place-contains", "", "Only keep earthquakes whose place contains this text (case-insensitive)")
+	cmd.PersistentFlags().StringSlice("network", []string{}, "Only keep earthquakes from these contributing networks (e.g. us, ci, nc)")
+	cmd.PersistentFlags().StringArray("exclude-region", []string{}, "Drop earthquakes inside this box (minLat,maxLat,minLon,maxLon; minLon > maxLon crosses the antimeridian); repeatable")
+	cmd.PersistentFlags().Int("min-sig", 0, "Only keep earthquakes with a USGS significance (sig) of at least this value (0 disables)")
+	cmd.PersistentFlags().StringSlice("mag-type", []string{}, "Only keep earthquakes with these magnitude types (e.g. mw, ml, mb); mw also matches mww, mwc, mwb, mwr")
+	cmd.PersistentFlags().Bool("summary", false, "Print aggregate statistics after saving")
+	cmd.PersistentFlags().Bool("no-save", false, "Fetch and validate earthquakes but discard them, printing only the count")
+	cmd.PersistentFlags().Bool("gzip", false, "Write gzip-compressed .json.gz files")
+	cmd.PersistentFlags().Bool("compact", false, "Write minified JSON instead of indented JSON")
+	cmd.PersistentFlags().Bool("collection-info", false, "Embed a collection_info object with the query, source URL, collection time and scraper version in saved files")
+	cmd.PersistentFlags().String("format", string(storage.FormatJSON), "Output format: json (a GeoJSON document) or ndjson (one compact feature per line, written to .ndjson files)")
+	cmd.PersistentFlags().Bool("deterministic-name", false, "Name output files from a hash of the query so re-running it overwrites the previous file")
+	cmd.PersistentFlags().Float64("min-quality", 0.0, "Fail if the fraction of valid records is below this threshold (0.0-1.0)")
+	cmd.PersistentFlags().String("order-by", "", "Result order (time, time-asc, magnitude, magnitude-asc)")
+	cmd.PersistentFlags().String("event-type", "earthquake", "USGS event type to collect (e.g. earthquake, quarry blast, explosion); empty for all types")
+	cmd.PersistentFlags().StringSlice("fields", []string{}, "Save only these fields of each earthquake as a JSON array instead of GeoJSON (e.g. id,time,mag,coordinates)")
+	cmd.PersistentFlags().String("catalog", "", "USGS catalog to query instead of the composite (e.g. ak for Alaska, nc for Northern California)")
+
 	// Recent earthquakes command
 	recentCmd := &cobra.Command{
 		Use:   "recent",
 		Short: "Collect recent earthquakes (last hour)",
-		RunE:  a.runRecentEarthquakes,
+		RunE:  a.withCollectionTimeout(a.runRecentEarthquakes),
 	}
 	recentCmd.Flags().IntP("limit", "l", 1000, "Limit number of records")
 	recentCmd.Flags().StringP("filename", "f", "", "Custom filename (without extension)")
+	recentCmd.Flags().Float64("min-mag", 0.0, "Minimum magnitude")
+	recentCmd.Flags().Float64("max-mag", 10.0, "Maximum magnitude")
+	recentCmd.Flags().String("source", "usgs", "Earthquake data source (usgs, emsc)")
+	recentCmd.Flags().String("fallback-source", "", "Source to fetch from when USGS fails (emsc)")
+	// Only recent collects the hour up to now, so it is the only command that may add to a baseline
+	recentCmd.Flags().String("compare-to-baseline", "", "Compare the last hour's event count and magnitudes against the hourly statistics in this baseline file, report anomalies, and add the hour to it")
+	recentCmd.Flags().Float64("anomaly-threshold", collector.DefaultAnomalyThreshold, "Standard deviations above the baseline mean at which --compare-to-baseline reports an anomaly")
+	addSeenCacheFlags(recentCmd)
 	cmd.AddCommand(recentCmd)
 
+	// Watch command
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Watch for new earthquakes and print them as they arrive",
+		Long:  `Poll USGS for recent earthquakes at an interval and print each newly seen earthquake to stdout as a single line of JSON. No files are written.`,
+		RunE:  a.runWatchEarthquakes,
+	}
+	watchCmd.Flags().Duration("interval", 30*time.Second, "Polling interval")
+	watchCmd.Flags().Float64("min-mag", 0.0, "Minimum magnitude")
+	watchCmd.Flags().IntP("limit", "l", 1000, "Limit number of records per poll")
+	addSeenCacheFlags(watchCmd)
+	cmd.AddCommand(watchCmd)
+
 	// Time range command
 	timeRangeCmd := &cobra.Command{
 		Use:   "time-range",
 		Short: "Collect earthquakes by time range",
-		RunE:  a.runTimeRangeEarthquakes,
+		RunE:  a.withCollectionTimeout(a.runTimeRangeEarthquakes),
 	}
 	timeRangeCmd.Flags().String("start", "", "Start time (YYYY-MM-DD)")
 	timeRangeCmd.Flags().String("end", "", "End time (YYYY-MM-DD)")
-	timeRangeCmd.Flags().IntP("limit", "l", 1000, "Limit number of records")
+	timeRangeCmd.Flags().IntP("limit", "l", 1000, "Limit number of records (0 fetches all, paginating as needed)")
+	timeRangeCmd.Flags().Bool("all", false, "Fetch every earthquake in the range, same as --limit 0")
 	timeRangeCmd.Flags().StringP("filename", "f", "", "Custom filename (without extension)")
+	timeRangeCmd.Flags().Bool("resume", false, "Record completed sub-queries and skip them when re-run after an interruption")
 	if err := timeRangeCmd.MarkFlagRequired("start"); err != nil {
 		panic(fmt.Sprintf("failed to mark start flag as required: %v", err))
 	}
 	if err := timeRangeCmd.MarkFlagRequired("end"); err != nil {
 		panic(fmt.Sprintf("failed to mark end flag as required: %v", err))
 	}
+	timeRangeCmd.MarkFlagsMutuallyExclusive("all", "limit")
 	cmd.AddCommand(timeRangeCmd)
 
+	// Backfill command
+	backfillCmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Collect a long time range in chunks, one file per chunk, resuming after an interruption",
+		Long: `Walk a long time range in chunks, saving each chunk's earthquakes to its own dated file
+(earthquakes_backfill_<start>_<end>.json). Requests are spaced to stay within api.usgs.rate_limit
+requests per minute. Saved chunks are recorded in a state file, so re-running the same command
+after an interruption or failure continues with the first unsaved chunk.`,
+		RunE: a.runBackfillEarthquakes,
+	}
+	backfillCmd.Flags().String("start", "", "Start time (YYYY-MM-DD)")
+	backfillCmd.Flags().String("end", "", "End time (YYYY-MM-DD)")
+	backfillCmd.Flags().Float64("min-mag", 0.0, "Minimum magnitude")
+	backfillCmd.Flags().String("chunk", "7d", "Time span of each chunk and file (e.g. 1d, 7d, 2w, 12h)")
+	if err := backfillCmd.MarkFlagRequired("start"); err != nil {
+		panic(fmt.Sprintf("failed to mark start flag as required: %v", err))
+	}
+	if err := backfillCmd.MarkFlagRequired("end"); err != nil {
+		panic(fmt.Sprintf("failed to mark end flag as required: %v", err))
+	}
+	cmd.AddCommand(backfillCmd)
+
 	// Magnitude command
 	magnitudeCmd := &cobra.Command{
 		Use:   "magnitude",
 		Short: "Collect earthquakes by magnitude range",
-		RunE:  a.runMagnitudeEarthquakes,
+		RunE:  a.withCollectionTimeout(a.runMagnitudeEarthquakes),
 	}
 	magnitudeCmd.Flags().Float64("min", 0.0, "Minimum magnitude")
 	magnitudeCmd.Flags().Float64("max", 10.0, "Maximum magnitude")
@@ -188,7 +505,7 @@ func (a *App) newEarthquakeCmd() *cobra.Command {
 	significantCmd := &cobra.Command{
 		Use:   "significant",
 		Short: "Collect significant earthquakes (M4.5+)",
-		RunE:  a.runSignificantEarthquakes,
+		RunE:  a.withCollectionTimeout(a.runSignificantEarthquakes),
 	}
 	significantCmd.Flags().String("start", "", "Start time (YYYY-MM-DD)")
 	significantCmd.Flags().String("end", "", "End time (YYYY-MM-DD)")
@@ -206,7 +523,7 @@ func (a *App) newEarthquakeCmd() *cobra.Command {
 	regionCmd := &cobra.Command{
 		Use:   "region",
 		Short: "Collect earthquakes by geographic region",
-		RunE:  a.runRegionEarthquakes,
+		RunE:  a.withCollectionTimeout(a.runRegionEarthquakes),
 	}
 	regionCmd.Flags().Float64("min-lat", -90.0, "Minimum latitude")
 	regionCmd.Flags().Float64("max-lat", 90.0, "Maximum latitude")
@@ -228,11 +545,29 @@ func (a *App) newEarthquakeCmd() *cobra.Command {
 	}
 	cmd.AddCommand(regionCmd)
 
+	// Radius command
+	radiusCmd := &cobra.Command{
+		Use:   "radius",
+		Short: "Collect earthquakes within a distance of a point",
+		RunE:  a.withCollectionTimeout(a.runRadiusEarthquakes),
+	}
+	radiusCmd.Flags().Float64("lat", 0, "Latitude of the center point")
+	radiusCmd.Flags().Float64("lon", 0, "Longitude of the center point")
+	radiusCmd.Flags().Float64("radius-km", 0, "Search radius in kilometers")
+	radiusCmd.Flags().IntP("limit", "l", 1000, "Limit number of records")
+	radiusCmd.Flags().StringP("filename", "f", "", "Custom filename (without extension)")
+	for _, name := range []string{"lat", "lon", "radius-km"} {
+		if err := radiusCmd.MarkFlagRequired(name); err != nil {
+			panic(fmt.Sprintf("failed to mark %s flag as required: %v", name, err))
+		}
+	}
+	cmd.AddCommand(radiusCmd)
+
 	// Country command
 	countryCmd := &cobra.Command{
 		Use:   "country",
 		Short: "Collect earthquakes by country",
-		RunE:  a.runCountryEarthquakes,
+		RunE:  a.withCollectionTimeout(a.runCountryEarthquakes),
 	}
 	countryCmd.Flags().String("country", "", "Country name to filter by")
 	countryCmd.Flags().String("start", "", "Start time (YYYY-MM-DD)")
@@ -246,6 +581,36 @@ func (a *App) newEarthquakeCmd() *cobra.Command {
 	}
 	cmd.AddCommand(countryCmd)
 
+	// Event detail command
+	detailCmd := &cobra.Command{
+		Use:   "detail",
+		Short: "Collect the full detail of a single event",
+		Long:  `Fetch the USGS detail GeoJSON of one event, including products such as moment tensors, phase data and ShakeMaps, and save it to the events directory.`,
+		RunE:  a.withCollectionTimeout(a.runEventDetail),
+	}
+	detailCmd.Flags().String("id", "", "USGS event ID (e.g. ci38457511)")
+	detailCmd.Flags().StringP("filename", "f", "", "Custom filename (without extension); defaults to event_<id>")
+	if err := detailCmd.MarkFlagRequired("id"); err != nil {
+		panic(fmt.Sprintf("failed to mark id flag as required: %v", err))
+	}
+	cmd.AddCommand(detailCmd)
+
+	// Query spec command
+	queryCmd := &cobra.Command{
+		Use:   "query",
+		Short: "Collect earthquakes described by a YAML or JSON query spec",
+		Long: `Collect earthquakes matching a query spec file. The spec can set the time range, magnitude,
+depth and region to query, the filters to apply, and how to write the results. Filters and
+output options given as flags are applied as well.`,
+		RunE: a.withCollectionTimeout(a.runQueryEarthquakes),
+	}
+	queryCmd.Flags().String("spec", "", "Path to the query spec (YAML or JSON)")
+	queryCmd.Flags().StringP("filename", "f", "", "Custom filename (without extension); overrides output.filename")
+	if err := queryCmd.MarkFlagRequired("spec"); err != nil {
+		panic(fmt.Sprintf("failed to mark spec flag as required: %v", err))
+	}
+	cmd.AddCommand(queryCmd)
+
 	return cmd
 }
 
@@ -256,25 +621,29 @@ func (a *App) newFaultCmd() *cobra.Command {
 		Short: "Collect fault data",
 		Long:  `Collect fault data from EMSC API`,
 	}
+	cmd.PersistentFlags().Bool("gzip", false, "Write gzip-compressed .json.gz files")
+	cmd.PersistentFlags().Bool("compact", false, "Write minified JSON instead of indented JSON")
+	cmd.PersistentFlags().String("format", string(storage.FormatJSON), "Output format: json (a GeoJSON document) or ndjson (one compact feature per line, written to .ndjson files)")
 
 	// Collect command
 	collectCmd := &cobra.Command{
 		Use:   "collect",
 		Short: "Collect fault data from EMSC",
-		RunE:  a.runCollectFaults,
+		RunE:  a.withCollectionTimeout(a.runCollectFaults),
 	}
 	collectCmd.Flags().StringP("filename", "f", "", "Custom filename (without extension)")
+	collectCmd.Flags().String("bbox", "", "Keep only faults with a coordinate inside this box (minLat,maxLat,minLon,maxLon; minLon > maxLon crosses the antimeridian)")
 	cmd.AddCommand(collectCmd)
 
 	// Update command
 	updateCmd := &cobra.Command{
 		Use:   "update",
 		Short: "Update fault data with retry logic",
-		RunE:  a.runUpdateFaults,
+		RunE:  a.withCollectionTimeout(a.runUpdateFaults),
 	}
 	updateCmd.Flags().StringP("filename", "f", "", "Custom filename (without extension)")
 	updateCmd.Flags().Int("retries", 3, "Number of retry attempts")
-	updateCmd.Flags().Duration("retry-delay", 5*time.Second, "Delay between retries")
+	updateCmd.Flags().Duration("retry-delay", 5*time.Second, "Initial delay between retries (doubles on each retry)")
 	cmd.AddCommand(updateCmd)
 
 	return cmd
@@ -289,6 +658,20 @@ func (a *App) newValidateCmd() *cobra.Command {
 	}
 	cmd.Flags().StringP("type", "t", "all", "Data type (earthquakes, faults, all)")
 	cmd.Flags().StringP("file", "f", "", "Specific file to validate")
+	cmd.Flags().Bool("fix", false, "Rewrite files that fail validation without their unreadable or incomplete records, keeping the original as .bak")
+	return cmd
+}
+
+// newVerifyCmd creates the verify command
+func (a *App) newVerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify data files against their manifests",
+		Long:  `Recompute the SHA-256 of each data file and compare it and the record count against the .sha256 manifest written when the file was saved, flagging modified or corrupt files.`,
+		RunE:  a.runVerify,
+	}
+	cmd.Flags().StringP("type", "t", "all", "Data type (earthquakes, faults, all)")
+	cmd.Flags().StringP("file", "f", "", "Specific file to verify")
 	return cmd
 }
 
@@ -301,6 +684,44 @@ func (a *App) newStatsCmd() *cobra.Command {
 	}
 	cmd.Flags().StringP("type", "t", "all", "Data type (earthquakes, faults, all)")
 	cmd.Flags().StringP("file", "f", "", "Specific file to show stats for")
+	cmd.Flags().Bool("wide", false, "Show extra columns such as magnitude range")
+	cmd.Flags().Bool("all-files", false, "Count unique earthquakes and faults across all files, merging records that appear in several")
+	return cmd
+}
+
+// newDiffCmd creates the diff command
+func (a *App) newDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare two data files",
+		Long:  `Compare two collected earthquake files by USGS ID, listing added and removed events and field changes such as magnitude or alert level revisions.`,
+		RunE:  a.runDiff,
+	}
+	cmd.Flags().StringP("type", "t", "earthquakes", "Data type (earthquakes)")
+	cmd.Flags().String("a", "", "Older file to compare")
+	cmd.Flags().String("b", "", "Newer file to compare")
+	cmd.Flags().Bool("json", false, "Output the diff as JSON")
+	if err := cmd.MarkFlagRequired("a"); err != nil {
+		panic(fmt.Sprintf("failed to mark a flag as required: %v", err))
+	}
+	if err := cmd.MarkFlagRequired("b"); err != nil {
+		panic(fmt.Sprintf("failed to mark b flag as required: %v", err))
+	}
+	return cmd
+}
+
+// newAggregateCmd creates the aggregate command
+func (a *App) newAggregateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "aggregate",
+		Short: "Export earthquake counts binned by time and magnitude",
+		Long:  `Load all collected earthquakes, count them per time window and magnitude band and write the counts as CSV (window_start, mag_band, count) for plotting seismicity rates. Windows and bands without earthquakes are included with a count of 0.`,
+		RunE:  a.runAggregate,
+	}
+	cmd.Flags().StringP("type", "t", "earthquakes", "Data type (earthquakes)")
+	cmd.Flags().String("bucket", "1h", "Length of each time window (e.g. '15m', '1h', '1d')")
+	cmd.Flags().Float64("mag-band", 1.0, "Width of each magnitude band")
+	cmd.Flags().String("out", "", "CSV file to write (default stdout)")
 	return cmd
 }
 
@@ -312,6 +733,7 @@ func (a *App) newListCmd() *cobra.Command {
 		RunE:  a.runList,
 	}
 	cmd.Flags().StringP("type", "t", "all", "Data type (earthquakes, faults, all)")
+	cmd.Flags().Bool("wide", false, "Show extra columns such as magnitude range")
 	return cmd
 }
 
@@ -326,6 +748,22 @@ func (a *App) newPurgeCmd() *cobra.Command {
 	cmd.Flags().StringP("type", "t", "all", "Data type to purge (earthquakes, faults, all)")
 	cmd.Flags().BoolP("force", "f", false, "Force deletion without confirmation")
 	cmd.Flags().Bool("dry-run", false, "Show what would be deleted without actually deleting")
+	cmd.Flags().String("older-than", "", "Only delete files collected longer ago than this (e.g. '30d', '12h')")
+	return cmd
+}
+
+// newDedupeCmd creates the dedupe command
+func (a *App) newDedupeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Consolidate overlapping data files into one",
+		Long: `Load every file of a data type and write a single file holding each record once. For earthquakes
+the most recently updated revision is kept; for faults, the copy from the newest file.`,
+		RunE: a.runDedupe,
+	}
+	cmd.Flags().StringP("type", "t", "earthquakes", "Data type to deduplicate (earthquakes, faults)")
+	cmd.Flags().StringP("filename", "f", "", "Name of the consolidated file (without extension)")
+	cmd.Flags().Bool("remove-originals", false, "Delete the merged files once the consolidated file is written")
 	return cmd
 }
 
@@ -336,6 +774,8 @@ func (a *App) newHealthCmd() *cobra.Command {
 		Short: "Check system health",
 		RunE:  a.runHealth,
 	}
+	cmd.Flags().Bool("json", false, "Output health check results as JSON")
+	cmd.Flags().String("max-data-age", "", "Fail the storage freshness check if the newest earthquake file is older than this (e.g. '2h', '1d')")
 	return cmd
 }
 
@@ -357,6 +797,16 @@ func (a *App) newConfigCmd() *cobra.Command {
 		Long:  `Create or update the application configuration file through interactive prompts.`,
 		RunE:  a.runConfig,
 	}
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the effective configuration",
+		Long:  `Print the fully resolved configuration (defaults, config file and environment overrides) as YAML.`,
+		RunE:  a.runConfigShow,
+	}
+	showCmd.Flags().Bool("show-secrets", false, "Include secrets such as the database password, webhook URL and URL credentials")
+	cmd.AddCommand(showCmd)
+
 	return cmd
 }
 
@@ -375,19 +825,129 @@ func (a *App) runRecentEarthquakes(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initialize components with configuration
-	storage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
-	usgsClient := api.NewUSGSClient(a.cfg.API.USGS.BaseURL, a.cfg.API.USGS.Timeout)
+	storage := a.newCollectionStorage(cmd)
+	usgsClient := a.newUSGSClient(cmd)
 	collector := collector.NewEarthquakeCollector(usgsClient, storage)
+	if err := a.configureEarthquakeCollector(cmd, collector); err != nil {
+		return err
+	}
+
+	if err := configureSeenCache(cmd, collector); err != nil {
+		return err
+	}
+
+	source, _ := cmd.Flags().GetString("source")
+	switch source {
+	case "usgs":
+	case "emsc":
+		if cmd.Flags().Changed("min-mag") || cmd.Flags().Changed("max-mag") {
+			return fmt.Errorf("--min-mag and --max-mag are only supported with --source usgs")
+		}
+		collector.SetRecentSource("emsc", a.newEMSCClient(cmd))
+	default:
+		return fmt.Errorf("invalid source: %s (must be usgs or emsc)", source)
+	}
+
+	fallbackSource, _ := cmd.Flags().GetString("fallback-source")
+	switch fallbackSource {
+	case "":
+	case "emsc":
+		if source != "usgs" {
+			return fmt.Errorf("--fallback-source is only supported with --source usgs")
+		}
+		if cmd.Flags().Changed("min-mag") || cmd.Flags().Changed("max-mag") {
+			return fmt.Errorf("--fallback-source cannot be combined with --min-mag or --max-mag")
+		}
+		collector.SetFallbackSource("emsc", a.newEMSCClient(cmd))
+	default:
+		return fmt.Errorf("invalid fallback source: %s (must be emsc)", fallbackSource)
+	}
+
+	// Only query by magnitude when a bound was given, so events below M0 are kept by default
+	if cmd.Flags().Changed("min-mag") || cmd.Flags().Changed("max-mag") {
+		minMag, _ := cmd.Flags().GetFloat64("min-mag")
+		maxMag, _ := cmd.Flags().GetFloat64("max-mag")
+		if minMag > maxMag {
+			return fmt.Errorf("--min-mag (%.1f) cannot be greater than --max-mag (%.1f)", minMag, maxMag)
+		}
+
+		if stdout {
+			earthquakes, err := collector.CollectRecentByMagnitudeData(cmd.Context(), 1, minMag, maxMag, limit)
+			if err != nil {
+				return err
+			}
+			if err := a.outputToStdout(collector.Output(earthquakes)); err != nil {
+				return err
+			}
+			return collector.SaveSeenCache()
+		}
+
+		return collector.CollectRecentByMagnitude(cmd.Context(), 1, minMag, maxMag, limit, filename)
+	}
 
 	if stdout {
-		earthquakes, err := collector.CollectRecentData(limit)
+		earthquakes, err := collector.CollectRecentData(cmd.Context(), limit)
 		if err != nil {
 			return err
 		}
-		return a.outputToStdout(earthquakes)
+		if err := a.outputToStdout(collector.Output(earthquakes)); err != nil {
+			return err
+		}
+		return collector.SaveSeenCache()
+	}
+
+	return collector.CollectRecent(cmd.Context(), limit, filename)
+}
+
+func (a *App) runWatchEarthquakes(cmd *cobra.Command, args []string) error {
+	// Watch streams earthquakes without saving a collection, so there is nothing to summarize or compress
+	for _, name := range []string{"summary", "gzip"} {
+		if set, _ := cmd.Flags().GetBool(name); set {
+			return fmt.Errorf("--%s cannot be used with watch", name)
+		}
+	}
+
+	interval, _ := cmd.Flags().GetDuration("interval")
+	minMag, _ := cmd.Flags().GetFloat64("min-mag")
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	// Use configuration values
+	if limit == 0 {
+		limit = a.cfg.Collection.DefaultLimit
+	}
+	if limit > a.cfg.Collection.MaxLimit {
+		limit = a.cfg.Collection.MaxLimit
 	}
 
-	return collector.CollectRecent(limit, filename)
+	// Initialize components with configuration
+	storage := a.newJSONStorage()
+	usgsClient := a.newUSGSClient(cmd)
+	eqCollector := collector.NewEarthquakeCollector(usgsClient, storage)
+	if err := a.configureEarthquakeCollector(cmd, eqCollector); err != nil {
+		return err
+	}
+	if minMag > 0 {
+		eqCollector.AddFilter(func(earthquakes []models.Earthquake) []models.Earthquake {
+			return collector.FilterByMagnitude(earthquakes, minMag, math.MaxFloat64)
+		})
+	}
+
+	if err := configureSeenCache(cmd, eqCollector); err != nil {
+		return err
+	}
+
+	// Keep stdout reserved for the earthquake stream
+	eqCollector.SetProgressOutput(progressOutput(cmd, true))
+
+	// Stop cleanly on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	encoder := json.NewEncoder(os.Stdout)
+	watcher := collector.NewWatcher(eqCollector, limit)
+	return watcher.Run(ctx, interval, func(eq models.Earthquake) error {
+		return encoder.Encode(eq)
+	})
 }
 
 func (a *App) runTimeRangeEarthquakes(cmd *cobra.Command, args []string) error {
@@ -396,6 +956,8 @@ func (a *App) runTimeRangeEarthquakes(cmd *cobra.Command, args []string) error {
 	limit, _ := cmd.Flags().GetInt("limit")
 	filename, _ := cmd.Flags().GetString("filename")
 	stdout, _ := cmd.Flags().GetBool("stdout")
+	resume, _ := cmd.Flags().GetBool("resume")
+	all, _ := cmd.Flags().GetBool("all")
 
 	startTime, err := time.Parse("2006-01-02", startStr)
 	if err != nil {
@@ -407,28 +969,120 @@ func (a *App) runTimeRangeEarthquakes(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid end time format: %w", err)
 	}
 
-	// Use configuration values
-	if limit == 0 {
-		limit = a.cfg.Collection.DefaultLimit
+	// --all or --limit 0 fetch everything in the range, paginating past the USGS cap;
+	// otherwise the limit is capped by the configuration
+	if limit < 0 {
+		return fmt.Errorf("invalid --limit: %d (must be 0 for all or positive)", limit)
+	}
+	if all {
+		limit = api.NoLimit
 	}
 	if limit > a.cfg.Collection.MaxLimit {
 		limit = a.cfg.Collection.MaxLimit
 	}
 
 	// Initialize components with configuration
-	storage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
-	usgsClient := api.NewUSGSClient(a.cfg.API.USGS.BaseURL, a.cfg.API.USGS.Timeout)
-	collector := collector.NewEarthquakeCollector(usgsClient, storage)
+	jsonStorage := a.newCollectionStorage(cmd)
+	usgsClient := a.newUSGSClient(cmd)
+	collector := collector.NewEarthquakeCollector(usgsClient, jsonStorage)
+	if err := a.configureEarthquakeCollector(cmd, collector); err != nil {
+		return err
+	}
+
+	var state *storage.CollectionState
+	if resume {
+		statePath := jsonStorage.CollectionStatePath(collector.TimeRangeStateName(startTime, endTime, limit))
+		state, err = storage.LoadCollectionState(statePath)
+		if err != nil {
+			return err
+		}
+		if len(state.SubQueries) > 0 {
+			fmt.Fprintf(progressOutput(cmd, stdout), "Resuming collection with %d recorded sub-queries from %s\n", len(state.SubQueries), statePath)
+		}
+		usgsClient.SetPaginationState(state)
+	}
 
 	if stdout {
-		earthquakes, err := collector.CollectByTimeRangeData(startTime, endTime, limit)
+		earthquakes, err := collector.CollectByTimeRangeData(cmd.Context(), startTime, endTime, limit)
 		if err != nil {
 			return err
 		}
-		return a.outputToStdout(earthquakes)
+		if state != nil {
+			if err := state.Remove(); err != nil {
+				return err
+			}
+		}
+		return a.outputToStdout(collector.Output(earthquakes))
+	}
+
+	if err := collector.CollectByTimeRange(cmd.Context(), startTime, endTime, limit, filename); err != nil {
+		return err
+	}
+	if state != nil {
+		return state.Remove()
+	}
+	return nil
+}
+
+func (a *App) runBackfillEarthquakes(cmd *cobra.Command, args []string) error {
+	startStr, _ := cmd.Flags().GetString("start")
+	endStr, _ := cmd.Flags().GetString("end")
+	minMag, _ := cmd.Flags().GetFloat64("min-mag")
+	chunkStr, _ := cmd.Flags().GetString("chunk")
+	stdout, _ := cmd.Flags().GetBool("stdout")
+
+	if stdout {
+		return fmt.Errorf("--stdout is not supported by backfill, which saves one file per chunk")
+	}
+	startTime, err := time.Parse("2006-01-02", startStr)
+	if err != nil {
+		return fmt.Errorf("invalid start time format: %w", err)
+	}
+	endTime, err := time.Parse("2006-01-02", endStr)
+	if err != nil {
+		return fmt.Errorf("invalid end time format: %w", err)
+	}
+	chunk, err := utils.ParseDuration(chunkStr)
+	if err != nil {
+		return fmt.Errorf("invalid --chunk: %w", err)
+	}
+	chunks, err := collector.BackfillChunks(startTime, endTime, chunk)
+	if err != nil {
+		return err
+	}
+
+	// Initialize components with configuration
+	jsonStorage := a.newCollectionStorage(cmd)
+	usgsClient := a.newUSGSClient(cmd)
+	eqCollector := collector.NewEarthquakeCollector(usgsClient, jsonStorage)
+	if err := a.configureEarthquakeCollector(cmd, eqCollector); err != nil {
+		return err
+	}
+
+	backfiller := collector.NewBackfiller(eqCollector, minMag, chunk)
+	statePath := jsonStorage.CollectionStatePath(backfiller.StateName(startTime, endTime))
+	state, err := storage.LoadCollectionState(statePath)
+	if err != nil {
+		return err
+	}
+	if len(state.SubQueries) > 0 {
+		fmt.Fprintf(progressOutput(cmd, false), "Resuming backfill with %d of %d chunks already saved (%s)\n", len(state.SubQueries), len(chunks), statePath)
 	}
+	backfiller.SetProgress(state)
+	if rateLimit := a.cfg.API.USGS.RateLimit; rateLimit > 0 {
+		backfiller.SetPause(time.Minute / time.Duration(rateLimit))
+	}
+
+	// Stop between requests on interrupt; saved chunks stay recorded for the next run
+	ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	return collector.CollectByTimeRange(startTime, endTime, limit, filename)
+	result, err := backfiller.Run(ctx, startTime, endTime)
+	if err != nil {
+		return fmt.Errorf("backfill stopped with %d of %d chunks saved; re-run the command to resume: %w", len(state.SubQueries), len(chunks), err)
+	}
+	fmt.Fprintf(progressOutput(cmd, false), "Backfilled %d chunks (%d from an earlier run) with %d earthquakes\n", result.Chunks, result.Skipped, result.Earthquakes)
+	return state.Remove()
 }
 
 func (a *App) runMagnitudeEarthquakes(cmd *cobra.Command, args []string) error {
@@ -447,19 +1101,22 @@ func (a *App) runMagnitudeEarthquakes(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initialize components with configuration
-	storage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
-	usgsClient := api.NewUSGSClient(a.cfg.API.USGS.BaseURL, a.cfg.API.USGS.Timeout)
+	storage := a.newCollectionStorage(cmd)
+	usgsClient := a.newUSGSClient(cmd)
 	collector := collector.NewEarthquakeCollector(usgsClient, storage)
+	if err := a.configureEarthquakeCollector(cmd, collector); err != nil {
+		return err
+	}
 
 	if stdout {
-		earthquakes, err := collector.CollectByMagnitudeData(minMag, maxMag, limit)
+		earthquakes, err := collector.CollectByMagnitudeData(cmd.Context(), minMag, maxMag, limit)
 		if err != nil {
 			return err
 		}
-		return a.outputToStdout(earthquakes)
+		return a.outputToStdout(collector.Output(earthquakes))
 	}
 
-	return collector.CollectByMagnitude(minMag, maxMag, limit, filename)
+	return collector.CollectByMagnitude(cmd.Context(), minMag, maxMag, limit, filename)
 }
 
 func (a *App) runSignificantEarthquakes(cmd *cobra.Command, args []string) error {
@@ -488,19 +1145,22 @@ func (a *App) runSignificantEarthquakes(cmd *cobra.Command, args []string) error
 	}
 
 	// Initialize components with configuration
-	storage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
-	usgsClient := api.NewUSGSClient(a.cfg.API.USGS.BaseURL, a.cfg.API.USGS.Timeout)
+	storage := a.newCollectionStorage(cmd)
+	usgsClient := a.newUSGSClient(cmd)
 	collector := collector.NewEarthquakeCollector(usgsClient, storage)
+	if err := a.configureEarthquakeCollector(cmd, collector); err != nil {
+		return err
+	}
 
 	if stdout {
-		earthquakes, err := collector.CollectSignificantData(startTime, endTime, limit)
+		earthquakes, err := collector.CollectSignificantData(cmd.Context(), startTime, endTime, limit)
 		if err != nil {
 			return err
 		}
-		return a.outputToStdout(earthquakes)
+		return a.outputToStdout(collector.Output(earthquakes))
 	}
 
-	return collector.CollectSignificant(startTime, endTime, limit, filename)
+	return collector.CollectSignificant(cmd.Context(), startTime, endTime, limit, filename)
 }
 
 func (a *App) runRegionEarthquakes(cmd *cobra.Command, args []string) error {
@@ -521,19 +1181,67 @@ func (a *App) runRegionEarthquakes(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initialize components with configuration
-	storage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
-	usgsClient := api.NewUSGSClient(a.cfg.API.USGS.BaseURL, a.cfg.API.USGS.Timeout)
+	storage := a.newCollectionStorage(cmd)
+	usgsClient := a.newUSGSClient(cmd)
+	collector := collector.NewEarthquakeCollector(usgsClient, storage)
+	if err := a.configureEarthquakeCollector(cmd, collector); err != nil {
+		return err
+	}
+
+	if stdout {
+		earthquakes, err := collector.CollectByRegionData(cmd.Context(), minLat, maxLat, minLon, maxLon, limit)
+		if err != nil {
+			return err
+		}
+		return a.outputToStdout(collector.Output(earthquakes))
+	}
+
+	return collector.CollectByRegion(cmd.Context(), minLat, maxLat, minLon, maxLon, limit, filename)
+}
+
+func (a *App) runRadiusEarthquakes(cmd *cobra.Command, args []string) error {
+	lat, _ := cmd.Flags().GetFloat64("lat")
+	lon, _ := cmd.Flags().GetFloat64("lon")
+	radiusKm, _ := cmd.Flags().GetFloat64("radius-km")
+	limit, _ := cmd.Flags().GetInt("limit")
+	filename, _ := cmd.Flags().GetString("filename")
+	stdout, _ := cmd.Flags().GetBool("stdout")
+
+	if lat < -90 || lat > 90 {
+		return fmt.Errorf("invalid --lat %g: must be between -90 and 90", lat)
+	}
+	if lon < -180 || lon > 180 {
+		return fmt.Errorf("invalid --lon %g: must be between -180 and 180", lon)
+	}
+	if radiusKm <= 0 || radiusKm > api.MaxRadiusKm {
+		return fmt.Errorf("invalid --radius-km %g: must be greater than 0 and at most %g", radiusKm, api.MaxRadiusKm)
+	}
+
+	// Use configuration values
+	if limit == 0 {
+		limit = a.cfg.Collection.DefaultLimit
+	}
+	if limit > a.cfg.Collection.MaxLimit {
+		limit = a.cfg.Collection.MaxLimit
+	}
+
+	// Initialize components with configuration
+	storage := a.newCollectionStorage(cmd)
+	usgsClient := a.newUSGSClient(cmd)
 	collector := collector.NewEarthquakeCollector(usgsClient, storage)
+	if err := a.configureEarthquakeCollector(cmd, collector); err != nil {
+		return err
+	}
 
 	if stdout {
-		earthquakes, err := collector.CollectByRegionData(minLat, maxLat, minLon, maxLon, limit)
+		earthquakes, err := collector.CollectByRadiusData(cmd.Context(), lat, lon, radiusKm, limit)
 		if err != nil {
 			return err
 		}
-		return a.outputToStdout(earthquakes)
+		return a.outputToStdout(collector.Output(earthquakes))
 	}
 
-	return collector.CollectByRegion(minLat, maxLat, minLon, maxLon, limit, filename)
+	return collector.CollectByRadius(cmd.Context(), lat, lon, radiusKm, limit, filename)
 }
 
 func (a *App) runCountryEarthquakes(cmd *cobra.Command, args []string) error {
@@ -573,148 +1281,633 @@ func (a *App) runCountryEarthquakes(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initialize components with configuration
-	storage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
-	usgsClient := api.NewUSGSClient(a.cfg.API.USGS.BaseURL, a.cfg.API.USGS.Timeout)
+	storage := a.newCollectionStorage(cmd)
+	usgsClient := a.newUSGSClient(cmd)
 	collector := collector.NewEarthquakeCollector(usgsClient, storage)
+	if err := a.configureEarthquakeCollector(cmd, collector); err != nil {
+		return err
+	}
 
 	if stdout {
-		earthquakes, err := collector.CollectByCountryData(country, startTime, endTime, minMag, maxMag, limit)
+		earthquakes, err := collector.CollectByCountryData(cmd.Context(), country, startTime, endTime, minMag, maxMag, limit)
 		if err != nil {
 			return err
 		}
-		return a.outputToStdout(earthquakes)
+		return a.outputToStdout(collector.Output(earthquakes))
 	}
 
-	return collector.CollectByCountry(country, startTime, endTime, minMag, maxMag, limit, filename)
+	return collector.CollectByCountry(cmd.Context(), country, startTime, endTime, minMag, maxMag, limit, filename)
 }
 
-func (a *App) runCollectFaults(cmd *cobra.Command, args []string) error {
+func (a *App) runEventDetail(cmd *cobra.Command, args []string) error {
+	eventID, _ := cmd.Flags().GetString("id")
 	filename, _ := cmd.Flags().GetString("filename")
 	stdout, _ := cmd.Flags().GetBool("stdout")
 
 	// Initialize components with configuration
-	storage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
-	emscClient := api.NewEMSCClient(a.cfg.API.EMSC.BaseURL, a.cfg.API.EMSC.Timeout)
-	collector := collector.NewFaultCollector(emscClient, storage)
+	storage := a.newCollectionStorage(cmd)
+	usgsClient := a.newUSGSClient(cmd)
+	collector := collector.NewEarthquakeCollector(usgsClient, storage)
+	collector.SetProgressOutput(progressOutput(cmd, stdout))
 
 	if stdout {
-		faults, err := collector.CollectFaultsData()
+		detail, err := collector.CollectEventDetailData(cmd.Context(), eventID)
 		if err != nil {
 			return err
 		}
-		return a.outputToStdout(faults)
+		return a.outputToStdout(detail)
 	}
 
-	return collector.CollectFaults(filename)
+	return collector.CollectEventDetail(cmd.Context(), eventID, filename)
 }
 
-func (a *App) runUpdateFaults(cmd *cobra.Command, args []string) error {
+func (a *App) runQueryEarthquakes(cmd *cobra.Command, args []string) error {
+	specPath, _ := cmd.Flags().GetString("spec")
 	filename, _ := cmd.Flags().GetString("filename")
-	retries, _ := cmd.Flags().GetInt("retries")
-	retryDelay, _ := cmd.Flags().GetDuration("retry-delay")
 	stdout, _ := cmd.Flags().GetBool("stdout")
 
-	// Use configuration values if not provided
-	if retries == 0 {
-		retries = a.cfg.Collection.RetryAttempts
+	spec, err := collector.LoadQuerySpec(specPath)
+	if err != nil {
+		return err
 	}
-	if retryDelay == 0 {
+
+	// Use configuration values
+	if spec.Limit > a.cfg.Collection.MaxLimit {
+		spec.Limit = a.cfg.Collection.MaxLimit
+	}
+	if filename == "" {
+		filename = spec.Output.Filename
+	}
+
+	// Initialize components with configuration
+	storage := a.newCollectionStorage(cmd)
+	if spec.Output.Gzip {
+		storage.SetCompression(true)
+	}
+	if spec.Output.Compact {
+		storage.SetCompact(true)
+	}
+	usgsClient := a.newUSGSClient(cmd)
+	collector := collector.NewEarthquakeCollector(usgsClient, storage)
+	if err := a.configureEarthquakeCollector(cmd, collector); err != nil {
+		return err
+	}
+	if err := spec.Configure(collector); err != nil {
+		return err
+	}
+	// --no-save overrides a spec that prints its results
+	noSave, _ := cmd.Flags().GetBool("no-save")
+	if spec.Output.Stdout && !noSave {
+		collector.SetProgressOutput(progressOutput(cmd, true))
+	}
+
+	if stdout || (spec.Output.Stdout && !noSave) {
+		earthquakes, err := collector.CollectQueryData(cmd.Context(), spec, a.cfg.Collection.DefaultLimit)
+		if err != nil {
+			return err
+		}
+		return a.outputToStdout(collector.Output(earthquakes))
+	}
+
+	return collector.CollectQuery(cmd.Context(), spec, a.cfg.Collection.DefaultLimit, filename)
+}
+
+// withCollectionTimeout bounds a whole collection command, including retries and paginated
+// requests, by the configured collection timeout
+func (a *App) withCollectionTimeout(run func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		timeout := a.cfg.Collection.Timeout
+		if timeout <= 0 {
+			return run(cmd, args)
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+		defer cancel()
+		cmd.SetContext(ctx)
+
+		err := run(cmd, args)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("collection timed out after %v (collection.timeout): %w", timeout, err)
+		}
+		return err
+	}
+}
+
+// httpTimeout returns the --http-timeout override, or the configured timeout when the flag is not set
+func httpTimeout(cmd *cobra.Command, configured time.Duration) time.Duration {
+	if timeout, _ := cmd.Flags().GetDuration("http-timeout"); timeout > 0 {
+		return timeout
+	}
+	return configured
+}
+
+// userAgent returns the --user-agent override, or the configured User-Agent when the flag is not set
+func (a *App) userAgent(cmd *cobra.Command) string {
+	if userAgent, _ := cmd.Flags().GetString("user-agent"); userAgent != "" {
+		return userAgent
+	}
+	return a.configuredUserAgent()
+}
+
+// configuredUserAgent returns api.user_agent, or UserAgent when it is not set
+func (a *App) configuredUserAgent() string {
+	if a.cfg.API.UserAgent != "" {
+		return a.cfg.API.UserAgent
+	}
+	return UserAgent
+}
+
+// concurrencyLimiter returns the limiter shared by all API clients of the command, honoring --concurrency
+func (a *App) concurrencyLimiter(cmd *cobra.Command) *utils.ConcurrencyLimiter {
+	if a.limiter == nil {
+		max := a.cfg.Collection.MaxConcurrency
+		if concurrency, _ := cmd.Flags().GetInt("concurrency"); concurrency > 0 {
+			max = concurrency
+		}
+		a.limiter = utils.NewConcurrencyLimiter(max)
+	}
+	return a.limiter
+}
+
+// progressOutput returns where collectors write progress messages: nowhere with --quiet, stderr
+// when stdout carries the collected data, and stdout otherwise
+func progressOutput(cmd *cobra.Command, dataOnStdout bool) io.Writer {
+	if quiet, _ := cmd.Flags().GetBool("quiet"); quiet {
+		return io.Discard
+	}
+	if dataOnStdout {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// newUSGSClient creates a USGS client from the configuration, honoring --http-timeout, --user-agent, --concurrency
+// and --decode-retries. Endpoints after the first in api.usgs.base_urls become failover mirrors.
+func (a *App) newUSGSClient(cmd *cobra.Command) *api.USGSClient {
+	urls := a.cfg.API.USGS.URLs()
+	client := api.NewUSGSClient(urls[0], httpTimeout(cmd, a.cfg.API.USGS.Timeout))
+	client.SetUserAgent(a.userAgent(cmd))
+	client.SetConcurrencyLimiter(a.concurrencyLimiter(cmd))
+	client.SetLogger(a.logger)
+	if len(urls) > 1 {
+		client.SetMirrors(urls[1:])
+		client.SetFailoverHook(func(failed, next string, err error) {
+			fmt.Fprintf(progressOutput(cmd, true), "USGS endpoint %s failed: %v (trying %s)\n", failed, err, next)
+		})
+	}
+	if retries, _ := cmd.Flags().GetInt("decode-retries"); retries > 0 {
+		maxRetryDelay := max(a.cfg.Collection.MaxRetryDelay, a.cfg.Collection.RetryDelay)
+		strategy := utils.NewRetryStrategy(retries, a.cfg.Collection.RetryDelay, maxRetryDelay)
+		strategy.OnRetry = func(attempt int, delay time.Duration, err error) {
+			fmt.Fprintf(progressOutput(cmd, true), "Truncated USGS response: %v (retrying in %v)\n", err, delay.Round(time.Millisecond))
+			if a.metrics != nil {
+				a.metrics.RecordRetry()
+			}
+		}
+		client.SetDecodeRetry(strategy)
+	}
+	return client
+}
+
+// newEMSCClient creates an EMSC client from the configuration, honoring --http-timeout, --user-agent and --concurrency
+func (a *App) newEMSCClient(cmd *cobra.Command) *api.EMSCClient {
+	client := api.NewEMSCClient(a.cfg.API.EMSC.BaseURL, httpTimeout(cmd, a.cfg.API.EMSC.Timeout))
+	client.SetUserAgent(a.userAgent(cmd))
+	client.SetConcurrencyLimiter(a.concurrencyLimiter(cmd))
+	client.SetLogger(a.logger)
+	if a.cfg.API.EMSC.EventsURL != "" {
+		client.SetEventsURL(a.cfg.API.EMSC.EventsURL)
+	}
+	return client
+}
+
+// newJSONStorage creates JSON storage for the configured output directory and layout
+func (a *App) newJSONStorage() *storage.JSONStorage {
+	jsonStorage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
+	layout, _ := storage.ParseLayout(a.cfg.Storage.Layout)
+	jsonStorage.SetLayout(layout)
+	return jsonStorage
+}
+
+// newCollectionStorage creates the JSON storage used by collection commands, honoring --gzip,
+// --compact and --format. Temporary files orphaned by earlier runs that crashed mid-write are
+// removed first.
+func (a *App) newCollectionStorage(cmd *cobra.Command) *storage.JSONStorage {
+	jsonStorage := a.newJSONStorage()
+
+	stdout, _ := cmd.Flags().GetBool("stdout")
+	removed, err := jsonStorage.SweepTempFiles(storage.StaleTempAge)
+	if removed > 0 {
+		fmt.Fprintf(progressOutput(cmd, stdout), "Removed %d stale temporary file(s) from %s\n", removed, a.cfg.Storage.OutputDir)
+	}
+	if err != nil {
+		fmt.Fprintf(progressOutput(cmd, true), "Warning: %v\n", err)
+	}
+
+	jsonStorage.SetFormat(a.outputFormat)
+	if compress, _ := cmd.Flags().GetBool("gzip"); compress {
+		jsonStorage.SetCompression(true)
+	}
+	if compact, _ := cmd.Flags().GetBool("compact"); compact {
+		jsonStorage.SetCompact(true)
+	}
+	if info, _ := cmd.Flags().GetBool("collection-info"); info {
+		jsonStorage.SetCollectionInfo(Version)
+	}
+	return jsonStorage
+}
+
+// configureEarthquakeCollector applies the filters and output options requested through the earthquake command flags
+func (a *App) configureEarthquakeCollector(cmd *cobra.Command, c *collector.EarthquakeCollector) error {
+	c.SetCollectedHook(a.recordCollected)
+
+	stdout, _ := cmd.Flags().GetBool("stdout")
+	c.SetProgressOutput(progressOutput(cmd, stdout))
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	c.SetVerbose(verbose)
+
+	if noSave, _ := cmd.Flags().GetBool("no-save"); noSave {
+		if stdout {
+			return fmt.Errorf("--no-save cannot be combined with --stdout")
+		}
+		c.SetNoSave(true)
+	}
+
+	var filters collector.FilterSpec
+	filters.Alert, _ = cmd.Flags().GetStringSlice("alert")
+	for _, level := range filters.Alert {
+		if !collector.IsValidAlertLevel(level) {
+			return fmt.Errorf("invalid alert level: %s (must be one of %s)", level, strings.Join(collector.ValidAlertLevels, ", "))
+		}
+	}
+	filters.Tsunami, _ = cmd.Flags().GetBool("tsunami")
+	filters.PlaceContains, _ = cmd.Flags().GetString("place-contains")
+	filters.Network, _ = cmd.Flags().GetStringSlice("network")
+	filters.ExcludeRegion, _ = cmd.Flags().GetStringArray("exclude-region")
+	filters.MinSig, _ = cmd.Flags().GetInt("min-sig")
+	filters.MagType, _ = cmd.Flags().GetStringSlice("mag-type")
+	if err := c.AddFilters(filters); err != nil {
+		return fmt.Errorf("invalid --exclude-region: %w", err)
+	}
+
+	if summary, _ := cmd.Flags().GetBool("summary"); summary {
+		c.EnableSummary()
+	}
+
+	if baseline, _ := cmd.Flags().GetString("compare-to-baseline"); baseline != "" {
+		threshold, _ := cmd.Flags().GetFloat64("anomaly-threshold")
+		if threshold <= 0 {
+			return fmt.Errorf("invalid --anomaly-threshold: %v (must be positive)", threshold)
+		}
+		c.SetBaseline(baseline, threshold)
+	}
+
+	if deterministic, _ := cmd.Flags().GetBool("deterministic-name"); deterministic {
+		c.EnableDeterministicNames()
+	}
+
+	minQuality, _ := cmd.Flags().GetFloat64("min-quality")
+	if minQuality < 0 || minQuality > 1 {
+		return fmt.Errorf("invalid --min-quality: %.2f (must be between 0.0 and 1.0)", minQuality)
+	}
+	c.SetMinQuality(minQuality)
+
+	orderBy, _ := cmd.Flags().GetString("order-by")
+	if err := c.SetOrderBy(orderBy); err != nil {
+		return err
+	}
+
+	eventType, _ := cmd.Flags().GetString("event-type")
+	c.SetEventType(eventType)
+
+	fields, _ := cmd.Flags().GetStringSlice("fields")
+	if err := c.SetFields(fields); err != nil {
+		return err
+	}
+
+	catalog, _ := cmd.Flags().GetString("catalog")
+	if cmd.Flags().Changed("catalog") && strings.TrimSpace(catalog) == "" {
+		return fmt.Errorf("--catalog must not be empty")
+	}
+	if err := c.SetCatalog(catalog); err != nil {
+		return err
+	}
+
+	if notifications := a.cfg.Notifications; notifications.WebhookURL != "" {
+		notifier := utils.NewNotifier(notifications.WebhookURL, notifications.MinMagnitude, notifications.Timeout)
+
+		// Remember what was notified so each run over an overlapping window does not repeat it
+		path := notifications.SentCache
+		if path == "" {
+			path = filepath.Join(a.cfg.Storage.OutputDir, ".notifications_sent")
+		}
+		ttl := notifications.DedupeTTL
+		if ttl <= 0 {
+			ttl = utils.DefaultSeenTTL
+		}
+		sent, err := utils.LoadSeenCache(path, ttl)
+		if err != nil {
+			return fmt.Errorf("invalid notifications.sent_cache: %w", err)
+		}
+		notifier.SetSentCache(sent)
+		c.SetNotifier(notifier)
+	}
+
+	return nil
+}
+
+// addSeenCacheFlags adds the flags of the cross-run seen cache to a command
+func addSeenCacheFlags(cmd *cobra.Command) {
+	cmd.Flags().String("seen-cache", "", "File of earthquake IDs collected by earlier runs; those earthquakes are skipped and new ones are added")
+	cmd.Flags().Duration("seen-ttl", utils.DefaultSeenTTL, "How long --seen-cache remembers an ID after it was last collected")
+}
+
+// configureSeenCache loads the --seen-cache file, if given, into the collector
+func configureSeenCache(cmd *cobra.Command, c *collector.EarthquakeCollector) error {
+	path, _ := cmd.Flags().GetString("seen-cache")
+	if path == "" {
+		return nil
+	}
+	ttl, _ := cmd.Flags().GetDuration("seen-ttl")
+	cache, err := utils.LoadSeenCache(path, ttl)
+	if err != nil {
+		return fmt.Errorf("invalid --seen-cache: %w", err)
+	}
+	c.SetSeenCache(cache)
+	return nil
+}
+
+func (a *App) runCollectFaults(cmd *cobra.Command, args []string) error {
+	filename, _ := cmd.Flags().GetString("filename")
+	stdout, _ := cmd.Flags().GetBool("stdout")
+
+	var box *collector.Box
+	if value, _ := cmd.Flags().GetString("bbox"); value != "" {
+		parsed, err := collector.ParseBox(value)
+		if err != nil {
+			return fmt.Errorf("invalid --bbox: %w", err)
+		}
+		box = &parsed
+	}
+
+	// Initialize components with configuration
+	storage := a.newCollectionStorage(cmd)
+	emscClient := a.newEMSCClient(cmd)
+	collector := collector.NewFaultCollector(emscClient, storage)
+	collector.SetCollectedHook(a.recordCollected)
+	// With --stdout, progress goes to stderr to keep stdout reserved for the GeoJSON output
+	collector.SetProgressOutput(progressOutput(cmd, stdout))
+	if box != nil {
+		collector.SetBoundingBox(*box)
+	}
+
+	if stdout {
+		faults, err := collector.CollectFaultsData(cmd.Context())
+		if err != nil {
+			return err
+		}
+		return a.outputToStdout(faults)
+	}
+
+	return collector.CollectFaults(cmd.Context(), filename)
+}
+
+func (a *App) runUpdateFaults(cmd *cobra.Command, args []string) error {
+	filename, _ := cmd.Flags().GetString("filename")
+	retries, _ := cmd.Flags().GetInt("retries")
+	retryDelay, _ := cmd.Flags().GetDuration("retry-delay")
+	stdout, _ := cmd.Flags().GetBool("stdout")
+
+	// Use configuration values if not provided
+	if retries == 0 {
+		retries = a.cfg.Collection.RetryAttempts
+	}
+	if retryDelay == 0 {
 		retryDelay = a.cfg.Collection.RetryDelay
 	}
 
+	maxRetryDelay := a.cfg.Collection.MaxRetryDelay
+	if maxRetryDelay < retryDelay {
+		maxRetryDelay = retryDelay
+	}
+	strategy := utils.NewRetryStrategy(retries, retryDelay, maxRetryDelay)
+	if a.metrics != nil {
+		strategy.OnRetry = func(attempt int, delay time.Duration, err error) {
+			a.metrics.RecordRetry()
+		}
+	}
+
 	// Initialize components with configuration
-	storage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
-	emscClient := api.NewEMSCClient(a.cfg.API.EMSC.BaseURL, a.cfg.API.EMSC.Timeout)
+	storage := a.newCollectionStorage(cmd)
+	emscClient := a.newEMSCClient(cmd)
 	collector := collector.NewFaultCollector(emscClient, storage)
+	collector.SetCollectedHook(a.recordCollected)
+	// With --stdout, progress goes to stderr to keep stdout reserved for the GeoJSON output
+	collector.SetProgressOutput(progressOutput(cmd, stdout))
 
 	if stdout {
-		faults, err := collector.UpdateFaultsData(retries, retryDelay)
+		faults, err := collector.UpdateFaultsData(cmd.Context(), strategy)
 		if err != nil {
 			return err
 		}
 		return a.outputToStdout(faults)
 	}
 
-	return collector.UpdateFaults(filename, retries, retryDelay)
+	return collector.UpdateFaults(cmd.Context(), filename, strategy)
 }
 
 func (a *App) runValidate(cmd *cobra.Command, args []string) error {
 	dataType, _ := cmd.Flags().GetString("type")
 	file, _ := cmd.Flags().GetString("file")
+	fix, _ := cmd.Flags().GetBool("fix")
 
-	storage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
+	storage := a.newJSONStorage()
+
+	// check validates a file and, with --fix, repairs it if it fails
+	check := func(dataType, filename string) bool {
+		if validateFile(cmd.Context(), storage, dataType, filename) {
+			return true
+		}
+		return fix && repairFile(cmd.Context(), storage, dataType, filename)
+	}
 
 	if file != "" {
-		// Validate specific file
-		stats, err := storage.GetFileStats(dataType, file)
-		if err != nil {
-			return fmt.Errorf("failed to validate file: %w", err)
+		if dataType == "all" {
+			return fmt.Errorf("--file requires --type earthquakes or --type faults")
+		}
+		if !check(dataType, file) {
+			return utils.NewCollectionError(utils.ErrorTypeValidation, false, fmt.Errorf("validation failed for %s", file))
 		}
-		fmt.Printf("File validation successful: %+v\n", stats)
 		return nil
 	}
 
+	dataTypes := []string{dataType}
 	if dataType == "all" {
 		fmt.Println("Validating all data files:")
+		dataTypes = []string{"earthquakes", "faults"}
+	}
 
-		// Validate earthquake files
-		earthquakeFiles, err := storage.ListFiles("earthquakes")
+	failed := 0
+	for _, dt := range dataTypes {
+		files, err := storage.ListFiles(dt)
 		if err != nil {
-			fmt.Printf("Error listing earthquake files: %v\n", err)
-		} else {
-			fmt.Println("Earthquakes:")
-			for _, filename := range earthquakeFiles {
-				stats, err := storage.GetFileStats("earthquakes", filename)
-				if err != nil {
-					fmt.Printf("  ✗ %s: %v\n", filename, err)
-					continue
-				}
-				fmt.Printf("  ✓ %s: %d records\n", filename, stats["count"])
-			}
+			return fmt.Errorf("failed to list files: %w", err)
 		}
 
-		// Validate fault files
-		faultFiles, err := storage.ListFiles("faults")
-		if err != nil {
-			fmt.Printf("Error listing fault files: %v\n", err)
-		} else {
-			fmt.Println("Faults:")
-			for _, filename := range faultFiles {
-				stats, err := storage.GetFileStats("faults", filename)
-				if err != nil {
-					fmt.Printf("  ✗ %s: %v\n", filename, err)
-					continue
-				}
-				fmt.Printf("  ✓ %s: %d records\n", filename, stats["count"])
+		fmt.Printf("%s:\n", dataTypeLabels[dt])
+		if len(files) == 0 {
+			fmt.Println("  (no files)")
+		}
+		for _, filename := range files {
+			if !check(dt, filename) {
+				failed++
 			}
 		}
+	}
 
+	if failed > 0 {
+		return utils.NewCollectionError(utils.ErrorTypeValidation, false, fmt.Errorf("validation failed for %d file(s)", failed))
+	}
+	return nil
+}
+
+func (a *App) runVerify(cmd *cobra.Command, args []string) error {
+	dataType, _ := cmd.Flags().GetString("type")
+	file, _ := cmd.Flags().GetString("file")
+
+	storage := a.newJSONStorage()
+
+	if file != "" {
+		if dataType == "all" {
+			return fmt.Errorf("--file requires --type earthquakes or --type faults")
+		}
+		if !verifyFile(storage, dataType, file) {
+			return utils.NewCollectionError(utils.ErrorTypeValidation, false, fmt.Errorf("verification failed for %s", file))
+		}
 		return nil
 	}
 
-	// Validate specific type
-	files, err := storage.ListFiles(dataType)
-	if err != nil {
-		return fmt.Errorf("failed to list files: %w", err)
+	dataTypes := []string{dataType}
+	if dataType == "all" {
+		fmt.Println("Verifying all data files:")
+		dataTypes = []string{"earthquakes", "faults"}
 	}
 
-	for _, filename := range files {
-		stats, err := storage.GetFileStats(dataType, filename)
+	failed := 0
+	for _, dt := range dataTypes {
+		files, err := storage.ListFiles(dt)
 		if err != nil {
-			fmt.Printf("Failed to validate %s: %v\n", filename, err)
-			continue
+			return fmt.Errorf("failed to list files: %w", err)
+		}
+
+		fmt.Printf("%s:\n", dataTypeLabels[dt])
+		if len(files) == 0 {
+			fmt.Println("  (no files)")
+		}
+		for _, filename := range files {
+			if !verifyFile(storage, dt, filename) {
+				failed++
+			}
 		}
-		fmt.Printf("✓ %s: %d records\n", filename, stats["count"])
 	}
 
+	if failed > 0 {
+		return utils.NewCollectionError(utils.ErrorTypeValidation, false, fmt.Errorf("verification failed for %d file(s)", failed))
+	}
 	return nil
 }
 
+// verifyFile checks a single data file against its manifest, prints the outcome and reports whether
+// it passed. Files without a manifest are reported but do not fail verification.
+func verifyFile(jsonStorage *storage.JSONStorage, dataType, filename string) bool {
+	result, err := jsonStorage.VerifyFile(dataType, filename)
+	if err != nil {
+		fmt.Printf("  ✗ %s: %v\n", filename, err)
+		return false
+	}
+
+	switch result.Status {
+	case storage.VerifyOK:
+		fmt.Printf("  ✓ %s: %s\n", filename, result.Detail)
+		return true
+	case storage.VerifyNoManifest:
+		fmt.Printf("  - %s: no manifest\n", filename)
+		return true
+	default:
+		fmt.Printf("  ✗ %s: %s (%s)\n", filename, result.Status, result.Detail)
+		return false
+	}
+}
+
+// maxListedValidationErrors caps how many problems validate prints per file
+const maxListedValidationErrors = 10
+
+// validateFile validates a single data file, prints the outcome and reports whether it passed
+func validateFile(ctx context.Context, jsonStorage *storage.JSONStorage, dataType, filename string) bool {
+	records, errs, err := jsonStorage.ValidateFile(ctx, dataType, filename)
+	if err != nil {
+		fmt.Printf("  ✗ %s: %v\n", filename, err)
+		return false
+	}
+
+	if len(errs) == 0 {
+		fmt.Printf("  ✓ %s: %d records\n", filename, records)
+		return true
+	}
+
+	fmt.Printf("  ✗ %s: %d of %d records invalid\n", filename, countInvalidRecords(errs), records)
+	for i, validationErr := range errs {
+		if i == maxListedValidationErrors {
+			fmt.Printf("      ... and %d more\n", len(errs)-i)
+			break
+		}
+		fmt.Printf("      %s\n", validationErr.Error())
+	}
+	return false
+}
+
+// repairFile drops the unreadable and incomplete records of a file that failed validation and
+// validates it again, reporting whether it now passes
+func repairFile(ctx context.Context, jsonStorage *storage.JSONStorage, dataType, filename string) bool {
+	removed, err := jsonStorage.RepairFile(ctx, dataType, filename)
+	if err != nil {
+		fmt.Printf("    Repair failed: %v\n", err)
+		return false
+	}
+	if removed == 0 {
+		fmt.Printf("    No records to drop; the invalid values must be fixed by hand\n")
+	} else {
+		fmt.Printf("    Repaired: dropped %d record(s), original kept as %s.bak\n", removed, filename)
+	}
+	return validateFile(ctx, jsonStorage, dataType, filename)
+}
+
+// countInvalidRecords counts the records behind a list of validation errors. Records are told apart by ID;
+// a record without one is counted through its missing-id error.
+func countInvalidRecords(errs []utils.ValidationError) int {
+	ids := make(map[string]bool)
+	count := 0
+	for _, err := range errs {
+		switch {
+		case err.ID != "":
+			if !ids[err.ID] {
+				ids[err.ID] = true
+				count++
+			}
+		case err.Field == "id":
+			count++
+		}
+	}
+	return count
+}
+
 func (a *App) runStats(cmd *cobra.Command, args []string) error {
 	dataType, _ := cmd.Flags().GetString("type")
 	file, _ := cmd.Flags().GetString("file")
+	wide, _ := cmd.Flags().GetBool("wide")
+	allFiles, _ := cmd.Flags().GetBool("all-files")
 
-	storage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
+	storage := a.newJSONStorage()
 
 	if file != "" {
 		// Show stats for specific file
@@ -731,122 +1924,323 @@ func (a *App) runStats(cmd *cobra.Command, args []string) error {
 
 	if dataType == "all" {
 		fmt.Println("Statistics for all data:")
-
-		// Show earthquake stats
-		earthquakeFiles, err := storage.ListFiles("earthquakes")
-		if err != nil {
-			fmt.Printf("  Error listing earthquake files: %v\n", err)
-		} else {
-			fmt.Printf("  Earthquake files: %d\n", len(earthquakeFiles))
-			totalEarthquakeRecords := 0
-			for _, filename := range earthquakeFiles {
-				stats, err := storage.GetFileStats("earthquakes", filename)
-				if err != nil {
-					fmt.Printf("    Failed to get stats for %s: %v\n", filename, err)
-					continue
-				}
-				if count, ok := stats["count"].(int); ok {
-					totalEarthquakeRecords += count
-				}
+		for _, dt := range []string{"earthquakes", "faults"} {
+			fmt.Printf("\n%s:\n", dataTypeLabels[dt])
+			if err := printFileStats(storage, dt, wide); err != nil {
+				fmt.Printf("  Error listing %s files: %v\n", dt, err)
 			}
-			fmt.Printf("  Total earthquake records: %d\n", totalEarthquakeRecords)
 		}
-
-		// Show fault stats
-		faultFiles, err := storage.ListFiles("faults")
-		if err != nil {
-			fmt.Printf("  Error listing fault files: %v\n", err)
-		} else {
-			fmt.Printf("  Fault files: %d\n", len(faultFiles))
-			totalFaultRecords := 0
-			for _, filename := range faultFiles {
-				stats, err := storage.GetFileStats("faults", filename)
-				if err != nil {
-					fmt.Printf("    Failed to get stats for %s: %v\n", filename, err)
-					continue
-				}
-				if count, ok := stats["count"].(int); ok {
-					totalFaultRecords += count
-				}
+		if allFiles {
+			if err := printUniqueEarthquakes(cmd.Context(), storage); err != nil {
+				return err
 			}
-			fmt.Printf("  Total fault records: %d\n", totalFaultRecords)
+			return printUniqueFaults(cmd.Context(), storage)
 		}
-
 		return nil
 	}
 
 	// Show stats for specific type
-	files, err := storage.ListFiles(dataType)
+	fmt.Printf("Statistics for %s data:\n", dataType)
+	if err := printFileStats(storage, dataType, wide); err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+
+	if allFiles {
+		switch dataType {
+		case "earthquakes":
+			return printUniqueEarthquakes(cmd.Context(), storage)
+		case "faults":
+			return printUniqueFaults(cmd.Context(), storage)
+		}
+	}
+
+	return nil
+}
+
+// printUniqueEarthquakes prints the number of distinct earthquakes across all stored files
+func printUniqueEarthquakes(ctx context.Context, jsonStorage *storage.JSONStorage) error {
+	merged, err := jsonStorage.LoadAllEarthquakes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to merge earthquake files: %w", err)
+	}
+	fmt.Printf("  Unique earthquakes (all files): %d\n", len(merged.Features))
+	return nil
+}
+
+// printUniqueFaults prints the number of distinct faults across all stored files
+func printUniqueFaults(ctx context.Context, jsonStorage *storage.JSONStorage) error {
+	merged, err := jsonStorage.LoadAllFaults(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to merge fault files: %w", err)
+	}
+	fmt.Printf("  Unique faults (all files): %d\n", len(merged.Features))
+	return nil
+}
+
+func (a *App) runDiff(cmd *cobra.Command, args []string) error {
+	dataType, _ := cmd.Flags().GetString("type")
+	fileA, _ := cmd.Flags().GetString("a")
+	fileB, _ := cmd.Flags().GetString("b")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	if dataType != "earthquakes" {
+		return fmt.Errorf("diff only supports earthquakes, got %q", dataType)
+	}
+
+	storage := a.newJSONStorage()
+	before, err := storage.LoadEarthquakes(fileA)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", fileA, err)
+	}
+	after, err := storage.LoadEarthquakes(fileB)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", fileB, err)
+	}
+
+	diff := collector.DiffEarthquakes(before.Features, after.Features)
+	if jsonOutput {
+		return a.outputToStdout(diff)
+	}
+
+	fmt.Printf("Comparing %s -> %s\n", fileA, fileB)
+	diff.Write(os.Stdout)
+	return nil
+}
+
+func (a *App) runAggregate(cmd *cobra.Command, args []string) error {
+	dataType, _ := cmd.Flags().GetString("type")
+	bucketFlag, _ := cmd.Flags().GetString("bucket")
+	bandWidth, _ := cmd.Flags().GetFloat64("mag-band")
+	out, _ := cmd.Flags().GetString("out")
+
+	if dataType != "earthquakes" {
+		return fmt.Errorf("aggregate only supports earthquakes, got %q", dataType)
+	}
+	bucket, err := utils.ParseDuration(bucketFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --bucket value: %w", err)
+	}
+
+	earthquakes, err := a.newJSONStorage().LoadAllEarthquakes(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to load earthquakes: %w", err)
+	}
+
+	tally, err := collector.TallyRates(earthquakes.Features, bucket, bandWidth)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		return tally.WriteCSV(os.Stdout)
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", out, err)
+	}
+	if err := tally.WriteCSV(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", out, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", out, err)
+	}
+
+	fmt.Printf("Wrote %d windows x %d magnitude bands from %d earthquakes to %s\n", len(tally.Windows), len(tally.Bands), len(earthquakes.Features), out)
+	return nil
+}
+
+func (a *App) runList(cmd *cobra.Command, args []string) error {
+	dataType, _ := cmd.Flags().GetString("type")
+	wide, _ := cmd.Flags().GetBool("wide")
+
+	storage := a.newJSONStorage()
+
+	if dataType == "all" {
+		fmt.Println("Available data files:")
+		for _, dt := range []string{"earthquakes", "faults"} {
+			fmt.Printf("%s:\n", dataTypeLabels[dt])
+			infos, err := describeFiles(storage, dt)
+			if err != nil {
+				fmt.Printf("  Error listing %s files: %v\n", dt, err)
+				continue
+			}
+			printFileTable(os.Stdout, infos, wide)
+		}
+		return nil
+	}
+
+	infos, err := describeFiles(storage, dataType)
 	if err != nil {
 		return fmt.Errorf("failed to list files: %w", err)
 	}
 
-	fmt.Printf("Statistics for %s data:\n", dataType)
-	fmt.Printf("  Total files: %d\n", len(files))
+	fmt.Printf("Available %s files:\n", dataType)
+	printFileTable(os.Stdout, infos, wide)
+	return nil
+}
 
-	totalRecords := 0
+// dataTypeLabels holds the section headings for each data type
+var dataTypeLabels = map[string]string{
+	"earthquakes": "Earthquakes",
+	"faults":      "Faults",
+}
+
+// fileDescription pairs a file's metadata with the error hit while reading it
+type fileDescription struct {
+	info *storage.FileInfo
+	err  error
+}
+
+// describeFiles gathers metadata for every file of a data type
+func describeFiles(jsonStorage *storage.JSONStorage, dataType string) ([]fileDescription, error) {
+	files, err := jsonStorage.ListFiles(dataType)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions := make([]fileDescription, 0, len(files))
 	for _, filename := range files {
-		stats, err := storage.GetFileStats(dataType, filename)
-		if err != nil {
-			fmt.Printf("  Failed to get stats for %s: %v\n", filename, err)
-			continue
+		info, err := jsonStorage.DescribeFile(dataType, filename)
+		descriptions = append(descriptions, fileDescription{info: info, err: err})
+	}
+	return descriptions, nil
+}
+
+// printFileStats prints the file table and record totals for a data type
+func printFileStats(jsonStorage *storage.JSONStorage, dataType string, wide bool) error {
+	descriptions, err := describeFiles(jsonStorage, dataType)
+	if err != nil {
+		return err
+	}
+
+	printFileTable(os.Stdout, descriptions, wide)
+
+	totalRecords := 0
+	var totalSize int64
+	for _, d := range descriptions {
+		totalRecords += d.info.Count
+		totalSize += d.info.Size
+	}
+	fmt.Printf("  Total files: %d\n", len(descriptions))
+	fmt.Printf("  Total records: %d\n", totalRecords)
+	fmt.Printf("  Total size: %s\n", formatBytes(totalSize))
+	return nil
+}
+
+// printFileTable renders file metadata as aligned columns, with extra columns when wide is set
+func printFileTable(out io.Writer, descriptions []fileDescription, wide bool) {
+	if len(descriptions) == 0 {
+		fmt.Fprintln(out, "  (no files)")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if wide {
+		fmt.Fprintln(w, "  FILENAME\tCOUNT\tNEWEST EVENT\tSIZE\tOLDEST EVENT\tMAGNITUDE\tMODIFIED")
+	} else {
+		fmt.Fprintln(w, "  FILENAME\tCOUNT\tNEWEST EVENT\tSIZE")
+	}
+
+	for _, d := range descriptions {
+		info := d.info
+		count := strconv.Itoa(info.Count)
+		if d.err != nil {
+			count = "invalid"
 		}
-		if count, ok := stats["count"].(int); ok {
-			totalRecords += count
+
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s", info.Filename, count, formatEventTime(info.Newest), formatBytes(info.Size))
+		if wide {
+			magnitude := "-"
+			if info.Count > 0 && info.DataType == "earthquakes" {
+				magnitude = fmt.Sprintf("%.1f-%.1f", info.MinMagnitude, info.MaxMagnitude)
+			}
+			fmt.Fprintf(w, "\t%s\t%s\t%s", formatEventTime(info.Oldest), magnitude, info.ModTime.Format("2006-01-02 15:04:05"))
 		}
+		fmt.Fprintln(w)
 	}
-	fmt.Printf("  Total records: %d\n", totalRecords)
 
-	return nil
+	w.Flush()
+}
+
+// formatEventTime formats an event time in UTC, or "-" if unknown
+func formatEventTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format("2006-01-02 15:04:05")
 }
 
-func (a *App) runList(cmd *cobra.Command, args []string) error {
-	dataType, _ := cmd.Flags().GetString("type")
+// formatBytes formats a byte count using binary units
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
 
-	storage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
 
-	if dataType == "all" {
-		fmt.Println("Available data files:")
-		fmt.Println("Earthquakes:")
-		earthquakeFiles, err := storage.ListFiles("earthquakes")
-		if err != nil {
-			fmt.Printf("  Error listing earthquake files: %v\n", err)
-		} else {
-			for _, file := range earthquakeFiles {
-				fmt.Printf("  %s\n", file)
-			}
-		}
+func (a *App) runDedupe(cmd *cobra.Command, args []string) error {
+	dataType, _ := cmd.Flags().GetString("type")
+	filename, _ := cmd.Flags().GetString("filename")
+	removeOriginals, _ := cmd.Flags().GetBool("remove-originals")
 
-		fmt.Println("Faults:")
-		faultFiles, err := storage.ListFiles("faults")
-		if err != nil {
-			fmt.Printf("  Error listing fault files: %v\n", err)
-		} else {
-			for _, file := range faultFiles {
-				fmt.Printf("  %s\n", file)
-			}
-		}
-	} else {
-		files, err := storage.ListFiles(dataType)
-		if err != nil {
-			return fmt.Errorf("failed to list files: %w", err)
-		}
+	if dataType != "earthquakes" && dataType != "faults" {
+		return fmt.Errorf("invalid --type: %s (must be earthquakes or faults)", dataType)
+	}
 
-		fmt.Printf("Available %s files:\n", dataType)
-		for _, file := range files {
-			fmt.Printf("  %s\n", file)
+	storage := a.newJSONStorage()
+	result, err := storage.Dedupe(cmd.Context(), dataType, filename, removeOriginals)
+	if err != nil {
+		if result != nil && len(result.Removed) > 0 {
+			fmt.Printf("Removed %d of %d original files before failing\n", len(result.Removed), len(result.Files))
 		}
+		return fmt.Errorf("failed to deduplicate %s: %w", dataType, err)
 	}
 
+	fmt.Printf("Merged %d files with %d records into %s (%d unique, %d duplicates dropped)\n",
+		len(result.Files), result.Records, result.Filename, result.Unique, result.Records-result.Unique)
+	if removeOriginals {
+		fmt.Printf("Removed %d original files\n", len(result.Removed))
+	}
 	return nil
 }
 
+// parseOlderThan parses an --older-than retention window. It must be positive: a zero or
+// negative window puts the cutoff at or after now and would delete everything.
+func parseOlderThan(value string) (time.Duration, error) {
+	age, err := utils.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --older-than value: %w", err)
+	}
+	if age <= 0 {
+		return 0, fmt.Errorf("invalid --older-than value: %s (must be positive)", value)
+	}
+	return age, nil
+}
+
 func (a *App) runPurge(cmd *cobra.Command, args []string) error {
 	dataType, _ := cmd.Flags().GetString("type")
 	force, _ := cmd.Flags().GetBool("force")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	olderThan, _ := cmd.Flags().GetString("older-than")
+
+	storage := a.newJSONStorage()
 
-	storage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
+	if olderThan != "" {
+		age, err := parseOlderThan(olderThan)
+		if err != nil {
+			return err
+		}
+		return a.purgeOlderThan(cmd.Context(), storage, dataType, time.Now().Add(-age), force, dryRun)
+	}
 
 	if dryRun {
 		fmt.Println("DRY RUN - Files that would be deleted:")
@@ -939,52 +2333,295 @@ func (a *App) runPurge(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func (a *App) runHealth(cmd *cobra.Command, args []string) error {
-	fmt.Println("System Health Check:")
+// purgeOlderThan deletes only the data files collected before the cutoff
+func (a *App) purgeOlderThan(ctx context.Context, store *storage.JSONStorage, dataType string, cutoff time.Time, force, dryRun bool) error {
+	dataTypes := []string{dataType}
+	if dataType == "all" {
+		dataTypes = []string{"earthquakes", "faults"}
+	}
+
+	staleFiles := make(map[string][]string)
+	totalFiles := 0
+	for _, dt := range dataTypes {
+		files, err := store.FilesOlderThan(dt, cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to list stale %s files: %w", dt, err)
+		}
+		staleFiles[dt] = files
+		totalFiles += len(files)
+	}
+
+	if dryRun {
+		fmt.Printf("DRY RUN - Files older than %s that would be deleted:\n", cutoff.Format("2006-01-02 15:04:05"))
+		for _, dt := range dataTypes {
+			fmt.Printf("  %s files (%d):\n", dt, len(staleFiles[dt]))
+			for _, filename := range staleFiles[dt] {
+				fmt.Printf("    %s\n", filename)
+			}
+		}
+		return nil
+	}
+
+	// Show what will be deleted
+	fmt.Printf("About to delete %s data files older than %s:\n", dataType, cutoff.Format("2006-01-02 15:04:05"))
+	for _, dt := range dataTypes {
+		fmt.Printf("  %s files: %d\n", dt, len(staleFiles[dt]))
+	}
+
+	if totalFiles == 0 {
+		fmt.Println("No files to delete.")
+		return nil
+	}
+
+	// Ask for confirmation unless force flag is used
+	if !force {
+		fmt.Printf("\nThis will permanently delete %d files. Are you sure? (y/N): ", totalFiles)
+
+		var response string
+		if _, err := fmt.Scanln(&response); err != nil {
+			return fmt.Errorf("failed to read user input: %w", err)
+		}
+
+		if response != "y" && response != "Y" && response != "yes" && response != "YES" {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+	}
+
+	deleted := 0
+	for _, dt := range dataTypes {
+		removed, err := store.PurgeOlderThan(ctx, dt, cutoff)
+		deleted += len(removed)
+		if err != nil {
+			return fmt.Errorf("failed to purge %s files: %w", dt, err)
+		}
+	}
+
+	fmt.Printf("Successfully deleted %d files.\n", deleted)
+	return nil
+}
+
+// healthCheck represents the outcome of a single health check
+type healthCheck struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// databaseHealthCheck represents the outcome of the database health check
+type databaseHealthCheck struct {
+	healthCheck
+	Disabled bool `json:"disabled"`
+}
 
-	// Check USGS API
-	usgsClient := api.NewUSGSClient(a.cfg.API.USGS.BaseURL, 10*time.Second)
-	_, err := usgsClient.GetRecentEarthquakes(1)
+// freshnessHealthCheck represents the outcome of the storage freshness check
+type freshnessHealthCheck struct {
+	healthCheck
+	NewestFile string `json:"newest_file,omitempty"`
+	Age        string `json:"age,omitempty"`
+	MaxAge     string `json:"max_age"`
+}
+
+// healthReport aggregates the results of all health checks
+type healthReport struct {
+	USGS      healthCheck           `json:"usgs"`
+	EMSC      healthCheck           `json:"emsc"`
+	Storage   healthCheck           `json:"storage"`
+	Freshness *freshnessHealthCheck `json:"freshness,omitempty"`
+	Database  databaseHealthCheck   `json:"database"`
+}
+
+// newHealthCheck builds a health check result from an error
+func newHealthCheck(err error) healthCheck {
 	if err != nil {
-		fmt.Printf("  ✗ USGS API: %v\n", err)
-	} else {
-		fmt.Println("  ✓ USGS API: OK")
+		return healthCheck{OK: false, Error: err.Error()}
+	}
+	return healthCheck{OK: true}
+}
+
+// Healthy returns true if no critical health check failed
+func (r *healthReport) Healthy() bool {
+	return r.USGS.OK && r.EMSC.OK && r.Storage.OK &&
+		(r.Freshness == nil || r.Freshness.OK) &&
+		(r.Database.Disabled || r.Database.OK)
+}
+
+func (a *App) runHealth(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	maxDataAgeStr, _ := cmd.Flags().GetString("max-data-age")
+
+	var maxDataAge time.Duration
+	if maxDataAgeStr != "" {
+		var err error
+		maxDataAge, err = utils.ParseDuration(maxDataAgeStr)
+		if err != nil {
+			return fmt.Errorf("invalid --max-data-age value: %w", err)
+		}
+	}
+
+	report := a.checkHealth(cmd.Context(), maxDataAge)
+
+	if !jsonOutput {
+		printHealthReport(report)
+		return nil
+	}
+
+	if err := a.outputToStdout(report); err != nil {
+		return fmt.Errorf("failed to write health report: %w", err)
+	}
+
+	if !report.Healthy() {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("one or more health checks failed")
 	}
 
-	// Check EMSC API
+	return nil
+}
+
+// healthCheckTimeout bounds how long a single health check may take
+const healthCheckTimeout = 5 * time.Second
+
+// checkHealth runs all health checks concurrently and collects their results. The storage
+// freshness check only runs when maxDataAge is positive.
+func (a *App) checkHealth(ctx context.Context, maxDataAge time.Duration) *healthReport {
+	report := &healthReport{}
+	usgsURLs := a.cfg.API.USGS.URLs()
+	usgsClient := api.NewUSGSClient(usgsURLs[0], 10*time.Second)
+	usgsClient.SetMirrors(usgsURLs[1:])
+	usgsClient.SetUserAgent(a.configuredUserAgent())
 	emscClient := api.NewEMSCClient(a.cfg.API.EMSC.BaseURL, 10*time.Second)
-	_, err = emscClient.GetFaults()
-	if err != nil {
-		fmt.Printf("  ✗ EMSC API: %v\n", err)
+	emscClient.SetUserAgent(a.configuredUserAgent())
+	storage := a.newJSONStorage()
+
+	checks := []func(ctx context.Context) error{
+		func(ctx context.Context) error {
+			_, err := usgsClient.GetRecentEarthquakes(ctx, 1)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := emscClient.GetFaults(ctx)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := storage.ListFiles("earthquakes")
+			return err
+		},
+	}
+	if a.cfg.Database.Enabled {
+		checks = append(checks, a.checkDatabaseHealth)
+	}
+
+	results := runHealthChecks(ctx, healthCheckTimeout, checks)
+	report.USGS, report.EMSC, report.Storage = results[0], results[1], results[2]
+
+	if maxDataAge > 0 {
+		report.Freshness = checkDataFreshness(storage, maxDataAge, time.Now())
+	}
+
+	if a.cfg.Database.Enabled {
+		report.Database.healthCheck = results[3]
 	} else {
-		fmt.Println("  ✓ EMSC API: OK")
+		report.Database.healthCheck = healthCheck{OK: true}
+		report.Database.Disabled = true
+	}
+
+	return report
+}
+
+// runHealthChecks runs the checks concurrently, giving each its own timeout, and returns
+// their results in the order of the checks
+func runHealthChecks(ctx context.Context, timeout time.Duration, checks []func(ctx context.Context) error) []healthCheck {
+	results := make([]healthCheck, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runHealthCheck(ctx, timeout, check)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runHealthCheck runs a single check, reporting a failure if it does not finish within the
+// timeout even when the check itself ignores its context
+func runHealthCheck(ctx context.Context, timeout time.Duration, check func(ctx context.Context) error) healthCheck {
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- check(checkCtx)
+	}()
+
+	select {
+	case err := <-done:
+		return newHealthCheck(err)
+	case <-checkCtx.Done():
+		return newHealthCheck(fmt.Errorf("check did not finish within %s", timeout))
 	}
+}
+
+// checkDataFreshness checks that the newest earthquake file was collected within maxAge of now
+func checkDataFreshness(jsonStorage *storage.JSONStorage, maxAge time.Duration, now time.Time) *freshnessHealthCheck {
+	check := &freshnessHealthCheck{MaxAge: maxAge.String()}
 
-	// Check storage
-	storage := storage.NewJSONStorage(a.cfg.Storage.OutputDir)
-	_, err = storage.ListFiles("earthquakes")
+	newest, timestamp, err := jsonStorage.NewestFile("earthquakes")
 	if err != nil {
-		fmt.Printf("  ✗ Storage: %v\n", err)
-	} else {
-		fmt.Println("  ✓ Storage: OK")
+		check.healthCheck = newHealthCheck(err)
+		return check
+	}
+	if newest == "" {
+		check.healthCheck = newHealthCheck(fmt.Errorf("no earthquake files found"))
+		return check
 	}
 
-	// Check database if enabled
-	if a.cfg.Database.Enabled {
-		if err := a.checkDatabaseHealth(); err != nil {
-			fmt.Printf("  ✗ Database: %v\n", err)
+	age := now.Sub(timestamp).Round(time.Second)
+	check.NewestFile = newest
+	check.Age = age.String()
+	if age > maxAge {
+		check.healthCheck = newHealthCheck(fmt.Errorf("newest file %s is %s old (max %s)", newest, age, maxAge))
+		return check
+	}
+
+	check.healthCheck = healthCheck{OK: true}
+	return check
+}
+
+// printHealthReport prints a human-readable health report
+func printHealthReport(report *healthReport) {
+	fmt.Println("System Health Check:")
+	printHealthCheck("USGS API", report.USGS)
+	printHealthCheck("EMSC API", report.EMSC)
+	printHealthCheck("Storage", report.Storage)
+	if report.Freshness != nil {
+		if report.Freshness.OK {
+			fmt.Printf("  ✓ Storage freshness: OK (newest file %s old)\n", report.Freshness.Age)
 		} else {
-			fmt.Println("  ✓ Database: OK")
+			printHealthCheck("Storage freshness", report.Freshness.healthCheck)
 		}
-	} else {
+	}
+
+	if report.Database.Disabled {
 		fmt.Println("  ⚪ Database: Disabled")
+	} else {
+		printHealthCheck("Database", report.Database.healthCheck)
 	}
+}
 
-	return nil
+// printHealthCheck prints a single health check line
+func printHealthCheck(name string, check healthCheck) {
+	if check.OK {
+		fmt.Printf("  ✓ %s: OK\n", name)
+	} else {
+		fmt.Printf("  ✗ %s: %s\n", name, check.Error)
+	}
 }
 
 func (a *App) runVersion(cmd *cobra.Command, args []string) {
-	fmt.Println("QuakeWatch Scraper v1.2.1")
+	fmt.Println("QuakeWatch Scraper v" + Version)
 	fmt.Println("Go version: 1.24")
 	fmt.Println("Build date: " + time.Now().Format("2006-01-02"))
 }
@@ -1008,28 +2645,59 @@ func (a *App) runConfig(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func (a *App) runConfigShow(cmd *cobra.Command, args []string) error {
+	showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+
+	cfg := a.cfg
+	if !showSecrets {
+		cfg = cfg.Redacted()
+	}
+
+	data, err := yaml.Marshal(cfg.Settings())
+	if err != nil {
+		return fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+
+	fmt.Print(string(data))
+	return nil
+}
+
+// database returns the shared database connection pool, opening it on first use
+func (a *App) database() (*sqlx.DB, error) {
+	if a.db != nil {
+		return a.db, nil
+	}
+
+	db, err := storage.OpenDatabase(&a.cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	a.db = db
+	return a.db, nil
+}
+
+// closeDatabase closes the shared database connection pool if it was opened
+func (a *App) closeDatabase() {
+	if a.db != nil {
+		a.db.Close()
+		a.db = nil
+	}
+}
+
 // checkDatabaseHealth checks the database connectivity
-func (a *App) checkDatabaseHealth() error {
-	// Build connection string
-	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		a.cfg.Database.Host,
-		a.cfg.Database.Port,
-		a.cfg.Database.User,
-		a.cfg.Database.Password,
-		a.cfg.Database.Database,
-		a.cfg.Database.SSLMode,
-	)
-
-	// Try to connect to the database
-	db, err := sql.Open("postgres", connStr)
+func (a *App) checkDatabaseHealth(ctx context.Context) error {
+	db, err := a.database()
 	if err != nil {
 		return fmt.Errorf("failed to open database connection: %w", err)
 	}
-	defer db.Close()
 
 	// Set connection timeout
-	ctx, cancel := context.WithTimeout(context.Background(), a.cfg.Database.ConnectionTimeout)
-	defer cancel()
+	if a.cfg.Database.ConnectionTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.cfg.Database.ConnectionTimeout)
+		defer cancel()
+	}
 
 	// Test the connection
 	if err := db.PingContext(ctx); err != nil {
@@ -1047,7 +2715,7 @@ func (a *App) showBanner(cmd *cobra.Command, args []string) {
 	fmt.Println("║  A powerful tool for collecting earthquake and fault data    ║")
 	fmt.Println("║  from various geological sources and APIs.                   ║")
 	fmt.Println("║                                                              ║")
-	fmt.Println("║  Version: 1.2.1                                              ║")
+	fmt.Printf("║  Version: %-51s║\n", Version)
 	fmt.Println("║  Built with Go                                               ║")
 	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
 	fmt.Println()
@@ -1075,6 +2743,10 @@ func (a *App) newIntervalCmd() *cobra.Command {
 	// Add custom interval commands
 	cmd.AddCommand(a.newIntervalCustomCmd())
 
+	// Add interval status and stop commands
+	cmd.AddCommand(a.newIntervalStatusCmd())
+	cmd.AddCommand(a.newIntervalStopCmd())
+
 	return cmd
 }
 
@@ -1105,7 +2777,7 @@ func (a *App) newIntervalEarthquakesCmd() *cobra.Command {
 	a.addIntervalFlags(timeRangeCmd)
 	timeRangeCmd.Flags().String("start", "", "Start time (YYYY-MM-DD)")
 	timeRangeCmd.Flags().String("end", "", "End time (YYYY-MM-DD)")
-	timeRangeCmd.Flags().IntP("limit", "l", 1000, "Limit number of records")
+	timeRangeCmd.Flags().IntP("limit", "l", 1000, "Limit number of records (0 fetches all, paginating as needed)")
 	if err := timeRangeCmd.MarkFlagRequired("start"); err != nil {
 		panic(fmt.Sprintf("failed to mark start flag as required: %v", err))
 	}
@@ -1216,9 +2888,11 @@ func (a *App) newIntervalCustomCmd() *cobra.Command {
 // addIntervalFlags adds common interval flags to a command
 func (a *App) addIntervalFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("interval", "i", "1h", "Time interval (e.g., '5m', '1h', '24h')")
+	cmd.Flags().String("interval-jitter", "", "Wait a random delay of up to this long before each execution, so instances started together do not poll at once (e.g., '2m')")
 	cmd.Flags().String("max-runtime", "", "Maximum total runtime (e.g., '24h', '7d')")
 	cmd.Flags().Int("max-executions", 0, "Maximum number of executions")
-	cmd.Flags().String("backoff", "exponential", "Backoff strategy ('none', 'linear', 'exponential')")
+	cmd.Flags().Int("max-total-retries", 0, "Stop the run once this many retries have been used across all executions (0 for no limit)")
+	cmd.Flags().String("backoff", "exponential", "Backoff strategy ('none', 'linear', 'exponential', 'fibonacci')")
 	cmd.Flags().String("max-backoff", "30m", "Maximum backoff duration")
 	cmd.Flags().Bool("continue-on-error", true, "Continue running on individual command failures")
 	cmd.Flags().Bool("skip-empty", false, "Skip execution if no new data is found")
@@ -1226,6 +2900,108 @@ func (a *App) addIntervalFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolP("daemon", "d", false, "Run in daemon mode (background)")
 	cmd.Flags().String("pid-file", "", "PID file location")
 	cmd.Flags().String("log-file", "", "Log file location for daemon mode")
+	cmd.Flags().String("status-file", "", "Status file the scheduler updates after each execution")
+}
+
+// newIntervalStatusCmd creates the interval status command
+func (a *App) newIntervalStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of a running interval scheduler",
+		Long:  `Read the status file written by an interval scheduler and print its execution metrics.`,
+		RunE:  a.runIntervalStatus,
+	}
+
+	cmd.Flags().String("status-file", "", "Status file location (defaults to interval.status_file)")
+
+	return cmd
+}
+
+// newIntervalStopCmd creates the interval stop command
+func (a *App) newIntervalStopCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop a running interval daemon",
+		Long:  `Send SIGTERM to the interval daemon recorded in the PID file, wait for it to exit and remove the PID file.`,
+		RunE:  a.runIntervalStop,
+	}
+
+	cmd.Flags().String("pid-file", "", "PID file location (defaults to interval.pid_file)")
+	cmd.Flags().Duration("timeout", 10*time.Second, "How long to wait for the daemon to exit")
+
+	return cmd
+}
+
+func (a *App) runIntervalStop(cmd *cobra.Command, args []string) error {
+	pidFile, _ := cmd.Flags().GetString("pid-file")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if pidFile == "" {
+		pidFile = a.cfg.Interval.PIDFile
+	}
+	if pidFile == "" {
+		return fmt.Errorf("no PID file configured (set interval.pid_file or --pid-file)")
+	}
+
+	daemon := sched.NewDaemonManager(pidFile, "", log.New(os.Stderr, "", 0))
+	pid, err := daemon.Terminate(timeout)
+	if errors.Is(err, sched.ErrDaemonNotRunning) {
+		return fmt.Errorf("no interval daemon is running (PID file: %s)", pidFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Stopped interval daemon (PID %d)\n", pid)
+	return nil
+}
+
+// runIntervalStatus prints the status file written by an interval scheduler
+func (a *App) runIntervalStatus(cmd *cobra.Command, args []string) error {
+	statusFile, _ := cmd.Flags().GetString("status-file")
+	if statusFile == "" {
+		statusFile = a.cfg.Interval.StatusFile
+	}
+	if statusFile == "" {
+		return fmt.Errorf("no status file configured (set interval.status_file or --status-file)")
+	}
+
+	status, err := sched.ReadStatus(statusFile)
+	if err != nil {
+		return err
+	}
+
+	state := "stopped"
+	if status.IsRunning {
+		state = "running"
+	}
+
+	fmt.Printf("Interval scheduler: %s\n", state)
+	fmt.Printf("  Command:          %s\n", status.Command)
+	fmt.Printf("  Interval:         %v\n", status.Interval)
+	if !status.StartTime.IsZero() {
+		fmt.Printf("  Started:          %s\n", status.StartTime.Format(time.RFC3339))
+	}
+	fmt.Printf("  Executions:       %d", status.Executions)
+	if status.MaxExecutions > 0 {
+		fmt.Printf(" of %d", status.MaxExecutions)
+	}
+	fmt.Println()
+	fmt.Printf("  Failures:         %d\n", status.Failures)
+	fmt.Printf("  Retries:          %d", status.Retries)
+	if status.MaxTotalRetries > 0 {
+		fmt.Printf(" of %d", status.MaxTotalRetries)
+	}
+	fmt.Println()
+	fmt.Printf("  Success rate:     %.1f%%\n", status.SuccessRate)
+	fmt.Printf("  Average runtime:  %v\n", status.AverageRuntime)
+	if !status.LastExecution.IsZero() {
+		fmt.Printf("  Last execution:   %s\n", status.LastExecution.Format(time.RFC3339))
+	}
+	if !status.NextExecution.IsZero() {
+		fmt.Printf("  Next execution:   %s\n", status.NextExecution.Format(time.RFC3339))
+	}
+
+	return nil
 }
 
 // runIntervalRecentEarthquakes runs recent earthquakes collection at intervals
@@ -1253,7 +3029,8 @@ func (a *App) runIntervalTimeRangeEarthquakes(cmd *cobra.Command, args []string)
 	if end, _ := cmd.Flags().GetString("end"); end != "" {
 		cmdArgs = append(cmdArgs, "--end", end)
 	}
-	if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 {
+	// An explicit --limit 0 is passed on, since it means all earthquakes in the range
+	if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 || cmd.Flags().Changed("limit") {
 		cmdArgs = append(cmdArgs, "--limit", fmt.Sprintf("%d", limit))
 	}
 
@@ -1377,10 +3154,23 @@ func (a *App) runIntervalCustom(cmd *cobra.Command, args []string) error {
 
 // buildIntervalConfig builds the interval configuration from command flags
 func (a *App) buildIntervalConfig(cmd *cobra.Command) *config.IntervalConfig {
+	return intervalConfigFrom(cmd, a.cfg)
+}
+
+// intervalConfigFrom builds the interval configuration from command flags, falling back to cfg
+// for settings that were not given. The interval, jitter and continue-on-error come from cfg
+// unless their flags were passed, so a configuration reload can change them.
+func intervalConfigFrom(cmd *cobra.Command, cfg *config.Config) *config.IntervalConfig {
 	intervalStr, _ := cmd.Flags().GetString("interval")
 	interval, _ := time.ParseDuration(intervalStr)
-	if interval == 0 {
-		interval = a.cfg.Interval.DefaultInterval
+	if (interval == 0 || !cmd.Flags().Changed("interval")) && cfg.Interval.DefaultInterval > 0 {
+		interval = cfg.Interval.DefaultInterval
+	}
+
+	jitterStr, _ := cmd.Flags().GetString("interval-jitter")
+	jitter, _ := time.ParseDuration(jitterStr)
+	if !cmd.Flags().Changed("interval-jitter") {
+		jitter = cfg.Interval.Jitter
 	}
 
 	maxRuntimeStr, _ := cmd.Flags().GetString("max-runtime")
@@ -1388,40 +3178,55 @@ func (a *App) buildIntervalConfig(cmd *cobra.Command) *config.IntervalConfig {
 
 	maxExecutions, _ := cmd.Flags().GetInt("max-executions")
 	if maxExecutions == 0 {
-		maxExecutions = a.cfg.Interval.MaxExecutions
+		maxExecutions = cfg.Interval.MaxExecutions
+	}
+
+	maxTotalRetries, _ := cmd.Flags().GetInt("max-total-retries")
+	if maxTotalRetries == 0 {
+		maxTotalRetries = cfg.Interval.MaxTotalRetries
 	}
 
 	backoffStrategy, _ := cmd.Flags().GetString("backoff")
 	maxBackoffStr, _ := cmd.Flags().GetString("max-backoff")
 	maxBackoff, _ := time.ParseDuration(maxBackoffStr)
 	if maxBackoff == 0 {
-		maxBackoff = a.cfg.Interval.MaxBackoff
+		maxBackoff = cfg.Interval.MaxBackoff
 	}
 
 	continueOnError, _ := cmd.Flags().GetBool("continue-on-error")
+	if !cmd.Flags().Changed("continue-on-error") {
+		continueOnError = cfg.Interval.ContinueOnError
+	}
 	skipEmpty, _ := cmd.Flags().GetBool("skip-empty")
 
 	healthCheckIntervalStr, _ := cmd.Flags().GetString("health-check-interval")
 	healthCheckInterval, _ := time.ParseDuration(healthCheckIntervalStr)
 	if healthCheckInterval == 0 {
-		healthCheckInterval = a.cfg.Interval.HealthCheckInterval
+		healthCheckInterval = cfg.Interval.HealthCheckInterval
 	}
 
 	daemonMode, _ := cmd.Flags().GetBool("daemon")
 	pidFile, _ := cmd.Flags().GetString("pid-file")
 	if pidFile == "" {
-		pidFile = a.cfg.Interval.PIDFile
+		pidFile = cfg.Interval.PIDFile
 	}
 
 	logFile, _ := cmd.Flags().GetString("log-file")
 	if logFile == "" {
-		logFile = a.cfg.Interval.LogFile
+		logFile = cfg.Interval.LogFile
+	}
+
+	statusFile, _ := cmd.Flags().GetString("status-file")
+	if statusFile == "" {
+		statusFile = cfg.Interval.StatusFile
 	}
 
 	return &config.IntervalConfig{
 		DefaultInterval:     interval,
+		Jitter:              jitter,
 		MaxRuntime:          maxRuntime,
 		MaxExecutions:       maxExecutions,
+		MaxTotalRetries:     maxTotalRetries,
 		BackoffStrategy:     backoffStrategy,
 		MaxBackoff:          maxBackoff,
 		ContinueOnError:     continueOnError,
@@ -1430,7 +3235,24 @@ func (a *App) buildIntervalConfig(cmd *cobra.Command) *config.IntervalConfig {
 		DaemonMode:          daemonMode,
 		PIDFile:             pidFile,
 		LogFile:             logFile,
+		StatusFile:          statusFile,
+	}
+}
+
+// reloadIntervalConfig re-reads the configuration file, applies --set overrides and hands the
+// resulting interval settings to a running scheduler. Flags given on the command line still win.
+func (a *App) reloadIntervalConfig(cmd *cobra.Command, scheduler *sched.IntervalScheduler) error {
+	configPath, _ := cmd.Flags().GetString("config")
+	cfg, err := config.ReloadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	overrides, _ := cmd.Flags().GetStringArray("set")
+	if cfg, err = cfg.ApplyOverrides(overrides); err != nil {
+		return err
 	}
+	return scheduler.Reload(intervalConfigFrom(cmd, cfg))
 }
 
 // runIntervalCommand runs a command at intervals using the scheduler
@@ -1463,6 +3285,8 @@ func (a *App) runIntervalCommand(cmd *cobra.Command, intervalConfig *config.Inte
 		executor.SetBackoffStrategy(sched.NewLinearBackoff(5 * time.Second))
 	case "exponential":
 		executor.SetBackoffStrategy(sched.NewExponentialBackoff(5*time.Second, intervalConfig.MaxBackoff))
+	case "fibonacci":
+		executor.SetBackoffStrategy(sched.NewFibonacciBackoff(5*time.Second, intervalConfig.MaxBackoff))
 	default:
 		executor.SetBackoffStrategy(sched.NewExponentialBackoff(5*time.Second, intervalConfig.MaxBackoff))
 	}
@@ -1481,6 +3305,26 @@ func (a *App) runIntervalCommand(cmd *cobra.Command, intervalConfig *config.Inte
 		scheduler.Stop()
 	}()
 
+	// Reload the configuration on SIGHUP. Each execution is a new process that reads the
+	// configuration itself, so only the scheduler's own settings need to be applied here.
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	defer signal.Stop(hupChan)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hupChan:
+				logger.Printf("Received SIGHUP, reloading configuration...")
+				if err := a.reloadIntervalConfig(cmd, scheduler); err != nil {
+					logger.Printf("Configuration reload failed, keeping the current settings: %v", err)
+				}
+			}
+		}
+	}()
+
 	// Start the scheduler
 	if intervalConfig.DaemonMode {
 		logger.Printf("Starting interval scheduler in daemon mode")
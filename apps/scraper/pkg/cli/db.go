@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"quakewatch-scraper/internal/storage"
+)
+
+// newDBCmd creates the database command
+func (a *App) newDBCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the PostgreSQL database",
+		Long:  `Load and maintain earthquake and fault data in the PostgreSQL database.`,
+	}
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create the database if needed and apply pending migrations",
+		Long: `Create the configured database if it does not exist, connecting to the postgres maintenance
+database to do so, then apply any pending migrations. Running it against an initialized database
+changes nothing, so it is safe to repeat.`,
+		Args: cobra.NoArgs,
+		RunE: a.runDBInit,
+	}
+	initCmd.Flags().Bool("force", false, "Drop and recreate the database first, deleting all of its data; fails while other sessions are connected")
+	initCmd.Flags().String("migrations-path", "", "Read migrations from this directory instead of the ones built into the binary; relative paths are resolved next to the executable")
+	cmd.AddCommand(initCmd)
+
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import JSON data files into the database",
+		Long:  `Load every JSON data file of a type and upsert its records into the database, without re-fetching them from the APIs.`,
+		RunE:  a.runDBImport,
+	}
+	importCmd.Flags().StringP("type", "t", "earthquakes", "Data type (earthquakes, faults, all)")
+	importCmd.Flags().String("dir", "", "Data directory containing earthquakes/ and faults/ (default: storage.output_dir)")
+	cmd.AddCommand(importCmd)
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete records older than a retention window",
+		Long:  `Delete earthquakes that occurred, and collection logs started, longer ago than the retention window, keeping the tables bounded.`,
+		RunE:  a.runDBPrune,
+	}
+	pruneCmd.Flags().String("older-than", "", "Retention window; older records are deleted (e.g. '1y', '90d')")
+	pruneCmd.Flags().Bool("dry-run", false, "Show how many records would be deleted without deleting them")
+	if err := pruneCmd.MarkFlagRequired("older-than"); err != nil {
+		panic(fmt.Sprintf("failed to mark older-than flag as required: %v", err))
+	}
+	cmd.AddCommand(pruneCmd)
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage database schema migrations",
+	}
+	migrateCmd.PersistentFlags().String("migrations-path", "", "Read migrations from this directory instead of the ones built into the binary; relative paths are resolved next to the executable")
+	migrateCmd.AddCommand(&cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: a.withMigrationManager(func(cmd *cobra.Command, args []string, m *storage.MigrationManager) error {
+			return m.MigrateUp()
+		}),
+	})
+	migrateCmd.AddCommand(&cobra.Command{
+		Use:   "down",
+		Short: "Roll back all migrations",
+		Args:  cobra.NoArgs,
+		RunE: a.withMigrationManager(func(cmd *cobra.Command, args []string, m *storage.MigrationManager) error {
+			return m.MigrateDown()
+		}),
+	})
+	migrateCmd.AddCommand(&cobra.Command{
+		Use:   "to <version>",
+		Short: "Migrate up or down to a specific version",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.withMigrationManager(func(cmd *cobra.Command, args []string, m *storage.MigrationManager) error {
+			version, err := parseMigrationVersion(args[0])
+			if err != nil {
+				return err
+			}
+			return m.MigrateToVersion(version)
+		}),
+	})
+	migrateCmd.AddCommand(&cobra.Command{
+		Use:   "force <version>",
+		Short: "Set the migration version without running migrations, clearing the dirty flag",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.withMigrationManager(func(cmd *cobra.Command, args []string, m *storage.MigrationManager) error {
+			version, err := parseMigrationVersion(args[0])
+			if err != nil {
+				return err
+			}
+			return m.ForceVersion(version)
+		}),
+	})
+	migrateCmd.AddCommand(&cobra.Command{
+		Use:   "version",
+		Short: "Show the current migration version",
+		Args:  cobra.NoArgs,
+		RunE: a.withMigrationManager(func(cmd *cobra.Command, args []string, m *storage.MigrationManager) error {
+			version, dirty, err := m.GetVersion()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Migration version: %d", version)
+			if dirty {
+				fmt.Print(" (dirty)")
+			}
+			fmt.Println()
+			return nil
+		}),
+	})
+	cmd.AddCommand(migrateCmd)
+
+	return cmd
+}
+
+// withMigrationManager runs fn with a migration manager for the configured database and migrations directory
+func (a *App) withMigrationManager(fn func(cmd *cobra.Command, args []string, m *storage.MigrationManager) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		dbConfig := a.cfg.Database
+		if path, _ := cmd.Flags().GetString("migrations-path"); path != "" {
+			dbConfig.MigrationsPath = path
+		}
+
+		m, err := storage.NewMigrationManager(&dbConfig)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		return fn(cmd, args, m)
+	}
+}
+
+// parseMigrationVersion parses a migration version argument
+func parseMigrationVersion(arg string) (uint, error) {
+	version, err := strconv.ParseUint(arg, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid migration version: %s", arg)
+	}
+	return uint(version), nil
+}
+
+func (a *App) runDBInit(cmd *cobra.Command, args []string) error {
+	force, _ := cmd.Flags().GetBool("force")
+	name := a.cfg.Database.Database
+
+	created, err := storage.EnsureDatabase(cmd.Context(), &a.cfg.Database, force)
+	if err != nil {
+		return err
+	}
+	if created {
+		fmt.Printf("Created database %s\n", name)
+	} else {
+		fmt.Printf("Database %s already exists\n", name)
+	}
+
+	return a.withMigrationManager(func(cmd *cobra.Command, args []string, m *storage.MigrationManager) error {
+		if err := m.MigrateUp(); err != nil {
+			return err
+		}
+		version, _, err := m.GetVersion()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Database %s is at migration version %d\n", name, version)
+		return nil
+	})(cmd, args)
+}
+
+func (a *App) runDBImport(cmd *cobra.Command, args []string) error {
+	dataType, _ := cmd.Flags().GetString("type")
+	dir, _ := cmd.Flags().GetString("dir")
+	if dir == "" {
+		dir = a.cfg.Storage.OutputDir
+	}
+
+	var dataTypes []string
+	switch dataType {
+	case "earthquakes", "faults":
+		dataTypes = []string{dataType}
+	case "all":
+		dataTypes = []string{"earthquakes", "faults"}
+	default:
+		return fmt.Errorf("unknown data type: %s (must be earthquakes, faults or all)", dataType)
+	}
+
+	db, err := storage.NewPostgreSQLStorage(&a.cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	source := storage.NewJSONStorage(dir)
+	layout, _ := storage.ParseLayout(a.cfg.Storage.Layout)
+	source.SetLayout(layout)
+	for _, dt := range dataTypes {
+		fmt.Printf("Importing %s from %s...\n", dt, dir)
+		result, err := db.ImportJSON(cmd.Context(), source, dt)
+		if result != nil && result.Files > 0 {
+			fmt.Printf("  Files: %d\n", result.Files)
+			fmt.Printf("  Inserted: %d\n", result.Inserted)
+			fmt.Printf("  Updated: %d\n", result.Updated)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", dt, err)
+		}
+		if result.Files == 0 {
+			fmt.Printf("  No %s files found\n", dt)
+		}
+	}
+
+	return nil
+}
+
+func (a *App) runDBPrune(cmd *cobra.Command, args []string) error {
+	olderThan, _ := cmd.Flags().GetString("older-than")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	age, err := parseOlderThan(olderThan)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-age)
+
+	db, err := storage.NewPostgreSQLStorage(&a.cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	earthquakes, logs, err := db.CountOlderThan(cmd.Context(), cutoff)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Records older than %s:\n", cutoff.Format(time.RFC3339))
+	fmt.Printf("  Earthquakes: %d\n", earthquakes)
+	fmt.Printf("  Collection logs: %d\n", logs)
+
+	if dryRun {
+		fmt.Println("DRY RUN - nothing was deleted")
+		return nil
+	}
+	if earthquakes+logs == 0 {
+		return nil
+	}
+
+	deleted, err := db.PruneOlderThan(cmd.Context(), cutoff)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deleted %d records\n", deleted)
+	return nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"errors"
+
+	"quakewatch-scraper/internal/utils"
+)
+
+// Exit codes returned by the scraper, so automation can tell failure classes apart
+const (
+	ExitOK = 0
+	// ExitFailure is any failure not classified below
+	ExitFailure = 1
+	// ExitConfig is an invalid configuration file, --set override or command line
+	ExitConfig = 2
+	// ExitAPI is a network, timeout, rate limit, HTTP status or response decoding failure
+	ExitAPI = 3
+	// ExitStorage is a failure to write data files or reach the database
+	ExitStorage = 4
+	// ExitValidation is data rejected by --min-quality, validate or verify
+	ExitValidation = 5
+)
+
+// ExitCode returns the process exit code for an error returned by App.Run
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var collectionErr *utils.CollectionError
+	if !errors.As(err, &collectionErr) {
+		return ExitFailure
+	}
+	switch collectionErr.Type {
+	case utils.ErrorTypeConfig:
+		return ExitConfig
+	case utils.ErrorTypeNetwork, utils.ErrorTypeTimeout, utils.ErrorTypeRateLimit,
+		utils.ErrorTypeServer, utils.ErrorTypeClient, utils.ErrorTypeDecode:
+		return ExitAPI
+	case utils.ErrorTypeStorage:
+		return ExitStorage
+	case utils.ErrorTypeValidation:
+		return ExitValidation
+	default:
+		return ExitFailure
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"quakewatch-scraper/internal/models"
+	"quakewatch-scraper/internal/utils"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, ExitOK},
+		{"unclassified", errors.New("boom"), ExitFailure},
+		{"config", utils.NewCollectionError(utils.ErrorTypeConfig, false, errors.New("bad config")), ExitConfig},
+		{"network", utils.NewCollectionError(utils.ErrorTypeNetwork, true, errors.New("refused")), ExitAPI},
+		{"timeout", utils.NewCollectionError(utils.ErrorTypeTimeout, true, errors.New("timeout")), ExitAPI},
+		{"rate limit", utils.NewCollectionError(utils.ErrorTypeRateLimit, true, errors.New("429")), ExitAPI},
+		{"server", utils.NewCollectionError(utils.ErrorTypeServer, true, errors.New("503")), ExitAPI},
+		{"client", utils.NewCollectionError(utils.ErrorTypeClient, false, errors.New("400")), ExitAPI},
+		{"decode", utils.NewCollectionError(utils.ErrorTypeDecode, false, errors.New("bad json")), ExitAPI},
+		{"storage", utils.NewCollectionError(utils.ErrorTypeStorage, false, errors.New("disk full")), ExitStorage},
+		{"validation", utils.NewCollectionError(utils.ErrorTypeValidation, false, errors.New("low quality")), ExitValidation},
+		{"unknown type", utils.NewCollectionError(utils.ErrorType("other"), false, errors.New("other")), ExitFailure},
+		{"wrapped", fmt.Errorf("failed to save earthquakes: %w", utils.NewCollectionError(utils.ErrorTypeStorage, false, errors.New("disk full"))), ExitStorage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApp_RunExitCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("minmagnitude") == "9.9" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		// An earthquake without coordinates fails validation
+		json.NewEncoder(w).Encode(models.USGSResponse{
+			Type:     "FeatureCollection",
+			Features: []models.Earthquake{{Type: "Feature", ID: "us1"}},
+		})
+	}))
+	defer server.Close()
+
+	// A file where the output directory should be makes every save fail
+	blocked := filepath.Join(t.TempDir(), "blocked")
+	if err := os.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		setup []string
+		want  int
+	}{
+		{"unknown flag", []string{"earthquakes", "recent", "--no-such-flag"}, nil, ExitConfig},
+		{"missing required flag", []string{"earthquakes", "time-range", "--start", "2024-01-01"}, nil, ExitConfig},
+		{"conflicting flags", []string{"earthquakes", "recent", "--verbose", "--quiet"}, nil, ExitConfig},
+		{"invalid override", []string{"earthquakes", "recent"}, []string{"--set", "collection.max_limit=many"}, ExitConfig},
+		{"API failure", []string{"earthquakes", "magnitude", "--min", "9.9", "--max", "10"}, nil, ExitAPI},
+		{"storage failure", []string{"earthquakes", "recent"}, []string{"--set", "storage.output_dir=" + blocked}, ExitStorage},
+		{"validation failure", []string{"earthquakes", "recent", "--min-quality", "1.0"}, nil, ExitValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			app := NewApp()
+			app.rootCmd.SetOut(io.Discard)
+			app.rootCmd.SetErr(io.Discard)
+			args := append([]string{"quakewatch-scraper"}, tt.args...)
+			args = append(args, "--quiet",
+				"--config", filepath.Join(outputDir, "missing.yaml"),
+				"--set", "api.usgs.base_url="+server.URL,
+				"--set", "storage.output_dir="+outputDir,
+			)
+			args = append(args, tt.setup...)
+
+			_, err := captureStdout(t, func() error { return app.Run(args) })
+			if got := ExitCode(err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"quakewatch-scraper/internal/server"
+	"quakewatch-scraper/internal/storage"
+)
+
+// serveShutdownTimeout is how long in-flight requests get to finish when the server stops
+const serveShutdownTimeout = 10 * time.Second
+
+// newServeCmd creates the serve command
+func (a *App) newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve collected data over a local HTTP API",
+		Long: `Start an HTTP server answering queries over the collected earthquakes, read from PostgreSQL when
+database.enabled is set and from the JSON data files otherwise.
+
+  GET /earthquakes?min_mag=&max_mag=&start=&end=&bbox=&limit=
+
+start and end are YYYY-MM-DD dates or RFC 3339 times (end is exclusive), bbox is
+minLon,minLat,maxLon,maxLat, and the response is a GeoJSON FeatureCollection, most recent first.`,
+		Args: cobra.NoArgs,
+		RunE: a.runServe,
+	}
+	cmd.Flags().String("addr", "127.0.0.1:8080", "Address to listen on")
+	return cmd
+}
+
+// runServe serves the HTTP API until interrupted
+func (a *App) runServe(cmd *cobra.Command, args []string) error {
+	addr, _ := cmd.Flags().GetString("addr")
+
+	var source server.EarthquakeSource
+	backend := a.cfg.Storage.OutputDir
+	if a.cfg.Database.Enabled {
+		db, err := storage.NewPostgreSQLStorage(&a.cfg.Database)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+		source, backend = db, "PostgreSQL"
+	} else {
+		source = a.newJSONStorage()
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           server.NewHandler(source, a.cfg.Collection.DefaultLimit, a.cfg.Collection.MaxLimit),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- srv.ListenAndServe()
+	}()
+	fmt.Printf("Serving earthquakes from %s on http://%s\n", backend, addr)
+
+	select {
+	case err := <-errChan:
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	fmt.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+	return nil
+}